@@ -5,6 +5,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
 	"github.com/rs/xid"
 	"github.com/stretchr/testify/assert"
@@ -17,6 +18,7 @@ type assertionData struct {
 	stopActions    int
 	stopAllActions int
 	lightActions   []action.LightAction
+	schedule       *pkg.ControllerSchedule
 }
 
 // AssertWaterActions is used to check that all expected WaterMessages were received, then reset recorded info
@@ -81,3 +83,20 @@ func (c *Controller) ClearLightActions() {
 	c.assertionData.lightActions = []action.LightAction{}
 	c.assertionData.Unlock()
 }
+
+// GetSchedule returns the most recent ControllerSchedule received by the controller, or nil if none was received
+func (c *Controller) GetSchedule() *pkg.ControllerSchedule {
+	c.assertionData.Lock()
+	defer c.assertionData.Unlock()
+	return c.assertionData.schedule
+}
+
+// ScheduleVersion returns the version of the ControllerSchedule that the controller has. This is reported in the
+// controller info so the server can re-send the schedule if it is out of date
+func (c *Controller) ScheduleVersion() string {
+	schedule := c.GetSchedule()
+	if schedule == nil {
+		return pkg.ControllerScheduleVersionNone
+	}
+	return schedule.Version
+}
@@ -322,9 +322,13 @@ func (c *Controller) PublishLog(topicPrefix string, msg []byte) error {
 // MQTT topic that the server listens on for controller info updates.
 func (c *Controller) PublishControllerInfo() error {
 	topic := fmt.Sprintf("%s/data/info", c.TopicPrefix)
-	msg := fmt.Sprintf("info mac=\"%s\",ip=\"%s\",version=\"%s\"", "AA:BB:CC:DD:EE:FF", "192.168.1.42", "test-version")
+	scheduleVersion := c.ScheduleVersion()
+	msg := fmt.Sprintf(
+		"info mac=\"%s\",ip=\"%s\",version=\"%s\",schedule_version=\"%s\"",
+		"AA:BB:CC:DD:EE:FF", "192.168.1.42", "test-version", scheduleVersion,
+	)
 
-	logger := c.pubLogger.With("topic", topic)
+	logger := c.pubLogger.With("topic", topic, "schedule_version", scheduleVersion)
 	logger.Info("publishing controller info")
 	err := c.mqttClient.Publish(context.Background(), topic, []byte(msg))
 	if err != nil {
@@ -422,6 +426,8 @@ func (c *Controller) getHandlerForTopic(topic string) paho.MessageHandler {
 		return c.stopAllHandler(topic)
 	case "light":
 		return c.lightHandler(topic)
+	case "schedule":
+		return c.scheduleHandler(topic)
 	default:
 		return paho.MessageHandler(func(_ paho.Client, msg paho.Message) {
 			c.subLogger.With(
@@ -440,6 +446,7 @@ func (c *Controller) topics() ([]string, error) {
 		mqtt.StopTopic,
 		mqtt.StopAllTopic,
 		mqtt.LightTopic,
+		mqtt.ScheduleTopic,
 	}
 	for _, templateFunc := range templateFuncs {
 		topic, err := templateFunc(c.TopicPrefix)
@@ -4,10 +4,13 @@ package controller
 import (
 	"bytes"
 	"fmt"
-	"html/template"
 	"log/slog"
+	"net/url"
 	"os"
 	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
 	"time"
 
 	"github.com/AlecAivazis/survey/v2"
@@ -23,6 +26,23 @@ const (
 
 #define MQTT_ADDRESS "{{ .MQTTConfig.Broker }}"
 #define MQTT_PORT {{ .MQTTConfig.Port }}
+{{ if .MQTTConfig.Username }}
+#define MQTT_USERNAME {{ cstring .MQTTConfig.Username }}
+#define MQTT_PASSWORD {{ cstring .MQTTConfig.Password }}
+{{ end }}
+{{- if .MQTTConfig.UseTLS }}
+#define MQTT_TLS true
+{{- if .MQTTCACert }}
+#define MQTT_CA_CERT {{ cstring .MQTTCACert }}
+{{- end }}
+{{- if .MQTTClientCert }}
+#define MQTT_CLIENT_CERT {{ cstring .MQTTClientCert }}
+#define MQTT_CLIENT_KEY {{ cstring .MQTTClientKey }}
+{{- end }}
+{{- if .MQTTConfig.TLS.InsecureSkipVerify }}
+#define MQTT_TLS_INSECURE true
+{{- end }}
+{{ end }}
 
 #define NUM_ZONES {{ len .Zones }}
 #define VALVES { {{ range $index, $z := .Zones }}{{if $index}}, {{end}}{{ $z.ValvePin }}{{ end }} }
@@ -187,23 +207,93 @@ func generateMainConfig(config Config, interactive bool) (string, error) {
 		}
 	}
 
+	data, err := newMainConfigData(config)
+	if err != nil {
+		return "", err
+	}
+
 	milliseconds := func(interval time.Duration) string {
 		return fmt.Sprintf("%d", interval.Milliseconds())
 	}
 	t := template.Must(template.
 		New("config.h").
-		Funcs(template.FuncMap{"milliseconds": milliseconds}).
+		Funcs(template.FuncMap{"milliseconds": milliseconds, "cstring": strconv.Quote}).
 		Parse(configTemplate))
 
 	var result bytes.Buffer
-	data := config
-	err := t.Execute(&result, data)
+	err = t.Execute(&result, data)
 	if err != nil {
 		return "", err
 	}
 	return removeExtraNewlines(result.String()), nil
 }
 
+// mainConfigData adds the contents of MQTT certificate files to the Config so they can be embedded in config.h
+type mainConfigData struct {
+	Config
+	MQTTCACert     string
+	MQTTClientCert string
+	MQTTClientKey  string
+}
+
+func newMainConfigData(config Config) (mainConfigData, error) {
+	data := mainConfigData{Config: config}
+
+	// The firmware needs the hostname and port separately, so split the broker if it is a URL
+	if strings.Contains(config.MQTTConfig.Broker, "://") {
+		u, err := url.Parse(config.MQTTConfig.Broker)
+		if err != nil {
+			return data, fmt.Errorf("invalid MQTT broker: %w", err)
+		}
+		data.MQTTConfig.Broker = u.Hostname()
+		data.MQTTConfig.Scheme = u.Scheme
+		if u.Port() != "" {
+			data.MQTTConfig.Port, err = strconv.Atoi(u.Port())
+			if err != nil {
+				return data, fmt.Errorf("invalid MQTT broker port: %w", err)
+			}
+		}
+	}
+
+	switch data.MQTTConfig.Scheme {
+	case "", "tcp", "ssl":
+	default:
+		return data, fmt.Errorf("unsupported MQTT scheme for controller: %q", data.MQTTConfig.Scheme)
+	}
+
+	if !data.MQTTConfig.UseTLS() {
+		return data, nil
+	}
+
+	readFile := func(filename string) (string, error) {
+		if filename == "" {
+			return "", nil
+		}
+		// nolint:gosec // filename is user-provided but this is for local config generation
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return "", fmt.Errorf("error reading %q: %w", filename, err)
+		}
+		return string(content), nil
+	}
+
+	var err error
+	data.MQTTCACert, err = readFile(config.MQTTConfig.TLS.CAFile)
+	if err != nil {
+		return data, err
+	}
+	data.MQTTClientCert, err = readFile(config.MQTTConfig.TLS.CertFile)
+	if err != nil {
+		return data, err
+	}
+	data.MQTTClientKey, err = readFile(config.MQTTConfig.TLS.KeyFile)
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
 func generateWiFiConfig(config WifiConfig, interactive bool) (string, error) {
 	qs := []*survey.Question{
 		{
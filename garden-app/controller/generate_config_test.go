@@ -193,6 +193,59 @@ func TestGenerateMainConfig(t *testing.T) {
 #define LIGHT_ENABLED false
 #define LIGHT_PIN GPIO_NUM_MAX
 
+#define ENABLE_DHT22 false
+#define DHT22_PIN GPIO_NUM_MAX
+#define DHT22_INTERVAL 0
+#endif
+`,
+		},
+		{
+			"MQTTAuthAndTLS",
+			Config{
+				NestedConfig: NestedConfig{
+					Zones: []ZoneConfig{
+						{
+							PumpPin:  "GPIO_NUM_18",
+							ValvePin: "GPIO_NUM_16",
+						},
+					},
+					TopicPrefix: "garden",
+				},
+				MQTTConfig: mqtt.Config{
+					Broker:   "ssl://broker.example.com:8883",
+					Port:     1883,
+					Username: "garden",
+					Password: `pa"ss`,
+					TLS: mqtt.TLSConfig{
+						CAFile:             "testdata/ca.pem",
+						InsecureSkipVerify: true,
+					},
+				},
+			},
+			`#ifndef config_h
+#define config_h
+
+#define TOPIC_PREFIX "garden"
+
+#define QUEUE_SIZE 10
+
+#define MQTT_ADDRESS "broker.example.com"
+#define MQTT_PORT 8883
+
+#define MQTT_USERNAME "garden"
+#define MQTT_PASSWORD "pa\"ss"
+
+#define MQTT_TLS true
+#define MQTT_CA_CERT "-----BEGIN CERTIFICATE-----\nMIIBfake+cert/data==\n-----END CERTIFICATE-----\n"
+#define MQTT_TLS_INSECURE true
+
+#define NUM_ZONES 1
+#define VALVES { GPIO_NUM_16 }
+#define PUMPS { GPIO_NUM_18 }
+
+#define LIGHT_ENABLED false
+#define LIGHT_PIN GPIO_NUM_MAX
+
 #define ENABLE_DHT22 false
 #define DHT22_PIN GPIO_NUM_MAX
 #define DHT22_INTERVAL 0
@@ -210,6 +263,13 @@ func TestGenerateMainConfig(t *testing.T) {
 	}
 }
 
+func TestGenerateMainConfigWebsocketsNotSupported(t *testing.T) {
+	_, err := generateMainConfig(Config{
+		MQTTConfig: mqtt.Config{Broker: "wss://broker.example.com/mqtt"},
+	}, false)
+	assert.EqualError(t, err, `unsupported MQTT scheme for controller: "wss"`)
+}
+
 func TestGenerateWifiConfig(t *testing.T) {
 	config, err := generateWiFiConfig(WifiConfig{
 		SSID:     "ssid",
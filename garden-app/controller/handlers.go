@@ -3,6 +3,7 @@ package controller
 import (
 	"encoding/json"
 
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
 	paho "github.com/eclipse/paho.mqtt.golang"
 )
@@ -81,3 +82,27 @@ func (c *Controller) lightHandler(topic string) paho.MessageHandler {
 		lightLogger.Info("received LightAction", "state", action.State)
 	})
 }
+
+func (c *Controller) scheduleHandler(topic string) paho.MessageHandler {
+	return paho.MessageHandler(func(_ paho.Client, msg paho.Message) {
+		scheduleLogger := c.subLogger.With("topic", topic)
+		var schedule pkg.ControllerSchedule
+		err := json.Unmarshal(msg.Payload(), &schedule)
+		if err != nil {
+			scheduleLogger.Error("unable to unmarshal ControllerSchedule JSON", "error", err)
+			return
+		}
+
+		c.assertionData.Lock()
+		c.assertionData.schedule = &schedule
+		c.assertionData.Unlock()
+
+		scheduleLogger.Info(
+			"received ControllerSchedule",
+			"version", schedule.Version,
+			"water_schedules", len(schedule.Water),
+			"light", schedule.Light != nil,
+			"fan", schedule.Fan != nil,
+		)
+	})
+}
@@ -73,6 +73,25 @@ func mqttPrompts(config *Config) error {
 	config.MQTTConfig.Broker = config.MQTTAddress
 	config.MQTTConfig.Port = config.MQTTPort
 
+	err = survey.AskOne(&survey.Input{
+		Message: "MQTT Username (optional)",
+		Default: config.MQTTConfig.Username,
+		Help:    "username for brokers that require authentication",
+	}, &config.MQTTConfig.Username)
+	if err != nil {
+		return fmt.Errorf("error in survey response: %w", err)
+	}
+
+	if config.MQTTConfig.Username != "" && config.MQTTConfig.Password == "" {
+		err = survey.AskOne(&survey.Password{
+			Message: "MQTT Password",
+			Help:    "password for the MQTT username",
+		}, &config.MQTTConfig.Password)
+		if err != nil {
+			return fmt.Errorf("error in survey response: %w", err)
+		}
+	}
+
 	if config.PublishHealth {
 		err = survey.AskOne(&survey.Input{
 			Message: "Health publishing interval",
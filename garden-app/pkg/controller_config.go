@@ -4,6 +4,7 @@ package pkg
 import (
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"strings"
 	"time"
 
@@ -55,6 +56,46 @@ type ControllerConfigMessage struct {
 	FanEnabled   bool                  `json:"fan"`
 	FanPin       uint                  `json:"fan_pin"`
 	Sensors      []SensorConfigMessage `json:"sensors"`
+	// ConfigHash is the hash that the controller is expected to report after applying this config. Current
+	// firmware computes it from the config it applied, but older firmware stores and reports this value
+	ConfigHash string `json:"config_hash"`
+}
+
+// Hash returns a hex-encoded FNV-1a hash of the config that the controller applies. The firmware computes the
+// same hash from its applied config (computeConfigHash in garden_config.cpp), so the hashed string must be built
+// the same way in both places
+func (m ControllerConfigMessage) Hash() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "zones=%d;", m.NumZones)
+	for i := range m.NumZones {
+		fmt.Fprintf(&b, "zone=%d,%d;", pinAt(m.ValvePins, i), pinAt(m.PumpPins, i))
+	}
+
+	// The controller ignores the pins for disabled outputs
+	lightPin, fanPin := uint(0), uint(0)
+	if m.LightEnabled {
+		lightPin = m.LightPin
+	}
+	if m.FanEnabled {
+		fanPin = m.FanPin
+	}
+	fmt.Fprintf(&b, "light=%t,%d;fan=%t,%d;", m.LightEnabled, lightPin, m.FanEnabled, fanPin)
+
+	for _, s := range m.Sensors {
+		fmt.Fprintf(&b, "sensor=%s,%s,%d,%d;", s.ID, s.Type, s.Pin, s.Interval)
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(b.String()))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// pinAt returns the pin at the index or 0 if it doesn't exist, which is what the controller reads for missing pins
+func pinAt(pins []uint, i uint) uint {
+	if i >= uint(len(pins)) {
+		return 0
+	}
+	return pins[i]
 }
 
 // SensorConfigMessage is the firmware-facing representation of a SensorConfig.
@@ -103,6 +144,8 @@ func (c *ControllerConfig) ToMessage() ControllerConfigMessage {
 		}
 	}
 
+	message.ConfigHash = message.Hash()
+
 	return message
 }
 
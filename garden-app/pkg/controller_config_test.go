@@ -184,6 +184,7 @@ func TestToMessage(t *testing.T) {
 					{ID: "sensor1", Type: "DHT22", Pin: 21, Interval: 1000},
 					{ID: "sensor2", Type: "DS18B20", Pin: 22, Interval: 2000},
 				},
+				ConfigHash: "b66fda13b5840ce0",
 			},
 		},
 		{
@@ -208,6 +209,7 @@ func TestToMessage(t *testing.T) {
 				Sensors: []SensorConfigMessage{
 					{ID: "sensor1", Type: "DHT22", Pin: 21, Interval: 5000},
 				},
+				ConfigHash: "52e9f6a120ab598e",
 			},
 		},
 		{
@@ -223,6 +225,7 @@ func TestToMessage(t *testing.T) {
 				LightEnabled: false,
 				FanEnabled:   false,
 				Sensors:      []SensorConfigMessage{},
+				ConfigHash:   "854642432f602e9e",
 			},
 		},
 	}
@@ -235,6 +238,41 @@ func TestToMessage(t *testing.T) {
 	}
 }
 
+func TestControllerConfigMessageHash(t *testing.T) {
+	// These values are also used in garden-controller's test_computeConfigHash so the firmware and the
+	// garden-app are guaranteed to calculate the same hash
+	message := ControllerConfigMessage{
+		NumZones:     4,
+		ValvePins:    []uint{4, 5, 6, 7},
+		PumpPins:     []uint{12, 13, 14, 15},
+		LightEnabled: true,
+		LightPin:     2,
+		FanEnabled:   true,
+		FanPin:       22,
+		Sensors: []SensorConfigMessage{
+			{ID: "sensor1", Type: "DHT22", Pin: 21, Interval: 5000},
+			{ID: "sensor2", Type: "DS18B20", Pin: 22, Interval: 5000},
+		},
+	}
+	assert.Equal(t, "66c7f6d93b4f75d9", message.Hash())
+
+	t.Run("IgnoresDisabledPins", func(t *testing.T) {
+		withoutLight := message
+		withoutLight.LightEnabled = false
+		withoutLightPin := withoutLight
+		withoutLightPin.LightPin = 0
+		assert.Equal(t, withoutLightPin.Hash(), withoutLight.Hash())
+	})
+
+	t.Run("MissingPumpPins", func(t *testing.T) {
+		missingPumps := message
+		missingPumps.PumpPins = []uint{12, 13}
+		zeroPumps := message
+		zeroPumps.PumpPins = []uint{12, 13, 0, 0}
+		assert.Equal(t, zeroPumps.Hash(), missingPumps.Hash())
+	})
+}
+
 func TestValvePin(t *testing.T) {
 	tests := []struct {
 		name     string
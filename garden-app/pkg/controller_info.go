@@ -4,9 +4,14 @@ import "time"
 
 // ControllerInfo represents runtime information about a physical garden controller
 type ControllerInfo struct {
-	GardenID        string     `json:"-"`
-	MACAddress      string     `json:"mac_address"`
-	IPAddress       string     `json:"ip_address"`
-	FirmwareVersion string     `json:"firmware_version"`
+	GardenID        string `json:"-"`
+	MACAddress      string `json:"mac_address"`
+	IPAddress       string `json:"ip_address"`
+	FirmwareVersion string `json:"firmware_version"`
+	// ConfigHash is the hash of the ControllerConfigMessage that the controller is currently using. It is nil if
+	// the controller doesn't report it (older firmware) and empty if the controller doesn't have a config
+	ConfigHash *string `json:"config_hash,omitempty"`
+	// ScheduleVersion is the version of the ControllerSchedule that the controller is keeping as a fallback
+	ScheduleVersion string     `json:"schedule_version,omitempty"`
 	UpdatedAt       *time.Time `json:"updated_at"`
 }
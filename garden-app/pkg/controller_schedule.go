@@ -0,0 +1,67 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+)
+
+// ControllerScheduleVersionNone is reported by controllers that support a ControllerSchedule but have not received
+// one yet
+const ControllerScheduleVersionNone = "none"
+
+// ControllerSchedule is a compact version of a Garden's active WaterSchedules, LightSchedule, and FanSchedule. It is
+// sent to the controller, which keeps it as a fallback so watering and the light and fan continue when the
+// garden-app or MQTT broker is unavailable. All times are in UTC
+type ControllerSchedule struct {
+	Version string                    `json:"version"`
+	Water   []ControllerWaterSchedule `json:"water,omitempty"`
+	Light   *ControllerLightSchedule  `json:"light,omitempty"`
+	Fan     *ControllerFanSchedule    `json:"fan,omitempty"`
+}
+
+// ControllerWaterSchedule waters all of its Zones every Interval. Start is the Unix timestamp of the earliest watering
+// time, so the next watering is the first Start + n*Interval after the current time. The Duration is already scaled
+// using the last-known weather scale factor
+type ControllerWaterSchedule struct {
+	ID         string                 `json:"id"`
+	Start      int64                  `json:"start"`    // s
+	Interval   int64                  `json:"interval"` // s
+	Duration   int64                  `json:"duration"` // ms
+	StartMonth uint                   `json:"start_month,omitempty"`
+	EndMonth   uint                   `json:"end_month,omitempty"`
+	Zones      []ControllerZoneTarget `json:"zones"`
+}
+
+// ControllerZoneTarget is a Zone watered by a ControllerWaterSchedule. The ZoneID is included in water events
+type ControllerZoneTarget struct {
+	Position uint   `json:"position"`
+	ZoneID   string `json:"zone_id"`
+}
+
+// ControllerLightSchedule turns the light on at Start seconds after midnight for Duration seconds each day
+type ControllerLightSchedule struct {
+	Start    int64 `json:"start"`    // s
+	Duration int64 `json:"duration"` // s
+}
+
+// ControllerFanSchedule runs the fan at Power for Duration followed by Interval off, starting at midnight
+type ControllerFanSchedule struct {
+	Duration      int64 `json:"duration"` // s
+	Interval      int64 `json:"interval"` // s
+	Power         uint8 `json:"power"`
+	OnlyWithLight bool  `json:"only_with_light,omitempty"`
+}
+
+// Hash returns a hex-encoded FNV-1a hash of the schedule without the Version
+func (s ControllerSchedule) Hash() string {
+	s.Version = ""
+	data, err := json.Marshal(s)
+	if err != nil {
+		return ""
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write(data)
+	return fmt.Sprintf("%016x", h.Sum64())
+}
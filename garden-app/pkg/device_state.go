@@ -0,0 +1,47 @@
+package pkg
+
+import "time"
+
+// DeviceState compares the actual state of a Garden's light and fan, as reported by the controller,
+// with the state that is expected from schedules and recent actions
+type DeviceState struct {
+	Light *LightDeviceState `json:"light,omitempty"`
+	Fan   *FanDeviceState   `json:"fan,omitempty"`
+}
+
+// LightDeviceState has the actual and expected state of a Garden's light. Expected is nil if there is no
+// schedule or recent action to compare with
+type LightDeviceState struct {
+	Actual     *LightState `json:"actual"`
+	Expected   *LightState `json:"expected"`
+	ReportedAt *time.Time  `json:"reported_at,omitempty"`
+}
+
+// Mismatch returns true if the actual and expected states are both known and are different
+func (s *LightDeviceState) Mismatch() bool {
+	if s == nil || s.Actual == nil || s.Expected == nil {
+		return false
+	}
+	return *s.Actual != *s.Expected
+}
+
+// FanDeviceState has the actual and expected state of a Garden's fan. ActualPower is the PWM value (0-255)
+// reported by the controller, so the fan is active when it is greater than zero
+type FanDeviceState struct {
+	ActualPower    *uint8     `json:"actual_power"`
+	ExpectedActive *bool      `json:"expected_active"`
+	ReportedAt     *time.Time `json:"reported_at,omitempty"`
+}
+
+// ActualActive returns true if the controller reported that the fan is running
+func (s *FanDeviceState) ActualActive() bool {
+	return s != nil && s.ActualPower != nil && *s.ActualPower > 0
+}
+
+// Mismatch returns true if the actual and expected states are both known and are different
+func (s *FanDeviceState) Mismatch() bool {
+	if s == nil || s.ActualPower == nil || s.ExpectedActive == nil {
+		return false
+	}
+	return s.ActualActive() != *s.ExpectedActive
+}
@@ -0,0 +1,112 @@
+// Package driver defines the interface used to send commands to the hardware controlled by a Garden and creates
+// the implementation configured for a Garden
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/driver/httprelay"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/driver/zigbee2mqtt"
+)
+
+// Driver sends commands to a Garden's hardware
+type Driver interface {
+	Water(ctx context.Context, g *pkg.Garden, msg action.WaterMessage) error
+	Stop(ctx context.Context, g *pkg.Garden, all bool) error
+	Light(ctx context.Context, g *pkg.Garden, input *action.LightAction) error
+	Fan(ctx context.Context, g *pkg.Garden, input *action.FanAction) error
+	UpdateConfig(ctx context.Context, g *pkg.Garden, cfg *pkg.ControllerConfig) error
+}
+
+var (
+	_ Driver = &httprelay.Driver{}
+	_ Driver = &zigbee2mqtt.Driver{}
+)
+
+// Option configures dependencies used by New
+type Option func(*options)
+
+type options struct {
+	mqtt       Driver
+	httpClient *http.Client
+	publisher  zigbee2mqtt.Publisher
+}
+
+// WithMQTTDriver sets the driver used for the garden-controller's MQTT protocol. This is required for Gardens
+// that use the default driver
+func WithMQTTDriver(d Driver) Option {
+	return func(o *options) {
+		o.mqtt = d
+	}
+}
+
+// WithHTTPClient sets the client used by the http_relay driver
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *options) {
+		o.httpClient = httpClient
+	}
+}
+
+// WithPublisher sets the MQTT client used by the zigbee2mqtt driver
+func WithPublisher(publisher zigbee2mqtt.Publisher) Option {
+	return func(o *options) {
+		o.publisher = publisher
+	}
+}
+
+// New creates the Driver for the config. A nil config uses the MQTT driver
+func New(cfg *pkg.DriverConfig, opts ...Option) (Driver, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	var driverOptions map[string]any
+	if cfg != nil {
+		driverOptions = cfg.Options
+	}
+
+	switch cfg.GetType() {
+	case pkg.DriverTypeMQTT:
+		if o.mqtt == nil {
+			return nil, errors.New("mqtt driver is not available")
+		}
+		return o.mqtt, nil
+	case pkg.DriverTypeHTTPRelay:
+		return httprelay.NewDriver(driverOptions, o.httpClient)
+	case pkg.DriverTypeZigbee2MQTT:
+		return zigbee2mqtt.NewDriver(driverOptions, o.publisher)
+	default:
+		return nil, fmt.Errorf("invalid driver type %q", cfg.Type)
+	}
+}
+
+// Validate checks the config's type and options without sending any commands
+func Validate(cfg *pkg.DriverConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	var err error
+	switch cfg.GetType() {
+	case pkg.DriverTypeHTTPRelay:
+		_, err = httprelay.NewDriver(cfg.Options, nil)
+	case pkg.DriverTypeZigbee2MQTT:
+		_, err = zigbee2mqtt.NewDriver(cfg.Options, noopPublisher{})
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %s driver options: %w", cfg.GetType(), err)
+	}
+	return nil
+}
+
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(context.Context, string, []byte) error {
+	return nil
+}
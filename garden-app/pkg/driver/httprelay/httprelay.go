@@ -0,0 +1,145 @@
+// Package httprelay provides a driver for relays controlled with the Shelly Gen1 HTTP API
+package httprelay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/mitchellh/mapstructure"
+)
+
+// defaultTimeout is used when an HTTP client isn't provided so an unreachable relay doesn't block watering
+const defaultTimeout = 10 * time.Second
+
+// Config maps the Garden's Zones, light, and fan to relay channels
+type Config struct {
+	// URL is the base URL of the relay device, like "http://192.168.1.50"
+	URL string `json:"url" yaml:"url" mapstructure:"url"`
+	// ZoneRelays is the relay channel for each Zone, indexed by the Zone's position
+	ZoneRelays []int `json:"zone_relays" yaml:"zone_relays" mapstructure:"zone_relays"`
+	LightRelay *int  `json:"light_relay,omitempty" yaml:"light_relay,omitempty" mapstructure:"light_relay"`
+	FanRelay   *int  `json:"fan_relay,omitempty" yaml:"fan_relay,omitempty" mapstructure:"fan_relay"`
+}
+
+// Driver turns relays on and off with HTTP requests. Watering and the fan use the relay's timer so they are
+// turned off by the device even if the garden-app is unavailable
+type Driver struct {
+	*Config
+	httpClient *http.Client
+}
+
+// NewDriver creates a new Driver from options
+func NewDriver(options map[string]any, httpClient *http.Client) (*Driver, error) {
+	d := &Driver{
+		Config:     &Config{},
+		httpClient: httpClient,
+	}
+
+	err := mapstructure.WeakDecode(options, d.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.URL == "" {
+		return nil, errors.New("url must be provided")
+	}
+	_, err = url.Parse(d.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	d.URL = strings.TrimSuffix(d.URL, "/")
+
+	if d.httpClient == nil {
+		d.httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+
+	return d, nil
+}
+
+// Water turns on the Zone's relay for the duration. Relays don't have a queue, so the worker only calls this after
+// the Garden's previous watering is done
+func (d *Driver) Water(ctx context.Context, _ *pkg.Garden, msg action.WaterMessage) error {
+	if msg.Position >= uint(len(d.ZoneRelays)) {
+		return fmt.Errorf("no relay configured for zone position %d", msg.Position)
+	}
+	return d.setRelay(ctx, d.ZoneRelays[msg.Position], "on", time.Duration(msg.Duration)*time.Millisecond)
+}
+
+// Stop turns off all Zone relays. Relays don't have a queue of watering, so stopping all and stopping the
+// current watering are the same
+func (d *Driver) Stop(ctx context.Context, _ *pkg.Garden, _ bool) error {
+	var errs []error
+	for _, relay := range d.ZoneRelays {
+		errs = append(errs, d.setRelay(ctx, relay, "off", 0))
+	}
+	return errors.Join(errs...)
+}
+
+// Light turns the light's relay on, off, or toggles it
+func (d *Driver) Light(ctx context.Context, _ *pkg.Garden, input *action.LightAction) error {
+	if d.LightRelay == nil {
+		return errors.New("light_relay is not configured")
+	}
+
+	turn := "toggle"
+	switch input.State {
+	case pkg.LightStateOn:
+		turn = "on"
+	case pkg.LightStateOff:
+		turn = "off"
+	}
+	return d.setRelay(ctx, *d.LightRelay, turn, 0)
+}
+
+// Fan turns on the fan's relay for the duration if the power is above 0, otherwise it is turned off. Relays can't
+// control the fan's speed
+func (d *Driver) Fan(ctx context.Context, _ *pkg.Garden, input *action.FanAction) error {
+	if d.FanRelay == nil {
+		return errors.New("fan_relay is not configured")
+	}
+
+	if input.Power == 0 {
+		return d.setRelay(ctx, *d.FanRelay, "off", 0)
+	}
+	return d.setRelay(ctx, *d.FanRelay, "on", time.Duration(input.Duration)*time.Millisecond)
+}
+
+// UpdateConfig is not supported since relays are configured on the device
+func (d *Driver) UpdateConfig(context.Context, *pkg.Garden, *pkg.ControllerConfig) error {
+	return errors.New("controller config is not supported by the http_relay driver")
+}
+
+// setRelay sends a request to change the relay's state. If timer is set, the relay flips back after it expires
+func (d *Driver) setRelay(ctx context.Context, relay int, turn string, timer time.Duration) error {
+	q := url.Values{}
+	q.Set("turn", turn)
+	if timer > 0 {
+		q.Set("timer", strconv.FormatFloat(timer.Seconds(), 'f', -1, 64))
+	}
+
+	reqURL := fmt.Sprintf("%s/relay/%d?%s", d.URL, relay, q.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, http.NoBody)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request to relay %d: %w", relay, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("relay %d returned status %d", relay, resp.StatusCode)
+	}
+
+	return nil
+}
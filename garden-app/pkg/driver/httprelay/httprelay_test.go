@@ -0,0 +1,132 @@
+package httprelay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDriver(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   map[string]any
+		errMsg string
+	}{
+		{
+			"Valid",
+			map[string]any{"url": "http://localhost", "zone_relays": []any{0, 1}, "light_relay": 2},
+			"",
+		},
+		{
+			"MissingURL",
+			map[string]any{"zone_relays": []any{0}},
+			"url must be provided",
+		},
+		{
+			"NilOptions",
+			nil,
+			"url must be provided",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDriver(tt.opts, nil)
+			if tt.errMsg == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.errMsg)
+		})
+	}
+}
+
+func TestDriver(t *testing.T) {
+	setup := func(t *testing.T, status int) (*Driver, func() []string) {
+		t.Helper()
+
+		requests := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.String())
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(server.Close)
+
+		d, err := NewDriver(map[string]any{
+			"url":         server.URL + "/",
+			"zone_relays": []any{1, 2},
+			"light_relay": 3,
+			"fan_relay":   "4",
+		}, server.Client())
+		require.NoError(t, err)
+
+		return d, func() []string {
+			result := requests
+			requests = []string{}
+			return result
+		}
+	}
+
+	t.Run("Water", func(t *testing.T) {
+		d, requests := setup(t, http.StatusOK)
+
+		err := d.Water(context.Background(), nil, action.WaterMessage{Position: 1, Duration: 90500})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/relay/2?timer=90.5&turn=on"}, requests())
+
+		err = d.Water(context.Background(), nil, action.WaterMessage{Position: 2, Duration: 1000})
+		require.EqualError(t, err, "no relay configured for zone position 2")
+		assert.Empty(t, requests())
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		d, requests := setup(t, http.StatusOK)
+
+		err := d.Stop(context.Background(), nil, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/relay/1?turn=off", "/relay/2?turn=off"}, requests())
+	})
+
+	t.Run("Light", func(t *testing.T) {
+		d, requests := setup(t, http.StatusOK)
+
+		for _, state := range []pkg.LightState{pkg.LightStateOn, pkg.LightStateOff, pkg.LightStateToggle} {
+			err := d.Light(context.Background(), nil, &action.LightAction{State: state})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []string{"/relay/3?turn=on", "/relay/3?turn=off", "/relay/3?turn=toggle"}, requests())
+	})
+
+	t.Run("Fan", func(t *testing.T) {
+		d, requests := setup(t, http.StatusOK)
+
+		err := d.Fan(context.Background(), nil, &action.FanAction{Power: 128, Duration: (30 * time.Minute).Milliseconds()})
+		require.NoError(t, err)
+		err = d.Fan(context.Background(), nil, &action.FanAction{Power: 0})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/relay/4?timer=1800&turn=on", "/relay/4?turn=off"}, requests())
+	})
+
+	t.Run("ErrorStatus", func(t *testing.T) {
+		d, _ := setup(t, http.StatusInternalServerError)
+
+		err := d.Light(context.Background(), nil, &action.LightAction{State: pkg.LightStateOn})
+		require.EqualError(t, err, "relay 3 returned status 500")
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		d, err := NewDriver(map[string]any{"url": "http://localhost"}, nil)
+		require.NoError(t, err)
+
+		require.EqualError(t, d.Light(context.Background(), nil, &action.LightAction{}), "light_relay is not configured")
+		require.EqualError(t, d.Fan(context.Background(), nil, &action.FanAction{}), "fan_relay is not configured")
+		require.Error(t, d.UpdateConfig(context.Background(), nil, &pkg.ControllerConfig{}))
+	})
+}
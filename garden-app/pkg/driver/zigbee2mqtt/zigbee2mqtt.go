@@ -0,0 +1,141 @@
+// Package zigbee2mqtt provides a driver for Zigbee valves and switches that are controlled through Zigbee2MQTT
+package zigbee2mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/mitchellh/mapstructure"
+)
+
+const defaultBaseTopic = "zigbee2mqtt"
+
+// Config maps the Garden's Zones, light, and fan to Zigbee2MQTT devices
+type Config struct {
+	// BaseTopic is Zigbee2MQTT's base topic. Defaults to "zigbee2mqtt"
+	BaseTopic string `json:"base_topic,omitempty" yaml:"base_topic,omitempty" mapstructure:"base_topic"`
+	// ZoneDevices is the friendly name of the device for each Zone, indexed by the Zone's position
+	ZoneDevices []string `json:"zone_devices" yaml:"zone_devices" mapstructure:"zone_devices"`
+	LightDevice string   `json:"light_device,omitempty" yaml:"light_device,omitempty" mapstructure:"light_device"`
+	FanDevice   string   `json:"fan_device,omitempty" yaml:"fan_device,omitempty" mapstructure:"fan_device"`
+}
+
+// Publisher is used to publish messages to the MQTT broker that Zigbee2MQTT is connected to
+type Publisher interface {
+	Publish(ctx context.Context, topic string, message []byte) error
+}
+
+// Driver publishes commands to Zigbee2MQTT devices. Watering and the fan use the device's "on_time" so they are
+// turned off even if the garden-app is unavailable
+type Driver struct {
+	*Config
+	publisher Publisher
+}
+
+// setMessage is the payload for a device's set topic
+type setMessage struct {
+	State  string `json:"state"`
+	OnTime int64  `json:"on_time,omitempty"`
+}
+
+// NewDriver creates a new Driver from options
+func NewDriver(options map[string]any, publisher Publisher) (*Driver, error) {
+	if publisher == nil {
+		return nil, errors.New("zigbee2mqtt driver requires an MQTT client")
+	}
+
+	d := &Driver{
+		Config:    &Config{},
+		publisher: publisher,
+	}
+
+	err := mapstructure.WeakDecode(options, d.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.BaseTopic == "" {
+		d.BaseTopic = defaultBaseTopic
+	}
+	d.BaseTopic = strings.TrimSuffix(d.BaseTopic, "/")
+
+	return d, nil
+}
+
+// Water turns on the Zone's device for the duration. Devices don't have a queue, so the worker only calls this
+// after the Garden's previous watering is done
+func (d *Driver) Water(ctx context.Context, _ *pkg.Garden, msg action.WaterMessage) error {
+	if msg.Position >= uint(len(d.ZoneDevices)) {
+		return fmt.Errorf("no device configured for zone position %d", msg.Position)
+	}
+	return d.set(ctx, d.ZoneDevices[msg.Position], setMessage{State: "ON", OnTime: onTimeSeconds(msg.Duration)})
+}
+
+// Stop turns off all Zone devices. Devices don't have a queue of watering, so stopping all and stopping the
+// current watering are the same
+func (d *Driver) Stop(ctx context.Context, _ *pkg.Garden, _ bool) error {
+	var errs []error
+	for _, device := range d.ZoneDevices {
+		errs = append(errs, d.set(ctx, device, setMessage{State: "OFF"}))
+	}
+	return errors.Join(errs...)
+}
+
+// Light turns the light's device on, off, or toggles it
+func (d *Driver) Light(ctx context.Context, _ *pkg.Garden, input *action.LightAction) error {
+	if d.LightDevice == "" {
+		return errors.New("light_device is not configured")
+	}
+
+	state := "TOGGLE"
+	switch input.State {
+	case pkg.LightStateOn:
+		state = "ON"
+	case pkg.LightStateOff:
+		state = "OFF"
+	}
+	return d.set(ctx, d.LightDevice, setMessage{State: state})
+}
+
+// Fan turns on the fan's device for the duration if the power is above 0, otherwise it is turned off
+func (d *Driver) Fan(ctx context.Context, _ *pkg.Garden, input *action.FanAction) error {
+	if d.FanDevice == "" {
+		return errors.New("fan_device is not configured")
+	}
+
+	if input.Power == 0 {
+		return d.set(ctx, d.FanDevice, setMessage{State: "OFF"})
+	}
+	return d.set(ctx, d.FanDevice, setMessage{State: "ON", OnTime: onTimeSeconds(input.Duration)})
+}
+
+// UpdateConfig is not supported since devices are configured in Zigbee2MQTT
+func (d *Driver) UpdateConfig(context.Context, *pkg.Garden, *pkg.ControllerConfig) error {
+	return errors.New("controller config is not supported by the zigbee2mqtt driver")
+}
+
+func (d *Driver) set(ctx context.Context, device string, msg setMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error marshalling message: %w", err)
+	}
+
+	topic := fmt.Sprintf("%s/%s/set", d.BaseTopic, device)
+	err = d.publisher.Publish(ctx, topic, data)
+	if err != nil {
+		return fmt.Errorf("error publishing to %q: %w", topic, err)
+	}
+	return nil
+}
+
+// onTimeSeconds converts milliseconds to the seconds used by "on_time". It rounds up so short durations still
+// turn on the device
+func onTimeSeconds(millis int64) int64 {
+	return int64(math.Ceil(float64(millis) / 1000))
+}
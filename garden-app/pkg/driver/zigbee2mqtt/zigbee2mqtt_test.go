@@ -0,0 +1,120 @@
+package zigbee2mqtt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPublisher struct {
+	messages []string
+	err      error
+}
+
+func (p *stubPublisher) Publish(_ context.Context, topic string, message []byte) error {
+	p.messages = append(p.messages, fmt.Sprintf("%s %s", topic, message))
+	return p.err
+}
+
+func TestNewDriver(t *testing.T) {
+	t.Run("DefaultBaseTopic", func(t *testing.T) {
+		d, err := NewDriver(nil, &stubPublisher{})
+		require.NoError(t, err)
+		assert.Equal(t, "zigbee2mqtt", d.BaseTopic)
+	})
+
+	t.Run("MissingPublisher", func(t *testing.T) {
+		_, err := NewDriver(nil, nil)
+		require.EqualError(t, err, "zigbee2mqtt driver requires an MQTT client")
+	})
+}
+
+func TestDriver(t *testing.T) {
+	setup := func(t *testing.T) (*Driver, *stubPublisher) {
+		t.Helper()
+
+		publisher := &stubPublisher{}
+		d, err := NewDriver(map[string]any{
+			"base_topic":   "z2m/",
+			"zone_devices": []any{"valve_1", "valve_2"},
+			"light_device": "grow_light",
+			"fan_device":   "fan",
+		}, publisher)
+		require.NoError(t, err)
+
+		return d, publisher
+	}
+
+	t.Run("Water", func(t *testing.T) {
+		d, publisher := setup(t)
+
+		err := d.Water(context.Background(), nil, action.WaterMessage{Position: 1, Duration: 90500})
+		require.NoError(t, err)
+		assert.Equal(t, []string{`z2m/valve_2/set {"state":"ON","on_time":91}`}, publisher.messages)
+
+		err = d.Water(context.Background(), nil, action.WaterMessage{Position: 2, Duration: 1000})
+		require.EqualError(t, err, "no device configured for zone position 2")
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		d, publisher := setup(t)
+
+		err := d.Stop(context.Background(), nil, true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`z2m/valve_1/set {"state":"OFF"}`,
+			`z2m/valve_2/set {"state":"OFF"}`,
+		}, publisher.messages)
+	})
+
+	t.Run("Light", func(t *testing.T) {
+		d, publisher := setup(t)
+
+		for _, state := range []pkg.LightState{pkg.LightStateOn, pkg.LightStateOff, pkg.LightStateToggle} {
+			err := d.Light(context.Background(), nil, &action.LightAction{State: state})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []string{
+			`z2m/grow_light/set {"state":"ON"}`,
+			`z2m/grow_light/set {"state":"OFF"}`,
+			`z2m/grow_light/set {"state":"TOGGLE"}`,
+		}, publisher.messages)
+	})
+
+	t.Run("Fan", func(t *testing.T) {
+		d, publisher := setup(t)
+
+		err := d.Fan(context.Background(), nil, &action.FanAction{Power: 255, Duration: time.Hour.Milliseconds()})
+		require.NoError(t, err)
+		err = d.Fan(context.Background(), nil, &action.FanAction{Power: 0})
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			`z2m/fan/set {"state":"ON","on_time":3600}`,
+			`z2m/fan/set {"state":"OFF"}`,
+		}, publisher.messages)
+	})
+
+	t.Run("PublishError", func(t *testing.T) {
+		d, publisher := setup(t)
+		publisher.err = errors.New("not connected")
+
+		err := d.Light(context.Background(), nil, &action.LightAction{State: pkg.LightStateOn})
+		require.EqualError(t, err, `error publishing to "z2m/grow_light/set": not connected`)
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		d, err := NewDriver(nil, &stubPublisher{})
+		require.NoError(t, err)
+
+		require.EqualError(t, d.Light(context.Background(), nil, &action.LightAction{}), "light_device is not configured")
+		require.EqualError(t, d.Fan(context.Background(), nil, &action.FanAction{}), "fan_device is not configured")
+		require.Error(t, d.UpdateConfig(context.Background(), nil, &pkg.ControllerConfig{}))
+	})
+}
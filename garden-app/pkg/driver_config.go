@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Driver types control which hardware a Garden sends commands to
+const (
+	DriverTypeMQTT        = "mqtt"
+	DriverTypeHTTPRelay   = "http_relay"
+	DriverTypeZigbee2MQTT = "zigbee2mqtt"
+)
+
+// DriverConfig chooses and configures the driver used to control a Garden's hardware. A Garden without a
+// DriverConfig uses the MQTT protocol implemented by the garden-controller firmware
+type DriverConfig struct {
+	Type    string         `json:"type" yaml:"type"`
+	Options map[string]any `json:"options,omitempty" yaml:"options,omitempty"`
+}
+
+// GetType returns the lowercase driver type, defaulting to MQTT
+func (d *DriverConfig) GetType() string {
+	if d == nil || d.Type == "" {
+		return DriverTypeMQTT
+	}
+	return strings.ToLower(d.Type)
+}
+
+// Validate checks that the type is known. Options are validated by the driver when it is created
+func (d *DriverConfig) Validate() error {
+	switch d.GetType() {
+	case DriverTypeMQTT, DriverTypeHTTPRelay, DriverTypeZigbee2MQTT:
+		return nil
+	default:
+		return fmt.Errorf("invalid driver.type %q", d.Type)
+	}
+}
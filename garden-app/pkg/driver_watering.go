@@ -0,0 +1,20 @@
+package pkg
+
+import "time"
+
+// DriverWatering is a watering for a Garden that uses a driver without its own queue. The worker waters one Zone
+// at a time for these Gardens, so waterings are stored until they complete. This allows the queue to continue
+// after restarting
+type DriverWatering struct {
+	ID                   int64
+	GardenID             string
+	ZoneID               string
+	Position             uint
+	EventID              string
+	Source               string
+	Duration             time.Duration
+	NotificationClientID string
+	QueuedAt             time.Time
+	// StartedAt is set once the driver turns on the Zone's valve. It is nil for waterings that are waiting
+	StartedAt *time.Time
+}
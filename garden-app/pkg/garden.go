@@ -32,6 +32,7 @@ type Garden struct {
 	NotificationClientID *string               `json:"notification_client_id,omitempty" yaml:"notification_client_id,omitempty"`
 	NotificationSettings *NotificationSettings `json:"notification_settings,omitempty" yaml:"notification_settings,omitempty"`
 	ControllerConfig     *ControllerConfig     `json:"controller_config,omitempty" yaml:"controller_config,omitempty"`
+	Driver               *DriverConfig         `json:"driver,omitempty" yaml:"driver,omitempty"`
 	// ControllerInfo is populated via LEFT JOIN when reading from storage and is not persisted directly on the Garden
 	ControllerInfo *ControllerInfo `json:"controller_info,omitempty" yaml:"controller_info,omitempty"`
 }
@@ -43,6 +44,7 @@ type NotificationSettings struct {
 	Downtime          *Duration `json:"downtime" yaml:"downtime"`
 	WateringStarted   bool      `json:"watering_started" yaml:"watering_started"`
 	WateringComplete  bool      `json:"watering_complete" yaml:"watering_complete"`
+	WateringErrors    bool      `json:"watering_errors" yaml:"watering_errors"`
 	FirmwareChanged   bool      `json:"firmware_changed" yaml:"firmware_changed"`
 }
 
@@ -91,6 +93,32 @@ func (g *Garden) SetEndDate(now time.Time) {
 	g.EndDate = &now
 }
 
+// ConfigSyncStatus describes whether the controller is using the Garden's current ControllerConfig
+type ConfigSyncStatus string
+
+const (
+	ConfigSyncStatusSynced    ConfigSyncStatus = "synced"
+	ConfigSyncStatusOutOfSync ConfigSyncStatus = "out_of_sync"
+	// ConfigSyncStatusUnknown is used when the controller hasn't reported a config hash. This happens before it
+	// connects or if the firmware is too old to report it
+	ConfigSyncStatusUnknown ConfigSyncStatus = "unknown"
+)
+
+// ConfigSyncStatus compares the config hash reported by the controller with the Garden's ControllerConfig. It is
+// empty if the Garden doesn't have a ControllerConfig
+func (g *Garden) ConfigSyncStatus() ConfigSyncStatus {
+	if g.ControllerConfig == nil {
+		return ""
+	}
+	if g.ControllerInfo == nil || g.ControllerInfo.ConfigHash == nil {
+		return ConfigSyncStatusUnknown
+	}
+	if *g.ControllerInfo.ConfigHash != g.ControllerConfig.ToMessage().ConfigHash {
+		return ConfigSyncStatusOutOfSync
+	}
+	return ConfigSyncStatusSynced
+}
+
 // Patch allows for easily updating individual fields of a Garden by passing in a new Garden containing
 // the desired values
 func (g *Garden) Patch(newGarden *Garden) *babyapi.ErrResponse {
@@ -137,6 +165,9 @@ func (g *Garden) Patch(newGarden *Garden) *babyapi.ErrResponse {
 	if newGarden.NotificationClientID != nil {
 		g.NotificationClientID = newGarden.NotificationClientID
 	}
+	if newGarden.Driver != nil {
+		g.Driver = newGarden.Driver
+	}
 
 	if newGarden.ControllerConfig != nil {
 		if g.ControllerConfig == nil {
@@ -158,6 +189,7 @@ func (g *Garden) Patch(newGarden *Garden) *babyapi.ErrResponse {
 		g.NotificationSettings.Downtime = newGarden.NotificationSettings.Downtime
 		g.NotificationSettings.WateringStarted = newGarden.NotificationSettings.WateringStarted
 		g.NotificationSettings.WateringComplete = newGarden.NotificationSettings.WateringComplete
+		g.NotificationSettings.WateringErrors = newGarden.NotificationSettings.WateringErrors
 		g.NotificationSettings.FirmwareChanged = newGarden.NotificationSettings.FirmwareChanged
 	}
 
@@ -286,6 +318,13 @@ func (g *Garden) Bind(r *http.Request) error {
 		g.NotificationClientID = nil
 	}
 
+	if g.Driver != nil {
+		err = g.Driver.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
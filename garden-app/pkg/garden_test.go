@@ -188,3 +188,50 @@ func TestGardenPatch(t *testing.T) {
 		}
 	})
 }
+
+func TestGardenConfigSyncStatus(t *testing.T) {
+	config := &ControllerConfig{ValvePins: []uint{1}, PumpPins: []uint{2}}
+
+	tests := []struct {
+		name     string
+		garden   *Garden
+		expected ConfigSyncStatus
+	}{
+		{
+			"NoControllerConfig",
+			&Garden{ControllerInfo: &ControllerInfo{ConfigHash: pointer("abc")}},
+			"",
+		},
+		{
+			"NoControllerInfo",
+			&Garden{ControllerConfig: config},
+			ConfigSyncStatusUnknown,
+		},
+		{
+			"NoConfigHash",
+			&Garden{ControllerConfig: config, ControllerInfo: &ControllerInfo{FirmwareVersion: "v1.0.0"}},
+			ConfigSyncStatusUnknown,
+		},
+		{
+			"EmptyConfigHash",
+			&Garden{ControllerConfig: config, ControllerInfo: &ControllerInfo{ConfigHash: pointer("")}},
+			ConfigSyncStatusOutOfSync,
+		},
+		{
+			"OutOfSync",
+			&Garden{ControllerConfig: config, ControllerInfo: &ControllerInfo{ConfigHash: pointer("abc")}},
+			ConfigSyncStatusOutOfSync,
+		},
+		{
+			"Synced",
+			&Garden{ControllerConfig: config, ControllerInfo: &ControllerInfo{ConfigHash: pointer(config.ToMessage().ConfigHash)}},
+			ConfigSyncStatusSynced,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.garden.ConfigSyncStatus())
+		})
+	}
+}
@@ -0,0 +1,441 @@
+// Package homeassistant creates Home Assistant MQTT discovery configs for Gardens and Zones
+package homeassistant
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+)
+
+const (
+	// PayloadOn and PayloadOff are used for commands and states of switches and fans
+	PayloadOn  = "ON"
+	PayloadOff = "OFF"
+	// PayloadOpen and PayloadClose are used for valve commands
+	PayloadOpen  = "OPEN"
+	PayloadClose = "CLOSE"
+	// StateOpen and StateClosed are used for valve states
+	StateOpen   = "open"
+	StateClosed = "closed"
+
+	// healthOffDelay is how long the health binary_sensor stays ON after the controller's last health message.
+	// This matches the threshold used for GardenHealth
+	healthOffDelay = 300
+
+	// maxWaterDurationMinutes is the maximum of the Zone's watering duration number entity
+	maxWaterDurationMinutes = 240
+)
+
+// Component is the type of entity created in Home Assistant
+type Component string
+
+const (
+	ComponentBinarySensor Component = "binary_sensor"
+	ComponentFan          Component = "fan"
+	ComponentNumber       Component = "number"
+	ComponentSensor       Component = "sensor"
+	ComponentSwitch       Component = "switch"
+	ComponentValve        Component = "valve"
+)
+
+// Device is used by Home Assistant to group entities. Each Garden and Zone is a Device
+type Device struct {
+	Identifiers  []string `json:"identifiers"`
+	Name         string   `json:"name"`
+	Manufacturer string   `json:"manufacturer,omitempty"`
+	Model        string   `json:"model,omitempty"`
+	SWVersion    string   `json:"sw_version,omitempty"`
+	ViaDevice    string   `json:"via_device,omitempty"`
+}
+
+// Origin describes the application that published the discovery config
+type Origin struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Config is the discovery config for a single entity. Only the fields used by the entity's Component are set
+type Config struct {
+	Name     string  `json:"name"`
+	UniqueID string  `json:"unique_id"`
+	Device   Device  `json:"device"`
+	Origin   *Origin `json:"origin,omitempty"`
+
+	Icon           string `json:"icon,omitempty"`
+	DeviceClass    string `json:"device_class,omitempty"`
+	EntityCategory string `json:"entity_category,omitempty"`
+
+	CommandTopic string `json:"command_topic,omitempty"`
+	StateTopic   string `json:"state_topic,omitempty"`
+
+	PayloadOn    string `json:"payload_on,omitempty"`
+	PayloadOff   string `json:"payload_off,omitempty"`
+	PayloadOpen  string `json:"payload_open,omitempty"`
+	PayloadClose string `json:"payload_close,omitempty"`
+	StateOpen    string `json:"state_open,omitempty"`
+	StateClosed  string `json:"state_closed,omitempty"`
+
+	PercentageCommandTopic string `json:"percentage_command_topic,omitempty"`
+	PercentageStateTopic   string `json:"percentage_state_topic,omitempty"`
+	SpeedRangeMin          int    `json:"speed_range_min,omitempty"`
+	SpeedRangeMax          int    `json:"speed_range_max,omitempty"`
+
+	Min  int    `json:"min,omitempty"`
+	Max  int    `json:"max,omitempty"`
+	Step int    `json:"step,omitempty"`
+	Mode string `json:"mode,omitempty"`
+
+	UnitOfMeasurement string `json:"unit_of_measurement,omitempty"`
+	StateClass        string `json:"state_class,omitempty"`
+	ValueTemplate     string `json:"value_template,omitempty"`
+	OffDelay          int    `json:"off_delay,omitempty"`
+}
+
+// Entity is a discovery Config with the information needed to create its discovery topic
+type Entity struct {
+	Component Component
+	ObjectID  string
+	Config    Config
+}
+
+// Options configures the topics and components used for discovery
+type Options struct {
+	// DiscoveryPrefix is the prefix Home Assistant subscribes to for discovery configs
+	DiscoveryPrefix string
+	// TopicPrefix is the prefix used for command and state topics
+	TopicPrefix string
+	// ZoneComponent is ComponentValve or ComponentSwitch
+	ZoneComponent Component
+}
+
+// DiscoveryTopic returns the topic that an Entity's Config is published to
+func (o Options) DiscoveryTopic(gardenID string, e Entity) string {
+	return fmt.Sprintf("%s/%s/%s/%s/config", o.DiscoveryPrefix, e.Component, NodeID(gardenID), e.ObjectID)
+}
+
+// StatusTopic is where Home Assistant publishes "online" when it starts
+func (o Options) StatusTopic() string {
+	return o.DiscoveryPrefix + "/status"
+}
+
+// Topic returns a command or state topic for a Garden
+func (o Options) Topic(gardenID string, parts ...string) string {
+	return strings.Join(append([]string{o.TopicPrefix, gardenID}, parts...), "/")
+}
+
+// LightCommandTopic is used by Home Assistant to turn the Garden's light ON or OFF
+func (o Options) LightCommandTopic(gardenID string) string {
+	return o.Topic(gardenID, "light", "set")
+}
+
+// LightStateTopic has the light state reported by the Garden's controller
+func (o Options) LightStateTopic(gardenID string) string {
+	return o.Topic(gardenID, "light", "state")
+}
+
+// FanCommandTopic is used by Home Assistant to turn the Garden's fan ON or OFF
+func (o Options) FanCommandTopic(gardenID string) string {
+	return o.Topic(gardenID, "fan", "set")
+}
+
+// FanStateTopic has the fan state reported by the Garden's controller
+func (o Options) FanStateTopic(gardenID string) string {
+	return o.Topic(gardenID, "fan", "state")
+}
+
+// FanPercentageCommandTopic is used by Home Assistant to set the fan's power percentage
+func (o Options) FanPercentageCommandTopic(gardenID string) string {
+	return o.Topic(gardenID, "fan", "percentage", "set")
+}
+
+// FanPercentageStateTopic has the fan power percentage reported by the Garden's controller
+func (o Options) FanPercentageStateTopic(gardenID string) string {
+	return o.Topic(gardenID, "fan", "percentage", "state")
+}
+
+// HealthStateTopic receives a message each time the Garden's controller publishes a health check-in
+func (o Options) HealthStateTopic(gardenID string) string {
+	return o.Topic(gardenID, "health", "state")
+}
+
+// SensorStateTopic has the most recent readings from a sensor as JSON
+func (o Options) SensorStateTopic(gardenID, sensorID string) string {
+	return o.Topic(gardenID, "sensor", sensorID, "state")
+}
+
+// ZoneCommandTopic is used by Home Assistant to start and stop watering a Zone
+func (o Options) ZoneCommandTopic(gardenID, zoneID string) string {
+	return o.Topic(gardenID, "zone", zoneID, "set")
+}
+
+// ZoneStateTopic has the Zone's watering state
+func (o Options) ZoneStateTopic(gardenID, zoneID string) string {
+	return o.Topic(gardenID, "zone", zoneID, "state")
+}
+
+// ZoneDurationCommandTopic is used by Home Assistant to set how long a Zone is watered when it is started
+func (o Options) ZoneDurationCommandTopic(gardenID, zoneID string) string {
+	return o.Topic(gardenID, "zone", zoneID, "duration", "set")
+}
+
+// ZoneDurationStateTopic has the Zone's watering duration in minutes
+func (o Options) ZoneDurationStateTopic(gardenID, zoneID string) string {
+	return o.Topic(gardenID, "zone", zoneID, "duration", "state")
+}
+
+// ZoneStates returns the states used for a Zone that is watering and a Zone that is not watering
+func (o Options) ZoneStates() (string, string) {
+	if o.ZoneComponent == ComponentSwitch {
+		return PayloadOn, PayloadOff
+	}
+	return StateOpen, StateClosed
+}
+
+// NodeID is used in discovery topics to group a Garden's entities
+func NodeID(gardenID string) string {
+	return "garden_app_" + gardenID
+}
+
+func zoneDeviceID(zoneID string) string {
+	return "garden_app_zone_" + zoneID
+}
+
+var origin = &Origin{
+	Name: "garden-app",
+	URL:  "https://github.com/calvinmclean/automated-garden",
+}
+
+// GardenEntities creates the Entities for a Garden and its Zones
+func (o Options) GardenEntities(g *pkg.Garden, zones []*pkg.Zone) []Entity {
+	gardenID := g.GetID()
+	device := Device{
+		Identifiers:  []string{NodeID(gardenID)},
+		Name:         g.Name,
+		Manufacturer: "automated-garden",
+		Model:        "garden-controller",
+	}
+	if g.ControllerInfo != nil {
+		device.SWVersion = g.ControllerInfo.FirmwareVersion
+	}
+
+	entities := []Entity{
+		{
+			Component: ComponentBinarySensor,
+			ObjectID:  "health",
+			Config: Config{
+				Name:           "Health",
+				DeviceClass:    "connectivity",
+				EntityCategory: "diagnostic",
+				StateTopic:     o.HealthStateTopic(gardenID),
+				PayloadOn:      PayloadOn,
+				PayloadOff:     PayloadOff,
+				OffDelay:       healthOffDelay,
+			},
+		},
+	}
+
+	if g.LightSchedule != nil || (g.ControllerConfig != nil && g.ControllerConfig.LightPin != nil) {
+		entities = append(entities, Entity{
+			Component: ComponentSwitch,
+			ObjectID:  "light",
+			Config: Config{
+				Name:         "Light",
+				Icon:         "mdi:lightbulb",
+				CommandTopic: o.LightCommandTopic(gardenID),
+				StateTopic:   o.LightStateTopic(gardenID),
+				PayloadOn:    PayloadOn,
+				PayloadOff:   PayloadOff,
+			},
+		})
+	}
+
+	if g.FanSchedule != nil || (g.ControllerConfig != nil && g.ControllerConfig.FanPin != nil) {
+		entities = append(entities, Entity{
+			Component: ComponentFan,
+			ObjectID:  "fan",
+			Config: Config{
+				Name:                   "Fan",
+				CommandTopic:           o.FanCommandTopic(gardenID),
+				StateTopic:             o.FanStateTopic(gardenID),
+				PayloadOn:              PayloadOn,
+				PayloadOff:             PayloadOff,
+				PercentageCommandTopic: o.FanPercentageCommandTopic(gardenID),
+				PercentageStateTopic:   o.FanPercentageStateTopic(gardenID),
+				SpeedRangeMin:          1,
+				SpeedRangeMax:          100,
+			},
+		})
+	}
+
+	if g.ControllerConfig != nil {
+		for _, sensor := range g.ControllerConfig.Sensors {
+			for _, capability := range pkg.SensorCapabilities(sensor.Type) {
+				entities = append(entities, sensorEntity(o, gardenID, sensor, capability))
+			}
+		}
+	}
+
+	for i := range entities {
+		entities[i].Config.Device = device
+	}
+
+	for _, z := range zones {
+		entities = append(entities, o.zoneEntities(gardenID, z)...)
+	}
+
+	for i := range entities {
+		entities[i].Config.UniqueID = fmt.Sprintf("%s_%s", NodeID(gardenID), entities[i].ObjectID)
+		entities[i].Config.Origin = origin
+	}
+
+	return entities
+}
+
+func sensorEntity(o Options, gardenID string, sensor pkg.SensorConfig, capability string) Entity {
+	e := Entity{
+		Component: ComponentSensor,
+		ObjectID:  fmt.Sprintf("sensor_%s_%s", sensor.ID, capability),
+		Config: Config{
+			DeviceClass:   capability,
+			StateTopic:    o.SensorStateTopic(gardenID, sensor.ID),
+			StateClass:    "measurement",
+			ValueTemplate: fmt.Sprintf("{{ value_json.%s }}", capability),
+		},
+	}
+
+	switch capability {
+	case "temperature":
+		e.Config.Name = sensor.Name + " Temperature"
+		e.Config.UnitOfMeasurement = "°C"
+	case "humidity":
+		e.Config.Name = sensor.Name + " Humidity"
+		e.Config.UnitOfMeasurement = "%"
+	}
+
+	return e
+}
+
+func (o Options) zoneEntities(gardenID string, z *pkg.Zone) []Entity {
+	zoneID := z.GetID()
+	device := Device{
+		Identifiers:  []string{zoneDeviceID(zoneID)},
+		Name:         z.Name,
+		Manufacturer: "automated-garden",
+		Model:        "Zone",
+		ViaDevice:    NodeID(gardenID),
+	}
+
+	zone := Entity{
+		Component: ComponentValve,
+		ObjectID:  "zone_" + zoneID,
+		Config: Config{
+			Name:         "Water",
+			Icon:         "mdi:water",
+			Device:       device,
+			DeviceClass:  "water",
+			CommandTopic: o.ZoneCommandTopic(gardenID, zoneID),
+			StateTopic:   o.ZoneStateTopic(gardenID, zoneID),
+			PayloadOpen:  PayloadOpen,
+			PayloadClose: PayloadClose,
+			StateOpen:    StateOpen,
+			StateClosed:  StateClosed,
+		},
+	}
+	if o.ZoneComponent == ComponentSwitch {
+		zone.Component = ComponentSwitch
+		zone.Config.DeviceClass = ""
+		zone.Config.PayloadOpen, zone.Config.PayloadClose = "", ""
+		zone.Config.StateOpen, zone.Config.StateClosed = "", ""
+		zone.Config.PayloadOn, zone.Config.PayloadOff = PayloadOn, PayloadOff
+	}
+
+	duration := Entity{
+		Component: ComponentNumber,
+		ObjectID:  fmt.Sprintf("zone_%s_duration", zoneID),
+		Config: Config{
+			Name:              "Watering Duration",
+			Icon:              "mdi:timer-outline",
+			Device:            device,
+			EntityCategory:    "config",
+			CommandTopic:      o.ZoneDurationCommandTopic(gardenID, zoneID),
+			StateTopic:        o.ZoneDurationStateTopic(gardenID, zoneID),
+			Min:               1,
+			Max:               maxWaterDurationMinutes,
+			Step:              1,
+			Mode:              "box",
+			UnitOfMeasurement: "min",
+		},
+	}
+
+	return []Entity{zone, duration}
+}
+
+// Command is a message received on one of the command or state topics
+type Command struct {
+	GardenID string
+	// ZoneID is only set for Zone commands
+	ZoneID string
+	Target Target
+	// State is true for messages on state topics, which are used to restore retained Zone durations
+	State bool
+}
+
+// Target is the entity that a Command is for
+type Target string
+
+const (
+	TargetLight         Target = "light"
+	TargetFan           Target = "fan"
+	TargetFanPercentage Target = "fan_percentage"
+	TargetZone          Target = "zone"
+	TargetZoneDuration  Target = "zone_duration"
+)
+
+// ParseTopic parses a topic with TopicPrefix into a Command. It returns false if the topic is not a command or a
+// Zone duration state
+func (o Options) ParseTopic(topic string) (Command, bool) {
+	rest, ok := strings.CutPrefix(topic, o.TopicPrefix+"/")
+	if !ok {
+		return Command{}, false
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) < 3 {
+		return Command{}, false
+	}
+
+	cmd := Command{GardenID: parts[0]}
+	path := parts[1 : len(parts)-1]
+
+	switch {
+	case len(path) == 1 && path[0] == "light":
+		cmd.Target = TargetLight
+	case len(path) == 1 && path[0] == "fan":
+		cmd.Target = TargetFan
+	case len(path) == 2 && path[0] == "fan" && path[1] == "percentage":
+		cmd.Target = TargetFanPercentage
+	case len(path) == 2 && path[0] == "zone":
+		cmd.Target = TargetZone
+		cmd.ZoneID = path[1]
+	case len(path) == 3 && path[0] == "zone" && path[2] == "duration":
+		cmd.Target = TargetZoneDuration
+		cmd.ZoneID = path[1]
+	default:
+		return Command{}, false
+	}
+
+	switch parts[len(parts)-1] {
+	case "set":
+	case "state":
+		// only the Zone duration state is used since it is retained
+		if cmd.Target != TargetZoneDuration {
+			return Command{}, false
+		}
+		cmd.State = true
+	default:
+		return Command{}, false
+	}
+
+	return cmd, true
+}
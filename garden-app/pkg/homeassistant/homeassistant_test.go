@@ -0,0 +1,165 @@
+package homeassistant
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/babyapi"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	gardenID, _ = xid.FromString("c5cvhpcbcv45e8bp16dg")
+	zoneID, _   = xid.FromString("chkodpg3lcj13q82mq40")
+)
+
+func testOptions() Options {
+	return Options{
+		DiscoveryPrefix: "homeassistant",
+		TopicPrefix:     "garden-app",
+		ZoneComponent:   ComponentValve,
+	}
+}
+
+func testGarden() *pkg.Garden {
+	lightPin := uint(1)
+	return &pkg.Garden{
+		ID:   babyapi.ID{ID: gardenID},
+		Name: "Garden",
+		FanSchedule: &pkg.FanSchedule{
+			Duration: &pkg.Duration{Duration: time.Hour},
+			Interval: &pkg.Duration{Duration: time.Hour},
+		},
+		ControllerConfig: &pkg.ControllerConfig{
+			LightPin: &lightPin,
+			Sensors: []pkg.SensorConfig{
+				{ID: "abc", Name: "Inside", Type: "DHT22"},
+				{ID: "def", Name: "Soil", Type: "DS18B20"},
+			},
+		},
+		ControllerInfo: &pkg.ControllerInfo{FirmwareVersion: "v1.2.3"},
+	}
+}
+
+func testZone() *pkg.Zone {
+	return &pkg.Zone{
+		ID:       babyapi.ID{ID: zoneID},
+		Name:     "Zone",
+		GardenID: gardenID,
+	}
+}
+
+func TestGardenEntities(t *testing.T) {
+	entities := testOptions().GardenEntities(testGarden(), []*pkg.Zone{testZone()})
+
+	topics := []string{}
+	for _, e := range entities {
+		topics = append(topics, testOptions().DiscoveryTopic(gardenID.String(), e))
+	}
+	assert.Equal(t, []string{
+		"homeassistant/binary_sensor/garden_app_c5cvhpcbcv45e8bp16dg/health/config",
+		"homeassistant/switch/garden_app_c5cvhpcbcv45e8bp16dg/light/config",
+		"homeassistant/fan/garden_app_c5cvhpcbcv45e8bp16dg/fan/config",
+		"homeassistant/sensor/garden_app_c5cvhpcbcv45e8bp16dg/sensor_abc_temperature/config",
+		"homeassistant/sensor/garden_app_c5cvhpcbcv45e8bp16dg/sensor_abc_humidity/config",
+		"homeassistant/sensor/garden_app_c5cvhpcbcv45e8bp16dg/sensor_def_temperature/config",
+		"homeassistant/valve/garden_app_c5cvhpcbcv45e8bp16dg/zone_chkodpg3lcj13q82mq40/config",
+		"homeassistant/number/garden_app_c5cvhpcbcv45e8bp16dg/zone_chkodpg3lcj13q82mq40_duration/config",
+	}, topics)
+
+	t.Run("Fan", func(t *testing.T) {
+		fan := entities[2].Config
+		assert.Equal(t, "garden_app_c5cvhpcbcv45e8bp16dg_fan", fan.UniqueID)
+		assert.Equal(t, "garden-app/c5cvhpcbcv45e8bp16dg/fan/set", fan.CommandTopic)
+		assert.Equal(t, "garden-app/c5cvhpcbcv45e8bp16dg/fan/percentage/set", fan.PercentageCommandTopic)
+		assert.Equal(t, "v1.2.3", fan.Device.SWVersion)
+	})
+
+	t.Run("Sensor", func(t *testing.T) {
+		humidity := entities[4].Config
+		assert.Equal(t, "Inside Humidity", humidity.Name)
+		assert.Equal(t, "garden-app/c5cvhpcbcv45e8bp16dg/sensor/abc/state", humidity.StateTopic)
+		assert.Equal(t, "{{ value_json.humidity }}", humidity.ValueTemplate)
+		assert.Equal(t, "%", humidity.UnitOfMeasurement)
+	})
+
+	t.Run("ZoneValve", func(t *testing.T) {
+		zone := entities[6].Config
+		assert.Equal(t, []string{"garden_app_zone_chkodpg3lcj13q82mq40"}, zone.Device.Identifiers)
+		assert.Equal(t, "garden_app_c5cvhpcbcv45e8bp16dg", zone.Device.ViaDevice)
+
+		data, err := json.Marshal(zone)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"name": "Water",
+			"unique_id": "garden_app_c5cvhpcbcv45e8bp16dg_zone_chkodpg3lcj13q82mq40",
+			"device": {
+				"identifiers": ["garden_app_zone_chkodpg3lcj13q82mq40"],
+				"name": "Zone",
+				"manufacturer": "automated-garden",
+				"model": "Zone",
+				"via_device": "garden_app_c5cvhpcbcv45e8bp16dg"
+			},
+			"origin": {"name": "garden-app", "url": "https://github.com/calvinmclean/automated-garden"},
+			"icon": "mdi:water",
+			"device_class": "water",
+			"command_topic": "garden-app/c5cvhpcbcv45e8bp16dg/zone/chkodpg3lcj13q82mq40/set",
+			"state_topic": "garden-app/c5cvhpcbcv45e8bp16dg/zone/chkodpg3lcj13q82mq40/state",
+			"payload_open": "OPEN",
+			"payload_close": "CLOSE",
+			"state_open": "open",
+			"state_closed": "closed"
+		}`, string(data))
+	})
+
+	t.Run("ZoneSwitch", func(t *testing.T) {
+		opts := testOptions()
+		opts.ZoneComponent = ComponentSwitch
+
+		entities := opts.GardenEntities(testGarden(), []*pkg.Zone{testZone()})
+		zone := entities[6]
+		assert.Equal(t, ComponentSwitch, zone.Component)
+		assert.Equal(t, PayloadOn, zone.Config.PayloadOn)
+		assert.Empty(t, zone.Config.PayloadOpen)
+		assert.Empty(t, zone.Config.DeviceClass)
+	})
+
+	t.Run("NoLightOrFan", func(t *testing.T) {
+		entities := testOptions().GardenEntities(&pkg.Garden{ID: babyapi.ID{ID: gardenID}, Name: "Garden"}, nil)
+		require.Len(t, entities, 1)
+		assert.Equal(t, "health", entities[0].ObjectID)
+	})
+}
+
+func TestParseTopic(t *testing.T) {
+	tests := []struct {
+		topic    string
+		expected Command
+		ok       bool
+	}{
+		{"garden-app/garden/light/set", Command{GardenID: "garden", Target: TargetLight}, true},
+		{"garden-app/garden/fan/set", Command{GardenID: "garden", Target: TargetFan}, true},
+		{"garden-app/garden/fan/percentage/set", Command{GardenID: "garden", Target: TargetFanPercentage}, true},
+		{"garden-app/garden/zone/zone1/set", Command{GardenID: "garden", ZoneID: "zone1", Target: TargetZone}, true},
+		{"garden-app/garden/zone/zone1/duration/set", Command{GardenID: "garden", ZoneID: "zone1", Target: TargetZoneDuration}, true},
+		{"garden-app/garden/zone/zone1/duration/state", Command{GardenID: "garden", ZoneID: "zone1", Target: TargetZoneDuration, State: true}, true},
+		{"garden-app/garden/light/state", Command{}, false},
+		{"garden-app/garden/zone/zone1/state", Command{}, false},
+		{"garden-app/garden/sensor/abc/state", Command{}, false},
+		{"garden-app/garden/unknown/set", Command{}, false},
+		{"garden-app/garden/set", Command{}, false},
+		{"other/garden/light/set", Command{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.topic, func(t *testing.T) {
+			cmd, ok := testOptions().ParseTopic(tt.topic)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, cmd)
+		})
+	}
+}
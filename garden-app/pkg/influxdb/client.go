@@ -76,6 +76,26 @@ waterEvents = from(bucket: "garden")
 |> filter(fn: (r) => r["_field"] == "temperature" or r["_field"] == "humidity")
 |> drop(columns: ["host"])
 |> last()`
+	sensorTemperatureHistoryQueryTemplate = `
+temperature = from(bucket: "{{.Bucket}}")
+  |> range(start: {{.RangeStart}}, stop: {{.RangeStop}})
+  |> filter(fn: (r) => r["_measurement"] == "sensor")
+  |> filter(fn: (r) => r["topic"] == "{{.TopicPrefix}}/data/sensor")
+  |> filter(fn: (r) => r["sensor_id"] == "{{.SensorID}}")
+  |> filter(fn: (r) => r["_field"] == "temperature")
+  |> keep(columns: ["_time", "_value", "_field"])
+
+maxTemperature = temperature
+  |> aggregateWindow(every: 1h, fn: max, createEmpty: false, timeSrc: "_start")
+  |> set(key: "_field", value: "max")
+
+minTemperature = temperature
+  |> aggregateWindow(every: 1h, fn: min, createEmpty: false, timeSrc: "_start")
+  |> set(key: "_field", value: "min")
+
+union(tables: [maxTemperature, minTemperature])
+  |> pivot(rowKey: ["_time"], columnKey: ["_field"], valueColumn: "_value")
+  |> sort(columns: ["_time"])`
 	controllerLogsQueryTemplate = `from(bucket: "{{.Bucket}}")
 |> range(start: -{{.Start}})
 |> filter(fn: (r) => r["_measurement"] == "logs")
@@ -108,12 +128,20 @@ func (r SensorReading) IsZero() bool {
 	return r.Temperature == nil && r.Humidity == nil
 }
 
+// SensorTemperature is the range of temperatures measured by a sensor during a period starting at Time
+type SensorTemperature struct {
+	Time time.Time
+	Min  float64
+	Max  float64
+}
+
 // Client is an interface that allows querying InfluxDB for data
 type Client interface {
 	GetLastContact(context.Context, string) (time.Time, error)
 	GetWaterHistory(context.Context, string, string, time.Duration, uint64, bool) ([]pkg.WaterHistory, error)
 	GetGardenWaterHistory(context.Context, string, time.Duration, uint64, bool) ([]pkg.WaterHistory, error)
 	GetSensorReading(context.Context, string, string) (SensorReading, error)
+	GetSensorTemperatureHistory(context.Context, string, string, time.Time, time.Time) ([]SensorTemperature, error)
 	GetControllerLogs(context.Context, string, time.Duration, uint64) ([]pkg.ControllerLog, error)
 	influxdb2.Client
 }
@@ -132,6 +160,8 @@ type Config struct {
 type queryData struct {
 	Bucket      string
 	Start       time.Duration
+	RangeStart  string
+	RangeStop   string
 	ZoneID      string
 	TopicPrefix string
 	SensorID    string
@@ -299,6 +329,46 @@ func (client *client) GetSensorReading(ctx context.Context, topicPrefix string,
 	return reading, queryResult.Err()
 }
 
+// GetSensorTemperatureHistory gets the minimum and maximum temperature measured by a sensor for each hour between
+// start and end
+func (client *client) GetSensorTemperatureHistory(ctx context.Context, topicPrefix string, sensorID string, start, end time.Time) ([]SensorTemperature, error) {
+	timer := prometheus.NewTimer(influxDBClientSummary.WithLabelValues("GetSensorTemperatureHistory"))
+	defer timer.ObserveDuration()
+
+	queryString, err := queryData{
+		Bucket:      client.config.Bucket,
+		RangeStart:  start.UTC().Format(time.RFC3339),
+		RangeStop:   end.UTC().Format(time.RFC3339),
+		TopicPrefix: topicPrefix,
+		SensorID:    sensorID,
+	}.Render(sensorTemperatureHistoryQueryTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	queryAPI := client.QueryAPI(client.config.Org)
+	queryResult, err := queryAPI.Query(ctx, queryString)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []SensorTemperature{}
+	for queryResult.Next() {
+		maxValue, maxOK := queryResult.Record().ValueByKey("max").(float64)
+		minValue, minOK := queryResult.Record().ValueByKey("min").(float64)
+		if !maxOK || !minOK {
+			continue
+		}
+		result = append(result, SensorTemperature{
+			Time: queryResult.Record().Time(),
+			Min:  minValue,
+			Max:  maxValue,
+		})
+	}
+
+	return result, queryResult.Err()
+}
+
 // GetControllerLogs retrieves recent log entries published by a garden controller.
 func (client *client) GetControllerLogs(ctx context.Context, topicPrefix string, timeRange time.Duration, limit uint64) ([]pkg.ControllerLog, error) {
 	timer := prometheus.NewTimer(influxDBClientSummary.WithLabelValues("GetControllerLogs"))
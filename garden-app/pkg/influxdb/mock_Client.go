@@ -220,6 +220,36 @@ func (_m *MockClient) GetSensorReading(_a0 context.Context, _a1 string, _a2 stri
 	return r0, r1
 }
 
+// GetSensorTemperatureHistory provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4
+func (_m *MockClient) GetSensorTemperatureHistory(_a0 context.Context, _a1 string, _a2 string, _a3 time.Time, _a4 time.Time) ([]SensorTemperature, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3, _a4)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSensorTemperatureHistory")
+	}
+
+	var r0 []SensorTemperature
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time) ([]SensorTemperature, error)); ok {
+		return rf(_a0, _a1, _a2, _a3, _a4)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time, time.Time) []SensorTemperature); ok {
+		r0 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]SensorTemperature)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time, time.Time) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3, _a4)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetWaterHistory provides a mock function with given fields: _a0, _a1, _a2, _a3, _a4, _a5
 func (_m *MockClient) GetWaterHistory(_a0 context.Context, _a1 string, _a2 string, _a3 time.Duration, _a4 uint64, _a5 bool) ([]pkg.WaterHistory, error) {
 	ret := _m.Called(_a0, _a1, _a2, _a3, _a4, _a5)
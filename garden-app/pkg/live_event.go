@@ -0,0 +1,47 @@
+package pkg
+
+import (
+	"time"
+)
+
+// LiveEventType is a type of LiveEvent
+type LiveEventType string
+
+const (
+	LiveEventWaterStatus LiveEventType = "water_status"
+	LiveEventHealth      LiveEventType = "health"
+	LiveEventSensor      LiveEventType = "sensor"
+	LiveEventLog         LiveEventType = "log"
+	LiveEventLight       LiveEventType = "light"
+	LiveEventFan         LiveEventType = "fan"
+)
+
+// LiveEvent is a message from a Garden's controller that is streamed to clients as soon as it is received
+type LiveEvent struct {
+	Type      LiveEventType `json:"type"`
+	GardenID  string        `json:"garden_id"`
+	ZoneID    string        `json:"zone_id,omitempty"`
+	Timestamp time.Time     `json:"timestamp"`
+	Data      any           `json:"data,omitempty"`
+}
+
+// Name is the name of the event in a Server-Sent Events stream. It includes the Garden ID so clients can listen for
+// events from a single Garden
+func (e LiveEvent) Name() string {
+	return string(e.Type) + ":" + e.GardenID
+}
+
+// ZoneName is the name of the event for clients that listen for events from a single Zone. It is empty if the
+// event is not for a Zone
+func (e LiveEvent) ZoneName() string {
+	if e.ZoneID == "" {
+		return ""
+	}
+	return e.Name() + ":" + e.ZoneID
+}
+
+// SensorLiveEventData is the data for a LiveEventSensor
+type SensorLiveEventData struct {
+	SensorID string             `json:"sensor_id"`
+	Readings map[string]float64 `json:"readings"`
+}
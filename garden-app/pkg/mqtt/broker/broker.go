@@ -0,0 +1,434 @@
+// Package broker provides a minimal MQTT 3.1.1 broker that can run inside the garden-app for small installs
+// that don't want to run a separate broker. It supports retained messages, persistent sessions, last will messages,
+// and optional username/password authentication. Messages are delivered to subscribers with QoS 0 or 1. QoS 1
+// messages are sent again when a persistent session reconnects until they are acknowledged. QoS 2 is accepted from
+// publishers without duplicates, but is downgraded to QoS 1 for delivery. It is built on the packet encoding from the
+// Paho client so it doesn't add a dependency for features that the garden-app doesn't use
+package broker
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"sync"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+const (
+	defaultAddress = ":1883"
+
+	// maxQueuedMessages limits the number of messages stored for a disconnected persistent session and the number
+	// of unacknowledged messages stored for each session
+	maxQueuedMessages = 1000
+)
+
+// Config configures the embedded broker
+type Config struct {
+	// Listeners are the addresses that the broker accepts connections on. Defaults to a single listener on ":1883"
+	Listeners []ListenerConfig `mapstructure:"listeners" yaml:"listeners"`
+	// Users enables authentication where keys are usernames and values are passwords. Anonymous clients are
+	// allowed if no users are configured
+	Users map[string]string `mapstructure:"users" yaml:"users"` // nolint:gosec // config struct field with mapstructure tag
+}
+
+// ListenerConfig configures an address for the broker to listen on
+type ListenerConfig struct {
+	Address string `mapstructure:"address" yaml:"address"`
+	// CertFile and KeyFile enable TLS for this listener
+	CertFile string `mapstructure:"cert_file" yaml:"cert_file"`
+	KeyFile  string `mapstructure:"key_file" yaml:"key_file"`
+}
+
+func (c ListenerConfig) tls() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// Broker is an in-process MQTT broker
+type Broker struct {
+	config Config
+	logger *slog.Logger
+
+	mu        sync.Mutex
+	listeners []net.Listener
+	plainAddr net.Addr
+	sessions  map[string]*session
+	conns     map[*conn]struct{}
+	retained  map[string]*packets.PublishPacket
+	closed    bool
+
+	wg sync.WaitGroup
+}
+
+// session holds the subscriptions for a client ID. Persistent sessions are kept after the client disconnects
+// and store QoS 1 messages until it reconnects
+type session struct {
+	clientID      string
+	clean         bool
+	subscriptions map[string]byte
+	conn          *conn
+	queue         []*packets.PublishPacket
+
+	// inflight are QoS 1 messages that were sent and not acknowledged yet, in the order they were sent
+	inflight      []*packets.PublishPacket
+	nextMessageID uint16
+	// receivedQoS2 are the IDs of QoS 2 messages from the client that were published and not released yet
+	receivedQoS2 map[uint16]struct{}
+}
+
+// New creates a Broker. Use Start to begin accepting connections
+func New(config Config, logger *slog.Logger) *Broker {
+	if len(config.Listeners) == 0 {
+		config.Listeners = []ListenerConfig{{Address: defaultAddress}}
+	}
+
+	return &Broker{
+		config:   config,
+		logger:   logger,
+		sessions: map[string]*session{},
+		conns:    map[*conn]struct{}{},
+		retained: map[string]*packets.PublishPacket{},
+	}
+}
+
+// Start opens all listeners and accepts connections in the background
+func (b *Broker) Start() error {
+	for _, lc := range b.config.Listeners {
+		listener, err := b.listen(lc)
+		if err != nil {
+			_ = b.Close()
+			return err
+		}
+
+		b.mu.Lock()
+		b.listeners = append(b.listeners, listener)
+		if b.plainAddr == nil && !lc.tls() {
+			b.plainAddr = listener.Addr()
+		}
+		b.mu.Unlock()
+
+		b.logger.Info("started MQTT broker listener", "address", listener.Addr().String(), "tls", lc.tls())
+
+		b.wg.Add(1)
+		go b.accept(listener)
+	}
+
+	return nil
+}
+
+func (b *Broker) listen(lc ListenerConfig) (net.Listener, error) {
+	address := lc.Address
+	if address == "" {
+		address = defaultAddress
+	}
+
+	if !lc.tls() {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("error starting listener on %q: %w", address, err)
+		}
+		return listener, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(lc.CertFile, lc.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error loading certificate for listener on %q: %w", address, err)
+	}
+
+	listener, err := tls.Listen("tcp", address, &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error starting TLS listener on %q: %w", address, err)
+	}
+	return listener, nil
+}
+
+// Addr returns the address of the first listener without TLS, which is used for loopback connections from the
+// garden-app. It returns nil if there is no listener without TLS
+func (b *Broker) Addr() net.Addr {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.plainAddr
+}
+
+// Close stops all listeners, disconnects all clients, and waits for connections to finish
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+
+	var errs []error
+	for _, listener := range b.listeners {
+		errs = append(errs, listener.Close())
+	}
+	for c := range b.conns {
+		_ = c.Close()
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	return errors.Join(errs...)
+}
+
+func (b *Broker) accept(listener net.Listener) {
+	defer b.wg.Done()
+
+	for {
+		netConn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			b.logger.Error("error accepting MQTT connection", "error", err)
+			continue
+		}
+
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			_ = netConn.Close()
+			return
+		}
+		c := newConn(netConn, b)
+		b.conns[c] = struct{}{}
+		b.wg.Add(1)
+		b.mu.Unlock()
+
+		go func() {
+			defer b.wg.Done()
+			c.serve()
+		}()
+	}
+}
+
+// authenticate checks the username and password if users are configured
+func (b *Broker) authenticate(username string, password []byte) bool {
+	if len(b.config.Users) == 0 {
+		return true
+	}
+
+	expected, ok := b.config.Users[username]
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), password) == 1
+}
+
+// connect attaches the connection to a session for the client ID and returns true if an existing session was
+// resumed. An existing connection using the same client ID is disconnected
+func (b *Broker) connect(c *conn, clientID string, clean bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	existing, ok := b.sessions[clientID]
+	if ok && existing.conn != nil {
+		b.logger.Debug("disconnecting existing client with same ID", "client_id", clientID)
+		_ = existing.conn.Close()
+		existing.conn = nil
+	}
+
+	if !ok || clean || existing.clean {
+		b.sessions[clientID] = &session{
+			clientID:      clientID,
+			clean:         clean,
+			subscriptions: map[string]byte{},
+			conn:          c,
+			receivedQoS2:  map[uint16]struct{}{},
+		}
+		c.session = b.sessions[clientID]
+		return false
+	}
+
+	existing.conn = c
+	c.session = existing
+	return true
+}
+
+// disconnect detaches the connection from its session and removes clean sessions
+func (b *Broker) disconnect(c *conn) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.conns, c)
+	if c.session == nil || c.session.conn != c {
+		return
+	}
+
+	c.session.conn = nil
+	if c.session.clean {
+		delete(b.sessions, c.session.clientID)
+	}
+}
+
+// takeQueue returns the messages that need to be sent when a session is resumed. Unacknowledged messages are sent
+// again and messages that were queued while the session was disconnected are added to the unacknowledged messages,
+// so they are still sent if the client disconnects before receiving them
+func (b *Broker) takeQueue(s *session) []*packets.PublishPacket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	result := make([]*packets.PublishPacket, 0, len(s.inflight)+len(s.queue))
+	for _, msg := range s.inflight {
+		redelivery := newPublish(msg.TopicName, msg.Payload, msg.Qos, msg.Retain)
+		redelivery.MessageID = msg.MessageID
+		redelivery.Dup = true
+		result = append(result, redelivery)
+	}
+
+	for _, msg := range s.queue {
+		b.addInflightLocked(s, msg)
+		result = append(result, msg)
+	}
+	s.queue = nil
+
+	return result
+}
+
+// addInflight assigns a message ID that isn't used by another unacknowledged message and stores the message until it
+// is acknowledged. The oldest message is dropped if there are too many
+func (b *Broker) addInflight(s *session, msg *packets.PublishPacket) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.addInflightLocked(s, msg)
+}
+
+func (b *Broker) addInflightLocked(s *session, msg *packets.PublishPacket) {
+	inUse := func(id uint16) bool {
+		return slices.ContainsFunc(s.inflight, func(m *packets.PublishPacket) bool { return m.MessageID == id })
+	}
+	for {
+		s.nextMessageID++
+		if s.nextMessageID != 0 && !inUse(s.nextMessageID) {
+			break
+		}
+	}
+	msg.MessageID = s.nextMessageID
+
+	if len(s.inflight) >= maxQueuedMessages {
+		s.inflight = s.inflight[1:]
+	}
+	s.inflight = append(s.inflight, msg)
+}
+
+// acknowledge removes a message from the session's unacknowledged messages after PUBACK
+func (b *Broker) acknowledge(s *session, messageID uint16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s.inflight = slices.DeleteFunc(s.inflight, func(m *packets.PublishPacket) bool { return m.MessageID == messageID })
+}
+
+// receiveQoS2 records a QoS 2 message from the client and returns false if it was already received and not released
+func (b *Broker) receiveQoS2(s *session, messageID uint16) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := s.receivedQoS2[messageID]; ok {
+		return false
+	}
+	s.receivedQoS2[messageID] = struct{}{}
+	return true
+}
+
+// releaseQoS2 allows the message ID to be used for a new QoS 2 message after PUBREL
+func (b *Broker) releaseQoS2(s *session, messageID uint16) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(s.receivedQoS2, messageID)
+}
+
+// subscribe adds subscriptions to the session and returns the retained messages that match them
+func (b *Broker) subscribe(s *session, filters []string, qoss []byte) []*packets.PublishPacket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var retained []*packets.PublishPacket
+	for i, filter := range filters {
+		s.subscriptions[filter] = qoss[i]
+
+		for topic, msg := range b.retained {
+			if matchTopic(filter, topic) {
+				retained = append(retained, newPublish(topic, msg.Payload, min(msg.Qos, qoss[i]), true))
+			}
+		}
+	}
+	return retained
+}
+
+func (b *Broker) unsubscribe(s *session, filters []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, filter := range filters {
+		delete(s.subscriptions, filter)
+	}
+}
+
+// publish stores retained messages and delivers the message to all matching subscriptions. Each session receives
+// the message once using the highest QoS of its matching subscriptions
+func (b *Broker) publish(topic string, payload []byte, qos byte, retain bool) {
+	type delivery struct {
+		conn *conn
+		msg  *packets.PublishPacket
+	}
+	var deliveries []delivery
+
+	b.mu.Lock()
+	if retain {
+		if len(payload) == 0 {
+			delete(b.retained, topic)
+		} else {
+			b.retained[topic] = newPublish(topic, payload, qos, true)
+		}
+	}
+
+	for _, s := range b.sessions {
+		matched := false
+		var subQoS byte
+		for filter, filterQoS := range s.subscriptions {
+			if matchTopic(filter, topic) {
+				matched = true
+				subQoS = max(subQoS, filterQoS)
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		msg := newPublish(topic, payload, min(qos, subQoS), false)
+		switch {
+		case s.conn != nil:
+			deliveries = append(deliveries, delivery{s.conn, msg})
+		case msg.Qos > 0:
+			if len(s.queue) >= maxQueuedMessages {
+				s.queue = s.queue[1:]
+			}
+			s.queue = append(s.queue, msg)
+		}
+	}
+	b.mu.Unlock()
+
+	for _, d := range deliveries {
+		d.conn.send(d.msg)
+	}
+}
+
+func newPublish(topic string, payload []byte, qos byte, retain bool) *packets.PublishPacket {
+	msg := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+	msg.TopicName = topic
+	msg.Payload = payload
+	msg.Qos = qos
+	msg.Retain = retain
+	return msg
+}
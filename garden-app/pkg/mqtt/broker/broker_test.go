@@ -0,0 +1,429 @@
+package broker
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	paho "github.com/eclipse/paho.mqtt.golang"
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const timeout = 2 * time.Second
+
+func startBroker(t *testing.T, config Config) *Broker {
+	t.Helper()
+
+	config.Listeners = []ListenerConfig{{Address: "127.0.0.1:0"}}
+	b := New(config, slog.Default())
+	require.NoError(t, b.Start())
+	t.Cleanup(func() { _ = b.Close() })
+	return b
+}
+
+func newClient(t *testing.T, b *Broker, clientID string, configure func(*paho.ClientOptions)) paho.Client {
+	t.Helper()
+
+	opts := paho.NewClientOptions().
+		AddBroker(fmt.Sprintf("tcp://%s", b.Addr().String())).
+		SetClientID(clientID).
+		SetAutoReconnect(false)
+	if configure != nil {
+		configure(opts)
+	}
+
+	client := paho.NewClient(opts)
+	token := client.Connect()
+	require.True(t, token.WaitTimeout(timeout))
+	require.NoError(t, token.Error())
+	t.Cleanup(func() { client.Disconnect(0) })
+	return client
+}
+
+func subscribe(t *testing.T, client paho.Client, filter string) chan paho.Message {
+	t.Helper()
+
+	messages := make(chan paho.Message, 10)
+	token := client.Subscribe(filter, 1, func(_ paho.Client, msg paho.Message) {
+		messages <- msg
+	})
+	require.True(t, token.WaitTimeout(timeout))
+	require.NoError(t, token.Error())
+	return messages
+}
+
+func publish(t *testing.T, client paho.Client, topic string, retained bool, payload string) {
+	t.Helper()
+
+	token := client.Publish(topic, 1, retained, payload)
+	require.True(t, token.WaitTimeout(timeout))
+	require.NoError(t, token.Error())
+}
+
+func receive(t *testing.T, messages chan paho.Message) paho.Message {
+	t.Helper()
+
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for message")
+		return nil
+	}
+}
+
+func TestBrokerPublishSubscribe(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	subscriber := newClient(t, b, "subscriber", nil)
+	publisher := newClient(t, b, "publisher", nil)
+
+	messages := subscribe(t, subscriber, "garden/command/+")
+	publish(t, publisher, "garden/command/water", false, "water")
+	publish(t, publisher, "garden/data/water", false, "ignored")
+	publish(t, publisher, "garden/command/light", false, "light")
+
+	msg := receive(t, messages)
+	assert.Equal(t, "garden/command/water", msg.Topic())
+	assert.Equal(t, "water", string(msg.Payload()))
+
+	msg = receive(t, messages)
+	assert.Equal(t, "garden/command/light", msg.Topic())
+	assert.False(t, msg.Retained())
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		token := subscriber.Unsubscribe("garden/command/+")
+		require.True(t, token.WaitTimeout(timeout))
+		require.NoError(t, token.Error())
+
+		publish(t, publisher, "garden/command/water", false, "water")
+		assert.Empty(t, messages)
+	})
+}
+
+func TestBrokerRetainedMessages(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	publisher := newClient(t, b, "publisher", nil)
+	publish(t, publisher, "garden/light/state", true, "ON")
+
+	subscriber := newClient(t, b, "subscriber", nil)
+	msg := receive(t, subscribe(t, subscriber, "garden/#"))
+	assert.Equal(t, "ON", string(msg.Payload()))
+	assert.True(t, msg.Retained())
+
+	t.Run("EmptyPayloadClearsRetained", func(t *testing.T) {
+		publish(t, publisher, "garden/light/state", true, "")
+
+		other := newClient(t, b, "other", nil)
+		messages := subscribe(t, other, "garden/#")
+		publish(t, publisher, "garden/light/other", false, "next")
+
+		assert.Equal(t, "next", string(receive(t, messages).Payload()))
+	})
+}
+
+func TestBrokerPersistentSession(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	persistent := func(opts *paho.ClientOptions) { opts.SetCleanSession(false) }
+
+	subscriber := newClient(t, b, "subscriber", persistent)
+	subscribe(t, subscriber, "garden/command/water")
+	subscriber.Disconnect(0)
+
+	publisher := newClient(t, b, "publisher", nil)
+	publish(t, publisher, "garden/command/water", false, "queued")
+
+	// paho routes messages for resumed sessions to the default handler
+	messages := make(chan paho.Message, 10)
+	newClient(t, b, "subscriber", func(opts *paho.ClientOptions) {
+		persistent(opts)
+		opts.SetDefaultPublishHandler(func(_ paho.Client, msg paho.Message) { messages <- msg })
+	})
+
+	assert.Equal(t, "queued", string(receive(t, messages).Payload()))
+}
+
+func TestBrokerWillMessage(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	subscriber := newClient(t, b, "subscriber", nil)
+	messages := subscribe(t, subscriber, "garden/status")
+
+	newClient(t, b, "controller", func(opts *paho.ClientOptions) {
+		opts.SetWill("garden/status", "offline", 1, false)
+	})
+
+	// Close the client's connection without sending DISCONNECT
+	b.mu.Lock()
+	for c := range b.conns {
+		if c.session != nil && c.session.clientID == "controller" {
+			_ = c.Close()
+		}
+	}
+	b.mu.Unlock()
+
+	assert.Equal(t, "offline", string(receive(t, messages).Payload()))
+}
+
+func TestBrokerAuthentication(t *testing.T) {
+	b := startBroker(t, Config{Users: map[string]string{"garden": "password"}})
+
+	t.Run("Valid", func(t *testing.T) {
+		newClient(t, b, "valid", func(opts *paho.ClientOptions) {
+			opts.SetUsername("garden").SetPassword("password")
+		})
+	})
+
+	for name, configure := range map[string]func(*paho.ClientOptions){
+		"WrongPassword": func(opts *paho.ClientOptions) { opts.SetUsername("garden").SetPassword("wrong") },
+		"UnknownUser":   func(opts *paho.ClientOptions) { opts.SetUsername("other").SetPassword("password") },
+		"Anonymous":     nil,
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := paho.NewClientOptions().
+				AddBroker(fmt.Sprintf("tcp://%s", b.Addr().String())).
+				SetClientID(name).
+				SetAutoReconnect(false)
+			if configure != nil {
+				configure(opts)
+			}
+
+			token := paho.NewClient(opts).Connect()
+			require.True(t, token.WaitTimeout(timeout))
+			assert.ErrorContains(t, token.Error(), "bad user name or password")
+		})
+	}
+}
+
+// rawClient connects without a client library so tests can control when packets are read and acknowledged
+type rawClient struct {
+	net.Conn
+}
+
+func newRawClient(t *testing.T, b *Broker, clientID string, cleanSession bool) *rawClient {
+	t.Helper()
+
+	netConn, err := net.Dial("tcp", b.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = netConn.Close() })
+
+	c := &rawClient{netConn}
+
+	connect := packets.NewControlPacket(packets.Connect).(*packets.ConnectPacket)
+	connect.ProtocolName = "MQTT"
+	connect.ProtocolVersion = 4
+	connect.ClientIdentifier = clientID
+	connect.CleanSession = cleanSession
+	c.write(t, connect)
+
+	connack, ok := c.read(t).(*packets.ConnackPacket)
+	require.True(t, ok)
+	require.Equal(t, byte(packets.Accepted), connack.ReturnCode)
+	return c
+}
+
+func (c *rawClient) write(t *testing.T, p packets.ControlPacket) {
+	t.Helper()
+	require.NoError(t, p.Write(c.Conn))
+}
+
+func (c *rawClient) read(t *testing.T) packets.ControlPacket {
+	t.Helper()
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(timeout)))
+	p, err := packets.ReadPacket(c.Conn)
+	require.NoError(t, err)
+	return p
+}
+
+func (c *rawClient) subscribe(t *testing.T, filter string, qos byte) {
+	t.Helper()
+
+	subscribe := packets.NewControlPacket(packets.Subscribe).(*packets.SubscribePacket)
+	subscribe.MessageID = 1
+	subscribe.Topics = []string{filter}
+	subscribe.Qoss = []byte{qos}
+	c.write(t, subscribe)
+
+	_, ok := c.read(t).(*packets.SubackPacket)
+	require.True(t, ok)
+}
+
+func (c *rawClient) publish(t *testing.T, topic, payload string, qos byte, messageID uint16) {
+	t.Helper()
+
+	msg := newPublish(topic, []byte(payload), qos, false)
+	msg.MessageID = messageID
+	c.write(t, msg)
+}
+
+func TestBrokerRedeliversUnacknowledged(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	subscriber := newRawClient(t, b, "subscriber", false)
+	subscriber.subscribe(t, "garden/command/water", 1)
+
+	publisher := newClient(t, b, "publisher", nil)
+	publish(t, publisher, "garden/command/water", false, "water")
+
+	msg, ok := subscriber.read(t).(*packets.PublishPacket)
+	require.True(t, ok)
+	assert.Equal(t, "water", string(msg.Payload))
+	assert.False(t, msg.Dup)
+
+	// Reconnect without sending PUBACK
+	_ = subscriber.Close()
+	subscriber = newRawClient(t, b, "subscriber", false)
+
+	redelivered, ok := subscriber.read(t).(*packets.PublishPacket)
+	require.True(t, ok)
+	assert.Equal(t, "water", string(redelivered.Payload))
+	assert.Equal(t, msg.MessageID, redelivered.MessageID)
+	assert.True(t, redelivered.Dup)
+
+	puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+	puback.MessageID = redelivered.MessageID
+	subscriber.write(t, puback)
+
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return len(b.sessions["subscriber"].inflight) == 0
+	}, timeout, 10*time.Millisecond)
+}
+
+func TestBrokerPersistentSessionLargeQueue(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	subscriber := newRawClient(t, b, "subscriber", false)
+	subscriber.subscribe(t, "garden/command/water", 1)
+	_ = subscriber.Close()
+
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		return b.sessions["subscriber"].conn == nil
+	}, timeout, 10*time.Millisecond)
+
+	// More messages are queued than fit in the outbound buffer
+	numMessages := outboundBufferSize * 2
+	publisher := newClient(t, b, "publisher", nil)
+	for i := range numMessages {
+		publish(t, publisher, "garden/command/water", false, fmt.Sprint(i))
+	}
+
+	subscriber = newRawClient(t, b, "subscriber", false)
+	for i := range numMessages {
+		msg, ok := subscriber.read(t).(*packets.PublishPacket)
+		require.True(t, ok)
+		assert.Equal(t, fmt.Sprint(i), string(msg.Payload))
+
+		puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+		puback.MessageID = msg.MessageID
+		subscriber.write(t, puback)
+	}
+
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		s := b.sessions["subscriber"]
+		return s.conn != nil && len(s.inflight) == 0 && len(s.queue) == 0
+	}, timeout, 10*time.Millisecond)
+}
+
+func TestBrokerQoS2Duplicates(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	subscriber := newClient(t, b, "subscriber", nil)
+	messages := subscribe(t, subscriber, "garden/command/water")
+
+	publisher := newRawClient(t, b, "publisher", true)
+	for range 2 {
+		publisher.publish(t, "garden/command/water", "first", 2, 1)
+		_, ok := publisher.read(t).(*packets.PubrecPacket)
+		require.True(t, ok)
+	}
+
+	pubrel := packets.NewControlPacket(packets.Pubrel).(*packets.PubrelPacket)
+	pubrel.MessageID = 1
+	publisher.write(t, pubrel)
+	_, ok := publisher.read(t).(*packets.PubcompPacket)
+	require.True(t, ok)
+
+	// The message ID can be used again after it is released
+	publisher.publish(t, "garden/command/water", "second", 2, 1)
+
+	assert.Equal(t, "first", string(receive(t, messages).Payload()))
+	assert.Equal(t, "second", string(receive(t, messages).Payload()))
+	assert.Empty(t, messages)
+}
+
+func TestBrokerSlowSubscriber(t *testing.T) {
+	b := startBroker(t, Config{})
+
+	// This client subscribes and never reads, so the socket buffers fill up
+	slow := newRawClient(t, b, "slow", true)
+	slow.subscribe(t, "garden/#", 0)
+
+	subscriber := newClient(t, b, "subscriber", nil)
+	messages := make(chan paho.Message, outboundBufferSize*2)
+	token := subscriber.Subscribe("garden/#", 0, func(_ paho.Client, msg paho.Message) { messages <- msg })
+	require.True(t, token.WaitTimeout(timeout))
+	require.NoError(t, token.Error())
+
+	publisher := newClient(t, b, "publisher", nil)
+	payload := strings.Repeat("x", 64*1024)
+	for range outboundBufferSize * 2 {
+		publish(t, publisher, "garden/data", false, payload)
+	}
+
+	require.Eventually(t, func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		_, ok := b.sessions["slow"]
+		return !ok
+	}, timeout, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		return len(messages) == outboundBufferSize*2
+	}, timeout, 10*time.Millisecond)
+}
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		filter, topic string
+		expected      bool
+	}{
+		{"garden/command/water", "garden/command/water", true},
+		{"garden/command/water", "garden/command/light", false},
+		{"garden/+/water", "garden/command/water", true},
+		{"garden/+", "garden/command/water", false},
+		{"garden/#", "garden/command/water", true},
+		{"garden/#", "garden", true},
+		{"#", "garden/command/water", true},
+		{"#", "$SYS/uptime", false},
+		{"+/uptime", "$SYS/uptime", false},
+		{"$SYS/#", "$SYS/uptime", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter+"_"+tt.topic, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchTopic(tt.filter, tt.topic))
+		})
+	}
+}
+
+func TestValidTopicFilter(t *testing.T) {
+	assert.True(t, validTopicFilter("garden/+/water"))
+	assert.True(t, validTopicFilter("garden/#"))
+	assert.False(t, validTopicFilter(""))
+	assert.False(t, validTopicFilter("garden/#/water"))
+	assert.False(t, validTopicFilter("garden/wat+er"))
+	assert.False(t, validTopicFilter("garden#"))
+}
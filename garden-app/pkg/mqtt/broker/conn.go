@@ -0,0 +1,353 @@
+package broker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+)
+
+const (
+	// connectTimeout is how long a new connection has to send CONNECT
+	connectTimeout = 10 * time.Second
+
+	// writeTimeout is how long a single packet can take to write before the client is disconnected
+	writeTimeout = 10 * time.Second
+
+	// outboundBufferSize is the number of packets that can be waiting to be written to a client. A client that falls
+	// this far behind on published messages is disconnected so it doesn't block publishers
+	outboundBufferSize = 256
+)
+
+var (
+	// errSlowClient is returned when a published message can't be delivered because the client's outbound buffer is
+	// full
+	errSlowClient = errors.New("client is not reading packets fast enough")
+	// errWriteTimeout is returned when a response to the client waits too long for room in the outbound buffer
+	errWriteTimeout = errors.New("timed out waiting to write to client")
+)
+
+// conn is a single client connection. Packets are written by a separate goroutine so a client that isn't reading
+// doesn't block publishers or other clients
+type conn struct {
+	net.Conn
+	broker  *Broker
+	session *session
+
+	outbound  chan packets.ControlPacket
+	done      chan struct{}
+	closeOnce sync.Once
+
+	will *packets.PublishPacket
+}
+
+func newConn(netConn net.Conn, b *Broker) *conn {
+	return &conn{
+		Conn:     netConn,
+		broker:   b,
+		outbound: make(chan packets.ControlPacket, outboundBufferSize),
+		done:     make(chan struct{}),
+	}
+}
+
+// Close stops writing to the client and closes the connection
+func (c *conn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.done)
+		err = c.Conn.Close()
+	})
+	return err
+}
+
+// serve handles the connection until the client disconnects or an error occurs
+func (c *conn) serve() {
+	defer func() {
+		_ = c.Close()
+		c.broker.disconnect(c)
+	}()
+
+	logger := c.broker.logger.With("remote_addr", c.RemoteAddr().String())
+
+	connect, err := c.readConnect()
+	if err != nil {
+		logger.Debug("error reading CONNECT", "error", err)
+		return
+	}
+	logger = logger.With("client_id", connect.ClientIdentifier)
+
+	sessionPresent := c.broker.connect(c, connect.ClientIdentifier, connect.CleanSession)
+
+	connack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+	connack.ReturnCode = packets.Accepted
+	connack.SessionPresent = sessionPresent
+
+	// The session's stored messages are written directly by the write loop instead of using the outbound buffer,
+	// which only limits how far behind the client can get on new messages
+	initial := []packets.ControlPacket{connack}
+	for _, msg := range c.broker.takeQueue(c.session) {
+		initial = append(initial, msg)
+	}
+
+	c.broker.wg.Add(1)
+	go func() {
+		defer c.broker.wg.Done()
+		c.writeLoop(initial)
+	}()
+	logger.Debug("MQTT client connected", "session_present", sessionPresent, "queued_messages", len(initial)-1)
+
+	err = c.readLoop(time.Duration(connect.Keepalive) * time.Second)
+	switch {
+	case err == nil:
+		logger.Debug("MQTT client disconnected")
+	case errors.Is(err, io.EOF), errors.Is(err, net.ErrClosed):
+		logger.Debug("MQTT client connection closed")
+	default:
+		logger.Debug("MQTT client connection error", "error", err)
+	}
+
+	// The will message is only published if the client did not disconnect cleanly
+	if err != nil && c.will != nil {
+		c.broker.publish(c.will.TopicName, c.will.Payload, min(c.will.Qos, 1), c.will.Retain)
+	}
+}
+
+// readConnect reads the CONNECT packet and responds with an error CONNACK if it is rejected
+func (c *conn) readConnect() (*packets.ConnectPacket, error) {
+	_ = c.SetReadDeadline(time.Now().Add(connectTimeout))
+
+	p, err := packets.ReadPacket(c)
+	if err != nil {
+		return nil, err
+	}
+
+	connect, ok := p.(*packets.ConnectPacket)
+	if !ok {
+		return nil, fmt.Errorf("expected CONNECT but received %s", p.String())
+	}
+
+	code := connect.Validate()
+	if code == packets.Accepted && !c.broker.authenticate(connect.Username, connect.Password) {
+		code = packets.ErrRefusedBadUsernameOrPassword
+	}
+	if code != packets.Accepted {
+		// The write loop isn't started until the connection is accepted, so this is written directly
+		connack := packets.NewControlPacket(packets.Connack).(*packets.ConnackPacket)
+		connack.ReturnCode = code
+		_ = c.SetWriteDeadline(time.Now().Add(writeTimeout))
+		_ = connack.Write(c.Conn)
+		return nil, fmt.Errorf("connection refused: %s", packets.ConnackReturnCodes[code])
+	}
+
+	if connect.ClientIdentifier == "" {
+		connect.ClientIdentifier = generateClientID()
+	}
+
+	if connect.WillFlag {
+		c.will = newPublish(connect.WillTopic, connect.WillMessage, connect.WillQos, connect.WillRetain)
+	}
+
+	return connect, nil
+}
+
+// readLoop handles packets from the client. It returns nil when the client sends DISCONNECT
+func (c *conn) readLoop(keepalive time.Duration) error {
+	for {
+		// Clients must send a packet within one and a half times the keepalive period
+		if keepalive > 0 {
+			_ = c.SetReadDeadline(time.Now().Add(keepalive * 3 / 2))
+		} else {
+			_ = c.SetReadDeadline(time.Time{})
+		}
+
+		p, err := packets.ReadPacket(c)
+		if err != nil {
+			return err
+		}
+
+		switch p := p.(type) {
+		case *packets.PublishPacket:
+			err = c.handlePublish(p)
+		case *packets.PubrelPacket:
+			c.broker.releaseQoS2(c.session, p.MessageID)
+			pubcomp := packets.NewControlPacket(packets.Pubcomp).(*packets.PubcompPacket)
+			pubcomp.MessageID = p.MessageID
+			err = c.write(pubcomp)
+		case *packets.SubscribePacket:
+			err = c.handleSubscribe(p)
+		case *packets.UnsubscribePacket:
+			c.broker.unsubscribe(c.session, p.Topics)
+			unsuback := packets.NewControlPacket(packets.Unsuback).(*packets.UnsubackPacket)
+			unsuback.MessageID = p.MessageID
+			err = c.write(unsuback)
+		case *packets.PingreqPacket:
+			err = c.write(packets.NewControlPacket(packets.Pingresp))
+		case *packets.DisconnectPacket:
+			return nil
+		case *packets.PubackPacket:
+			c.broker.acknowledge(c.session, p.MessageID)
+		case *packets.PubrecPacket, *packets.PubcompPacket:
+			// Messages are not sent to clients with QoS 2, so these are not expected
+		default:
+			return fmt.Errorf("unexpected packet: %s", p.String())
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *conn) handlePublish(p *packets.PublishPacket) error {
+	if !validTopicName(p.TopicName) {
+		return fmt.Errorf("invalid topic name %q", p.TopicName)
+	}
+
+	// A QoS 2 message is published when it is first received. It can be sent again until PUBREL is received, so
+	// duplicates are acknowledged without publishing them again
+	if p.Qos < 2 || c.broker.receiveQoS2(c.session, p.MessageID) {
+		c.broker.publish(p.TopicName, p.Payload, min(p.Qos, 1), p.Retain)
+	}
+
+	switch p.Qos {
+	case 1:
+		puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+		puback.MessageID = p.MessageID
+		return c.write(puback)
+	case 2:
+		pubrec := packets.NewControlPacket(packets.Pubrec).(*packets.PubrecPacket)
+		pubrec.MessageID = p.MessageID
+		return c.write(pubrec)
+	}
+	return nil
+}
+
+func (c *conn) handleSubscribe(p *packets.SubscribePacket) error {
+	suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+	suback.MessageID = p.MessageID
+
+	filters := []string{}
+	qoss := []byte{}
+	for i, filter := range p.Topics {
+		if !validTopicFilter(filter) {
+			suback.ReturnCodes = append(suback.ReturnCodes, 0x80)
+			continue
+		}
+
+		// QoS 2 is not supported, so it is downgraded to QoS 1
+		qos := min(p.Qoss[i], 1)
+		filters = append(filters, filter)
+		qoss = append(qoss, qos)
+		suback.ReturnCodes = append(suback.ReturnCodes, qos)
+	}
+
+	retained := c.broker.subscribe(c.session, filters, qoss)
+
+	err := c.write(suback)
+	if err != nil {
+		return err
+	}
+
+	// Retained messages are a response to the subscription, so they wait for room in the outbound buffer instead
+	// of disconnecting the client when there are a lot of them
+	for _, msg := range retained {
+		if msg.Qos > 0 {
+			c.broker.addInflight(c.session, msg)
+		}
+		err = c.write(msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send delivers a published message to the client. QoS 1 messages are kept in the session until the client
+// acknowledges them so they can be sent again if the client reconnects. The publisher doesn't wait for the client,
+// so a client that is too far behind is disconnected
+func (c *conn) send(msg *packets.PublishPacket) {
+	if msg.Qos > 0 {
+		c.broker.addInflight(c.session, msg)
+	}
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.outbound <- msg:
+	default:
+		c.broker.logger.Warn("disconnecting slow MQTT client", "remote_addr", c.RemoteAddr().String(), "error", errSlowClient)
+		_ = c.Close()
+	}
+}
+
+// write queues a response to the client. It is called while handling the client's packets, so waiting for room in
+// the outbound buffer stops reading from the client until it catches up. The client is disconnected if it doesn't
+// catch up within the writeTimeout
+func (c *conn) write(p packets.ControlPacket) error {
+	select {
+	case <-c.done:
+		return net.ErrClosed
+	case c.outbound <- p:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(writeTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+		return net.ErrClosed
+	case c.outbound <- p:
+		return nil
+	case <-timer.C:
+		_ = c.Close()
+		return errWriteTimeout
+	}
+}
+
+// writeLoop writes the initial packets and then writes queued packets until the connection is closed. Each write has
+// a deadline so a client that stops reading is disconnected
+func (c *conn) writeLoop(initial []packets.ControlPacket) {
+	for _, p := range initial {
+		if !c.writePacket(p) {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case p := <-c.outbound:
+			if !c.writePacket(p) {
+				return
+			}
+		}
+	}
+}
+
+// writePacket writes the packet to the connection and closes it if there is an error
+func (c *conn) writePacket(p packets.ControlPacket) bool {
+	_ = c.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := p.Write(c.Conn); err != nil {
+		_ = c.Close()
+		return false
+	}
+	return true
+}
+
+func generateClientID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "auto-" + hex.EncodeToString(b)
+}
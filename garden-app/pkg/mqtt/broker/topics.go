@@ -0,0 +1,52 @@
+package broker
+
+import "strings"
+
+// matchTopic returns true if the topic name matches the subscription filter, which can use "+" and "#" wildcards
+func matchTopic(filter, topic string) bool {
+	filterLevels := strings.Split(filter, "/")
+	topicLevels := strings.Split(topic, "/")
+
+	// Topics starting with "$" are reserved and don't match filters starting with a wildcard
+	if strings.HasPrefix(topic, "$") && (filterLevels[0] == "+" || filterLevels[0] == "#") {
+		return false
+	}
+
+	for i, level := range filterLevels {
+		if level == "#" {
+			return true
+		}
+		if i >= len(topicLevels) {
+			return false
+		}
+		if level != "+" && level != topicLevels[i] {
+			return false
+		}
+	}
+
+	return len(filterLevels) == len(topicLevels)
+}
+
+// validTopicName returns true if the topic can be published to
+func validTopicName(topic string) bool {
+	return topic != "" && !strings.ContainsAny(topic, "+#")
+}
+
+// validTopicFilter returns true if the filter can be subscribed to. Wildcards must occupy an entire level and "#"
+// must be the last level
+func validTopicFilter(filter string) bool {
+	if filter == "" {
+		return false
+	}
+
+	levels := strings.Split(filter, "/")
+	for i, level := range levels {
+		if strings.Contains(level, "#") && (level != "#" || i != len(levels)-1) {
+			return false
+		}
+		if strings.Contains(level, "+") && level != "+" {
+			return false
+		}
+	}
+	return true
+}
@@ -59,6 +59,42 @@ func (_m *MockClient) Publish(_a0 context.Context, _a1 string, _a2 []byte) error
 	return r0
 }
 
+// PublishRetained provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockClient) PublishRetained(_a0 context.Context, _a1 string, _a2 []byte) error {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishRetained")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) error); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Status provides a mock function with no fields
+func (_m *MockClient) Status() Status {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Status")
+	}
+
+	var r0 Status
+	if rf, ok := ret.Get(0).(func() Status); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(Status)
+	}
+
+	return r0
+}
+
 // NewMockClient creates a new instance of MockClient. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMockClient(t interface {
@@ -5,17 +5,31 @@ package mqtt
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt/broker"
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 const QOS = byte(1)
 
+// ErrNotConnected is returned by Publish when the message could not be sent because the client is not connected
+// to the broker
+var ErrNotConnected = errors.New("unable to connect to MQTT broker")
+
 var mqttClientSummary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
 	Namespace: "garden_app",
 	Name:      "mqtt_client_duration_seconds",
@@ -25,16 +39,137 @@ var mqttClientSummary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
 // Config is used to read the necessary configuration values from a YAML file
 type Config struct {
 	ClientID string `mapstructure:"client_id" yaml:"client_id"`
-	Broker   string `mapstructure:"broker" yaml:"broker"`
-	Port     int    `mapstructure:"port" yaml:"port"`
+	// Broker is the hostname or IP address of the broker. It can also be a full URL like "wss://broker:443/mqtt",
+	// in which case Scheme and Port are only used if the URL doesn't include them
+	Broker string `mapstructure:"broker" yaml:"broker"`
+	Port   int    `mapstructure:"port" yaml:"port"`
+	// Scheme is the protocol used to connect to the broker: tcp (default), ssl, ws, or wss
+	Scheme string `mapstructure:"scheme" yaml:"scheme"`
+
+	Username string    `mapstructure:"username" yaml:"username"`
+	Password string    `mapstructure:"password" yaml:"password"` // nolint:gosec // config struct field with mapstructure tag
+	TLS      TLSConfig `mapstructure:"tls" yaml:"tls"`
+
+	// Embedded starts an in-process broker when running "garden-app serve". The garden-app connects to it over
+	// loopback, so Broker, Port, and Scheme are not used. Username and Password are still used if the broker
+	// has users configured
+	Embedded       bool          `mapstructure:"embedded" yaml:"embedded"`
+	EmbeddedBroker broker.Config `mapstructure:"embedded_broker" yaml:"embedded_broker"`
+}
+
+// StartEmbeddedBroker starts the embedded broker and returns a copy of the Config that connects to it over loopback
+func (c Config) StartEmbeddedBroker(logger *slog.Logger) (*broker.Broker, Config, error) {
+	b := broker.New(c.EmbeddedBroker, logger)
+	err := b.Start()
+	if err != nil {
+		return nil, c, fmt.Errorf("error starting embedded MQTT broker: %w", err)
+	}
+
+	addr, ok := b.Addr().(*net.TCPAddr)
+	if !ok {
+		_ = b.Close()
+		return nil, c, errors.New("embedded MQTT broker requires a listener without TLS for the garden-app to connect to")
+	}
+
+	host := addr.IP
+	if host.IsUnspecified() {
+		host = net.IPv4(127, 0, 0, 1)
+	}
+
+	c.Broker = host.String()
+	c.Port = addr.Port
+	c.Scheme = "tcp"
+
+	return b, c, nil
+}
+
+// TLSConfig configures TLS for ssl:// and wss:// connections
+type TLSConfig struct {
+	// CAFile is a PEM file with the CA certificates used to verify the broker. System certificates are used if
+	// it is not set
+	CAFile string `mapstructure:"ca_file" yaml:"ca_file"`
+	// CertFile and KeyFile are PEM files with the client certificate and key used for mutual TLS
+	CertFile           string `mapstructure:"cert_file" yaml:"cert_file"`
+	KeyFile            string `mapstructure:"key_file" yaml:"key_file"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+// BrokerURL returns the full URL used to connect to the broker
+func (c Config) BrokerURL() (string, error) {
+	broker := c.Broker
+	if !strings.Contains(broker, "://") {
+		scheme := c.Scheme
+		if scheme == "" {
+			scheme = "tcp"
+		}
+		broker = fmt.Sprintf("%s://%s", scheme, broker)
+	}
+
+	u, err := url.Parse(broker)
+	if err != nil {
+		return "", fmt.Errorf("invalid broker: %w", err)
+	}
+
+	switch u.Scheme {
+	case "tcp", "ssl", "ws", "wss":
+	default:
+		return "", fmt.Errorf("unsupported scheme %q: must be one of tcp, ssl, ws, or wss", u.Scheme)
+	}
+
+	if u.Port() == "" && c.Port != 0 {
+		u.Host = net.JoinHostPort(u.Hostname(), strconv.Itoa(c.Port))
+	}
+
+	return u.String(), nil
+}
+
+// UseTLS returns true if the broker URL uses a TLS scheme
+func (c Config) UseTLS() bool {
+	brokerURL, err := c.BrokerURL()
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(brokerURL, "ssl://") || strings.HasPrefix(brokerURL, "wss://")
+}
+
+// tlsConfig creates the tls.Config using the configured certificate files
+func (c TLSConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify, // nolint:gosec // explicitly enabled by user config
+	}
+
+	if c.CAFile != "" {
+		caCert, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading CA file: %w", err)
+		}
+
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no valid certificates found in CA file %q", c.CAFile)
+		}
+	}
+
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
 }
 
 // Client is an interface that allows access to MQTT functionality within the garden-app
 type Client interface {
 	Publish(context.Context, string, []byte) error
+	PublishRetained(context.Context, string, []byte) error
 	Connect() error
 	Disconnect(uint)
 	AddHandler(TopicHandler)
+	Status() Status
 }
 
 // client is a wrapper struct for connecting our config and MQTT Client. It implements the Client interface
@@ -42,7 +177,14 @@ type client struct {
 	mu sync.Mutex
 	mqtt.Client
 
-	handlers []TopicHandler
+	// stateMu protects the handlers and connection status, which are updated by paho callbacks
+	stateMu       sync.Mutex
+	handlers      []TopicHandler
+	status        Status
+	subscriptions map[string]SubscriptionStatus
+	generation    uint64
+
+	subscribeRetryDelays []time.Duration
 
 	Config
 }
@@ -57,41 +199,64 @@ type TopicHandler struct {
 // using the supplied functions to handle incoming messages. It really should be used with only one function,
 // but I wanted to make it an optional argument, which required using the variadic function argument
 func NewClient(config Config, defaultHandler mqtt.MessageHandler, handlers ...TopicHandler) (Client, error) {
+	now := clock.Now()
 	client := &client{
-		Config:   config,
-		handlers: handlers,
+		Config:        config,
+		handlers:      handlers,
+		status:        Status{DisconnectedSince: &now},
+		subscriptions: map[string]SubscriptionStatus{},
+
+		subscribeRetryDelays: defaultSubscribeRetryDelays,
+	}
+
+	brokerURL, err := config.BrokerURL()
+	if err != nil {
+		return nil, err
 	}
 
-	opts := mqtt.NewClientOptions().AddBroker(fmt.Sprintf("tcp://%s:%d", config.Broker, config.Port))
+	opts := mqtt.NewClientOptions().AddBroker(brokerURL)
 	opts.ClientID = config.ClientID
-	opts.AutoReconnect = true
-	opts.CleanSession = false
-	opts.OnConnect = func(c mqtt.Client) {
-		for _, handler := range client.handlers {
-			token := c.Subscribe(handler.Topic, QOS, handler.Handler)
-			if token.Wait() && token.Error() != nil {
-				// TODO: can I return an error instead of panicking (recover maybe?)
-				panic(token.Error())
-			}
+	opts.Username = config.Username
+	opts.Password = config.Password
+	if config.UseTLS() {
+		tlsConfig, err := config.TLS.tlsConfig()
+		if err != nil {
+			return nil, err
 		}
+		opts.SetTLSConfig(tlsConfig)
 	}
+	opts.AutoReconnect = true
+	opts.CleanSession = false
+	opts.OnConnect = client.onConnect
+	opts.OnConnectionLost = client.onConnectionLost
 	opts.DefaultPublishHandler = defaultHandler
 
-	err := prometheus.Register(mqttClientSummary)
-	if err != nil && errors.Is(err, prometheus.AlreadyRegisteredError{}) {
-		return nil, err
+	for _, collector := range []prometheus.Collector{
+		mqttClientSummary, mqttConnected, mqttSubscribed, mqttSubscribeErrors, mqttConnectionLost,
+	} {
+		err = prometheus.Register(collector)
+		if err != nil && errors.Is(err, prometheus.AlreadyRegisteredError{}) {
+			return nil, err
+		}
 	}
+	mqttConnected.WithLabelValues(config.ClientID).Set(0)
 
 	client.Client = mqtt.NewClient(opts)
 
 	return client, nil
 }
 
+// AddHandler adds a topic subscription. If the client is already connected, it subscribes in the background
+// and retries until it succeeds
 func (c *client) AddHandler(handler TopicHandler) {
+	c.stateMu.Lock()
 	c.handlers = append(c.handlers, handler)
-	if c.Client != nil && c.Client.IsConnected() {
-		token := c.Client.Subscribe(handler.Topic, QOS, handler.Handler)
-		_ = token.Wait()
+	connected := c.status.Connected
+	generation := c.generation
+	c.stateMu.Unlock()
+
+	if c.Client != nil && connected {
+		go c.subscribe(c.Client, handler, generation)
 	}
 }
 
@@ -105,7 +270,20 @@ func (c *client) Connect() error {
 	}
 	token := c.Client.Connect()
 	token.Wait()
-	return token.Error()
+
+	err := token.Error()
+	if err != nil {
+		c.stateMu.Lock()
+		c.status.LastError = err.Error()
+		c.stateMu.Unlock()
+	}
+	return err
+}
+
+// Disconnect closes the connection to the broker and stops retrying subscriptions
+func (c *client) Disconnect(quiesce uint) {
+	c.Client.Disconnect(quiesce)
+	c.setDisconnected(nil)
 }
 
 // Publish will send the message to the specified MQTT topic
@@ -113,18 +291,34 @@ func (c *client) Publish(ctx context.Context, topic string, message []byte) erro
 	timer := prometheus.NewTimer(mqttClientSummary.WithLabelValues("Publish", topic))
 	defer timer.ObserveDuration()
 
+	return c.publish(ctx, topic, message, false)
+}
+
+// PublishRetained will send the message to the specified MQTT topic and the broker will keep it for new
+// subscribers. An empty message clears the retained message
+func (c *client) PublishRetained(ctx context.Context, topic string, message []byte) error {
+	timer := prometheus.NewTimer(mqttClientSummary.WithLabelValues("PublishRetained", topic))
+	defer timer.ObserveDuration()
+
+	return c.publish(ctx, topic, message, true)
+}
+
+func (c *client) publish(ctx context.Context, topic string, message []byte, retained bool) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	if len(topic) == 0 {
 		return fmt.Errorf("unable to publish with an empty topic")
 	}
 	if err := c.Connect(); err != nil {
-		return fmt.Errorf("unable to connect to MQTT broker: %v", err)
+		return fmt.Errorf("%w: %v", ErrNotConnected, err)
 	}
 
-	token := c.Client.Publish(topic, byte(1), false, message)
+	token := c.Client.Publish(topic, QOS, retained, message)
 	select {
 	case <-token.Done():
+		if errors.Is(token.Error(), mqtt.ErrNotConnected) {
+			return fmt.Errorf("%w: %v", ErrNotConnected, token.Error())
+		}
 		if token.Error() != nil {
 			return fmt.Errorf("unable to publish MQTT message: %v", token.Error())
 		}
@@ -0,0 +1,162 @@
+package mqtt
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt/broker"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		expected    string
+		expectedTLS bool
+		expectedErr string
+	}{
+		{
+			"DefaultTCP",
+			Config{Broker: "localhost", Port: 1883},
+			"tcp://localhost:1883",
+			false,
+			"",
+		},
+		{
+			"SSLScheme",
+			Config{Broker: "broker.example.com", Port: 8883, Scheme: "ssl"},
+			"ssl://broker.example.com:8883",
+			true,
+			"",
+		},
+		{
+			"WebsocketURL",
+			Config{Broker: "wss://broker.example.com/mqtt", Port: 443},
+			"wss://broker.example.com:443/mqtt",
+			true,
+			"",
+		},
+		{
+			"URLPortTakesPrecedence",
+			Config{Broker: "ws://broker.example.com:8080/mqtt", Port: 1883},
+			"ws://broker.example.com:8080/mqtt",
+			false,
+			"",
+		},
+		{
+			"UnsupportedScheme",
+			Config{Broker: "localhost", Port: 1883, Scheme: "http"},
+			"",
+			false,
+			`unsupported scheme "http": must be one of tcp, ssl, ws, or wss`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			brokerURL, err := tt.config.BrokerURL()
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, brokerURL)
+			assert.Equal(t, tt.expectedTLS, tt.config.UseTLS())
+		})
+	}
+}
+
+func TestNewClientTLSErrors(t *testing.T) {
+	t.Run("MissingCAFile", func(t *testing.T) {
+		_, err := NewClient(Config{
+			Broker: "localhost",
+			Port:   8883,
+			Scheme: "ssl",
+			TLS:    TLSConfig{CAFile: "does-not-exist.pem"},
+		}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "error reading CA file")
+	})
+
+	t.Run("TLSIgnoredForTCP", func(t *testing.T) {
+		client, err := NewClient(Config{
+			Broker: "localhost",
+			Port:   1883,
+			TLS:    TLSConfig{CAFile: "does-not-exist.pem"},
+		}, nil)
+		require.NoError(t, err)
+		assert.NotNil(t, client)
+	})
+}
+
+func TestClientStatus(t *testing.T) {
+	b := broker.New(broker.Config{Listeners: []broker.ListenerConfig{{Address: "127.0.0.1:0"}}}, slog.Default())
+	require.NoError(t, b.Start())
+	defer func() { _ = b.Close() }()
+
+	addr := b.Addr().(*net.TCPAddr)
+	received := make(chan string, 1)
+	c, err := NewClient(Config{
+		ClientID: "status-test",
+		Broker:   addr.IP.String(),
+		Port:     addr.Port,
+	}, nil, TopicHandler{
+		Topic:   "garden/data/+",
+		Handler: func(_ mqtt.Client, msg mqtt.Message) { received <- msg.Topic() },
+	})
+	require.NoError(t, err)
+
+	t.Run("NotConnected", func(t *testing.T) {
+		status := c.Status()
+		assert.False(t, status.Connected)
+		assert.False(t, status.Healthy())
+		assert.NotNil(t, status.DisconnectedSince)
+		assert.Equal(t, []SubscriptionStatus{{Topic: "garden/data/+"}}, status.Subscriptions)
+	})
+
+	require.NoError(t, c.Connect())
+
+	t.Run("Connected", func(t *testing.T) {
+		require.Eventually(t, func() bool { return c.Status().Healthy() }, time.Second, 10*time.Millisecond)
+
+		status := c.Status()
+		assert.NotNil(t, status.LastConnect)
+		assert.Nil(t, status.DisconnectedSince)
+		assert.Equal(t, time.Duration(0), status.DisconnectedFor())
+
+		require.NoError(t, c.Publish(context.Background(), "garden/data/water", []byte("data")))
+		assert.Equal(t, "garden/data/water", <-received)
+	})
+
+	t.Run("SubscriptionErrorRetried", func(t *testing.T) {
+		c.(*client).subscribeRetryDelays = []time.Duration{10 * time.Millisecond}
+		c.AddHandler(TopicHandler{Topic: "garden/#/invalid", Handler: func(mqtt.Client, mqtt.Message) {}})
+
+		require.Eventually(t, func() bool {
+			for _, sub := range c.Status().Subscriptions {
+				if sub.Topic == "garden/#/invalid" {
+					return sub.Error != ""
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+		assert.False(t, c.Status().Healthy())
+	})
+
+	t.Run("Disconnected", func(t *testing.T) {
+		c.Disconnect(0)
+
+		status := c.Status()
+		assert.False(t, status.Connected)
+		assert.NotNil(t, status.DisconnectedSince)
+		for _, sub := range status.Subscriptions {
+			assert.False(t, sub.Subscribed)
+		}
+	})
+}
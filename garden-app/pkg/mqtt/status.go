@@ -0,0 +1,230 @@
+package mqtt
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// subscribeTimeout is how long to wait for the broker to acknowledge a subscription before retrying
+	subscribeTimeout = 10 * time.Second
+
+	// subscriptionFailure is the return code used by the broker to reject a subscription
+	subscriptionFailure = 0x80
+)
+
+// defaultSubscribeRetryDelays are the delays between subscription attempts. The last delay is used for all
+// remaining attempts until the subscription succeeds or the connection is lost
+var defaultSubscribeRetryDelays = []time.Duration{
+	time.Second,
+	5 * time.Second,
+	15 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+var (
+	mqttConnected = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "garden_app",
+		Name:      "mqtt_connected",
+		Help:      "1 if the client is connected to the MQTT broker",
+	}, []string{"client_id"})
+
+	mqttSubscribed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "garden_app",
+		Name:      "mqtt_subscribed",
+		Help:      "1 if the client is subscribed to the topic",
+	}, []string{"client_id", "topic"})
+
+	mqttSubscribeErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "garden_app",
+		Name:      "mqtt_subscribe_errors_total",
+		Help:      "count of failed subscription attempts",
+	}, []string{"client_id", "topic"})
+
+	mqttConnectionLost = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "garden_app",
+		Name:      "mqtt_connection_lost_total",
+		Help:      "count of times the connection to the MQTT broker was lost",
+	}, []string{"client_id"})
+)
+
+// Status is the state of the client's connection and subscriptions
+type Status struct {
+	Connected bool `json:"connected"`
+	// LastConnect is the last time the client connected to the broker
+	LastConnect *time.Time `json:"last_connect,omitempty"`
+	// DisconnectedSince is when the connection was lost, or when the client was created if it has not connected
+	DisconnectedSince *time.Time `json:"disconnected_since,omitempty"`
+	// LastError is the most recent connection error
+	LastError     string               `json:"last_error,omitempty"`
+	Subscriptions []SubscriptionStatus `json:"subscriptions"`
+}
+
+// SubscriptionStatus is the state of a single topic subscription
+type SubscriptionStatus struct {
+	Topic      string `json:"topic"`
+	Subscribed bool   `json:"subscribed"`
+	// Error is the error from the last failed subscription attempt
+	Error string `json:"error,omitempty"`
+}
+
+// Healthy returns true if the client is connected and subscribed to all topics
+func (s Status) Healthy() bool {
+	if !s.Connected {
+		return false
+	}
+	for _, sub := range s.Subscriptions {
+		if !sub.Subscribed {
+			return false
+		}
+	}
+	return true
+}
+
+// DisconnectedFor returns how long the client has been disconnected. It returns 0 if the client is connected
+func (s Status) DisconnectedFor() time.Duration {
+	if s.Connected || s.DisconnectedSince == nil {
+		return 0
+	}
+	return clock.Now().Sub(*s.DisconnectedSince)
+}
+
+// Status returns the current state of the connection and subscriptions
+func (c *client) Status() Status {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	status := c.status
+	status.Subscriptions = []SubscriptionStatus{}
+	for _, handler := range c.handlers {
+		sub, ok := c.subscriptions[handler.Topic]
+		if !ok {
+			sub = SubscriptionStatus{Topic: handler.Topic}
+		}
+		status.Subscriptions = append(status.Subscriptions, sub)
+	}
+	slices.SortFunc(status.Subscriptions, func(a, b SubscriptionStatus) int {
+		return strings.Compare(a.Topic, b.Topic)
+	})
+
+	return status
+}
+
+// onConnect updates the status and subscribes to all topics. Each connection gets a new generation so retries
+// from a previous connection stop
+func (c *client) onConnect(mqttClient mqtt.Client) {
+	c.stateMu.Lock()
+	now := clock.Now()
+	c.generation++
+	generation := c.generation
+	c.status.Connected = true
+	c.status.LastConnect = &now
+	c.status.DisconnectedSince = nil
+	c.status.LastError = ""
+	c.subscriptions = map[string]SubscriptionStatus{}
+	handlers := slices.Clone(c.handlers)
+	c.stateMu.Unlock()
+
+	mqttConnected.WithLabelValues(c.ClientID).Set(1)
+
+	for _, handler := range handlers {
+		go c.subscribe(mqttClient, handler, generation)
+	}
+}
+
+// onConnectionLost updates the status when the connection is lost unexpectedly
+func (c *client) onConnectionLost(_ mqtt.Client, err error) {
+	c.setDisconnected(err)
+	mqttConnectionLost.WithLabelValues(c.ClientID).Inc()
+}
+
+func (c *client) setDisconnected(err error) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	c.generation++
+	if c.status.Connected || c.status.DisconnectedSince == nil {
+		now := clock.Now()
+		c.status.DisconnectedSince = &now
+	}
+	c.status.Connected = false
+	if err != nil {
+		c.status.LastError = err.Error()
+	}
+	for topic, sub := range c.subscriptions {
+		sub.Subscribed = false
+		c.subscriptions[topic] = sub
+		mqttSubscribed.WithLabelValues(c.ClientID, topic).Set(0)
+	}
+
+	mqttConnected.WithLabelValues(c.ClientID).Set(0)
+}
+
+// subscribe subscribes to the topic and retries with backoff until it succeeds or the connection changes. The
+// subscription is attempted again when the client reconnects
+func (c *client) subscribe(mqttClient mqtt.Client, handler TopicHandler, generation uint64) {
+	for attempt := 0; c.currentGeneration(generation); attempt++ {
+		err := waitForSubscription(mqttClient.Subscribe(handler.Topic, QOS, handler.Handler), handler.Topic)
+		if !c.setSubscriptionStatus(handler.Topic, generation, err) || err == nil {
+			return
+		}
+
+		mqttSubscribeErrors.WithLabelValues(c.ClientID, handler.Topic).Inc()
+		time.Sleep(c.subscribeRetryDelays[min(attempt, len(c.subscribeRetryDelays)-1)])
+	}
+}
+
+func (c *client) currentGeneration(generation uint64) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+	return c.generation == generation
+}
+
+// setSubscriptionStatus records the result of a subscription attempt. It returns false if the connection changed
+// since the attempt started, so the result is ignored
+func (c *client) setSubscriptionStatus(topic string, generation uint64, err error) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if c.generation != generation {
+		return false
+	}
+
+	sub := SubscriptionStatus{Topic: topic, Subscribed: err == nil}
+	if err != nil {
+		sub.Error = err.Error()
+	}
+	c.subscriptions[topic] = sub
+
+	subscribed := 0.0
+	if sub.Subscribed {
+		subscribed = 1
+	}
+	mqttSubscribed.WithLabelValues(c.ClientID, topic).Set(subscribed)
+
+	return true
+}
+
+// waitForSubscription waits for the broker to acknowledge the subscription. The broker can also reject the
+// subscription in its acknowledgement, which is not an error for the token
+func waitForSubscription(token mqtt.Token, topic string) error {
+	if !token.WaitTimeout(subscribeTimeout) {
+		return errors.New("timed out waiting for broker")
+	}
+	if token.Error() != nil {
+		return token.Error()
+	}
+
+	subscribeToken, ok := token.(*mqtt.SubscribeToken)
+	if ok && subscribeToken.Result()[topic] == subscriptionFailure {
+		return errors.New("subscription rejected by broker")
+	}
+	return nil
+}
@@ -6,12 +6,16 @@ import (
 )
 
 const (
-	waterTopicTemplate   = "{{.Garden}}/command/water"
-	stopTopicTemplate    = "{{.Garden}}/command/stop"
-	stopAllTopicTemplate = "{{.Garden}}/command/stop_all"
-	lightTopicTemplate   = "{{.Garden}}/command/light"
-	fanTopicTemplate     = "{{.Garden}}/command/fan"
-	updateTopicTemplate  = "{{.Garden}}/command/update_config"
+	waterTopicTemplate    = "{{.Garden}}/command/water"
+	stopTopicTemplate     = "{{.Garden}}/command/stop"
+	stopAllTopicTemplate  = "{{.Garden}}/command/stop_all"
+	lightTopicTemplate    = "{{.Garden}}/command/light"
+	fanTopicTemplate      = "{{.Garden}}/command/fan"
+	updateTopicTemplate   = "{{.Garden}}/command/update_config"
+	scheduleTopicTemplate = "{{.Garden}}/command/schedule"
+
+	desiredLightTopicTemplate = "{{.Garden}}/desired/light"
+	desiredFanTopicTemplate   = "{{.Garden}}/desired/fan"
 )
 
 // WaterTopic returns the topic string for watering a zone
@@ -44,6 +48,21 @@ func UpdateTopic(topicPrefix string) (string, error) {
 	return executeTopicTemplate(updateTopicTemplate, topicPrefix)
 }
 
+// ScheduleTopic returns the topic string for sending a fallback schedule to a controller
+func ScheduleTopic(topicPrefix string) (string, error) {
+	return executeTopicTemplate(scheduleTopicTemplate, topicPrefix)
+}
+
+// DesiredLightTopic returns the topic string for the retained desired light state in a Garden
+func DesiredLightTopic(topicPrefix string) (string, error) {
+	return executeTopicTemplate(desiredLightTopicTemplate, topicPrefix)
+}
+
+// DesiredFanTopic returns the topic string for the retained desired fan state in a Garden
+func DesiredFanTopic(topicPrefix string) (string, error) {
+	return executeTopicTemplate(desiredFanTopicTemplate, topicPrefix)
+}
+
 // executeTopicTemplate is a helper function used by all the exported topic evaluation functions
 func executeTopicTemplate(templateString string, topicPrefix string) (string, error) {
 	t := template.Must(template.New("topic").Parse(templateString))
@@ -0,0 +1,14 @@
+package pkg
+
+import "time"
+
+// PendingDevice is a controller that is publishing messages with a topic prefix that doesn't belong to any Garden.
+// It can be adopted to create a new Garden
+type PendingDevice struct {
+	TopicPrefix     string     `json:"topic_prefix"`
+	MACAddress      string     `json:"mac_address,omitempty"`
+	IPAddress       string     `json:"ip_address,omitempty"`
+	FirmwareVersion string     `json:"firmware_version,omitempty"`
+	FirstSeen       *time.Time `json:"first_seen"`
+	LastSeen        *time.Time `json:"last_seen"`
+}
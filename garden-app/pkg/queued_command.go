@@ -0,0 +1,26 @@
+package pkg
+
+import (
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+)
+
+// QueuedCommand is an MQTT command that could not be published because the broker was unavailable. It is published
+// in order when the connection is restored unless it expires first
+type QueuedCommand struct {
+	ID        int64      `json:"id"`
+	GardenID  string     `json:"garden_id"`
+	Type      string     `json:"type"`
+	Topic     string     `json:"topic"`
+	Payload   string     `json:"payload"`
+	QueuedAt  time.Time  `json:"queued_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Attempts  int        `json:"attempts"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// Expired returns true if the command is too old to be delivered
+func (c *QueuedCommand) Expired() bool {
+	return c.ExpiresAt != nil && !clock.Now().Before(*c.ExpiresAt)
+}
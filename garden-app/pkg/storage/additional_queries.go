@@ -63,7 +63,7 @@ func (a *AdditionalQueries) GetZonesUsingWaterSchedule(id string) ([]*pkg.ZoneAn
 				ControllerConfig:     dbGarden.ControllerConfig,
 				LightSchedule:        dbGarden.LightSchedule,
 			},
-			dbGarden.MacAddress, dbGarden.IpAddress, dbGarden.FirmwareVersion, dbGarden.UpdatedAt,
+			dbGarden.MacAddress, dbGarden.IpAddress, dbGarden.FirmwareVersion, dbGarden.UpdatedAt, dbGarden.ConfigHash, dbGarden.ScheduleVersion,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("invalid garden: %w", err)
@@ -89,6 +89,9 @@ func (a *AdditionalQueries) GetWaterSchedulesUsingWeatherClient(id string) ([]*p
 	dbWaterSchedules, err := a.q.FindWaterSchedulesByWeatherClientID(ctx, db.FindWaterSchedulesByWeatherClientIDParams{
 		WeatherControl:   sql.NullString{String: id, Valid: true},
 		WeatherControl_2: sql.NullString{String: id, Valid: true},
+		WeatherControl_3: sql.NullString{String: id, Valid: true},
+		WeatherControl_4: sql.NullString{String: id, Valid: true},
+		WeatherControl_5: sql.NullString{String: id, Valid: true},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error finding water schedules by weather client ID: %w", err)
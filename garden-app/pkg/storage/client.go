@@ -9,6 +9,7 @@ import (
 	"fmt"
 
 	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
@@ -41,6 +42,17 @@ type Client struct {
 	WaterRoutines             babyapi.Storage[*pkg.WaterRoutine]
 	Notes                     babyapi.Storage[*pkg.Note]
 	ControllerInfo            *ControllerInfoStorage
+	WeatherHistory            *WeatherHistoryStorage
+	WeatherClientHealth       *WeatherClientHealthStorage
+	WaterScaleFactors         *WaterScaleFactorStorage
+	PWSReadings               *PWSReadingStorage
+	CommandQueue              *CommandQueueStorage
+	DriverWaterings           *DriverWateringStorage
+	PendingDevices            *PendingDeviceStorage
+	HomeAssistantTopics       *HomeAssistantTopicStorage
+	Webhooks                  babyapi.Storage[*pkg.Webhook]
+	WebhookDeliveries         *WebhookDeliveryStorage
+	SensorSource              *SensorSource
 
 	*AdditionalQueries
 }
@@ -80,6 +92,16 @@ func NewClient(config Config) (*Client, error) {
 		WaterRoutines:             NewWaterRoutineStorage(db),
 		Notes:                     NewNoteStorage(db),
 		ControllerInfo:            NewControllerInfoStorage(db),
+		WeatherHistory:            NewWeatherHistoryStorage(db),
+		WeatherClientHealth:       NewWeatherClientHealthStorage(db),
+		WaterScaleFactors:         NewWaterScaleFactorStorage(db),
+		PWSReadings:               NewPWSReadingStorage(db),
+		CommandQueue:              NewCommandQueueStorage(db),
+		DriverWaterings:           NewDriverWateringStorage(db),
+		PendingDevices:            NewPendingDeviceStorage(db),
+		HomeAssistantTopics:       NewHomeAssistantTopicStorage(db),
+		Webhooks:                  NewWebhookStorage(db),
+		WebhookDeliveries:         NewWebhookDeliveryStorage(db),
 		AdditionalQueries:         NewAdditionalQueries(db),
 	}, nil
 }
@@ -98,7 +120,26 @@ func (c *Client) GetWeatherClient(id xid.ID) (weather.Client, error) {
 	return weather.NewClient(clientConfig, func(weatherClientOptions map[string]any) error {
 		clientConfig.Options = weatherClientOptions
 		return c.WeatherClientConfigs.Set(context.Background(), clientConfig)
-	})
+	}, c.WeatherClientOptions()...)
+}
+
+// SetInfluxDBClient enables WeatherClients that read sensor history from InfluxDB
+func (c *Client) SetInfluxDBClient(influxdbClient influxdb.Client) {
+	c.SensorSource = NewSensorSource(c.Gardens, influxdbClient)
+}
+
+// WeatherClientOptions returns the options used to initialize WeatherClients with access to storage
+func (c *Client) WeatherClientOptions() []weather.ClientOption {
+	opts := []weather.ClientOption{
+		weather.WithConfigStorage(c.WeatherClientConfigs),
+		weather.WithHistoryStorage(c.WeatherHistory),
+		weather.WithHealthStorage(c.WeatherClientHealth),
+		weather.WithPWSStorage(c.PWSReadings),
+	}
+	if c.SensorSource != nil {
+		opts = append(opts, weather.WithSensorSource(c.SensorSource))
+	}
+	return opts
 }
 
 // GetUserSetting retrieves a user setting by key
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+)
+
+// CommandQueueStorage persists MQTT commands that are waiting to be published
+type CommandQueueStorage struct {
+	q *db.Queries
+}
+
+// NewCommandQueueStorage creates a new CommandQueueStorage instance
+func NewCommandQueueStorage(sqlDB *sql.DB) *CommandQueueStorage {
+	return &CommandQueueStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// Add appends a command to the end of the queue and sets its ID
+func (s *CommandQueueStorage) Add(ctx context.Context, cmd *pkg.QueuedCommand) error {
+	var expiresAt sql.NullString
+	if cmd.ExpiresAt != nil {
+		expiresAt = timeToNullString(*cmd.ExpiresAt)
+	}
+
+	var lastError sql.NullString
+	if cmd.LastError != "" {
+		lastError = sql.NullString{String: cmd.LastError, Valid: true}
+	}
+
+	dbCommand, err := s.q.EnqueueCommand(ctx, db.EnqueueCommandParams{
+		GardenID:    cmd.GardenID,
+		CommandType: cmd.Type,
+		Topic:       cmd.Topic,
+		Payload:     cmd.Payload,
+		QueuedAt:    cmd.QueuedAt.Format(time.RFC3339),
+		ExpiresAt:   expiresAt,
+		Attempts:    int64(cmd.Attempts),
+		LastError:   lastError,
+	})
+	if err != nil {
+		return fmt.Errorf("error adding command to queue: %w", err)
+	}
+
+	cmd.ID = dbCommand.ID
+	return nil
+}
+
+// Get retrieves a queued command. It returns nil if the command is not in the queue
+func (s *CommandQueueStorage) Get(ctx context.Context, id int64) (*pkg.QueuedCommand, error) {
+	dbCommand, err := s.q.GetQueuedCommand(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting queued command: %w", err)
+	}
+	return dbCommandToQueuedCommand(dbCommand), nil
+}
+
+// List returns queued commands in the order they were added. If gardenID is empty, commands for all Gardens are
+// returned
+func (s *CommandQueueStorage) List(ctx context.Context, gardenID string) ([]*pkg.QueuedCommand, error) {
+	var dbCommands []db.CommandQueue
+	var err error
+	if gardenID == "" {
+		dbCommands, err = s.q.ListQueuedCommands(ctx)
+	} else {
+		dbCommands, err = s.q.ListQueuedCommandsForGarden(ctx, gardenID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error listing queued commands: %w", err)
+	}
+
+	result := make([]*pkg.QueuedCommand, 0, len(dbCommands))
+	for _, dbCommand := range dbCommands {
+		result = append(result, dbCommandToQueuedCommand(dbCommand))
+	}
+	return result, nil
+}
+
+// Count returns the number of queued commands
+func (s *CommandQueueStorage) Count(ctx context.Context) (int, error) {
+	count, err := s.q.CountQueuedCommands(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error counting queued commands: %w", err)
+	}
+	return int(count), nil
+}
+
+// RecordAttempt increments the number of attempts for a command and stores the error from the attempt
+func (s *CommandQueueStorage) RecordAttempt(ctx context.Context, id int64, attemptErr error) error {
+	var lastError sql.NullString
+	if attemptErr != nil {
+		lastError = sql.NullString{String: attemptErr.Error(), Valid: true}
+	}
+	return s.q.RecordQueuedCommandAttempt(ctx, db.RecordQueuedCommandAttemptParams{
+		LastError: lastError,
+		ID:        id,
+	})
+}
+
+// Delete removes a command from the queue
+func (s *CommandQueueStorage) Delete(ctx context.Context, id int64) error {
+	return s.q.DeleteQueuedCommand(ctx, id)
+}
+
+// DeleteByType removes all queued commands of a type for a Garden
+func (s *CommandQueueStorage) DeleteByType(ctx context.Context, gardenID, commandType string) error {
+	return s.q.DeleteQueuedCommandsByType(ctx, db.DeleteQueuedCommandsByTypeParams{
+		GardenID:    gardenID,
+		CommandType: commandType,
+	})
+}
+
+// DeleteNewestByType removes the most recently queued command of a type for a Garden. It returns false if there
+// was no command to remove
+func (s *CommandQueueStorage) DeleteNewestByType(ctx context.Context, gardenID, commandType string) (bool, error) {
+	deleted, err := s.q.DeleteNewestQueuedCommandByType(ctx, db.DeleteNewestQueuedCommandByTypeParams{
+		GardenID:    gardenID,
+		CommandType: commandType,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error deleting queued command: %w", err)
+	}
+	return deleted > 0, nil
+}
+
+func dbCommandToQueuedCommand(dbCommand db.CommandQueue) *pkg.QueuedCommand {
+	cmd := &pkg.QueuedCommand{
+		ID:        dbCommand.ID,
+		GardenID:  dbCommand.GardenID,
+		Type:      dbCommand.CommandType,
+		Topic:     dbCommand.Topic,
+		Payload:   dbCommand.Payload,
+		ExpiresAt: nullStringToTime(dbCommand.ExpiresAt),
+		Attempts:  int(dbCommand.Attempts),
+	}
+	if queuedAt, err := time.Parse(time.RFC3339, dbCommand.QueuedAt); err == nil {
+		cmd.QueuedAt = queuedAt
+	}
+	if dbCommand.LastError.Valid {
+		cmd.LastError = dbCommand.LastError.String
+	}
+	return cmd
+}
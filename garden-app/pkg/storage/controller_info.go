@@ -51,6 +51,16 @@ func (s *ControllerInfoStorage) Upsert(ctx context.Context, info *pkg.Controller
 		firmwareVersion = sql.NullString{String: info.FirmwareVersion, Valid: true}
 	}
 
+	var configHash sql.NullString
+	if info.ConfigHash != nil {
+		configHash = sql.NullString{String: *info.ConfigHash, Valid: true}
+	}
+
+	var scheduleVersion sql.NullString
+	if info.ScheduleVersion != "" {
+		scheduleVersion = sql.NullString{String: info.ScheduleVersion, Valid: true}
+	}
+
 	updatedAt := time.Now().Format(time.RFC3339)
 	if info.UpdatedAt != nil {
 		updatedAt = info.UpdatedAt.Format(time.RFC3339)
@@ -62,6 +72,8 @@ func (s *ControllerInfoStorage) Upsert(ctx context.Context, info *pkg.Controller
 		IpAddress:       ipAddress,
 		FirmwareVersion: firmwareVersion,
 		UpdatedAt:       updatedAt,
+		ConfigHash:      configHash,
+		ScheduleVersion: scheduleVersion,
 	})
 }
 
@@ -84,6 +96,12 @@ func dbControllerInfoToControllerInfo(dbInfo db.GardenControllerInfo) *pkg.Contr
 	if dbInfo.FirmwareVersion.Valid {
 		info.FirmwareVersion = dbInfo.FirmwareVersion.String
 	}
+	if dbInfo.ConfigHash.Valid {
+		info.ConfigHash = &dbInfo.ConfigHash.String
+	}
+	if dbInfo.ScheduleVersion.Valid {
+		info.ScheduleVersion = dbInfo.ScheduleVersion.String
+	}
 
 	if dbInfo.UpdatedAt != "" {
 		updatedAt, err := time.Parse(time.RFC3339, dbInfo.UpdatedAt)
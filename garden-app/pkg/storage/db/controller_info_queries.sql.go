@@ -20,7 +20,7 @@ func (q *Queries) DeleteControllerInfo(ctx context.Context, gardenID string) err
 }
 
 const getControllerInfo = `-- name: GetControllerInfo :one
-SELECT garden_id, mac_address, ip_address, firmware_version, updated_at FROM garden_controller_info WHERE garden_id = ? LIMIT 1
+SELECT garden_id, mac_address, ip_address, firmware_version, updated_at, config_hash, schedule_version FROM garden_controller_info WHERE garden_id = ? LIMIT 1
 `
 
 func (q *Queries) GetControllerInfo(ctx context.Context, gardenID string) (GardenControllerInfo, error) {
@@ -32,19 +32,23 @@ func (q *Queries) GetControllerInfo(ctx context.Context, gardenID string) (Garde
 		&i.IpAddress,
 		&i.FirmwareVersion,
 		&i.UpdatedAt,
+		&i.ConfigHash,
+		&i.ScheduleVersion,
 	)
 	return i, err
 }
 
 const upsertControllerInfo = `-- name: UpsertControllerInfo :exec
-INSERT INTO garden_controller_info (garden_id, mac_address, ip_address, firmware_version, updated_at)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO garden_controller_info (garden_id, mac_address, ip_address, firmware_version, updated_at, config_hash, schedule_version)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (garden_id)
 DO UPDATE SET
     mac_address = EXCLUDED.mac_address,
     ip_address = EXCLUDED.ip_address,
     firmware_version = EXCLUDED.firmware_version,
-    updated_at = EXCLUDED.updated_at
+    updated_at = EXCLUDED.updated_at,
+    config_hash = EXCLUDED.config_hash,
+    schedule_version = EXCLUDED.schedule_version
 `
 
 type UpsertControllerInfoParams struct {
@@ -53,6 +57,8 @@ type UpsertControllerInfoParams struct {
 	IpAddress       sql.NullString
 	FirmwareVersion sql.NullString
 	UpdatedAt       string
+	ConfigHash      sql.NullString
+	ScheduleVersion sql.NullString
 }
 
 func (q *Queries) UpsertControllerInfo(ctx context.Context, arg UpsertControllerInfoParams) error {
@@ -62,6 +68,8 @@ func (q *Queries) UpsertControllerInfo(ctx context.Context, arg UpsertController
 		arg.IpAddress,
 		arg.FirmwareVersion,
 		arg.UpdatedAt,
+		arg.ConfigHash,
+		arg.ScheduleVersion,
 	)
 	return err
 }
@@ -20,7 +20,7 @@ func (q *Queries) DeleteGarden(ctx context.Context, id string) error {
 }
 
 const getGarden = `-- name: GetGarden :one
-SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, g.driver, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id
 WHERE g.id = ? LIMIT 1
@@ -38,10 +38,13 @@ type GetGardenRow struct {
 	ControllerConfig     sql.NullString
 	LightSchedule        sql.NullString
 	FanSchedule          sql.NullString
+	Driver               sql.NullString
 	MacAddress           sql.NullString
 	IpAddress            sql.NullString
 	FirmwareVersion      sql.NullString
 	UpdatedAt            sql.NullString
+	ConfigHash           sql.NullString
+	ScheduleVersion      sql.NullString
 }
 
 func (q *Queries) GetGarden(ctx context.Context, id string) (GetGardenRow, error) {
@@ -59,16 +62,19 @@ func (q *Queries) GetGarden(ctx context.Context, id string) (GetGardenRow, error
 		&i.ControllerConfig,
 		&i.LightSchedule,
 		&i.FanSchedule,
+		&i.Driver,
 		&i.MacAddress,
 		&i.IpAddress,
 		&i.FirmwareVersion,
 		&i.UpdatedAt,
+		&i.ConfigHash,
+		&i.ScheduleVersion,
 	)
 	return i, err
 }
 
 const getGardenByTopicPrefix = `-- name: GetGardenByTopicPrefix :one
-SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, g.driver, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id
 WHERE g.topic_prefix = ? LIMIT 1
@@ -86,10 +92,13 @@ type GetGardenByTopicPrefixRow struct {
 	ControllerConfig     sql.NullString
 	LightSchedule        sql.NullString
 	FanSchedule          sql.NullString
+	Driver               sql.NullString
 	MacAddress           sql.NullString
 	IpAddress            sql.NullString
 	FirmwareVersion      sql.NullString
 	UpdatedAt            sql.NullString
+	ConfigHash           sql.NullString
+	ScheduleVersion      sql.NullString
 }
 
 func (q *Queries) GetGardenByTopicPrefix(ctx context.Context, topicPrefix string) (GetGardenByTopicPrefixRow, error) {
@@ -107,16 +116,19 @@ func (q *Queries) GetGardenByTopicPrefix(ctx context.Context, topicPrefix string
 		&i.ControllerConfig,
 		&i.LightSchedule,
 		&i.FanSchedule,
+		&i.Driver,
 		&i.MacAddress,
 		&i.IpAddress,
 		&i.FirmwareVersion,
 		&i.UpdatedAt,
+		&i.ConfigHash,
+		&i.ScheduleVersion,
 	)
 	return i, err
 }
 
 const listActiveGardens = `-- name: ListActiveGardens :many
-SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, g.driver, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id
 WHERE g.end_date IS NULL
@@ -135,10 +147,13 @@ type ListActiveGardensRow struct {
 	ControllerConfig     sql.NullString
 	LightSchedule        sql.NullString
 	FanSchedule          sql.NullString
+	Driver               sql.NullString
 	MacAddress           sql.NullString
 	IpAddress            sql.NullString
 	FirmwareVersion      sql.NullString
 	UpdatedAt            sql.NullString
+	ConfigHash           sql.NullString
+	ScheduleVersion      sql.NullString
 }
 
 func (q *Queries) ListActiveGardens(ctx context.Context, endDate sql.NullString) ([]ListActiveGardensRow, error) {
@@ -162,10 +177,13 @@ func (q *Queries) ListActiveGardens(ctx context.Context, endDate sql.NullString)
 			&i.ControllerConfig,
 			&i.LightSchedule,
 			&i.FanSchedule,
+			&i.Driver,
 			&i.MacAddress,
 			&i.IpAddress,
 			&i.FirmwareVersion,
 			&i.UpdatedAt,
+			&i.ConfigHash,
+			&i.ScheduleVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -181,7 +199,7 @@ func (q *Queries) ListActiveGardens(ctx context.Context, endDate sql.NullString)
 }
 
 const listAllGardens = `-- name: ListAllGardens :many
-SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.id, g.name, g.topic_prefix, g.max_zones, g.created_at, g.end_date, g.notification_client_id, g.notification_settings, g.controller_config, g.light_schedule, g.fan_schedule, g.driver, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id
 `
@@ -198,10 +216,13 @@ type ListAllGardensRow struct {
 	ControllerConfig     sql.NullString
 	LightSchedule        sql.NullString
 	FanSchedule          sql.NullString
+	Driver               sql.NullString
 	MacAddress           sql.NullString
 	IpAddress            sql.NullString
 	FirmwareVersion      sql.NullString
 	UpdatedAt            sql.NullString
+	ConfigHash           sql.NullString
+	ScheduleVersion      sql.NullString
 }
 
 func (q *Queries) ListAllGardens(ctx context.Context) ([]ListAllGardensRow, error) {
@@ -225,10 +246,13 @@ func (q *Queries) ListAllGardens(ctx context.Context) ([]ListAllGardensRow, erro
 			&i.ControllerConfig,
 			&i.LightSchedule,
 			&i.FanSchedule,
+			&i.Driver,
 			&i.MacAddress,
 			&i.IpAddress,
 			&i.FirmwareVersion,
 			&i.UpdatedAt,
+			&i.ConfigHash,
+			&i.ScheduleVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -265,9 +289,10 @@ INSERT INTO gardens (
   max_zones,
   created_at, end_date,
   notification_client_id, notification_settings,
-  controller_config, light_schedule, fan_schedule
+  controller_config, light_schedule, fan_schedule,
+  driver
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT (id)
 DO UPDATE SET
   name = EXCLUDED.name,
@@ -278,7 +303,8 @@ DO UPDATE SET
   notification_settings = EXCLUDED.notification_settings,
   controller_config = EXCLUDED.controller_config,
   light_schedule = EXCLUDED.light_schedule,
-  fan_schedule = EXCLUDED.fan_schedule
+  fan_schedule = EXCLUDED.fan_schedule,
+  driver = EXCLUDED.driver
 `
 
 type UpsertGardenParams struct {
@@ -293,6 +319,7 @@ type UpsertGardenParams struct {
 	ControllerConfig     sql.NullString
 	LightSchedule        sql.NullString
 	FanSchedule          sql.NullString
+	Driver               sql.NullString
 }
 
 func (q *Queries) UpsertGarden(ctx context.Context, arg UpsertGardenParams) error {
@@ -308,6 +335,7 @@ func (q *Queries) UpsertGarden(ctx context.Context, arg UpsertGardenParams) erro
 		arg.ControllerConfig,
 		arg.LightSchedule,
 		arg.FanSchedule,
+		arg.Driver,
 	)
 	return err
 }
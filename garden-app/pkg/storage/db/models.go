@@ -9,6 +9,31 @@ import (
 	"encoding/json"
 )
 
+type CommandQueue struct {
+	ID          int64
+	GardenID    string
+	CommandType string
+	Topic       string
+	Payload     string
+	QueuedAt    string
+	ExpiresAt   sql.NullString
+	Attempts    int64
+	LastError   sql.NullString
+}
+
+type DriverWatering struct {
+	ID                   int64
+	GardenID             string
+	ZoneID               string
+	Position             int64
+	EventID              string
+	Source               string
+	DurationMs           int64
+	NotificationClientID sql.NullString
+	QueuedAt             string
+	StartedAt            sql.NullString
+}
+
 type Garden struct {
 	ID                   string
 	Name                 string
@@ -21,6 +46,7 @@ type Garden struct {
 	ControllerConfig     sql.NullString
 	LightSchedule        sql.NullString
 	FanSchedule          sql.NullString
+	Driver               sql.NullString
 }
 
 type GardenControllerInfo struct {
@@ -29,6 +55,13 @@ type GardenControllerInfo struct {
 	IpAddress       sql.NullString
 	FirmwareVersion sql.NullString
 	UpdatedAt       string
+	ConfigHash      sql.NullString
+	ScheduleVersion sql.NullString
+}
+
+type HomeAssistantTopic struct {
+	GardenID string
+	Topic    string
 }
 
 type Note struct {
@@ -46,6 +79,25 @@ type NotificationClient struct {
 	Url  string
 }
 
+type PendingDevice struct {
+	TopicPrefix     string
+	MacAddress      sql.NullString
+	IpAddress       sql.NullString
+	FirmwareVersion sql.NullString
+	FirstSeen       string
+	LastSeen        string
+}
+
+type PwsReading struct {
+	WeatherClientID    string
+	Timestamp          string
+	TemperatureCelsius sql.NullFloat64
+	Humidity           sql.NullFloat64
+	DailyRainMm        sql.NullFloat64
+	SolarRadiation     sql.NullFloat64
+	TotalRainMm        sql.NullFloat64
+}
+
 type UserSetting struct {
 	Key   string
 	Value string
@@ -73,6 +125,12 @@ type WaterSchedule struct {
 	NotificationSettings   sql.NullString
 }
 
+type WaterScheduleScaleFactor struct {
+	WaterScheduleID string
+	ScaleFactor     float64
+	UpdatedAt       string
+}
+
 type WeatherClient struct {
 	ID      string
 	Type    string
@@ -80,6 +138,51 @@ type WeatherClient struct {
 	Name    string
 }
 
+type WeatherClientHealth struct {
+	WeatherClientID     string
+	LastSuccess         sql.NullString
+	LastError           sql.NullString
+	LastErrorTime       sql.NullString
+	ConsecutiveFailures int64
+	FailingSince        sql.NullString
+	AuthState           sql.NullString
+	AuthTokenExpiration sql.NullString
+	AuthLastChecked     sql.NullString
+	AuthError           sql.NullString
+}
+
+type WeatherDailyObservation struct {
+	WeatherClientID       string
+	Date                  string
+	RainMm                sql.NullFloat64
+	MaxTemperatureCelsius sql.NullFloat64
+	EvapotranspirationMm  sql.NullFloat64
+	Complete              bool
+	UpdatedAt             string
+}
+
+type Webhook struct {
+	ID         string
+	Name       string
+	Url        string
+	Secret     sql.NullString
+	EventTypes string
+	Disabled   bool
+}
+
+type WebhookDelivery struct {
+	ID          int64
+	WebhookID   string
+	EventID     string
+	EventType   string
+	Payload     string
+	CreatedAt   string
+	Attempts    int64
+	StatusCode  sql.NullInt64
+	LastError   sql.NullString
+	DeliveredAt sql.NullString
+}
+
 type Zone struct {
 	ID                 string
 	Name               string
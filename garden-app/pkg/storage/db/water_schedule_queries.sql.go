@@ -24,16 +24,28 @@ SELECT id, name, description, duration, interval, start_date, start_time, end_da
 WHERE weather_control IS NOT NULL AND (
     json_extract(weather_control, '$.rain_control.client_id') = ?
     OR json_extract(weather_control, '$.temperature_control.client_id') = ?
+    OR json_extract(weather_control, '$.evapotranspiration_control.client_id') = ?
+    OR json_extract(weather_control, '$.humidity_control.client_id') = ?
+    OR json_extract(weather_control, '$.solar_radiation_control.client_id') = ?
 )
 `
 
 type FindWaterSchedulesByWeatherClientIDParams struct {
 	WeatherControl   sql.NullString
 	WeatherControl_2 sql.NullString
+	WeatherControl_3 sql.NullString
+	WeatherControl_4 sql.NullString
+	WeatherControl_5 sql.NullString
 }
 
 func (q *Queries) FindWaterSchedulesByWeatherClientID(ctx context.Context, arg FindWaterSchedulesByWeatherClientIDParams) ([]WaterSchedule, error) {
-	rows, err := q.db.QueryContext(ctx, findWaterSchedulesByWeatherClientID, arg.WeatherControl, arg.WeatherControl_2)
+	rows, err := q.db.QueryContext(ctx, findWaterSchedulesByWeatherClientID,
+		arg.WeatherControl,
+		arg.WeatherControl_2,
+		arg.WeatherControl_3,
+		arg.WeatherControl_4,
+		arg.WeatherControl_5,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+)
+
+// DriverWateringStorage persists waterings for Gardens that use a driver without its own queue
+type DriverWateringStorage struct {
+	q *db.Queries
+}
+
+// NewDriverWateringStorage creates a new DriverWateringStorage instance
+func NewDriverWateringStorage(sqlDB *sql.DB) *DriverWateringStorage {
+	return &DriverWateringStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// Add stores a watering at the end of the Garden's queue and sets its ID
+func (s *DriverWateringStorage) Add(ctx context.Context, watering *pkg.DriverWatering) error {
+	var notificationClientID sql.NullString
+	if watering.NotificationClientID != "" {
+		notificationClientID = sql.NullString{String: watering.NotificationClientID, Valid: true}
+	}
+
+	var startedAt sql.NullString
+	if watering.StartedAt != nil {
+		startedAt = timeToNullString(*watering.StartedAt)
+	}
+
+	dbWatering, err := s.q.AddDriverWatering(ctx, db.AddDriverWateringParams{
+		GardenID:             watering.GardenID,
+		ZoneID:               watering.ZoneID,
+		Position:             int64(watering.Position),
+		EventID:              watering.EventID,
+		Source:               watering.Source,
+		DurationMs:           watering.Duration.Milliseconds(),
+		NotificationClientID: notificationClientID,
+		QueuedAt:             watering.QueuedAt.Format(time.RFC3339),
+		StartedAt:            startedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("error adding driver watering: %w", err)
+	}
+
+	watering.ID = dbWatering.ID
+	return nil
+}
+
+// List returns stored waterings for all Gardens in the order they were added
+func (s *DriverWateringStorage) List(ctx context.Context) ([]*pkg.DriverWatering, error) {
+	dbWaterings, err := s.q.ListDriverWaterings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing driver waterings: %w", err)
+	}
+
+	result := make([]*pkg.DriverWatering, 0, len(dbWaterings))
+	for _, dbWatering := range dbWaterings {
+		watering, err := dbDriverWateringToDriverWatering(dbWatering)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing driver watering %q: %w", dbWatering.EventID, err)
+		}
+		result = append(result, watering)
+	}
+	return result, nil
+}
+
+// SetStarted records when the driver started the watering
+func (s *DriverWateringStorage) SetStarted(ctx context.Context, eventID string, startedAt time.Time) error {
+	return s.q.SetDriverWateringStarted(ctx, db.SetDriverWateringStartedParams{
+		StartedAt: timeToNullString(startedAt),
+		EventID:   eventID,
+	})
+}
+
+// Delete removes a watering after it completes, is cancelled, or fails
+func (s *DriverWateringStorage) Delete(ctx context.Context, eventID string) error {
+	return s.q.DeleteDriverWatering(ctx, eventID)
+}
+
+// DeleteForGarden removes all waterings for a Garden
+func (s *DriverWateringStorage) DeleteForGarden(ctx context.Context, gardenID string) error {
+	return s.q.DeleteDriverWateringsForGarden(ctx, gardenID)
+}
+
+func dbDriverWateringToDriverWatering(dbWatering db.DriverWatering) (*pkg.DriverWatering, error) {
+	position, err := safeInt64ToUint(dbWatering.Position)
+	if err != nil {
+		return nil, fmt.Errorf("invalid position: %w", err)
+	}
+
+	watering := &pkg.DriverWatering{
+		ID:        dbWatering.ID,
+		GardenID:  dbWatering.GardenID,
+		ZoneID:    dbWatering.ZoneID,
+		Position:  position,
+		EventID:   dbWatering.EventID,
+		Source:    dbWatering.Source,
+		Duration:  time.Duration(dbWatering.DurationMs) * time.Millisecond,
+		StartedAt: nullStringToTime(dbWatering.StartedAt),
+	}
+	if queuedAt, err := time.Parse(time.RFC3339, dbWatering.QueuedAt); err == nil {
+		watering.QueuedAt = queuedAt
+	}
+	if dbWatering.NotificationClientID.Valid {
+		watering.NotificationClientID = dbWatering.NotificationClientID.String
+	}
+	return watering, nil
+}
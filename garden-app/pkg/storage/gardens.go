@@ -55,8 +55,9 @@ func (s *GardenStorage) Get(ctx context.Context, id string) (*pkg.Garden, error)
 			ControllerConfig:     row.ControllerConfig,
 			LightSchedule:        row.LightSchedule,
 			FanSchedule:          row.FanSchedule,
+			Driver:               row.Driver,
 		},
-		row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt,
+		row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt, row.ConfigHash, row.ScheduleVersion,
 	)
 }
 
@@ -93,8 +94,9 @@ func (s *GardenStorage) Search(ctx context.Context, _ string, q url.Values) iter
 						ControllerConfig:     row.ControllerConfig,
 						LightSchedule:        row.LightSchedule,
 						FanSchedule:          row.FanSchedule,
+						Driver:               row.Driver,
 					},
-					row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt,
+					row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt, row.ConfigHash, row.ScheduleVersion,
 				)
 				if err != nil {
 					if !yield(nil, fmt.Errorf("invalid garden: %w", err)) {
@@ -121,8 +123,9 @@ func (s *GardenStorage) Search(ctx context.Context, _ string, q url.Values) iter
 						ControllerConfig:     row.ControllerConfig,
 						LightSchedule:        row.LightSchedule,
 						FanSchedule:          row.FanSchedule,
+						Driver:               row.Driver,
 					},
-					row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt,
+					row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt, row.ConfigHash, row.ScheduleVersion,
 				)
 				if err != nil {
 					if !yield(nil, fmt.Errorf("invalid garden: %w", err)) {
@@ -198,6 +201,18 @@ func (s *GardenStorage) Set(ctx context.Context, garden *pkg.Garden) error {
 		}
 	}
 
+	var driver sql.NullString
+	if garden.Driver != nil {
+		driverStr, err := json.Marshal(garden.Driver)
+		if err != nil {
+			return fmt.Errorf("error marshaling driver: %w", err)
+		}
+		driver = sql.NullString{
+			String: string(driverStr),
+			Valid:  true,
+		}
+	}
+
 	var maxZones int64
 	if garden.MaxZones != nil {
 		var err error
@@ -224,6 +239,7 @@ func (s *GardenStorage) Set(ctx context.Context, garden *pkg.Garden) error {
 		ControllerConfig:     controllerConfig,
 		LightSchedule:        lightSchedule,
 		FanSchedule:          fanSchedule,
+		Driver:               driver,
 	})
 	if err != nil {
 		var sqliteErr *sqlite.Error
@@ -268,24 +284,25 @@ func (s *GardenStorage) GetByTopicPrefix(ctx context.Context, topicPrefix string
 			ControllerConfig:     row.ControllerConfig,
 			LightSchedule:        row.LightSchedule,
 			FanSchedule:          row.FanSchedule,
+			Driver:               row.Driver,
 		},
-		row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt,
+		row.MacAddress, row.IpAddress, row.FirmwareVersion, row.UpdatedAt, row.ConfigHash, row.ScheduleVersion,
 	)
 }
 
 func gardenFromRow(
 	dbGarden db.Garden,
-	macAddress, ipAddress, firmwareVersion, updatedAt sql.NullString,
+	macAddress, ipAddress, firmwareVersion, updatedAt, configHash, scheduleVersion sql.NullString,
 ) (*pkg.Garden, error) {
 	garden, err := dbGardenToGarden(dbGarden)
 	if err != nil {
 		return nil, err
 	}
-	garden.ControllerInfo = controllerInfoFromRow(macAddress, ipAddress, firmwareVersion, updatedAt)
+	garden.ControllerInfo = controllerInfoFromRow(macAddress, ipAddress, firmwareVersion, updatedAt, configHash, scheduleVersion)
 	return garden, nil
 }
 
-func controllerInfoFromRow(macAddress, ipAddress, firmwareVersion, updatedAt sql.NullString) *pkg.ControllerInfo {
+func controllerInfoFromRow(macAddress, ipAddress, firmwareVersion, updatedAt, configHash, scheduleVersion sql.NullString) *pkg.ControllerInfo {
 	if !macAddress.Valid && !ipAddress.Valid && !firmwareVersion.Valid {
 		return nil
 	}
@@ -300,6 +317,12 @@ func controllerInfoFromRow(macAddress, ipAddress, firmwareVersion, updatedAt sql
 	if firmwareVersion.Valid {
 		info.FirmwareVersion = firmwareVersion.String
 	}
+	if configHash.Valid {
+		info.ConfigHash = &configHash.String
+	}
+	if scheduleVersion.Valid {
+		info.ScheduleVersion = scheduleVersion.String
+	}
 	if updatedAt.Valid && updatedAt.String != "" {
 		if t, err := time.Parse(time.RFC3339, updatedAt.String); err == nil {
 			info.UpdatedAt = &t
@@ -380,5 +403,14 @@ func dbGardenToGarden(dbGarden db.Garden) (*pkg.Garden, error) {
 		garden.FanSchedule = &fanSchedule
 	}
 
+	if dbGarden.Driver.Valid && len(dbGarden.Driver.String) > 0 {
+		var driver pkg.DriverConfig
+		err := json.Unmarshal([]byte(dbGarden.Driver.String), &driver)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling driver: %w", err)
+		}
+		garden.Driver = &driver
+	}
+
 	return garden, nil
 }
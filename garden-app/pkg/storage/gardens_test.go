@@ -43,3 +43,37 @@ func TestGardenFanScheduleRoundTrip(t *testing.T) {
 	assert.Equal(t, 2*time.Hour, g2.FanSchedule.Interval.Duration)
 	assert.Equal(t, power, *g2.FanSchedule.Power)
 }
+
+func TestGardenDriverRoundTrip(t *testing.T) {
+	dbPath := t.TempDir() + "/gardens_test.db"
+	client, err := NewClient(Config{ConnectionString: dbPath})
+	require.NoError(t, err)
+
+	two := uint(2)
+	now := time.Now()
+	g := &pkg.Garden{
+		Name:        "test",
+		TopicPrefix: "test",
+		MaxZones:    &two,
+		ID:          babyapi.NewID(),
+		CreatedAt:   &now,
+		Driver: &pkg.DriverConfig{
+			Type: pkg.DriverTypeHTTPRelay,
+			Options: map[string]any{
+				"url": "http://localhost",
+			},
+		},
+	}
+
+	err = client.Gardens.Set(context.Background(), g)
+	require.NoError(t, err)
+
+	g2, err := client.Gardens.Get(context.Background(), g.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, g.Driver, g2.Driver)
+
+	for g3, err := range client.Gardens.Search(context.Background(), "", nil) {
+		require.NoError(t, err)
+		assert.Equal(t, g.Driver, g3.Driver)
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+)
+
+// HomeAssistantTopicStorage persists the Home Assistant discovery topics that were published for each Garden so
+// they can be removed after a restart
+type HomeAssistantTopicStorage struct {
+	q *db.Queries
+}
+
+// NewHomeAssistantTopicStorage creates a new HomeAssistantTopicStorage instance
+func NewHomeAssistantTopicStorage(sqlDB *sql.DB) *HomeAssistantTopicStorage {
+	return &HomeAssistantTopicStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// List returns the topics that were published for a Garden
+func (s *HomeAssistantTopicStorage) List(ctx context.Context, gardenID string) ([]string, error) {
+	topics, err := s.q.ListHomeAssistantTopics(ctx, gardenID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing Home Assistant topics: %w", err)
+	}
+	return topics, nil
+}
+
+// GardenIDs returns the IDs of Gardens that have published topics, which includes deleted Gardens that still need
+// their topics removed
+func (s *HomeAssistantTopicStorage) GardenIDs(ctx context.Context) ([]string, error) {
+	gardenIDs, err := s.q.ListHomeAssistantTopicGardenIDs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing Home Assistant topic Garden IDs: %w", err)
+	}
+	return gardenIDs, nil
+}
+
+// Add records a topic that was published for a Garden
+func (s *HomeAssistantTopicStorage) Add(ctx context.Context, gardenID, topic string) error {
+	return s.q.InsertHomeAssistantTopic(ctx, db.InsertHomeAssistantTopicParams{
+		GardenID: gardenID,
+		Topic:    topic,
+	})
+}
+
+// Delete removes a topic after its discovery config is removed
+func (s *HomeAssistantTopicStorage) Delete(ctx context.Context, gardenID, topic string) error {
+	return s.q.DeleteHomeAssistantTopic(ctx, db.DeleteHomeAssistantTopicParams{
+		GardenID: gardenID,
+		Topic:    topic,
+	})
+}
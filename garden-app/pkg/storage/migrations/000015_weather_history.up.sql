@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS weather_daily_observations (
+    weather_client_id VARCHAR(20) NOT NULL,
+    date TEXT NOT NULL,
+    rain_mm REAL,
+    max_temperature_celsius REAL,
+    evapotranspiration_mm REAL,
+    complete BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at DATETIME NOT NULL,
+    PRIMARY KEY (weather_client_id, date),
+    FOREIGN KEY (weather_client_id) REFERENCES weather_clients(id) ON DELETE CASCADE
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS pws_readings (
+    weather_client_id VARCHAR(20) NOT NULL,
+    timestamp DATETIME NOT NULL,
+    temperature_celsius REAL,
+    humidity REAL,
+    daily_rain_mm REAL,
+    solar_radiation REAL,
+    PRIMARY KEY (weather_client_id, timestamp),
+    FOREIGN KEY (weather_client_id) REFERENCES weather_clients(id) ON DELETE CASCADE
+);
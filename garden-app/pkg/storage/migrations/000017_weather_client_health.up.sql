@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS weather_client_health (
+    weather_client_id VARCHAR(20) PRIMARY KEY,
+    last_success DATETIME,
+    last_error TEXT,
+    last_error_time DATETIME,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    failing_since DATETIME,
+    FOREIGN KEY (weather_client_id) REFERENCES weather_clients(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_command_queue_garden_type;
+DROP TABLE IF EXISTS command_queue;
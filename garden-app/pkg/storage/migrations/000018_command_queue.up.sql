@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS command_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    garden_id VARCHAR(20) NOT NULL,
+    command_type TEXT NOT NULL,
+    topic TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    queued_at DATETIME NOT NULL,
+    expires_at DATETIME,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    FOREIGN KEY (garden_id) REFERENCES gardens(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_command_queue_garden_type ON command_queue (garden_id, command_type);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS driver_waterings;
+ALTER TABLE gardens DROP COLUMN driver;
@@ -0,0 +1,15 @@
+ALTER TABLE gardens ADD COLUMN driver TEXT; -- JSON
+
+CREATE TABLE IF NOT EXISTS driver_waterings (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    garden_id VARCHAR(20) NOT NULL,
+    zone_id VARCHAR(20) NOT NULL,
+    position INTEGER NOT NULL,
+    event_id TEXT NOT NULL UNIQUE,
+    source TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL,
+    notification_client_id TEXT,
+    queued_at DATETIME NOT NULL,
+    started_at DATETIME,
+    FOREIGN KEY (garden_id) REFERENCES gardens(id) ON DELETE CASCADE
+);
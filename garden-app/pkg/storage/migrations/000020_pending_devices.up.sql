@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS pending_devices (
+    topic_prefix TEXT PRIMARY KEY,
+    mac_address TEXT,
+    ip_address TEXT,
+    firmware_version TEXT,
+    first_seen DATETIME NOT NULL,
+    last_seen DATETIME NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE garden_controller_info DROP COLUMN schedule_version;
@@ -0,0 +1 @@
+ALTER TABLE garden_controller_info ADD COLUMN schedule_version TEXT;
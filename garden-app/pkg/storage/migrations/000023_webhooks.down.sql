@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_webhook_deliveries_webhook;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS water_schedule_scale_factors (
+    water_schedule_id VARCHAR(20) PRIMARY KEY,
+    scale_factor REAL NOT NULL,
+    updated_at DATETIME NOT NULL,
+    FOREIGN KEY (water_schedule_id) REFERENCES water_schedules(id) ON DELETE CASCADE
+);
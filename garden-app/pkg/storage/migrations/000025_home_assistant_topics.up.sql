@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS home_assistant_topics (
+    garden_id VARCHAR(20) NOT NULL,
+    topic TEXT NOT NULL,
+    PRIMARY KEY (garden_id, topic)
+);
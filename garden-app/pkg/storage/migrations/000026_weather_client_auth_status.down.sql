@@ -0,0 +1,4 @@
+ALTER TABLE weather_client_health DROP COLUMN auth_error;
+ALTER TABLE weather_client_health DROP COLUMN auth_last_checked;
+ALTER TABLE weather_client_health DROP COLUMN auth_token_expiration;
+ALTER TABLE weather_client_health DROP COLUMN auth_state;
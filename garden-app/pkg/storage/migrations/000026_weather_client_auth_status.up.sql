@@ -0,0 +1,4 @@
+ALTER TABLE weather_client_health ADD COLUMN auth_state TEXT;
+ALTER TABLE weather_client_health ADD COLUMN auth_token_expiration DATETIME;
+ALTER TABLE weather_client_health ADD COLUMN auth_last_checked DATETIME;
+ALTER TABLE weather_client_health ADD COLUMN auth_error TEXT;
@@ -0,0 +1 @@
+ALTER TABLE pws_readings DROP COLUMN total_rain_mm;
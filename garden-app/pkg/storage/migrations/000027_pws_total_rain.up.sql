@@ -0,0 +1 @@
+ALTER TABLE pws_readings ADD COLUMN total_rain_mm REAL;
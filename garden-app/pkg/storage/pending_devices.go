@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+)
+
+// PendingDeviceStorage implements storage for PendingDevices
+type PendingDeviceStorage struct {
+	q *db.Queries
+}
+
+// NewPendingDeviceStorage creates a new PendingDeviceStorage instance
+func NewPendingDeviceStorage(sqlDB *sql.DB) *PendingDeviceStorage {
+	return &PendingDeviceStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// Upsert records a PendingDevice. If it already exists, LastSeen is updated and empty fields keep their
+// existing values
+func (s *PendingDeviceStorage) Upsert(ctx context.Context, device *pkg.PendingDevice) error {
+	lastSeen := time.Now()
+	if device.LastSeen != nil {
+		lastSeen = *device.LastSeen
+	}
+
+	firstSeen := lastSeen
+	if device.FirstSeen != nil {
+		firstSeen = *device.FirstSeen
+	}
+
+	return s.q.UpsertPendingDevice(ctx, db.UpsertPendingDeviceParams{
+		TopicPrefix:     device.TopicPrefix,
+		MacAddress:      nullString(device.MACAddress),
+		IpAddress:       nullString(device.IPAddress),
+		FirmwareVersion: nullString(device.FirmwareVersion),
+		FirstSeen:       firstSeen.Format(time.RFC3339),
+		LastSeen:        lastSeen.Format(time.RFC3339),
+	})
+}
+
+// Get retrieves a PendingDevice by topic prefix. It returns nil if it does not exist
+func (s *PendingDeviceStorage) Get(ctx context.Context, topicPrefix string) (*pkg.PendingDevice, error) {
+	dbDevice, err := s.q.GetPendingDevice(ctx, topicPrefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting pending device: %w", err)
+	}
+	return dbPendingDeviceToPendingDevice(dbDevice), nil
+}
+
+// List returns all PendingDevices, ordered by when they were first seen
+func (s *PendingDeviceStorage) List(ctx context.Context) ([]*pkg.PendingDevice, error) {
+	dbDevices, err := s.q.ListPendingDevices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing pending devices: %w", err)
+	}
+
+	result := make([]*pkg.PendingDevice, 0, len(dbDevices))
+	for _, dbDevice := range dbDevices {
+		result = append(result, dbPendingDeviceToPendingDevice(dbDevice))
+	}
+	return result, nil
+}
+
+// Delete removes a PendingDevice
+func (s *PendingDeviceStorage) Delete(ctx context.Context, topicPrefix string) error {
+	return s.q.DeletePendingDevice(ctx, topicPrefix)
+}
+
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
+
+func dbPendingDeviceToPendingDevice(dbDevice db.PendingDevice) *pkg.PendingDevice {
+	device := &pkg.PendingDevice{
+		TopicPrefix:     dbDevice.TopicPrefix,
+		MACAddress:      dbDevice.MacAddress.String,
+		IPAddress:       dbDevice.IpAddress.String,
+		FirmwareVersion: dbDevice.FirmwareVersion.String,
+	}
+
+	firstSeen, err := time.Parse(time.RFC3339, dbDevice.FirstSeen)
+	if err == nil {
+		device.FirstSeen = &firstSeen
+	}
+	lastSeen, err := time.Parse(time.RFC3339, dbDevice.LastSeen)
+	if err == nil {
+		device.LastSeen = &lastSeen
+	}
+
+	return device
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/pws"
+)
+
+// pwsReadingRetention is how long raw readings are kept. Daily values are persisted separately by weather history
+const pwsReadingRetention = 60 * 24 * time.Hour
+
+// PWSReadingStorage implements pws.Storage to persist readings uploaded by personal weather stations
+type PWSReadingStorage struct {
+	q *db.Queries
+}
+
+// NewPWSReadingStorage creates a new PWSReadingStorage instance
+func NewPWSReadingStorage(sqlDB *sql.DB) *PWSReadingStorage {
+	return &PWSReadingStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// AddReading stores a reading for the weather client and removes readings older than the retention period
+func (s *PWSReadingStorage) AddReading(ctx context.Context, clientID string, reading pws.Reading) error {
+	err := s.q.UpsertPWSReading(ctx, db.UpsertPWSReadingParams{
+		WeatherClientID:    clientID,
+		Timestamp:          formatPWSTimestamp(reading.Timestamp),
+		TemperatureCelsius: float32ToNullFloat(reading.TemperatureCelsius),
+		Humidity:           float32ToNullFloat(reading.Humidity),
+		DailyRainMm:        float32ToNullFloat(reading.DailyRainMM),
+		SolarRadiation:     float32ToNullFloat(reading.SolarRadiation),
+		TotalRainMm:        float32ToNullFloat(reading.TotalRainMM),
+	})
+	if err != nil {
+		return fmt.Errorf("error storing reading: %w", err)
+	}
+
+	err = s.q.DeletePWSReadingsBefore(ctx, db.DeletePWSReadingsBeforeParams{
+		WeatherClientID: clientID,
+		Timestamp:       formatPWSTimestamp(reading.Timestamp.Add(-pwsReadingRetention)),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting old readings: %w", err)
+	}
+
+	return nil
+}
+
+// ListReadings returns stored readings for the weather client between start and end, inclusive
+func (s *PWSReadingStorage) ListReadings(ctx context.Context, clientID string, start, end time.Time) ([]pws.Reading, error) {
+	dbReadings, err := s.q.ListPWSReadings(ctx, db.ListPWSReadingsParams{
+		WeatherClientID: clientID,
+		Timestamp:       formatPWSTimestamp(start),
+		Timestamp_2:     formatPWSTimestamp(end),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing readings: %w", err)
+	}
+
+	result := make([]pws.Reading, 0, len(dbReadings))
+	for _, r := range dbReadings {
+		timestamp, err := time.Parse(time.RFC3339, r.Timestamp)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing reading timestamp: %w", err)
+		}
+
+		result = append(result, pws.Reading{
+			Timestamp:          timestamp,
+			TemperatureCelsius: nullFloatToFloat32(r.TemperatureCelsius),
+			Humidity:           nullFloatToFloat32(r.Humidity),
+			DailyRainMM:        nullFloatToFloat32(r.DailyRainMm),
+			SolarRadiation:     nullFloatToFloat32(r.SolarRadiation),
+			TotalRainMM:        nullFloatToFloat32(r.TotalRainMm),
+		})
+	}
+
+	return result, nil
+}
+
+// formatPWSTimestamp uses UTC so timestamps can be compared as strings
+func formatPWSTimestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
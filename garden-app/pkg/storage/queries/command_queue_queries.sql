@@ -0,0 +1,30 @@
+-- name: EnqueueCommand :one
+INSERT INTO command_queue (garden_id, command_type, topic, payload, queued_at, expires_at, attempts, last_error)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListQueuedCommands :many
+SELECT * FROM command_queue ORDER BY id;
+
+-- name: ListQueuedCommandsForGarden :many
+SELECT * FROM command_queue WHERE garden_id = ? ORDER BY id;
+
+-- name: GetQueuedCommand :one
+SELECT * FROM command_queue WHERE id = ? LIMIT 1;
+
+-- name: CountQueuedCommands :one
+SELECT COUNT(*) FROM command_queue;
+
+-- name: RecordQueuedCommandAttempt :exec
+UPDATE command_queue SET attempts = attempts + 1, last_error = ? WHERE id = ?;
+
+-- name: DeleteQueuedCommand :exec
+DELETE FROM command_queue WHERE id = ?;
+
+-- name: DeleteQueuedCommandsByType :exec
+DELETE FROM command_queue WHERE garden_id = ? AND command_type = ?;
+
+-- name: DeleteNewestQueuedCommandByType :execrows
+DELETE FROM command_queue WHERE id = (
+    SELECT id FROM command_queue WHERE garden_id = ? AND command_type = ? ORDER BY id DESC LIMIT 1
+);
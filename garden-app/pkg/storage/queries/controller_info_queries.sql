@@ -1,12 +1,14 @@
 -- name: UpsertControllerInfo :exec
-INSERT INTO garden_controller_info (garden_id, mac_address, ip_address, firmware_version, updated_at)
-VALUES (?, ?, ?, ?, ?)
+INSERT INTO garden_controller_info (garden_id, mac_address, ip_address, firmware_version, updated_at, config_hash, schedule_version)
+VALUES (?, ?, ?, ?, ?, ?, ?)
 ON CONFLICT (garden_id)
 DO UPDATE SET
     mac_address = EXCLUDED.mac_address,
     ip_address = EXCLUDED.ip_address,
     firmware_version = EXCLUDED.firmware_version,
-    updated_at = EXCLUDED.updated_at;
+    updated_at = EXCLUDED.updated_at,
+    config_hash = EXCLUDED.config_hash,
+    schedule_version = EXCLUDED.schedule_version;
 
 -- name: GetControllerInfo :one
 SELECT * FROM garden_controller_info WHERE garden_id = ? LIMIT 1;
@@ -0,0 +1,16 @@
+-- name: AddDriverWatering :one
+INSERT INTO driver_waterings (garden_id, zone_id, position, event_id, source, duration_ms, notification_client_id, queued_at, started_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: ListDriverWaterings :many
+SELECT * FROM driver_waterings ORDER BY id;
+
+-- name: SetDriverWateringStarted :exec
+UPDATE driver_waterings SET started_at = ? WHERE event_id = ?;
+
+-- name: DeleteDriverWatering :exec
+DELETE FROM driver_waterings WHERE event_id = ?;
+
+-- name: DeleteDriverWateringsForGarden :exec
+DELETE FROM driver_waterings WHERE garden_id = ?;
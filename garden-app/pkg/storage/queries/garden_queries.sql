@@ -1,16 +1,16 @@
 -- name: GetGarden :one
-SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id
 WHERE g.id = ? LIMIT 1;
 
 -- name: ListAllGardens :many
-SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id;
 
 -- name: ListActiveGardens :many
-SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id
 WHERE g.end_date IS NULL
@@ -22,9 +22,10 @@ INSERT INTO gardens (
   max_zones,
   created_at, end_date,
   notification_client_id, notification_settings,
-  controller_config, light_schedule, fan_schedule
+  controller_config, light_schedule, fan_schedule,
+  driver
 ) VALUES (
-  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+  ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
 ) ON CONFLICT (id)
 DO UPDATE SET
   name = EXCLUDED.name,
@@ -35,7 +36,8 @@ DO UPDATE SET
   notification_settings = EXCLUDED.notification_settings,
   controller_config = EXCLUDED.controller_config,
   light_schedule = EXCLUDED.light_schedule,
-  fan_schedule = EXCLUDED.fan_schedule;
+  fan_schedule = EXCLUDED.fan_schedule,
+  driver = EXCLUDED.driver;
 
 -- name: SetGardenEndDate :exec
 UPDATE gardens
@@ -46,7 +48,7 @@ WHERE id = ?;
 DELETE FROM gardens WHERE id = ?;
 
 -- name: GetGardenByTopicPrefix :one
-SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at
+SELECT g.*, ci.mac_address, ci.ip_address, ci.firmware_version, ci.updated_at, ci.config_hash, ci.schedule_version
 FROM gardens g
 LEFT JOIN garden_controller_info ci ON g.id = ci.garden_id
 WHERE g.topic_prefix = ? LIMIT 1;
@@ -0,0 +1,13 @@
+-- name: ListHomeAssistantTopics :many
+SELECT topic FROM home_assistant_topics WHERE garden_id = ? ORDER BY topic;
+
+-- name: ListHomeAssistantTopicGardenIDs :many
+SELECT DISTINCT garden_id FROM home_assistant_topics ORDER BY garden_id;
+
+-- name: InsertHomeAssistantTopic :exec
+INSERT INTO home_assistant_topics (garden_id, topic)
+VALUES (?, ?)
+ON CONFLICT (garden_id, topic) DO NOTHING;
+
+-- name: DeleteHomeAssistantTopic :exec
+DELETE FROM home_assistant_topics WHERE garden_id = ? AND topic = ?;
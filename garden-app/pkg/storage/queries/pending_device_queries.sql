@@ -0,0 +1,18 @@
+-- name: UpsertPendingDevice :exec
+INSERT INTO pending_devices (topic_prefix, mac_address, ip_address, firmware_version, first_seen, last_seen)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (topic_prefix)
+DO UPDATE SET
+    mac_address = COALESCE(EXCLUDED.mac_address, pending_devices.mac_address),
+    ip_address = COALESCE(EXCLUDED.ip_address, pending_devices.ip_address),
+    firmware_version = COALESCE(EXCLUDED.firmware_version, pending_devices.firmware_version),
+    last_seen = EXCLUDED.last_seen;
+
+-- name: GetPendingDevice :one
+SELECT * FROM pending_devices WHERE topic_prefix = ? LIMIT 1;
+
+-- name: ListPendingDevices :many
+SELECT * FROM pending_devices ORDER BY first_seen;
+
+-- name: DeletePendingDevice :exec
+DELETE FROM pending_devices WHERE topic_prefix = ?;
@@ -0,0 +1,18 @@
+-- name: UpsertPWSReading :exec
+INSERT INTO pws_readings (weather_client_id, timestamp, temperature_celsius, humidity, daily_rain_mm, solar_radiation, total_rain_mm)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (weather_client_id, timestamp)
+DO UPDATE SET
+    temperature_celsius = EXCLUDED.temperature_celsius,
+    humidity = EXCLUDED.humidity,
+    daily_rain_mm = EXCLUDED.daily_rain_mm,
+    solar_radiation = EXCLUDED.solar_radiation,
+    total_rain_mm = EXCLUDED.total_rain_mm;
+
+-- name: ListPWSReadings :many
+SELECT * FROM pws_readings
+WHERE weather_client_id = ? AND timestamp >= ? AND timestamp <= ?
+ORDER BY timestamp ASC;
+
+-- name: DeletePWSReadingsBefore :exec
+DELETE FROM pws_readings WHERE weather_client_id = ? AND timestamp < ?;
@@ -14,6 +14,9 @@ SELECT id, name, description, duration, interval, start_date, start_time, end_da
 WHERE weather_control IS NOT NULL AND (
     json_extract(weather_control, '$.rain_control.client_id') = ?
     OR json_extract(weather_control, '$.temperature_control.client_id') = ?
+    OR json_extract(weather_control, '$.evapotranspiration_control.client_id') = ?
+    OR json_extract(weather_control, '$.humidity_control.client_id') = ?
+    OR json_extract(weather_control, '$.solar_radiation_control.client_id') = ?
 );
 
 -- name: UpsertWaterSchedule :exec
@@ -0,0 +1,13 @@
+-- name: GetWaterScheduleScaleFactor :one
+SELECT * FROM water_schedule_scale_factors WHERE water_schedule_id = ? LIMIT 1;
+
+-- name: UpsertWaterScheduleScaleFactor :exec
+INSERT INTO water_schedule_scale_factors (water_schedule_id, scale_factor, updated_at)
+VALUES (?, ?, ?)
+ON CONFLICT (water_schedule_id)
+DO UPDATE SET
+    scale_factor = EXCLUDED.scale_factor,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteWaterScheduleScaleFactor :exec
+DELETE FROM water_schedule_scale_factors WHERE water_schedule_id = ?;
@@ -0,0 +1,34 @@
+-- name: GetWeatherClientHealth :one
+SELECT * FROM weather_client_health WHERE weather_client_id = ? LIMIT 1;
+
+-- name: RecordWeatherClientSuccess :exec
+INSERT INTO weather_client_health (weather_client_id, last_success, consecutive_failures)
+VALUES (?, ?, 0)
+ON CONFLICT (weather_client_id)
+DO UPDATE SET
+    last_success = EXCLUDED.last_success,
+    consecutive_failures = 0,
+    failing_since = NULL;
+
+-- name: RecordWeatherClientFailure :exec
+INSERT INTO weather_client_health (weather_client_id, last_error, last_error_time, consecutive_failures, failing_since)
+VALUES (?, ?, ?, 1, ?)
+ON CONFLICT (weather_client_id)
+DO UPDATE SET
+    last_error = EXCLUDED.last_error,
+    last_error_time = EXCLUDED.last_error_time,
+    consecutive_failures = weather_client_health.consecutive_failures + 1,
+    failing_since = COALESCE(weather_client_health.failing_since, EXCLUDED.failing_since);
+
+-- name: DeleteWeatherClientHealth :exec
+DELETE FROM weather_client_health WHERE weather_client_id = ?;
+
+-- name: RecordWeatherClientAuthStatus :exec
+INSERT INTO weather_client_health (weather_client_id, auth_state, auth_token_expiration, auth_last_checked, auth_error)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (weather_client_id)
+DO UPDATE SET
+    auth_state = EXCLUDED.auth_state,
+    auth_token_expiration = EXCLUDED.auth_token_expiration,
+    auth_last_checked = EXCLUDED.auth_last_checked,
+    auth_error = EXCLUDED.auth_error;
@@ -0,0 +1,18 @@
+-- name: UpsertWeatherDailyObservation :exec
+INSERT INTO weather_daily_observations (weather_client_id, date, rain_mm, max_temperature_celsius, evapotranspiration_mm, complete, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (weather_client_id, date)
+DO UPDATE SET
+    rain_mm = EXCLUDED.rain_mm,
+    max_temperature_celsius = EXCLUDED.max_temperature_celsius,
+    evapotranspiration_mm = EXCLUDED.evapotranspiration_mm,
+    complete = EXCLUDED.complete,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: ListWeatherDailyObservations :many
+SELECT * FROM weather_daily_observations
+WHERE weather_client_id = ? AND date >= ? AND date <= ?
+ORDER BY date ASC;
+
+-- name: DeleteWeatherDailyObservations :exec
+DELETE FROM weather_daily_observations WHERE weather_client_id = ?;
@@ -0,0 +1,48 @@
+-- name: GetWebhook :one
+SELECT * FROM webhooks
+WHERE id = ? LIMIT 1;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks;
+
+-- name: UpsertWebhook :exec
+INSERT INTO webhooks (
+  id, name, url, secret, event_types, disabled
+) VALUES (
+  ?, ?, ?, ?, ?, ?
+) ON CONFLICT (id)
+DO UPDATE SET
+  name = EXCLUDED.name,
+  url = EXCLUDED.url,
+  secret = EXCLUDED.secret,
+  event_types = EXCLUDED.event_types,
+  disabled = EXCLUDED.disabled;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks WHERE id = ?;
+
+-- name: AddWebhookDelivery :one
+INSERT INTO webhook_deliveries (webhook_id, event_id, event_type, payload, created_at)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries WHERE id = ? LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries WHERE webhook_id = ? ORDER BY id DESC LIMIT ?;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET attempts = attempts + 1, status_code = ?, last_error = ?, delivered_at = ?
+WHERE id = ?;
+
+-- name: PruneWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE id IN (
+  SELECT id FROM webhook_deliveries
+  WHERE webhook_id = ?
+  ORDER BY id DESC LIMIT -1 OFFSET ?
+);
+
+-- name: DeleteWebhookDeliveries :exec
+DELETE FROM webhook_deliveries WHERE webhook_id = ?;
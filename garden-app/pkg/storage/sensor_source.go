@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/sensor"
+)
+
+// SensorSource implements sensor.Source by looking up the Garden's sensor and reading its history from InfluxDB
+type SensorSource struct {
+	gardens        *GardenStorage
+	influxdbClient influxdb.Client
+}
+
+// NewSensorSource creates a new SensorSource instance
+func NewSensorSource(gardens *GardenStorage, influxdbClient influxdb.Client) *SensorSource {
+	return &SensorSource{
+		gardens:        gardens,
+		influxdbClient: influxdbClient,
+	}
+}
+
+// GetSensorTemperatures returns the hourly temperature range measured by the Garden's sensor between start and end
+func (s *SensorSource) GetSensorTemperatures(ctx context.Context, gardenID, sensorID string, start, end time.Time) ([]sensor.Reading, error) {
+	garden, err := s.gardens.Get(ctx, gardenID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Garden %q: %w", gardenID, err)
+	}
+
+	if garden.ControllerConfig == nil {
+		return nil, fmt.Errorf("sensor %q not found in Garden %q", sensorID, gardenID)
+	}
+
+	idx := slices.IndexFunc(garden.ControllerConfig.Sensors, func(s pkg.SensorConfig) bool {
+		return s.ID == sensorID
+	})
+	if idx < 0 {
+		return nil, fmt.Errorf("sensor %q not found in Garden %q", sensorID, gardenID)
+	}
+
+	sensorConfig := garden.ControllerConfig.Sensors[idx]
+	if !slices.Contains(pkg.SensorCapabilities(sensorConfig.Type), "temperature") {
+		return nil, fmt.Errorf("sensor type %q does not measure temperature", strings.ToUpper(sensorConfig.Type))
+	}
+
+	values, err := s.influxdbClient.GetSensorTemperatureHistory(ctx, garden.TopicPrefix, sensorID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sensor history: %w", err)
+	}
+
+	result := make([]sensor.Reading, 0, len(values))
+	for _, v := range values {
+		result = append(result, sensor.Reading{
+			Time:                  v.Time,
+			MinTemperatureCelsius: float32(v.Min),
+			MaxTemperatureCelsius: float32(v.Max),
+		})
+	}
+
+	return result, nil
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/sensor"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensorSource(t *testing.T) {
+	client, err := NewClient(Config{ConnectionString: ":memory:"})
+	require.NoError(t, err)
+
+	two := uint(2)
+	now := time.Now()
+	g := &pkg.Garden{
+		Name:        "test",
+		TopicPrefix: "test-garden",
+		MaxZones:    &two,
+		ID:          babyapi.NewID(),
+		CreatedAt:   &now,
+		ControllerConfig: &pkg.ControllerConfig{
+			Sensors: []pkg.SensorConfig{
+				{ID: "dht", Name: "Air", Type: "DHT22", Pin: 4},
+			},
+		},
+	}
+	require.NoError(t, client.Gardens.Set(context.Background(), g))
+
+	start := time.Date(2023, time.August, 20, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, time.August, 23, 0, 0, 0, 0, time.UTC)
+
+	influxdbClient := new(influxdb.MockClient)
+	influxdbClient.On("GetSensorTemperatureHistory", mock.Anything, "test-garden", "dht", start, end).
+		Return([]influxdb.SensorTemperature{{Time: start, Min: 20, Max: 25.5}}, nil)
+	client.SetInfluxDBClient(influxdbClient)
+
+	t.Run("Successful", func(t *testing.T) {
+		readings, err := client.SensorSource.GetSensorTemperatures(context.Background(), g.GetID(), "dht", start, end)
+		require.NoError(t, err)
+		assert.Equal(t, []sensor.Reading{{Time: start, MinTemperatureCelsius: 20, MaxTemperatureCelsius: 25.5}}, readings)
+	})
+
+	t.Run("ErrorSensorNotFound", func(t *testing.T) {
+		_, err := client.SensorSource.GetSensorTemperatures(context.Background(), g.GetID(), "other", start, end)
+		require.EqualError(t, err, `sensor "other" not found in Garden "`+g.GetID()+`"`)
+	})
+
+	influxdbClient.AssertExpectations(t)
+}
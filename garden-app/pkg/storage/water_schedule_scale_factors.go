@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+)
+
+// WaterScaleFactorStorage persists the most recent weather scale factor for each WaterSchedule so it is still
+// available after a restart
+type WaterScaleFactorStorage struct {
+	q *db.Queries
+}
+
+// NewWaterScaleFactorStorage creates a new WaterScaleFactorStorage instance
+func NewWaterScaleFactorStorage(sqlDB *sql.DB) *WaterScaleFactorStorage {
+	return &WaterScaleFactorStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// Get retrieves the scale factor for a WaterSchedule. It returns false if none has been recorded
+func (s *WaterScaleFactorStorage) Get(ctx context.Context, waterScheduleID string) (float64, bool, error) {
+	result, err := s.q.GetWaterScheduleScaleFactor(ctx, waterScheduleID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("error getting water schedule scale factor: %w", err)
+	}
+	return result.ScaleFactor, true, nil
+}
+
+// Set records the scale factor for a WaterSchedule
+func (s *WaterScaleFactorStorage) Set(ctx context.Context, waterScheduleID string, scaleFactor float64, t time.Time) error {
+	return s.q.UpsertWaterScheduleScaleFactor(ctx, db.UpsertWaterScheduleScaleFactorParams{
+		WaterScheduleID: waterScheduleID,
+		ScaleFactor:     scaleFactor,
+		UpdatedAt:       t.Format(time.RFC3339),
+	})
+}
+
+// Delete removes the scale factor for a WaterSchedule
+func (s *WaterScaleFactorStorage) Delete(ctx context.Context, waterScheduleID string) error {
+	return s.q.DeleteWaterScheduleScaleFactor(ctx, waterScheduleID)
+}
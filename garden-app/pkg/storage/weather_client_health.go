@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+)
+
+// WeatherClientHealthStorage implements weather.HealthStorage to persist the results of weather client requests
+type WeatherClientHealthStorage struct {
+	q *db.Queries
+}
+
+var _ weather.HealthStorage = &WeatherClientHealthStorage{}
+
+// NewWeatherClientHealthStorage creates a new WeatherClientHealthStorage instance
+func NewWeatherClientHealthStorage(sqlDB *sql.DB) *WeatherClientHealthStorage {
+	return &WeatherClientHealthStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// Get retrieves Health for a weather client. It returns nil if no requests have been recorded
+func (s *WeatherClientHealthStorage) Get(ctx context.Context, clientID string) (*weather.Health, error) {
+	dbHealth, err := s.q.GetWeatherClientHealth(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting weather client health: %w", err)
+	}
+	// The row is also created when only the AuthStatus is recorded
+	if !dbHealth.LastSuccess.Valid && !dbHealth.LastErrorTime.Valid {
+		return nil, nil
+	}
+	return dbHealthToHealth(dbHealth), nil
+}
+
+// GetAuthStatus retrieves the result of the last authentication check for a weather client. It returns nil if the
+// client has not been checked
+func (s *WeatherClientHealthStorage) GetAuthStatus(ctx context.Context, clientID string) (*weather.AuthStatus, error) {
+	dbHealth, err := s.q.GetWeatherClientHealth(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting weather client auth status: %w", err)
+	}
+	if !dbHealth.AuthState.Valid {
+		return nil, nil
+	}
+
+	status := &weather.AuthStatus{
+		State:           weather.AuthState(dbHealth.AuthState.String),
+		TokenExpiration: nullStringToTime(dbHealth.AuthTokenExpiration),
+		Error:           dbHealth.AuthError.String,
+	}
+	if lastChecked := nullStringToTime(dbHealth.AuthLastChecked); lastChecked != nil {
+		status.LastChecked = *lastChecked
+	}
+	return status, nil
+}
+
+// RecordAuthStatus stores the result of an authentication check for a weather client
+func (s *WeatherClientHealthStorage) RecordAuthStatus(ctx context.Context, clientID string, status *weather.AuthStatus) error {
+	params := db.RecordWeatherClientAuthStatusParams{
+		WeatherClientID: clientID,
+		AuthState:       sql.NullString{String: string(status.State), Valid: true},
+		AuthLastChecked: timeToNullString(status.LastChecked),
+		AuthError:       sql.NullString{String: status.Error, Valid: status.Error != ""},
+	}
+	if status.TokenExpiration != nil {
+		params.AuthTokenExpiration = timeToNullString(*status.TokenExpiration)
+	}
+	return s.q.RecordWeatherClientAuthStatus(ctx, params)
+}
+
+// RecordSuccess records a successful request and resets the consecutive failures
+func (s *WeatherClientHealthStorage) RecordSuccess(ctx context.Context, clientID string, t time.Time) error {
+	return s.q.RecordWeatherClientSuccess(ctx, db.RecordWeatherClientSuccessParams{
+		WeatherClientID: clientID,
+		LastSuccess:     timeToNullString(t),
+	})
+}
+
+// RecordFailure records a failed request. The first failure after a success starts the failing period
+func (s *WeatherClientHealthStorage) RecordFailure(ctx context.Context, clientID string, t time.Time, err error) error {
+	return s.q.RecordWeatherClientFailure(ctx, db.RecordWeatherClientFailureParams{
+		WeatherClientID: clientID,
+		LastError:       sql.NullString{String: err.Error(), Valid: true},
+		LastErrorTime:   timeToNullString(t),
+		FailingSince:    timeToNullString(t),
+	})
+}
+
+// Delete removes Health for a weather client
+func (s *WeatherClientHealthStorage) Delete(ctx context.Context, clientID string) error {
+	return s.q.DeleteWeatherClientHealth(ctx, clientID)
+}
+
+func dbHealthToHealth(dbHealth db.WeatherClientHealth) *weather.Health {
+	health := &weather.Health{
+		LastSuccess:         nullStringToTime(dbHealth.LastSuccess),
+		LastErrorTime:       nullStringToTime(dbHealth.LastErrorTime),
+		ConsecutiveFailures: int(dbHealth.ConsecutiveFailures),
+		FailingSince:        nullStringToTime(dbHealth.FailingSince),
+	}
+	if dbHealth.LastError.Valid {
+		health.LastError = dbHealth.LastError.String
+	}
+	return health
+}
+
+func timeToNullString(t time.Time) sql.NullString {
+	return sql.NullString{String: t.Format(time.RFC3339), Valid: true}
+}
+
+func nullStringToTime(s sql.NullString) *time.Time {
+	if !s.Valid || s.String == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s.String)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
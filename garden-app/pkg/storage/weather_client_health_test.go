@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeatherClientHealthStorage(t *testing.T) {
+	client, err := NewClient(Config{ConnectionString: ":memory:"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	wc := &weather.Config{
+		ID:      babyapi.NewID(),
+		Name:    "test",
+		Type:    "fake",
+		Options: map[string]any{},
+	}
+	require.NoError(t, client.WeatherClientConfigs.Set(ctx, wc))
+
+	start := time.Date(2023, time.August, 23, 10, 0, 0, 0, time.UTC)
+
+	t.Run("NoRecords", func(t *testing.T) {
+		health, err := client.WeatherClientHealth.Get(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Nil(t, health)
+	})
+
+	t.Run("Failures", func(t *testing.T) {
+		require.NoError(t, client.WeatherClientHealth.RecordFailure(ctx, wc.GetID(), start, errors.New("first")))
+		require.NoError(t, client.WeatherClientHealth.RecordFailure(ctx, wc.GetID(), start.Add(time.Hour), errors.New("second")))
+
+		health, err := client.WeatherClientHealth.Get(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, &weather.Health{
+			LastError:           "second",
+			LastErrorTime:       ptr(start.Add(time.Hour)),
+			ConsecutiveFailures: 2,
+			FailingSince:        &start,
+		}, health)
+	})
+
+	t.Run("SuccessResetsFailures", func(t *testing.T) {
+		require.NoError(t, client.WeatherClientHealth.RecordSuccess(ctx, wc.GetID(), start.Add(2*time.Hour)))
+
+		health, err := client.WeatherClientHealth.Get(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, &weather.Health{
+			LastSuccess:   ptr(start.Add(2 * time.Hour)),
+			LastError:     "second",
+			LastErrorTime: ptr(start.Add(time.Hour)),
+		}, health)
+	})
+
+	t.Run("NewFailingPeriod", func(t *testing.T) {
+		require.NoError(t, client.WeatherClientHealth.RecordFailure(ctx, wc.GetID(), start.Add(3*time.Hour), errors.New("third")))
+
+		health, err := client.WeatherClientHealth.Get(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, 1, health.ConsecutiveFailures)
+		assert.Equal(t, start.Add(3*time.Hour), *health.FailingSince)
+	})
+}
+
+func TestWeatherClientAuthStatusStorage(t *testing.T) {
+	client, err := NewClient(Config{ConnectionString: ":memory:"})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	wc := &weather.Config{
+		ID:      babyapi.NewID(),
+		Name:    "test",
+		Type:    "fake",
+		Options: map[string]any{},
+	}
+	require.NoError(t, client.WeatherClientConfigs.Set(ctx, wc))
+
+	start := time.Date(2023, time.August, 23, 10, 0, 0, 0, time.UTC)
+
+	t.Run("NotChecked", func(t *testing.T) {
+		status, err := client.WeatherClientHealth.GetAuthStatus(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Nil(t, status)
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		status := &weather.AuthStatus{
+			State:           weather.AuthStateOK,
+			TokenExpiration: ptr(start.Add(3 * time.Hour)),
+			LastChecked:     start,
+		}
+		require.NoError(t, client.WeatherClientHealth.RecordAuthStatus(ctx, wc.GetID(), status))
+
+		result, err := client.WeatherClientHealth.GetAuthStatus(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, status, result)
+
+		// Health is not created by recording the AuthStatus
+		health, err := client.WeatherClientHealth.Get(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Nil(t, health)
+	})
+
+	t.Run("ReauthorizationRequired", func(t *testing.T) {
+		require.NoError(t, client.WeatherClientHealth.RecordSuccess(ctx, wc.GetID(), start))
+
+		status := &weather.AuthStatus{
+			State:       weather.AuthStateReauthorizationRequired,
+			LastChecked: start.Add(time.Hour),
+			Error:       "invalid_grant",
+		}
+		require.NoError(t, client.WeatherClientHealth.RecordAuthStatus(ctx, wc.GetID(), status))
+
+		result, err := client.WeatherClientHealth.GetAuthStatus(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, status, result)
+
+		health, err := client.WeatherClientHealth.Get(ctx, wc.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, &weather.Health{LastSuccess: &start}, health)
+	})
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+)
+
+const weatherHistoryDateLayout = time.DateOnly
+
+// WeatherHistoryStorage implements weather.HistoryStorage to persist daily weather observations
+type WeatherHistoryStorage struct {
+	q *db.Queries
+}
+
+// NewWeatherHistoryStorage creates a new WeatherHistoryStorage instance
+func NewWeatherHistoryStorage(sqlDB *sql.DB) *WeatherHistoryStorage {
+	return &WeatherHistoryStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// ListDailyObservations returns stored observations for the weather client between the start and end dates, inclusive
+func (s *WeatherHistoryStorage) ListDailyObservations(ctx context.Context, clientID string, start, end time.Time) ([]weather.DailyObservation, error) {
+	dbObservations, err := s.q.ListWeatherDailyObservations(ctx, db.ListWeatherDailyObservationsParams{
+		WeatherClientID: clientID,
+		Date:            start.Format(weatherHistoryDateLayout),
+		Date_2:          end.Format(weatherHistoryDateLayout),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing weather observations: %w", err)
+	}
+
+	result := make([]weather.DailyObservation, 0, len(dbObservations))
+	for _, o := range dbObservations {
+		result = append(result, weather.DailyObservation{
+			Date:                  o.Date,
+			RainMM:                nullFloatToFloat32(o.RainMm),
+			MaxTemperatureCelsius: nullFloatToFloat32(o.MaxTemperatureCelsius),
+			EvapotranspirationMM:  nullFloatToFloat32(o.EvapotranspirationMm),
+			Complete:              o.Complete,
+		})
+	}
+
+	return result, nil
+}
+
+// SetDailyObservations creates or updates observations for the weather client
+func (s *WeatherHistoryStorage) SetDailyObservations(ctx context.Context, clientID string, observations []weather.DailyObservation) error {
+	updatedAt := time.Now().Format(time.RFC3339)
+	for _, o := range observations {
+		err := s.q.UpsertWeatherDailyObservation(ctx, db.UpsertWeatherDailyObservationParams{
+			WeatherClientID:       clientID,
+			Date:                  o.Date,
+			RainMm:                float32ToNullFloat(o.RainMM),
+			MaxTemperatureCelsius: float32ToNullFloat(o.MaxTemperatureCelsius),
+			EvapotranspirationMm:  float32ToNullFloat(o.EvapotranspirationMM),
+			Complete:              o.Complete,
+			UpdatedAt:             updatedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("error storing weather observation for %s: %w", o.Date, err)
+		}
+	}
+	return nil
+}
+
+// Delete removes all stored observations for the weather client
+func (s *WeatherHistoryStorage) Delete(ctx context.Context, clientID string) error {
+	return s.q.DeleteWeatherDailyObservations(ctx, clientID)
+}
+
+func nullFloatToFloat32(f sql.NullFloat64) *float32 {
+	if !f.Valid {
+		return nil
+	}
+	result := float32(f.Float64)
+	return &result
+}
+
+func float32ToNullFloat(f *float32) sql.NullFloat64 {
+	if f == nil {
+		return sql.NullFloat64{}
+	}
+	return sql.NullFloat64{Float64: float64(*f), Valid: true}
+}
@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage/db"
+	"github.com/calvinmclean/babyapi"
+)
+
+// WebhookStorage implements babyapi.Storage interface for Webhooks using SQL
+type WebhookStorage struct {
+	q *db.Queries
+}
+
+var _ babyapi.Storage[*pkg.Webhook] = &WebhookStorage{}
+
+// NewWebhookStorage creates a new WebhookStorage instance
+func NewWebhookStorage(sqlDB *sql.DB) *WebhookStorage {
+	return &WebhookStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// Get retrieves a Webhook from storage by ID
+func (s *WebhookStorage) Get(ctx context.Context, id string) (*pkg.Webhook, error) {
+	dbWebhook, err := s.q.GetWebhook(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, babyapi.ErrNotFound
+		}
+		return nil, fmt.Errorf("error getting webhook: %w", err)
+	}
+
+	return dbWebhookToWebhook(dbWebhook)
+}
+
+// Search returns all Webhooks from storage
+func (s *WebhookStorage) Search(ctx context.Context, _ string, _ url.Values) iter.Seq2[*pkg.Webhook, error] {
+	return func(yield func(*pkg.Webhook, error) bool) {
+		dbWebhooks, err := s.q.ListWebhooks(ctx)
+		if err != nil {
+			yield(nil, fmt.Errorf("error listing webhooks: %w", err))
+			return
+		}
+
+		for _, dbWebhook := range dbWebhooks {
+			webhook, err := dbWebhookToWebhook(dbWebhook)
+			if err != nil {
+				if !yield(nil, fmt.Errorf("invalid webhook: %w", err)) {
+					return
+				}
+				continue
+			}
+			if !yield(webhook, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Set saves a Webhook to storage (creates or updates)
+func (s *WebhookStorage) Set(ctx context.Context, webhook *pkg.Webhook) error {
+	eventTypes := make([]string, 0, len(webhook.EventTypes))
+	for _, eventType := range webhook.EventTypes {
+		eventTypes = append(eventTypes, string(eventType))
+	}
+
+	return s.q.UpsertWebhook(ctx, db.UpsertWebhookParams{
+		ID:         webhook.ID.String(),
+		Name:       webhook.Name,
+		Url:        webhook.URL,
+		Secret:     nullString(webhook.Secret),
+		EventTypes: strings.Join(eventTypes, ","),
+		Disabled:   webhook.IsDisabled(),
+	})
+}
+
+// Delete removes a Webhook and its delivery log from storage
+func (s *WebhookStorage) Delete(ctx context.Context, id string) error {
+	err := s.q.DeleteWebhookDeliveries(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error deleting webhook deliveries: %w", err)
+	}
+	return s.q.DeleteWebhook(ctx, id)
+}
+
+func dbWebhookToWebhook(dbWebhook db.Webhook) (*pkg.Webhook, error) {
+	webhookID, err := parseID(dbWebhook.ID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	webhook := &pkg.Webhook{
+		ID:     webhookID,
+		Name:   dbWebhook.Name,
+		URL:    dbWebhook.Url,
+		Secret: dbWebhook.Secret.String,
+	}
+	if dbWebhook.Disabled {
+		webhook.Disabled = &dbWebhook.Disabled
+	}
+
+	if dbWebhook.EventTypes != "" {
+		for _, eventType := range strings.Split(dbWebhook.EventTypes, ",") {
+			webhook.EventTypes = append(webhook.EventTypes, pkg.WebhookEventType(eventType))
+		}
+	}
+
+	return webhook, nil
+}
+
+// WebhookDeliveryStorage persists the log of WebhookDeliveries
+type WebhookDeliveryStorage struct {
+	q *db.Queries
+}
+
+// NewWebhookDeliveryStorage creates a new WebhookDeliveryStorage instance
+func NewWebhookDeliveryStorage(sqlDB *sql.DB) *WebhookDeliveryStorage {
+	return &WebhookDeliveryStorage{
+		q: db.New(sqlDB),
+	}
+}
+
+// Add records a new WebhookDelivery and sets its ID. Only the newest keep deliveries are kept for each Webhook
+func (s *WebhookDeliveryStorage) Add(ctx context.Context, delivery *pkg.WebhookDelivery, keep int) error {
+	dbDelivery, err := s.q.AddWebhookDelivery(ctx, db.AddWebhookDeliveryParams{
+		WebhookID: delivery.WebhookID,
+		EventID:   delivery.EventID,
+		EventType: string(delivery.EventType),
+		Payload:   delivery.Payload,
+		CreatedAt: delivery.CreatedAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return fmt.Errorf("error adding webhook delivery: %w", err)
+	}
+	delivery.ID = dbDelivery.ID
+
+	if keep > 0 {
+		err = s.q.PruneWebhookDeliveries(ctx, db.PruneWebhookDeliveriesParams{
+			WebhookID: delivery.WebhookID,
+			Offset:    int64(keep),
+		})
+		if err != nil {
+			return fmt.Errorf("error pruning webhook deliveries: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Get retrieves a WebhookDelivery. It returns nil if it does not exist
+func (s *WebhookDeliveryStorage) Get(ctx context.Context, id int64) (*pkg.WebhookDelivery, error) {
+	dbDelivery, err := s.q.GetWebhookDelivery(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting webhook delivery: %w", err)
+	}
+	return dbDeliveryToWebhookDelivery(dbDelivery), nil
+}
+
+// List returns the newest WebhookDeliveries for a Webhook, up to the limit
+func (s *WebhookDeliveryStorage) List(ctx context.Context, webhookID string, limit int) ([]*pkg.WebhookDelivery, error) {
+	dbDeliveries, err := s.q.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		WebhookID: webhookID,
+		Limit:     int64(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %w", err)
+	}
+
+	result := make([]*pkg.WebhookDelivery, 0, len(dbDeliveries))
+	for _, dbDelivery := range dbDeliveries {
+		result = append(result, dbDeliveryToWebhookDelivery(dbDelivery))
+	}
+	return result, nil
+}
+
+// RecordAttempt increments the number of attempts for a WebhookDelivery and stores the result. A successful attempt
+// sets DeliveredAt
+func (s *WebhookDeliveryStorage) RecordAttempt(ctx context.Context, id int64, statusCode int, attemptErr error) error {
+	var status sql.NullInt64
+	if statusCode != 0 {
+		status = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+
+	var lastError, deliveredAt sql.NullString
+	if attemptErr != nil {
+		lastError = sql.NullString{String: attemptErr.Error(), Valid: true}
+	} else {
+		deliveredAt = timeToNullString(time.Now())
+	}
+
+	return s.q.RecordWebhookDeliveryAttempt(ctx, db.RecordWebhookDeliveryAttemptParams{
+		StatusCode:  status,
+		LastError:   lastError,
+		DeliveredAt: deliveredAt,
+		ID:          id,
+	})
+}
+
+func dbDeliveryToWebhookDelivery(dbDelivery db.WebhookDelivery) *pkg.WebhookDelivery {
+	delivery := &pkg.WebhookDelivery{
+		ID:          dbDelivery.ID,
+		WebhookID:   dbDelivery.WebhookID,
+		EventID:     dbDelivery.EventID,
+		EventType:   pkg.WebhookEventType(dbDelivery.EventType),
+		Payload:     dbDelivery.Payload,
+		Attempts:    int(dbDelivery.Attempts),
+		StatusCode:  int(dbDelivery.StatusCode.Int64),
+		LastError:   dbDelivery.LastError.String,
+		DeliveredAt: nullStringToTime(dbDelivery.DeliveredAt),
+	}
+	if createdAt, err := time.Parse(time.RFC3339, dbDelivery.CreatedAt); err == nil {
+		delivery.CreatedAt = createdAt
+	}
+	return delivery
+}
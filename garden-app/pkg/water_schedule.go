@@ -116,7 +116,8 @@ func (ws *WaterSchedule) SetEndDate(now time.Time) {
 // This checks that WeatherControl is defined and has at least one type of control configured
 func (ws *WaterSchedule) HasWeatherControl() bool {
 	return ws != nil &&
-		(ws.HasRainControl() || ws.HasTemperatureControl() || ws.HasEvapotranspirationControl())
+		(ws.HasRainControl() || ws.HasTemperatureControl() || ws.HasEvapotranspirationControl() ||
+			ws.HasHumidityControl() || ws.HasSolarRadiationControl())
 }
 
 // Patch allows modifying the struct in-place with values from a different instance
@@ -190,6 +191,18 @@ func (ws *WaterSchedule) HasEvapotranspirationControl() bool {
 		ws.WeatherControl.Evapotranspiration != nil
 }
 
+// HasHumidityControl is used to determine if humidity conditions should be used for scaling
+func (ws *WaterSchedule) HasHumidityControl() bool {
+	return ws.WeatherControl != nil &&
+		ws.WeatherControl.Humidity != nil
+}
+
+// HasSolarRadiationControl is used to determine if solar radiation conditions should be used for scaling
+func (ws *WaterSchedule) HasSolarRadiationControl() bool {
+	return ws.WeatherControl != nil &&
+		ws.WeatherControl.SolarRadiation != nil
+}
+
 // IsActive determines if the WaterSchedule is currently in it's ActivePeriod. Always true if no ActivePeriod is configured
 func (ws *WaterSchedule) IsActive(now time.Time) bool {
 	if ws.ActivePeriod == nil {
@@ -251,6 +264,15 @@ func (ap *ActivePeriod) Validate() error {
 	return nil
 }
 
+// Months returns the start and end months of the ActivePeriod. It returns an error if either month is invalid
+func (ap *ActivePeriod) Months() (time.Month, time.Month, error) {
+	err := ap.Validate()
+	if err != nil {
+		return 0, 0, err
+	}
+	return ap.start.Month(), ap.end.Month(), nil
+}
+
 // Patch allows for easily updating/editing an ActivePeriod
 func (ap *ActivePeriod) Patch(newActivePeriod *ActivePeriod) {
 	if newActivePeriod.StartMonth != "" {
@@ -378,5 +400,17 @@ func ValidateWeatherControl(wc *weather.Control) error {
 			return fmt.Errorf("error validating evapotranspiration_control: %w", err)
 		}
 	}
+	if wc.Humidity != nil {
+		err := wc.Humidity.Validate()
+		if err != nil {
+			return fmt.Errorf("error validating humidity_control: %w", err)
+		}
+	}
+	if wc.SolarRadiation != nil {
+		err := wc.SolarRadiation.Validate()
+		if err != nil {
+			return fmt.Errorf("error validating solar_radiation_control: %w", err)
+		}
+	}
 	return nil
 }
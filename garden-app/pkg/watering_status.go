@@ -0,0 +1,34 @@
+package pkg
+
+import "time"
+
+// WateringState is the state of a watering that is tracked after the command is sent to the controller
+type WateringState string
+
+const (
+	WateringStateSent         WateringState = "sent"
+	WateringStateStarted      WateringState = "started"
+	WateringStateCompleted    WateringState = "completed"
+	WateringStateCancelled    WateringState = "cancelled"
+	WateringStateNotStarted   WateringState = "not_started"
+	WateringStateNotCompleted WateringState = "not_completed"
+)
+
+// WateringStatus is the most recent watering for a Zone as seen by the worker's watchdog, which expects the
+// controller to report that watering started and completed on time
+type WateringStatus struct {
+	EventID     string        `json:"event_id"`
+	State       WateringState `json:"state"`
+	Duration    Duration      `json:"duration"`
+	SentAt      time.Time     `json:"sent_at"`
+	StartedAt   *time.Time    `json:"started_at,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
+	// Retries is the number of times the command was sent again because watering didn't start
+	Retries int    `json:"retries"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Failed returns true if the watering did not start or complete when expected
+func (s *WateringStatus) Failed() bool {
+	return s.State == WateringStateNotStarted || s.State == WateringStateNotCompleted
+}
@@ -0,0 +1,66 @@
+package weather
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+)
+
+// ErrReauthorizationRequired is returned when a client's OAuth tokens can no longer be refreshed and the user
+// needs to authorize the application again
+var ErrReauthorizationRequired = weatherapi.ErrReauthorizationRequired
+
+// TokenRefresher is an optional capability interface for weather clients that authenticate with expiring OAuth
+// tokens
+type TokenRefresher interface {
+	RefreshTokenBefore(window time.Duration) (time.Time, error)
+}
+
+// AuthState describes whether a weather client is able to authenticate with its API
+type AuthState string
+
+const (
+	// AuthStateOK means the client's tokens are valid and can be refreshed
+	AuthStateOK AuthState = "ok"
+	// AuthStateReauthorizationRequired means the user needs to authorize the application again
+	AuthStateReauthorizationRequired AuthState = "reauthorization_required"
+	// AuthStateError means the last check failed for a reason that might be temporary
+	AuthStateError AuthState = "error"
+)
+
+// AuthStatus is the result of the last authentication check for a weather client
+type AuthStatus struct {
+	State           AuthState  `json:"state"`
+	TokenExpiration *time.Time `json:"token_expiration,omitempty"`
+	LastChecked     time.Time  `json:"last_checked"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// ReauthorizationRequired is a helper for templates to check the state
+func (s *AuthStatus) ReauthorizationRequired() bool {
+	return s != nil && s.State == AuthStateReauthorizationRequired
+}
+
+// HasTokenRefresh returns true if this weather client type uses expiring tokens that should be refreshed in the
+// background
+func (wc *Config) HasTokenRefresh() bool {
+	_, ok := clientTypes[strings.ToLower(wc.Type)].(TokenRefresher)
+	return ok
+}
+
+// RefreshToken refreshes the client's tokens if they expire within the window and returns the new expiration.
+// Clients created by NewClient are wrapped, so this uses the underlying implementation
+func RefreshToken(client Client, window time.Duration) (time.Time, error) {
+	if wrapper, ok := client.(*clientWrapper); ok {
+		client = wrapper.Client
+	}
+
+	refresher, ok := client.(TokenRefresher)
+	if !ok {
+		return time.Time{}, errors.New("weather client does not use token authentication")
+	}
+
+	return refresher.RefreshTokenBefore(window)
+}
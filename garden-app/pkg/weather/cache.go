@@ -0,0 +1,125 @@
+package weather
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/patrickmn/go-cache"
+	"golang.org/x/sync/singleflight"
+)
+
+// defaultCacheTTL is used for client types that don't have a configured TTL
+const defaultCacheTTL = 5 * time.Minute
+
+var (
+	responseCache = cache.New(defaultCacheTTL, 1*time.Minute)
+
+	// requestGroup merges concurrent identical requests so only one of them reaches the weather provider
+	requestGroup singleflight.Group
+
+	// cacheTTLs controls how long responses are cached for each client type. Providers that only update daily
+	// data or have strict rate limits are cached longer
+	cacheTTLs = map[string]time.Duration{
+		"openmeteo": time.Hour,
+		"nws":       30 * time.Minute,
+	}
+	cacheTTLMutex sync.RWMutex
+)
+
+// CacheConfig configures caching of weather client responses
+type CacheConfig struct {
+	// TTL overrides how long responses are cached for each client type, like "openmeteo" or "netatmo"
+	TTL map[string]time.Duration `mapstructure:"ttl" yaml:"ttl"`
+}
+
+// ConfigureCache applies the CacheConfig. Types that are not configured keep their default TTL
+func ConfigureCache(cfg CacheConfig) {
+	cacheTTLMutex.Lock()
+	defer cacheTTLMutex.Unlock()
+
+	for clientType, ttl := range cfg.TTL {
+		cacheTTLs[strings.ToLower(clientType)] = ttl
+	}
+}
+
+// CacheTTL returns how long responses from this weather client type are cached
+func (wc *Config) CacheTTL() time.Duration {
+	cacheTTLMutex.RLock()
+	defer cacheTTLMutex.RUnlock()
+
+	ttl, ok := cacheTTLs[strings.ToLower(wc.Type)]
+	if !ok || ttl <= 0 {
+		return defaultCacheTTL
+	}
+	return ttl
+}
+
+// ResetCache removes all cached responses
+func ResetCache() {
+	responseCache.Flush()
+}
+
+// ResetClientCache removes cached responses for a single weather client. This is used when the client's
+// configuration changes so it isn't used to serve responses for the previous configuration
+func ResetClientCache(clientID string) {
+	for key := range responseCache.Items() {
+		if strings.HasSuffix(key, clientID) {
+			responseCache.Delete(key)
+		}
+	}
+}
+
+// cachedRequest returns the cached result for the key or uses fetch to get and cache it. Concurrent calls with the
+// same key are merged into one fetch. It also records Prometheus metrics for the function
+func cachedRequest[T any](
+	ctx context.Context,
+	c *clientWrapper,
+	function, cacheKey string,
+	fetch func(context.Context) (T, error),
+) (T, error) {
+	now := clock.Now()
+	cached := false
+	defer func() {
+		weatherClientSummary.WithLabelValues(function, fmt.Sprintf("%t", cached)).Observe(time.Since(now).Seconds())
+	}()
+
+	cachedData, found := responseCache.Get(cacheKey)
+	if found {
+		cached = true
+		return cachedData.(T), nil
+	}
+
+	return coalesce(ctx, cacheKey, func(ctx context.Context) (T, error) {
+		result, err := fetch(ctx)
+		if err != nil {
+			return result, err
+		}
+		responseCache.Set(cacheKey, result, c.CacheTTL())
+		return result, nil
+	})
+}
+
+// coalesce runs fetch once for all concurrent callers using the same key. The shared request is not canceled
+// with the first caller's context since other callers might still be waiting for it, but each caller stops
+// waiting when its own context is done
+func coalesce[T any](ctx context.Context, key string, fetch func(context.Context) (T, error)) (T, error) {
+	var zero T
+
+	resultChan := requestGroup.DoChan(key, func() (any, error) {
+		return fetch(context.WithoutCancel(ctx))
+	})
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case result := <-resultChan:
+		if result.Err != nil {
+			return zero, result.Err
+		}
+		return result.Val.(T), nil
+	}
+}
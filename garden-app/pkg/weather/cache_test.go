@@ -0,0 +1,93 @@
+package weather
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingClient counts requests and blocks each one until release is closed
+type countingClient struct {
+	requests atomic.Int32
+	started  chan struct{}
+	release  chan struct{}
+}
+
+func (c *countingClient) GetTotalRain(ctx context.Context, _ time.Duration) (float32, error) {
+	if c.requests.Add(1) == 1 {
+		close(c.started)
+	}
+	select {
+	case <-c.release:
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+	return 10, nil
+}
+
+func (c *countingClient) GetAverageHighTemperature(context.Context, time.Duration) (float32, error) {
+	return 30, nil
+}
+
+func TestCoalescedRequests(t *testing.T) {
+	defer ResetCache()
+
+	provider := &countingClient{started: make(chan struct{}), release: make(chan struct{})}
+	client := newMetricsWrapperClient(provider, &Config{ID: babyapi.ID{ID: xid.New()}, Type: "fake"})
+
+	var wg sync.WaitGroup
+	results := make([]float32, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rain, err := client.GetTotalRain(context.Background(), 24*time.Hour)
+			assert.NoError(t, err)
+			results[i] = rain
+		}()
+	}
+
+	<-provider.started
+	close(provider.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), provider.requests.Load())
+	assert.Equal(t, []float32{10, 10, 10, 10, 10}, results)
+
+	t.Run("CanceledCallerStopsWaiting", func(t *testing.T) {
+		ResetCache()
+		provider := &countingClient{started: make(chan struct{}), release: make(chan struct{})}
+		defer close(provider.release)
+		client := newMetricsWrapperClient(provider, &Config{ID: babyapi.ID{ID: xid.New()}, Type: "fake"})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			<-provider.started
+			cancel()
+		}()
+
+		_, err := client.GetTotalRain(ctx, 24*time.Hour)
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestCacheTTL(t *testing.T) {
+	assert.Equal(t, time.Hour, (&Config{Type: "openmeteo"}).CacheTTL())
+	assert.Equal(t, 30*time.Minute, (&Config{Type: "nws"}).CacheTTL())
+	assert.Equal(t, defaultCacheTTL, (&Config{Type: "fake"}).CacheTTL())
+
+	t.Run("Configured", func(t *testing.T) {
+		defer ConfigureCache(CacheConfig{TTL: map[string]time.Duration{"netatmo": 0}})
+
+		ConfigureCache(CacheConfig{TTL: map[string]time.Duration{"Netatmo": 15 * time.Minute}})
+		assert.Equal(t, 15*time.Minute, (&Config{Type: "netatmo"}).CacheTTL())
+		assert.Equal(t, time.Hour, (&Config{Type: "openmeteo"}).CacheTTL())
+	})
+}
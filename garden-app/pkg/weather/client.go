@@ -10,18 +10,18 @@ import (
 	"strings"
 	"time"
 
-	"github.com/calvinmclean/automated-garden/garden-app/clock"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/fake"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/netatmo"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/nws"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/openmeteo"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/pws"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/sensor"
 	"github.com/calvinmclean/babyapi"
-	"github.com/patrickmn/go-cache"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
-	responseCache = cache.New(5*time.Minute, 1*time.Minute)
-
 	weatherClientSummary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
 		Namespace: "garden_app",
 		Name:      "weather_client_duration_seconds",
@@ -30,7 +30,7 @@ var (
 )
 
 func init() {
-	prometheus.MustRegister(weatherClientSummary)
+	prometheus.MustRegister(weatherClientSummary, weatherClientCompositeResponses)
 }
 
 // Client is an interface defining the possible methods used to interact with the weather client APIs
@@ -45,27 +45,93 @@ type ETProvider interface {
 	GetAverageEvapotranspiration(ctx context.Context, since time.Duration) (float32, error)
 }
 
+// ForecastProvider is an optional capability interface for weather clients that support forecasted rain and
+// temperature for an upcoming period
+type ForecastProvider interface {
+	GetForecastTotalRain(ctx context.Context, next time.Duration) (float32, error)
+	GetForecastAverageHighTemperature(ctx context.Context, next time.Duration) (float32, error)
+}
+
+// HumidityProvider is an optional capability interface for weather clients that support average daily relative
+// humidity, as a percentage
+type HumidityProvider interface {
+	GetAverageHumidity(ctx context.Context, since time.Duration) (float32, error)
+}
+
+// SolarRadiationProvider is an optional capability interface for weather clients that support average daily solar
+// radiation, in MJ/m²
+type SolarRadiationProvider interface {
+	GetAverageSolarRadiation(ctx context.Context, since time.Duration) (float32, error)
+}
+
 // Config is used to identify and configure a client type
 type Config struct {
 	ID      babyapi.ID     `json:"id" yaml:"id"`
 	Name    string         `json:"name" yaml:"name"`
 	Type    string         `json:"type" yaml:"type"`
 	Options map[string]any `json:"options" yaml:"options"`
+
+	// members are the Configs used by a composite client. They are set by ResolveCompositeMembers so the
+	// capabilities of a composite client can be checked from its Config
+	members []*Config
 }
 
 func (wc *Config) GetID() string {
 	return wc.ID.String()
 }
 
-// HasEvapotranspiration returns true if this weather client supports evapotranspiration data.
-// Currently only OpenMeteo clients have this capability.
+// clientTypes contains the implementation used for each type so capabilities can be checked from a Config
+// without creating a client, which can require API calls
+var clientTypes = map[string]Client{
+	"netatmo":   (*netatmo.Client)(nil),
+	"openmeteo": (*openmeteo.Client)(nil),
+	"nws":       (*nws.Client)(nil),
+	"pws":       (*pws.Client)(nil),
+	"sensor":    (*sensor.Client)(nil),
+	"fake":      (*fake.Client)(nil),
+}
+
+// HasEvapotranspiration returns true if this weather client supports evapotranspiration data. This is true for
+// clients that provide ET directly and for clients that provide ET inputs when a latitude is configured. Composite
+// clients support it if any member does, which requires ResolveCompositeMembers
 func (wc *Config) HasEvapotranspiration() bool {
-	switch strings.ToLower(wc.Type) {
-	case "openmeteo":
-		return true
-	default:
+	if wc.isComposite() {
+		return wc.anyMember((*Config).HasEvapotranspiration)
+	}
+
+	client, ok := clientTypes[strings.ToLower(wc.Type)]
+	if !ok {
 		return false
 	}
+
+	if _, ok := client.(ETProvider); ok {
+		return true
+	}
+
+	_, ok = client.(ETInputProvider)
+	return ok && wc.etLocation() != nil
+}
+
+// HasHumidity returns true if this weather client type supports humidity data. Composite clients support it if
+// any member does, which requires ResolveCompositeMembers
+func (wc *Config) HasHumidity() bool {
+	if wc.isComposite() {
+		return wc.anyMember((*Config).HasHumidity)
+	}
+
+	_, ok := clientTypes[strings.ToLower(wc.Type)].(HumidityProvider)
+	return ok
+}
+
+// HasSolarRadiation returns true if this weather client type supports solar radiation data. Composite clients
+// support it if any member does, which requires ResolveCompositeMembers
+func (wc *Config) HasSolarRadiation() bool {
+	if wc.isComposite() {
+		return wc.anyMember((*Config).HasSolarRadiation)
+	}
+
+	_, ok := clientTypes[strings.ToLower(wc.Type)].(SolarRadiationProvider)
+	return ok
 }
 
 func (wc *Config) ParentID() string {
@@ -102,16 +168,68 @@ func (wc *Config) Bind(r *http.Request) error {
 	return nil
 }
 
+// ClientOption configures optional dependencies used by NewClient
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	configStorage  babyapi.Storage[*Config]
+	historyStorage HistoryStorage
+	healthStorage  HealthStorage
+	pwsStorage     pws.Storage
+	sensorSource   sensor.Source
+}
+
+func newClientOptions(opts []ClientOption) *clientOptions {
+	options := &clientOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithConfigStorage provides access to other stored WeatherClient Configs. This is required for clients that
+// wrap other WeatherClients, like the composite client
+func WithConfigStorage(configStorage babyapi.Storage[*Config]) ClientOption {
+	return func(o *clientOptions) {
+		o.configStorage = configStorage
+	}
+}
+
+// WithPWSStorage provides access to readings uploaded by personal weather stations. This is required for the
+// pws client
+func WithPWSStorage(pwsStorage pws.Storage) ClientOption {
+	return func(o *clientOptions) {
+		o.pwsStorage = pwsStorage
+	}
+}
+
+// WithSensorSource provides access to readings from garden-controller sensors. This is required for the sensor client
+func WithSensorSource(sensorSource sensor.Source) ClientOption {
+	return func(o *clientOptions) {
+		o.sensorSource = sensorSource
+	}
+}
+
 // NewClient will use the config to create and return the correct type of weather client. If no type is provided, this will
 // return a nil client rather than an error since Weather client is not required
-func NewClient(c *Config, storageCallback func(map[string]any) error) (client Client, err error) {
+func NewClient(c *Config, storageCallback func(map[string]any) error, opts ...ClientOption) (client Client, err error) {
+	options := newClientOptions(opts)
+
 	switch strings.ToLower(c.Type) {
 	case "netatmo":
 		client, err = netatmo.NewClient(c.Options, storageCallback)
 	case "openmeteo":
 		client, err = openmeteo.NewClient(c.Options)
+	case "nws":
+		client, err = nws.NewClient(c.Options, storageCallback)
+	case "pws":
+		client, err = pws.NewClient(c.GetID(), c.Options, options.pwsStorage)
+	case "sensor":
+		client, err = sensor.NewClient(c.Options, options.sensorSource)
 	case "fake":
 		client, err = fake.NewClient(c.Options)
+	case "composite":
+		client, err = newCompositeClient(c, opts...)
 	default:
 		err = fmt.Errorf("invalid type '%s'", c.Type)
 	}
@@ -119,7 +237,20 @@ func NewClient(c *Config, storageCallback func(map[string]any) error) (client Cl
 		return nil, err
 	}
 
-	return newMetricsWrapperClient(client, c), nil
+	wrapper := newMetricsWrapperClient(client, c)
+	wrapper.historyStorage = options.historyStorage
+	wrapper.healthStorage = options.healthStorage
+
+	// Clients that don't provide ET directly can calculate it from their measurements if the location is known
+	if inputProvider, ok := client.(ETInputProvider); ok {
+		if _, ok := client.(ETProvider); !ok {
+			if location := c.etLocation(); location != nil {
+				wrapper.etCalculator = NewETCalculator(inputProvider, *location)
+			}
+		}
+	}
+
+	return wrapper, nil
 }
 
 // Patch allows modifying an existing Config with fields from a new one
@@ -147,103 +278,110 @@ func (*Config) EndDated() bool {
 func (*Config) SetEndDate(_ time.Time) {}
 
 // clientWrapper wraps any other implementation of the interface in order to add basic Prometheus summary metrics
-// and caching
+// and caching. Interval queries for clients that support DailyHistoryProvider are served from a single cached daily
+// series, falling back to the provider if the series fails or is missing data. If historyStorage is set, daily
+// observations are persisted. If healthStorage is set, the result of each request is recorded. If etCalculator is
+// set, it is used to provide ET for clients that don't implement ETProvider
 type clientWrapper struct {
 	Client
 	*Config
+
+	historyStorage HistoryStorage
+	healthStorage  HealthStorage
+	etCalculator   *ETCalculator
 }
 
 // newMetricsWrapperClient returns the input client wrapped with a Prometheus metrics collector. It is intended to
 // directly wrap functions to create other clients
-func newMetricsWrapperClient(client Client, config *Config) Client {
-	return &clientWrapper{client, config}
+func newMetricsWrapperClient(client Client, config *Config) *clientWrapper {
+	return &clientWrapper{Client: client, Config: config}
 }
 
 // GetTotalRain ...
 func (c *clientWrapper) GetTotalRain(ctx context.Context, since time.Duration) (float32, error) {
-	now := clock.Now()
-	cached := false
-	defer func() {
-		weatherClientSummary.WithLabelValues("GetTotalRain", fmt.Sprintf("%t", cached)).Observe(time.Since(now).Seconds())
-	}()
-
 	cacheKey := fmt.Sprintf("total_rain_%d_%s", since, c.Config.ID)
-	cachedData, found := responseCache.Get(cacheKey)
-	if found {
-		cached = true
-		return cachedData.(float32), nil
-	}
+	return cachedRequest(ctx, c, "GetTotalRain", cacheKey, func(ctx context.Context) (float32, error) {
+		if c.useDailySeries() {
+			start, end, firstDayFraction := rainHistoryRange(max(since, minHistoryRainInterval))
+			firstDay := start.Format(weatherapi.DateLayout)
+			totalRain, ok, err := c.aggregateDailySeries(ctx, start, end, func(o DailyObservation) *float32 {
+				if o.RainMM == nil || o.Date != firstDay {
+					return o.RainMM
+				}
+				rain := float32(float64(*o.RainMM) * firstDayFraction)
+				return &rain
+			}, sum)
+			if err == nil && ok {
+				return totalRain, nil
+			}
+		}
 
-	totalRain, err := WithRetries(ctx, func(ctx context.Context) (float32, error) {
-		return c.Client.GetTotalRain(ctx, since)
+		return withHealthTracking(ctx, c, func(ctx context.Context) (float32, error) {
+			return c.Client.GetTotalRain(ctx, since)
+		})
 	})
-	if err != nil {
-		return 0, err
-	}
-	responseCache.Set(cacheKey, totalRain, cache.DefaultExpiration)
-
-	return totalRain, nil
 }
 
 // GetAverageHighTemperature ...
 func (c *clientWrapper) GetAverageHighTemperature(ctx context.Context, since time.Duration) (float32, error) {
-	now := clock.Now()
-	cached := false
-	defer func() {
-		weatherClientSummary.WithLabelValues("GetAverageHighTemperature", fmt.Sprintf("%t", cached)).Observe(time.Since(now).Seconds())
-	}()
-
 	cacheKey := fmt.Sprintf("avg_temp_%d_%s", since, c.Config.ID)
-	cachedData, found := responseCache.Get(cacheKey)
-	if found {
-		cached = true
-		return cachedData.(float32), nil
-	}
+	return cachedRequest(ctx, c, "GetAverageHighTemperature", cacheKey, func(ctx context.Context) (float32, error) {
+		if c.useDailySeries() {
+			start, end := historyRange(max(since, minHistoryTemperatureInterval), false)
+			avgTemp, ok, err := c.aggregateDailySeries(ctx, start, end, func(o DailyObservation) *float32 { return o.MaxTemperatureCelsius }, mean)
+			if err == nil && ok {
+				return avgTemp, nil
+			}
+		}
 
-	avgTemp, err := WithRetries(ctx, func(ctx context.Context) (float32, error) {
-		return c.Client.GetAverageHighTemperature(ctx, since)
+		return withHealthTracking(ctx, c, func(ctx context.Context) (float32, error) {
+			return c.Client.GetAverageHighTemperature(ctx, since)
+		})
 	})
-	if err != nil {
-		return 0, err
+}
+
+// SupportsEvapotranspiration returns true if the client is able to provide evapotranspiration data. Clients
+// created by NewClient are wrapped, so this checks the underlying implementation
+func SupportsEvapotranspiration(client Client) bool {
+	if wrapper, ok := client.(*clientWrapper); ok {
+		if wrapper.etCalculator != nil {
+			return true
+		}
+		client = wrapper.Client
 	}
-	responseCache.Set(cacheKey, avgTemp, cache.DefaultExpiration)
 
-	return avgTemp, nil
-}
+	if composite, ok := client.(*compositeClient); ok {
+		return composite.SupportsEvapotranspiration()
+	}
 
-func ResetCache() {
-	responseCache = cache.New(5*time.Minute, 1*time.Minute)
+	_, ok := client.(ETProvider)
+	return ok
 }
 
 // GetAverageEvapotranspiration implements the ETProvider interface for the wrapper.
-// It forwards to the underlying client if it supports ETProvider.
+// It forwards to the underlying client if it supports ETProvider, otherwise ET is calculated if possible.
 func (c *clientWrapper) GetAverageEvapotranspiration(ctx context.Context, since time.Duration) (float32, error) {
-	now := clock.Now()
-	cached := false
-	defer func() {
-		weatherClientSummary.WithLabelValues("GetAverageEvapotranspiration", fmt.Sprintf("%t", cached)).Observe(time.Since(now).Seconds())
-	}()
-
-	cacheKey := fmt.Sprintf("avg_et_%d_%s", since, c.Config.ID)
-	cachedData, found := responseCache.Get(cacheKey)
-	if found {
-		cached = true
-		return cachedData.(float32), nil
-	}
-
-	// Check if underlying client supports ETProvider
+	// Check if underlying client supports ETProvider or can be used to calculate it
 	etClient, ok := c.Client.(ETProvider)
+	if !ok && c.etCalculator != nil {
+		etClient, ok = c.etCalculator, true
+	}
 	if !ok {
 		return 0, fmt.Errorf("weather client does not support evapotranspiration data")
 	}
 
-	avgET, err := WithRetries(ctx, func(ctx context.Context) (float32, error) {
-		return etClient.GetAverageEvapotranspiration(ctx, since)
-	})
-	if err != nil {
-		return 0, err
-	}
-	responseCache.Set(cacheKey, avgET, cache.DefaultExpiration)
+	cacheKey := fmt.Sprintf("avg_et_%d_%s", since, c.Config.ID)
+	return cachedRequest(ctx, c, "GetAverageEvapotranspiration", cacheKey, func(ctx context.Context) (float32, error) {
+		if c.useDailySeries() {
+			start, end := historyRange(max(since, minHistoryEvapotranspirationInterval), false)
+			avgET, ok, err := c.aggregateDailySeries(ctx, start, end, func(o DailyObservation) *float32 { return o.EvapotranspirationMM }, mean)
+			if err == nil && ok {
+				return avgET, nil
+			}
+		}
 
-	return avgET, nil
+		return withHealthTracking(ctx, c, func(ctx context.Context) (float32, error) {
+			return etClient.GetAverageEvapotranspiration(ctx, since)
+		})
+	})
 }
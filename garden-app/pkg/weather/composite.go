@@ -0,0 +1,398 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CompositeStrategy determines how a composite client combines responses from its member clients
+type CompositeStrategy string
+
+const (
+	// CompositeFirst uses the response from the first member, in configured order, that succeeds
+	CompositeFirst CompositeStrategy = "first"
+	// CompositeAverage uses the mean of all successful member responses
+	CompositeAverage CompositeStrategy = "average"
+	// CompositeMedian uses the median of all successful member responses
+	CompositeMedian CompositeStrategy = "median"
+)
+
+// IsValid checks if the strategy is one of the supported values
+func (s CompositeStrategy) IsValid() bool {
+	switch s {
+	case CompositeFirst, CompositeAverage, CompositeMedian:
+		return true
+	}
+	return false
+}
+
+var weatherClientCompositeResponses = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "garden_app",
+	Name:      "weather_client_composite_responses",
+	Help:      "count of responses provided by each member of a composite weather client",
+}, []string{"client_id", "member_id", "function"})
+
+// compositeConfig holds the options for a composite client. ClientIDs can be provided as a list or as a
+// comma-separated string, which is how it is submitted by the HTML form
+type compositeConfig struct {
+	Strategy  CompositeStrategy `mapstructure:"strategy"`
+	ClientIDs []string          `mapstructure:"client_ids"`
+}
+
+// compositeMember is a single WeatherClient used by a composite client
+type compositeMember struct {
+	id string
+	Client
+}
+
+// compositeClient wraps multiple other WeatherClients and combines their responses using a CompositeStrategy.
+// This allows falling back to another provider when one is unavailable, or smoothing out data from multiple
+// sources
+type compositeClient struct {
+	id       string
+	strategy CompositeStrategy
+	members  []compositeMember
+}
+
+// newCompositeClient creates a composite client. Member clients are read from the config storage and initialized
+// with NewClient using the same options. Composite clients cannot be nested, which also prevents reference cycles
+func newCompositeClient(c *Config, opts ...ClientOption) (*compositeClient, error) {
+	configStorage := newClientOptions(opts).configStorage
+	if configStorage == nil {
+		return nil, errors.New("composite client requires access to WeatherClient storage")
+	}
+
+	config, err := parseCompositeConfig(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Strategy == "" {
+		config.Strategy = CompositeFirst
+	}
+	if !config.Strategy.IsValid() {
+		return nil, fmt.Errorf("invalid strategy %q", config.Strategy)
+	}
+
+	clientIDs := config.ClientIDs
+	if len(clientIDs) == 0 {
+		return nil, errors.New("at least one client_id must be provided")
+	}
+
+	client := &compositeClient{
+		id:       c.GetID(),
+		strategy: config.Strategy,
+	}
+
+	for _, id := range clientIDs {
+		if id == c.GetID() {
+			return nil, errors.New("composite client cannot include itself")
+		}
+		if slices.ContainsFunc(client.members, func(m compositeMember) bool { return m.id == id }) {
+			return nil, fmt.Errorf("duplicate client_id %q", id)
+		}
+
+		memberConfig, err := configStorage.Get(context.Background(), id)
+		if err != nil {
+			return nil, fmt.Errorf("error getting member WeatherClient %q: %w", id, err)
+		}
+		if memberConfig.isComposite() {
+			return nil, fmt.Errorf("member WeatherClient %q is a composite client, which is not supported", id)
+		}
+
+		member, err := NewClient(memberConfig, func(weatherClientOptions map[string]any) error {
+			memberConfig.Options = weatherClientOptions
+			return configStorage.Set(context.Background(), memberConfig)
+		}, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("error initializing member WeatherClient %q: %w", id, err)
+		}
+
+		client.members = append(client.members, compositeMember{id, member})
+	}
+
+	return client, nil
+}
+
+// parseCompositeConfig decodes the composite options and splits comma-separated ClientIDs
+func parseCompositeConfig(c *Config) (compositeConfig, error) {
+	var config compositeConfig
+	err := mapstructure.WeakDecode(c.Options, &config)
+	if err != nil {
+		return compositeConfig{}, err
+	}
+
+	clientIDs := []string{}
+	for _, id := range config.ClientIDs {
+		for _, split := range strings.Split(id, ",") {
+			split = strings.TrimSpace(split)
+			if split != "" {
+				clientIDs = append(clientIDs, split)
+			}
+		}
+	}
+	config.ClientIDs = clientIDs
+
+	return config, nil
+}
+
+// CompositeMemberIDs returns the IDs of the member clients used by a composite client. It returns nil for other
+// client types or if the options are invalid
+func CompositeMemberIDs(c *Config) []string {
+	if !c.isComposite() {
+		return nil
+	}
+
+	config, err := parseCompositeConfig(c)
+	if err != nil {
+		return nil
+	}
+	return config.ClientIDs
+}
+
+// ResolveCompositeMembers sets the members of each composite client from the other Configs. This allows checking
+// capabilities like HasEvapotranspiration for composite clients without creating them
+func ResolveCompositeMembers(configs []*Config) {
+	byID := map[string]*Config{}
+	for _, c := range configs {
+		byID[c.GetID()] = c
+	}
+
+	for _, c := range configs {
+		c.members = nil
+		for _, id := range CompositeMemberIDs(c) {
+			member, ok := byID[id]
+			// Composite clients cannot be nested, so they are skipped to prevent cycles
+			if ok && !member.isComposite() {
+				c.members = append(c.members, member)
+			}
+		}
+	}
+}
+
+func (wc *Config) isComposite() bool {
+	return strings.ToLower(wc.Type) == "composite"
+}
+
+// anyMember returns true if any of the composite client's resolved members have a capability
+func (wc *Config) anyMember(has func(*Config) bool) bool {
+	return slices.ContainsFunc(wc.members, has)
+}
+
+// GetTotalRain returns the total rain combined from member clients using the configured strategy
+func (c *compositeClient) GetTotalRain(ctx context.Context, since time.Duration) (float32, error) {
+	return c.combine(ctx, "GetTotalRain", since, c.members, func(ctx context.Context, member Client) (float32, error) {
+		return member.GetTotalRain(ctx, since)
+	})
+}
+
+// GetAverageHighTemperature returns the average high temperature combined from member clients using
+// the configured strategy
+func (c *compositeClient) GetAverageHighTemperature(ctx context.Context, since time.Duration) (float32, error) {
+	return c.combine(ctx, "GetAverageHighTemperature", since, c.members, func(ctx context.Context, member Client) (float32, error) {
+		return member.GetAverageHighTemperature(ctx, since)
+	})
+}
+
+// GetAverageEvapotranspiration returns the average ET combined from member clients that support it
+func (c *compositeClient) GetAverageEvapotranspiration(ctx context.Context, since time.Duration) (float32, error) {
+	members := c.etMembers()
+	if len(members) == 0 {
+		return 0, errors.New("no member weather clients support evapotranspiration data")
+	}
+
+	return c.combine(ctx, "GetAverageEvapotranspiration", since, members, func(ctx context.Context, member Client) (float32, error) {
+		return member.(ETProvider).GetAverageEvapotranspiration(ctx, since)
+	})
+}
+
+// SupportsEvapotranspiration returns true if any member client supports ET data
+func (c *compositeClient) SupportsEvapotranspiration() bool {
+	return len(c.etMembers()) > 0
+}
+
+// GetAverageHumidity returns the average humidity combined from member clients that support it
+func (c *compositeClient) GetAverageHumidity(ctx context.Context, since time.Duration) (float32, error) {
+	members := c.humidityMembers()
+	if len(members) == 0 {
+		return 0, errors.New("no member weather clients support humidity data")
+	}
+
+	return c.combine(ctx, "GetAverageHumidity", since, members, func(ctx context.Context, member Client) (float32, error) {
+		return member.(HumidityProvider).GetAverageHumidity(ctx, since)
+	})
+}
+
+// GetAverageSolarRadiation returns the average solar radiation combined from member clients that support it
+func (c *compositeClient) GetAverageSolarRadiation(ctx context.Context, since time.Duration) (float32, error) {
+	members := c.solarRadiationMembers()
+	if len(members) == 0 {
+		return 0, errors.New("no member weather clients support solar radiation data")
+	}
+
+	return c.combine(ctx, "GetAverageSolarRadiation", since, members, func(ctx context.Context, member Client) (float32, error) {
+		return member.(SolarRadiationProvider).GetAverageSolarRadiation(ctx, since)
+	})
+}
+
+func (c *compositeClient) etMembers() []compositeMember {
+	return c.membersWith(SupportsEvapotranspiration)
+}
+
+func (c *compositeClient) humidityMembers() []compositeMember {
+	return c.membersWith(SupportsHumidity)
+}
+
+func (c *compositeClient) solarRadiationMembers() []compositeMember {
+	return c.membersWith(SupportsSolarRadiation)
+}
+
+// membersWith returns the members that have a capability
+func (c *compositeClient) membersWith(supports func(Client) bool) []compositeMember {
+	result := []compositeMember{}
+	for _, m := range c.members {
+		if supports(m.Client) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// recordResponders saves the members that provided a successful response so they can be shown with the result,
+// and counts them so the metrics show which members are being used
+func (c *compositeClient) recordResponders(function string, since time.Duration, ids []string) {
+	// Responders are stored with the client's other cached data so they are cleared with it. They don't
+	// expire since they describe the response that might still be cached
+	responseCache.Set(respondersCacheKey(c.id, function, since), ids, cache.NoExpiration)
+
+	for _, id := range ids {
+		weatherClientCompositeResponses.WithLabelValues(c.id, id, function).Inc()
+	}
+}
+
+func respondersCacheKey(clientID, function string, since time.Duration) string {
+	return fmt.Sprintf("composite_responders_%s_%d_%s", function, since, clientID)
+}
+
+// CompositeResponders returns the IDs of the member clients that provided the most recent response from a composite
+// client for the function, like "GetTotalRain", and interval. It returns nil for other client types or if there
+// is no response yet
+func CompositeResponders(c *Config, function string, since time.Duration) []string {
+	if !c.isComposite() {
+		return nil
+	}
+
+	ids, found := responseCache.Get(respondersCacheKey(c.GetID(), function, since))
+	if !found {
+		return nil
+	}
+	return slices.Clone(ids.([]string))
+}
+
+type memberResult struct {
+	id    string
+	value float32
+	err   error
+}
+
+func (c *compositeClient) combine(
+	ctx context.Context,
+	function string,
+	since time.Duration,
+	members []compositeMember,
+	get func(context.Context, Client) (float32, error),
+) (float32, error) {
+	var results []memberResult
+	if c.strategy == CompositeFirst {
+		results = firstMemberResult(ctx, members, get)
+	} else {
+		results = allMemberResults(ctx, members, get)
+	}
+
+	var errs []error
+	var values []float32
+	var responders []string
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.id, r.err))
+			continue
+		}
+		values = append(values, r.value)
+		responders = append(responders, r.id)
+	}
+
+	if len(values) == 0 {
+		// Member clients already retry transient errors, so the joined errors are formatted
+		// without wrapping to prevent retrying every member again
+		return 0, fmt.Errorf("all member weather clients failed: %v", errors.Join(errs...))
+	}
+
+	c.recordResponders(function, since, responders)
+
+	switch c.strategy {
+	case CompositeAverage:
+		return mean(values), nil
+	case CompositeMedian:
+		return median(values), nil
+	default:
+		return values[0], nil
+	}
+}
+
+// firstMemberResult calls members in order until one succeeds. The returned results include all
+// failures followed by the successful result
+func firstMemberResult(ctx context.Context, members []compositeMember, get func(context.Context, Client) (float32, error)) []memberResult {
+	results := []memberResult{}
+	for _, m := range members {
+		value, err := get(ctx, m.Client)
+		results = append(results, memberResult{m.id, value, err})
+		if err == nil {
+			break
+		}
+	}
+	return results
+}
+
+// allMemberResults concurrently calls all members and returns results in the configured member order
+func allMemberResults(ctx context.Context, members []compositeMember, get func(context.Context, Client) (float32, error)) []memberResult {
+	results := make([]memberResult, len(members))
+
+	var wg sync.WaitGroup
+	for i, m := range members {
+		wg.Go(func() {
+			value, err := get(ctx, m.Client)
+			results[i] = memberResult{m.id, value, err}
+		})
+	}
+	wg.Wait()
+
+	return results
+}
+
+func mean(values []float32) float32 {
+	var sum float32
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float32(len(values))
+}
+
+func median(values []float32) float32 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
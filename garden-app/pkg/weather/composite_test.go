@@ -0,0 +1,276 @@
+package weather
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/calvinmclean/babyapi/storage/kv"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupCompositeStorage(t *testing.T, members ...*Config) babyapi.Storage[*Config] {
+	t.Helper()
+
+	configStorage := babyapi.NewKVStorage[*Config](kv.NewDefaultDB(), "WeatherClient")
+	for _, member := range members {
+		require.NoError(t, configStorage.Set(context.Background(), member))
+	}
+	return configStorage
+}
+
+func fakeMemberConfig(rainMM, avgHighTemp float32, errMsg string) *Config {
+	return &Config{
+		ID:   babyapi.ID{ID: xid.New()},
+		Name: "member",
+		Type: "fake",
+		Options: map[string]any{
+			"rain_mm":              rainMM,
+			"rain_interval":        "24h",
+			"avg_high_temperature": avgHighTemp,
+			"error":                errMsg,
+		},
+	}
+}
+
+func TestCompositeClient(t *testing.T) {
+	restore := SetRetryDelaysForTest([]time.Duration{0, 0, 0, 0})
+	defer restore()
+
+	failing := fakeMemberConfig(0, 0, "unavailable")
+	first := fakeMemberConfig(10, 20, "")
+	second := fakeMemberConfig(20, 30, "")
+	third := fakeMemberConfig(60, 25, "")
+
+	tests := []struct {
+		name               string
+		strategy           CompositeStrategy
+		members            []*Config
+		expectedRain       float32
+		expectedTemp       float32
+		expectedResponders []string
+	}{
+		{
+			"FirstFallsBackToNextMember",
+			CompositeFirst,
+			[]*Config{failing, first, second},
+			10,
+			20,
+			[]string{first.GetID()},
+		},
+		{
+			"Average",
+			CompositeAverage,
+			[]*Config{first, failing, second, third},
+			30,
+			25,
+			[]string{first.GetID(), second.GetID(), third.GetID()},
+		},
+		{
+			"MedianOdd",
+			CompositeMedian,
+			[]*Config{first, second, third},
+			20,
+			25,
+			[]string{first.GetID(), second.GetID(), third.GetID()},
+		},
+		{
+			"MedianEven",
+			CompositeMedian,
+			[]*Config{first, second},
+			15,
+			25,
+			[]string{first.GetID(), second.GetID()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer ResetCache()
+
+			clientIDs := []any{}
+			for _, m := range tt.members {
+				clientIDs = append(clientIDs, m.GetID())
+			}
+
+			config := &Config{
+				ID:   babyapi.ID{ID: xid.New()},
+				Type: "composite",
+				Options: map[string]any{
+					"strategy":   string(tt.strategy),
+					"client_ids": clientIDs,
+				},
+			}
+			client, err := NewClient(config, func(map[string]any) error { return nil }, WithConfigStorage(setupCompositeStorage(t, tt.members...)))
+			require.NoError(t, err)
+
+			rain, err := client.GetTotalRain(context.Background(), 24*time.Hour)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expectedRain, rain, 0.01)
+
+			temp, err := client.GetAverageHighTemperature(context.Background(), 24*time.Hour)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expectedTemp, temp, 0.01)
+
+			assert.Equal(t, tt.expectedResponders, CompositeResponders(config, "GetTotalRain", 24*time.Hour))
+			assert.Equal(t, tt.expectedResponders, CompositeResponders(config, "GetAverageHighTemperature", 24*time.Hour))
+			assert.Nil(t, CompositeResponders(config, "GetTotalRain", 48*time.Hour))
+
+			for _, m := range tt.members {
+				expected := 0.0
+				if slices.Contains(tt.expectedResponders, m.GetID()) {
+					expected = 1
+				}
+				responses := weatherClientCompositeResponses.WithLabelValues(config.GetID(), m.GetID(), "GetTotalRain")
+				assert.InDelta(t, expected, testutil.ToFloat64(responses), 0.01, m.GetID())
+			}
+		})
+	}
+}
+
+func TestCompositeClientAllMembersFail(t *testing.T) {
+	restore := SetRetryDelaysForTest([]time.Duration{0, 0, 0, 0})
+	defer restore()
+	defer ResetCache()
+
+	failing1 := fakeMemberConfig(0, 0, "error one")
+	failing2 := fakeMemberConfig(0, 0, "error two")
+
+	client, err := NewClient(&Config{
+		ID:   babyapi.ID{ID: xid.New()},
+		Type: "composite",
+		Options: map[string]any{
+			"client_ids": failing1.GetID() + "," + failing2.GetID(),
+		},
+	}, func(map[string]any) error { return nil }, WithConfigStorage(setupCompositeStorage(t, failing1, failing2)))
+	require.NoError(t, err)
+
+	_, err = client.GetTotalRain(context.Background(), 24*time.Hour)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error one")
+	assert.Contains(t, err.Error(), "error two")
+}
+
+func TestCompositeClientEvapotranspiration(t *testing.T) {
+	member := fakeMemberConfig(10, 20, "")
+	client, err := NewClient(&Config{
+		ID:      babyapi.ID{ID: xid.New()},
+		Type:    "composite",
+		Options: map[string]any{"client_ids": []any{member.GetID()}},
+	}, func(map[string]any) error { return nil }, WithConfigStorage(setupCompositeStorage(t, member)))
+	require.NoError(t, err)
+
+	assert.False(t, SupportsEvapotranspiration(client))
+
+	_, err = client.(ETProvider).GetAverageEvapotranspiration(context.Background(), 24*time.Hour)
+	assert.EqualError(t, err, "no member weather clients support evapotranspiration data")
+}
+
+func TestResolveCompositeMembers(t *testing.T) {
+	openMeteo := &Config{ID: babyapi.ID{ID: xid.New()}, Type: "openmeteo"}
+	nws := &Config{ID: babyapi.ID{ID: xid.New()}, Type: "nws"}
+	withOpenMeteo := &Config{
+		ID:      babyapi.ID{ID: xid.New()},
+		Type:    "composite",
+		Options: map[string]any{"client_ids": nws.GetID() + "," + openMeteo.GetID()},
+	}
+	onlyNWS := &Config{
+		ID:      babyapi.ID{ID: xid.New()},
+		Type:    "composite",
+		Options: map[string]any{"client_ids": []any{nws.GetID(), withOpenMeteo.GetID()}},
+	}
+
+	assert.False(t, withOpenMeteo.HasHumidity())
+
+	ResolveCompositeMembers([]*Config{openMeteo, nws, withOpenMeteo, onlyNWS})
+
+	assert.True(t, withOpenMeteo.HasEvapotranspiration())
+	assert.True(t, withOpenMeteo.HasHumidity())
+	assert.True(t, withOpenMeteo.HasSolarRadiation())
+
+	// Nested composite clients are not used as members
+	assert.False(t, onlyNWS.HasEvapotranspiration())
+	assert.False(t, onlyNWS.HasHumidity())
+	assert.False(t, onlyNWS.HasSolarRadiation())
+}
+
+func TestNewCompositeClientErrors(t *testing.T) {
+	member := fakeMemberConfig(10, 20, "")
+	compositeID := babyapi.ID{ID: xid.New()}
+	nested := &Config{
+		ID:      babyapi.ID{ID: xid.New()},
+		Type:    "composite",
+		Options: map[string]any{"client_ids": member.GetID()},
+	}
+
+	tests := []struct {
+		name          string
+		options       map[string]any
+		noStorage     bool
+		expectedError string
+	}{
+		{
+			"MissingStorage",
+			map[string]any{"client_ids": member.GetID()},
+			true,
+			"composite client requires access to WeatherClient storage",
+		},
+		{
+			"InvalidStrategy",
+			map[string]any{"strategy": "mode", "client_ids": member.GetID()},
+			false,
+			`invalid strategy "mode"`,
+		},
+		{
+			"NoClientIDs",
+			map[string]any{"strategy": "first"},
+			false,
+			"at least one client_id must be provided",
+		},
+		{
+			"IncludesSelf",
+			map[string]any{"client_ids": compositeID.String()},
+			false,
+			"composite client cannot include itself",
+		},
+		{
+			"Duplicate",
+			map[string]any{"client_ids": member.GetID() + "," + member.GetID()},
+			false,
+			`duplicate client_id "` + member.GetID() + `"`,
+		},
+		{
+			"Nested",
+			map[string]any{"client_ids": nested.GetID()},
+			false,
+			`member WeatherClient "` + nested.GetID() + `" is a composite client, which is not supported`,
+		},
+		{
+			"MemberNotFound",
+			map[string]any{"client_ids": "does-not-exist"},
+			false,
+			`error getting member WeatherClient "does-not-exist": resource not found`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []ClientOption{WithConfigStorage(setupCompositeStorage(t, member, nested))}
+			if tt.noStorage {
+				opts = nil
+			}
+
+			_, err := NewClient(&Config{
+				ID:      compositeID,
+				Type:    "composite",
+				Options: tt.options,
+			}, func(map[string]any) error { return nil }, opts...)
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
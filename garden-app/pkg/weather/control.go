@@ -6,6 +6,8 @@ type Control struct {
 	Rain               *WeatherScaler            `json:"rain_control,omitempty"`
 	Temperature        *WeatherScaler            `json:"temperature_control,omitempty"`
 	Evapotranspiration *EvapotranspirationScaler `json:"evapotranspiration_control,omitempty"`
+	Humidity           *WeatherScaler            `json:"humidity_control,omitempty"`
+	SolarRadiation     *WeatherScaler            `json:"solar_radiation_control,omitempty"`
 }
 
 // Patch allows modifying the struct in-place with values from a different instance
@@ -25,4 +27,16 @@ func (wc *Control) Patch(newControl *Control) {
 	if newControl.Evapotranspiration != nil {
 		wc.Evapotranspiration = newControl.Evapotranspiration
 	}
+	if newControl.Humidity != nil {
+		if wc.Humidity == nil {
+			wc.Humidity = &WeatherScaler{}
+		}
+		wc.Humidity.Patch(newControl.Humidity)
+	}
+	if newControl.SolarRadiation != nil {
+		if wc.SolarRadiation == nil {
+			wc.SolarRadiation = &WeatherScaler{}
+		}
+		wc.SolarRadiation.Patch(newControl.SolarRadiation)
+	}
 }
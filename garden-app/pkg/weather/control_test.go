@@ -56,6 +56,15 @@ func TestControlPatch(t *testing.T) {
 				},
 			},
 		},
+		{
+			"PatchRain.Points",
+			&Control{
+				Rain: &WeatherScaler{
+					Interpolation: Curve,
+					Points:        []CurvePoint{{Input: 0, Factor: 1}, {Input: 10, Factor: 0}},
+				},
+			},
+		},
 		{
 			"PatchTemperature.InputMin",
 			&Control{
@@ -64,6 +73,22 @@ func TestControlPatch(t *testing.T) {
 				},
 			},
 		},
+		{
+			"PatchHumidity.InputMax",
+			&Control{
+				Humidity: &WeatherScaler{
+					InputMax: float64Ptr(80.0),
+				},
+			},
+		},
+		{
+			"PatchSolarRadiation.FactorMax",
+			&Control{
+				SolarRadiation: &WeatherScaler{
+					FactorMax: float64Ptr(1.5),
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -79,9 +104,17 @@ func TestControlPatch(t *testing.T) {
 			if tt.newControl.Temperature == nil {
 				tt.newControl.Temperature = &WeatherScaler{}
 			}
+			if tt.newControl.Humidity == nil {
+				tt.newControl.Humidity = &WeatherScaler{}
+			}
+			if tt.newControl.SolarRadiation == nil {
+				tt.newControl.SolarRadiation = &WeatherScaler{}
+			}
 			c := &Control{
-				Rain:        &WeatherScaler{},
-				Temperature: &WeatherScaler{},
+				Rain:           &WeatherScaler{},
+				Temperature:    &WeatherScaler{},
+				Humidity:       &WeatherScaler{},
+				SolarRadiation: &WeatherScaler{},
 			}
 			c.Patch(tt.newControl)
 			assert.Equal(t, tt.newControl, c)
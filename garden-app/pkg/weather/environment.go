@@ -0,0 +1,70 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SupportsHumidity returns true if the client is able to provide humidity data. Clients created by NewClient are
+// wrapped, so this checks the underlying implementation
+func SupportsHumidity(client Client) bool {
+	if wrapper, ok := client.(*clientWrapper); ok {
+		client = wrapper.Client
+	}
+
+	if composite, ok := client.(*compositeClient); ok {
+		return len(composite.humidityMembers()) > 0
+	}
+
+	_, ok := client.(HumidityProvider)
+	return ok
+}
+
+// SupportsSolarRadiation returns true if the client is able to provide solar radiation data. Clients created by
+// NewClient are wrapped, so this checks the underlying implementation
+func SupportsSolarRadiation(client Client) bool {
+	if wrapper, ok := client.(*clientWrapper); ok {
+		client = wrapper.Client
+	}
+
+	if composite, ok := client.(*compositeClient); ok {
+		return len(composite.solarRadiationMembers()) > 0
+	}
+
+	_, ok := client.(SolarRadiationProvider)
+	return ok
+}
+
+// GetAverageHumidity implements the HumidityProvider interface for the wrapper.
+// It forwards to the underlying client if it supports HumidityProvider.
+func (c *clientWrapper) GetAverageHumidity(ctx context.Context, since time.Duration) (float32, error) {
+	humidityClient, ok := c.Client.(HumidityProvider)
+	if !ok {
+		return 0, errors.New("weather client does not support humidity data")
+	}
+
+	cacheKey := fmt.Sprintf("avg_humidity_%d_%s", since, c.Config.ID)
+	return cachedRequest(ctx, c, "GetAverageHumidity", cacheKey, func(ctx context.Context) (float32, error) {
+		return withHealthTracking(ctx, c, func(ctx context.Context) (float32, error) {
+			return humidityClient.GetAverageHumidity(ctx, since)
+		})
+	})
+}
+
+// GetAverageSolarRadiation implements the SolarRadiationProvider interface for the wrapper.
+// It forwards to the underlying client if it supports SolarRadiationProvider.
+func (c *clientWrapper) GetAverageSolarRadiation(ctx context.Context, since time.Duration) (float32, error) {
+	solarRadiationClient, ok := c.Client.(SolarRadiationProvider)
+	if !ok {
+		return 0, errors.New("weather client does not support solar radiation data")
+	}
+
+	cacheKey := fmt.Sprintf("avg_solar_radiation_%d_%s", since, c.Config.ID)
+	return cachedRequest(ctx, c, "GetAverageSolarRadiation", cacheKey, func(ctx context.Context) (float32, error) {
+		return withHealthTracking(ctx, c, func(ctx context.Context) (float32, error) {
+			return solarRadiationClient.GetAverageSolarRadiation(ctx, since)
+		})
+	})
+}
@@ -0,0 +1,113 @@
+package weather
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasHumidityAndSolarRadiation(t *testing.T) {
+	tests := []struct {
+		name                   string
+		config                 *Config
+		expectedHumidity       bool
+		expectedSolarRadiation bool
+	}{
+		{"OpenMeteo", &Config{Type: "openmeteo"}, true, true},
+		{"Netatmo", &Config{Type: "netatmo"}, true, false},
+		{"Fake", &Config{Type: "fake"}, true, true},
+		{"NWS", &Config{Type: "nws"}, false, false},
+		{"Composite", &Config{Type: "composite"}, false, false},
+		{"Invalid", &Config{Type: "other"}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedHumidity, tt.config.HasHumidity())
+			assert.Equal(t, tt.expectedSolarRadiation, tt.config.HasSolarRadiation())
+		})
+	}
+}
+
+func TestGetAverageHumidityAndSolarRadiation(t *testing.T) {
+	defer ResetCache()
+
+	client, err := NewClient(&Config{
+		ID:   babyapi.ID{ID: xid.New()},
+		Type: "fake",
+		Options: map[string]any{
+			"rain_interval":       "24h",
+			"avg_humidity":        65,
+			"avg_solar_radiation": 22.5,
+		},
+	}, func(map[string]any) error { return nil })
+	require.NoError(t, err)
+
+	assert.True(t, SupportsHumidity(client))
+	assert.True(t, SupportsSolarRadiation(client))
+
+	humidity, err := client.(HumidityProvider).GetAverageHumidity(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, float32(65), humidity)
+
+	solarRadiation, err := client.(SolarRadiationProvider).GetAverageSolarRadiation(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, float32(22.5), solarRadiation)
+
+	t.Run("NotSupported", func(t *testing.T) {
+		client, err := NewClient(&Config{
+			ID:      babyapi.ID{ID: xid.New()},
+			Type:    "sensor",
+			Options: map[string]any{"garden_id": "garden", "sensor_id": "sensor"},
+		}, func(map[string]any) error { return nil }, WithSensorSource(sensorSource{}))
+		require.NoError(t, err)
+
+		assert.False(t, SupportsHumidity(client))
+		assert.False(t, SupportsSolarRadiation(client))
+
+		_, err = client.(HumidityProvider).GetAverageHumidity(context.Background(), 72*time.Hour)
+		assert.EqualError(t, err, "weather client does not support humidity data")
+
+		_, err = client.(SolarRadiationProvider).GetAverageSolarRadiation(context.Background(), 72*time.Hour)
+		assert.EqualError(t, err, "weather client does not support solar radiation data")
+	})
+}
+
+func TestCompositeClientHumidity(t *testing.T) {
+	defer ResetCache()
+
+	fakeMember := fakeMemberConfig(10, 20, "")
+	fakeMember.Options["avg_humidity"] = 40
+	otherFakeMember := fakeMemberConfig(10, 20, "")
+	otherFakeMember.Options["avg_humidity"] = 60
+	sensorMember := &Config{
+		ID:      babyapi.ID{ID: xid.New()},
+		Type:    "sensor",
+		Options: map[string]any{"garden_id": "garden", "sensor_id": "sensor"},
+	}
+
+	client, err := NewClient(&Config{
+		ID:   babyapi.ID{ID: xid.New()},
+		Type: "composite",
+		Options: map[string]any{
+			"strategy":   "average",
+			"client_ids": []any{sensorMember.GetID(), fakeMember.GetID(), otherFakeMember.GetID()},
+		},
+	}, func(map[string]any) error { return nil },
+		WithConfigStorage(setupCompositeStorage(t, sensorMember, fakeMember, otherFakeMember)),
+		WithSensorSource(sensorSource{}),
+	)
+	require.NoError(t, err)
+
+	assert.True(t, SupportsHumidity(client))
+
+	// The sensor member doesn't support humidity, so it is not included in the average
+	humidity, err := client.(HumidityProvider).GetAverageHumidity(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, float32(50), humidity)
+}
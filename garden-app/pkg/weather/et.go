@@ -0,0 +1,176 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	// solarConstant is in MJ/m²/min
+	solarConstant = 0.0820
+	// stefanBoltzmann is in MJ/K⁴/m²/day
+	stefanBoltzmann = 4.903e-9
+	// defaultWindSpeed is recommended by FAO-56 when wind speed is not measured
+	defaultWindSpeed = 2.0
+)
+
+// DailyETInputs contains the daily measurements used to calculate reference evapotranspiration
+type DailyETInputs = weatherapi.DailyETInputs
+
+// ETInputProvider is an optional capability interface for weather clients that can provide the daily
+// measurements needed to calculate reference evapotranspiration
+type ETInputProvider interface {
+	GetDailyETInputs(ctx context.Context, start, end time.Time) ([]DailyETInputs, error)
+}
+
+// ETLocation is the location used to calculate extraterrestrial radiation and atmospheric pressure. It is
+// configured with the latitude and elevation (meters) options of any weather client
+type ETLocation struct {
+	Latitude        float64 `mapstructure:"latitude"`
+	ElevationMeters float64 `mapstructure:"elevation"`
+}
+
+// etLocation reads the ETLocation from the Config's options. It returns nil if latitude is not configured. The
+// option is checked instead of the decoded value since 0 is a valid latitude at the equator
+func (wc *Config) etLocation() *ETLocation {
+	// The HTML form submits an empty string when the optional latitude isn't set
+	latitude, ok := wc.Options["latitude"]
+	if !ok || latitude == nil || latitude == "" {
+		return nil
+	}
+
+	var location ETLocation
+	err := mapstructure.WeakDecode(wc.Options, &location)
+	if err != nil {
+		return nil
+	}
+	return &location
+}
+
+// ETCalculator gives a client that implements ETInputProvider an ETProvider implementation by calculating
+// reference evapotranspiration (ET₀) from its daily measurements
+type ETCalculator struct {
+	provider ETInputProvider
+	location ETLocation
+}
+
+var _ ETProvider = &ETCalculator{}
+
+// NewETCalculator creates a new ETCalculator for the provider's location
+func NewETCalculator(provider ETInputProvider, location ETLocation) *ETCalculator {
+	return &ETCalculator{provider, location}
+}
+
+// GetAverageEvapotranspiration returns the average daily ET₀ for the full days in the period, ending yesterday
+func (c *ETCalculator) GetAverageEvapotranspiration(ctx context.Context, since time.Duration) (float32, error) {
+	start, end := historyRange(max(since, minHistoryEvapotranspirationInterval), false)
+
+	inputs, err := c.provider.GetDailyETInputs(ctx, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("error getting evapotranspiration inputs: %w", err)
+	}
+
+	values := []float32{}
+	for _, in := range inputs {
+		et, ok := c.location.ReferenceET(in)
+		if ok {
+			values = append(values, et)
+		}
+	}
+
+	if len(values) == 0 {
+		return 0, errors.New("no valid evapotranspiration inputs for the specified period")
+	}
+
+	return mean(values), nil
+}
+
+// ReferenceET calculates the daily ET₀ in millimeters. FAO-56 Penman-Monteith is used when humidity and solar
+// radiation are available, otherwise the Hargreaves equation is used with only temperature. It returns false
+// if the inputs don't include min and max temperature
+func (l ETLocation) ReferenceET(in DailyETInputs) (float32, bool) {
+	if in.MinTemperatureCelsius == nil || in.MaxTemperatureCelsius == nil {
+		return 0, false
+	}
+
+	date, err := time.Parse(weatherapi.DateLayout, in.Date)
+	if err != nil {
+		return 0, false
+	}
+
+	tMin, tMax := float64(*in.MinTemperatureCelsius), float64(*in.MaxTemperatureCelsius)
+	ra := extraterrestrialRadiation(l.Latitude, date.YearDay())
+
+	var et float64
+	if in.RelativeHumidity != nil && in.SolarRadiationMJ != nil {
+		windSpeed := defaultWindSpeed
+		if in.WindSpeedMPS != nil {
+			windSpeed = float64(*in.WindSpeedMPS)
+		}
+		et = PenmanMonteith(tMin, tMax, float64(*in.RelativeHumidity), float64(*in.SolarRadiationMJ), windSpeed, ra, l.ElevationMeters)
+	} else {
+		et = Hargreaves(tMin, tMax, ra)
+	}
+
+	return float32(max(et, 0)), true
+}
+
+// Hargreaves calculates daily ET₀ in millimeters from min and max temperature (°C) and extraterrestrial
+// radiation (MJ/m²/day)
+func Hargreaves(tMin, tMax, ra float64) float64 {
+	tMean := (tMin + tMax) / 2
+	return 0.0023 * (tMean + 17.8) * math.Sqrt(max(tMax-tMin, 0)) * 0.408 * ra
+}
+
+// PenmanMonteith calculates daily ET₀ in millimeters using the FAO-56 Penman-Monteith equation. Inputs are
+// temperature (°C), mean relative humidity (%), solar radiation (MJ/m²/day), wind speed at 2m (m/s),
+// extraterrestrial radiation (MJ/m²/day), and elevation (m). Soil heat flux is ignored for daily periods
+func PenmanMonteith(tMin, tMax, relativeHumidity, solarRadiation, windSpeed, ra, elevation float64) float64 {
+	tMean := (tMin + tMax) / 2
+
+	pressure := 101.3 * math.Pow((293-0.0065*elevation)/293, 5.26)
+	psychrometric := 0.000665 * pressure
+
+	slope := 4098 * saturationVaporPressure(tMean) / math.Pow(tMean+237.3, 2)
+	es := (saturationVaporPressure(tMax) + saturationVaporPressure(tMin)) / 2
+	ea := relativeHumidity / 100 * es
+
+	clearSkyRadiation := (0.75 + 2e-5*elevation) * ra
+	relativeShortwave := 1.0
+	if clearSkyRadiation > 0 {
+		relativeShortwave = min(solarRadiation/clearSkyRadiation, 1)
+	}
+
+	netShortwave := 0.77 * solarRadiation
+	netLongwave := stefanBoltzmann * (math.Pow(tMax+273.16, 4) + math.Pow(tMin+273.16, 4)) / 2 *
+		(0.34 - 0.14*math.Sqrt(ea)) * (1.35*relativeShortwave - 0.35)
+	netRadiation := netShortwave - netLongwave
+
+	numerator := 0.408*slope*netRadiation + psychrometric*(900/(tMean+273))*windSpeed*(es-ea)
+	denominator := slope + psychrometric*(1+0.34*windSpeed)
+
+	return numerator / denominator
+}
+
+// saturationVaporPressure returns the saturation vapor pressure (kPa) at the temperature (°C)
+func saturationVaporPressure(t float64) float64 {
+	return 0.6108 * math.Exp(17.27*t/(t+237.3))
+}
+
+// extraterrestrialRadiation returns the daily extraterrestrial radiation (MJ/m²/day) for the latitude (degrees)
+// and day of year
+func extraterrestrialRadiation(latitude float64, dayOfYear int) float64 {
+	phi := latitude * math.Pi / 180
+	inverseDistance := 1 + 0.033*math.Cos(2*math.Pi*float64(dayOfYear)/365)
+	declination := 0.409 * math.Sin(2*math.Pi*float64(dayOfYear)/365-1.39)
+	sunsetAngle := math.Acos(math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(declination))))
+
+	return 24 * 60 / math.Pi * solarConstant * inverseDistance *
+		(sunsetAngle*math.Sin(phi)*math.Sin(declination) + math.Cos(phi)*math.Cos(declination)*math.Sin(sunsetAngle))
+}
@@ -0,0 +1,162 @@
+package weather
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/sensor"
+	"github.com/calvinmclean/babyapi"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtraterrestrialRadiation(t *testing.T) {
+	// FAO-56 Example 8: 20°S on 3 September
+	assert.InDelta(t, 32.2, extraterrestrialRadiation(-20, 246), 0.05)
+}
+
+func TestReferenceET(t *testing.T) {
+	ptr := func(f float32) *float32 { return &f }
+
+	// FAO-56 Example 18: Brussels (50°48'N, 100m) on 6 July
+	brussels := ETLocation{Latitude: 50.8, ElevationMeters: 100}
+
+	tests := []struct {
+		name     string
+		inputs   DailyETInputs
+		expected float32
+		ok       bool
+	}{
+		{
+			"PenmanMonteith",
+			DailyETInputs{
+				Date:                  "2023-07-06",
+				MinTemperatureCelsius: ptr(12.3),
+				MaxTemperatureCelsius: ptr(21.5),
+				RelativeHumidity:      ptr(70.55),
+				SolarRadiationMJ:      ptr(22.07),
+				WindSpeedMPS:          ptr(2.078),
+			},
+			3.88,
+			true,
+		},
+		{
+			"HargreavesWithoutHumidity",
+			DailyETInputs{
+				Date:                  "2023-07-06",
+				MinTemperatureCelsius: ptr(12.3),
+				MaxTemperatureCelsius: ptr(21.5),
+				SolarRadiationMJ:      ptr(22.07),
+			},
+			4.06,
+			true,
+		},
+		{
+			"MissingMinTemperature",
+			DailyETInputs{Date: "2023-07-06", MaxTemperatureCelsius: ptr(21.5)},
+			0,
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			et, ok := brussels.ReferenceET(tt.inputs)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.expected, et, 0.01)
+		})
+	}
+}
+
+// etInputProvider returns the same temperature range for every day
+type etInputProvider struct {
+	historyProvider
+}
+
+func (p *etInputProvider) GetDailyETInputs(_ context.Context, start, end time.Time) ([]DailyETInputs, error) {
+	result := []DailyETInputs{}
+	for _, day := range weatherapi.Days(start, end) {
+		minTemperature, maxTemperature := float32(12.3), float32(21.5)
+		result = append(result, DailyETInputs{Date: day, MinTemperatureCelsius: &minTemperature, MaxTemperatureCelsius: &maxTemperature})
+	}
+	return result, nil
+}
+
+func TestETCalculator(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+
+	calculator := NewETCalculator(&etInputProvider{}, ETLocation{Latitude: 50.8})
+
+	// Mock time is in August, so radiation is lower than the July example
+	et, err := calculator.GetAverageEvapotranspiration(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, 3.21, et, 0.01)
+}
+
+func TestHasEvapotranspiration(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *Config
+		expected bool
+	}{
+		{"OpenMeteo", &Config{Type: "openmeteo"}, true},
+		{"NetatmoWithoutLatitude", &Config{Type: "netatmo", Options: map[string]any{}}, false},
+		{"NetatmoWithLatitude", &Config{Type: "netatmo", Options: map[string]any{"latitude": 33.4}}, true},
+		{"NetatmoAtEquator", &Config{Type: "netatmo", Options: map[string]any{"latitude": 0}}, true},
+		{"NetatmoWithEmptyLatitude", &Config{Type: "netatmo", Options: map[string]any{"latitude": ""}}, false},
+		{"NetatmoWithInvalidLatitude", &Config{Type: "netatmo", Options: map[string]any{"latitude": "north"}}, false},
+		{"PWSWithLatitudeString", &Config{Type: "pws", Options: map[string]any{"latitude": "33.4"}}, true},
+		{"FakeWithLatitude", &Config{Type: "fake", Options: map[string]any{"latitude": 33.4}}, false},
+		{"Composite", &Config{Type: "composite"}, false},
+		{"Invalid", &Config{Type: "other"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.config.HasEvapotranspiration())
+		})
+	}
+}
+
+// sensorSource returns the same temperature range for every hour
+type sensorSource struct{}
+
+func (sensorSource) GetSensorTemperatures(_ context.Context, _, _ string, start, end time.Time) ([]sensor.Reading, error) {
+	result := []sensor.Reading{}
+	for t := start; t.Before(end); t = t.Add(time.Hour) {
+		result = append(result, sensor.Reading{Time: t, MinTemperatureCelsius: 12.3, MaxTemperatureCelsius: 21.5})
+	}
+	return result, nil
+}
+
+func TestNewClientWithETCalculator(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+
+	client, err := NewClient(&Config{
+		ID:      babyapi.ID{ID: xid.New()},
+		Type:    "sensor",
+		Options: map[string]any{"garden_id": "garden", "sensor_id": "sensor", "latitude": 50.8},
+	}, nil, WithSensorSource(&sensorSource{}))
+	require.NoError(t, err)
+	assert.True(t, SupportsEvapotranspiration(client))
+
+	et, err := client.(ETProvider).GetAverageEvapotranspiration(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, 3.21, et, 0.01)
+
+	client, err = NewClient(&Config{
+		ID:      babyapi.ID{ID: xid.New()},
+		Type:    "sensor",
+		Options: map[string]any{"garden_id": "garden", "sensor_id": "sensor"},
+	}, nil, WithSensorSource(&sensorSource{}))
+	require.NoError(t, err)
+	assert.False(t, SupportsEvapotranspiration(client))
+}
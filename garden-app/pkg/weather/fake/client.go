@@ -6,6 +6,8 @@ import (
 	"errors"
 	"time"
 
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
 	"github.com/mitchellh/mapstructure"
 )
 
@@ -16,6 +18,8 @@ type Config struct {
 	rainInterval time.Duration
 
 	AverageHighTemperature float32 `mapstructure:"avg_high_temperature"`
+	AverageHumidity        float32 `mapstructure:"avg_humidity"`
+	AverageSolarRadiation  float32 `mapstructure:"avg_solar_radiation"`
 
 	Error      string `mapstructure:"error"`
 	ErrorCount int    `mapstructure:"error_count"`
@@ -66,6 +70,56 @@ func (c *Client) GetAverageHighTemperature(_ context.Context, _ time.Duration) (
 	return c.AverageHighTemperature, nil
 }
 
+// GetAverageHumidity returns the configured value
+func (c *Client) GetAverageHumidity(_ context.Context, _ time.Duration) (float32, error) {
+	if c.shouldError() {
+		return 0, errors.New(c.Error)
+	}
+
+	return c.AverageHumidity, nil
+}
+
+// GetAverageSolarRadiation returns the configured value
+func (c *Client) GetAverageSolarRadiation(_ context.Context, _ time.Duration) (float32, error) {
+	if c.shouldError() {
+		return 0, errors.New(c.Error)
+	}
+
+	return c.AverageSolarRadiation, nil
+}
+
+// GetDailyObservations returns an observation for each day using the configured rain and temperature values. Each
+// day's rain is the same as GetTotalRain for the part of the day that has passed, so today only includes rain so far
+func (c *Client) GetDailyObservations(_ context.Context, start, end time.Time) ([]weatherapi.DailyObservation, error) {
+	if c.shouldError() {
+		return nil, errors.New(c.Error)
+	}
+
+	now := clock.Now()
+
+	result := []weatherapi.DailyObservation{}
+	for _, day := range weatherapi.Days(start, end) {
+		dayStart, err := time.ParseInLocation(weatherapi.DateLayout, day, start.Location())
+		if err != nil {
+			return nil, err
+		}
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		if dayEnd.After(now) {
+			dayEnd = now
+		}
+
+		rain := float32(max(dayEnd.Sub(dayStart), 0).Hours() / c.rainInterval.Hours() * float64(c.RainMM))
+		temperature := c.AverageHighTemperature
+		result = append(result, weatherapi.DailyObservation{
+			Date:                  day,
+			RainMM:                &rain,
+			MaxTemperatureCelsius: &temperature,
+		})
+	}
+
+	return result, nil
+}
+
 // shouldError returns true if the fake client should return an error for this call.
 // When ErrorCount is greater than zero, it returns true for the first ErrorCount
 // calls and then succeeds. When ErrorCount is zero or negative, it returns true for
@@ -0,0 +1,51 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SupportsForecast returns true if the client is able to provide forecast data
+func SupportsForecast(client Client) bool {
+	if wrapper, ok := client.(*clientWrapper); ok {
+		client = wrapper.Client
+	}
+
+	_, ok := client.(ForecastProvider)
+	return ok
+}
+
+// GetForecastTotalRain implements the ForecastProvider interface for the wrapper.
+// It forwards to the underlying client if it supports ForecastProvider.
+func (c *clientWrapper) GetForecastTotalRain(ctx context.Context, next time.Duration) (float32, error) {
+	return c.getForecast(ctx, "GetForecastTotalRain", fmt.Sprintf("forecast_rain_%d_%s", next, c.Config.ID), func(ctx context.Context, fp ForecastProvider) (float32, error) {
+		return fp.GetForecastTotalRain(ctx, next)
+	})
+}
+
+// GetForecastAverageHighTemperature implements the ForecastProvider interface for the wrapper.
+// It forwards to the underlying client if it supports ForecastProvider.
+func (c *clientWrapper) GetForecastAverageHighTemperature(ctx context.Context, next time.Duration) (float32, error) {
+	return c.getForecast(ctx, "GetForecastAverageHighTemperature", fmt.Sprintf("forecast_temp_%d_%s", next, c.Config.ID), func(ctx context.Context, fp ForecastProvider) (float32, error) {
+		return fp.GetForecastAverageHighTemperature(ctx, next)
+	})
+}
+
+func (c *clientWrapper) getForecast(
+	ctx context.Context,
+	function, cacheKey string,
+	get func(context.Context, ForecastProvider) (float32, error),
+) (float32, error) {
+	forecastClient, ok := c.Client.(ForecastProvider)
+	if !ok {
+		return 0, errors.New("weather client does not support forecast data")
+	}
+
+	return cachedRequest(ctx, c, function, cacheKey, func(ctx context.Context) (float32, error) {
+		return withHealthTracking(ctx, c, func(ctx context.Context) (float32, error) {
+			return get(ctx, forecastClient)
+		})
+	})
+}
@@ -0,0 +1,54 @@
+package weather
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type forecastClient struct {
+	historyProvider
+	calls int
+}
+
+func (c *forecastClient) GetForecastTotalRain(context.Context, time.Duration) (float32, error) {
+	c.calls++
+	return 12, nil
+}
+
+func (c *forecastClient) GetForecastAverageHighTemperature(context.Context, time.Duration) (float32, error) {
+	c.calls++
+	return 30, nil
+}
+
+func TestForecast(t *testing.T) {
+	defer ResetCache()
+
+	fc := &forecastClient{}
+	client := newMetricsWrapperClient(fc, &Config{ID: babyapi.ID{ID: xid.New()}})
+	assert.True(t, SupportsForecast(client))
+
+	for range 2 {
+		rain, err := client.GetForecastTotalRain(context.Background(), 24*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 12, rain, 0.01)
+
+		temp, err := client.GetForecastAverageHighTemperature(context.Background(), 24*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 30, temp, 0.01)
+	}
+
+	// The second set of calls are cached
+	assert.Equal(t, 2, fc.calls)
+
+	noForecast := newMetricsWrapperClient(&historyProvider{}, &Config{ID: babyapi.ID{ID: xid.New()}})
+	assert.False(t, SupportsForecast(noForecast))
+
+	_, err := noForecast.GetForecastTotalRain(context.Background(), 24*time.Hour)
+	assert.EqualError(t, err, "weather client does not support forecast data")
+}
@@ -0,0 +1,64 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+)
+
+// Health tracks recent successes and failures of requests to a weather client's API. Failures are only recorded
+// after retries are exhausted, so ConsecutiveFailures counts failed requests rather than individual attempts
+type Health struct {
+	LastSuccess         *time.Time `json:"last_success,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorTime       *time.Time `json:"last_error_time,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	FailingSince        *time.Time `json:"failing_since,omitempty"`
+}
+
+// Failing returns true if the most recent request failed
+func (h *Health) Failing() bool {
+	return h != nil && h.ConsecutiveFailures > 0
+}
+
+// FailingFor returns how long the client has been failing, or zero if it is healthy
+func (h *Health) FailingFor() time.Duration {
+	if !h.Failing() || h.FailingSince == nil {
+		return 0
+	}
+	return clock.Now().Sub(*h.FailingSince)
+}
+
+// HealthStorage is used to persist the results of requests to weather client APIs
+type HealthStorage interface {
+	RecordSuccess(ctx context.Context, clientID string, t time.Time) error
+	RecordFailure(ctx context.Context, clientID string, t time.Time, err error) error
+}
+
+// WithHealthStorage enables recording the result of each request to the weather client's API
+func WithHealthStorage(healthStorage HealthStorage) ClientOption {
+	return func(o *clientOptions) {
+		o.healthStorage = healthStorage
+	}
+}
+
+// withHealthTracking runs the operation using WithRetries and records the final result. Canceled requests are not
+// recorded because they don't say anything about the weather client
+func withHealthTracking[T any](ctx context.Context, c *clientWrapper, operation func(context.Context) (T, error)) (T, error) {
+	result, err := WithRetries(ctx, operation)
+	if c.healthStorage == nil || errors.Is(err, context.Canceled) {
+		return result, err
+	}
+
+	// Use a separate context so the result is still recorded if the request timed out
+	recordCtx := context.WithoutCancel(ctx)
+	if err != nil {
+		_ = c.healthStorage.RecordFailure(recordCtx, c.GetID(), clock.Now(), err)
+	} else {
+		_ = c.healthStorage.RecordSuccess(recordCtx, c.GetID(), clock.Now())
+	}
+
+	return result, err
+}
@@ -0,0 +1,100 @@
+package weather
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryHealthStorage struct {
+	health map[string]*Health
+}
+
+func (s *memoryHealthStorage) RecordSuccess(_ context.Context, clientID string, t time.Time) error {
+	h := s.get(clientID)
+	h.LastSuccess = &t
+	h.ConsecutiveFailures = 0
+	h.FailingSince = nil
+	return nil
+}
+
+func (s *memoryHealthStorage) RecordFailure(_ context.Context, clientID string, t time.Time, err error) error {
+	h := s.get(clientID)
+	h.LastError = err.Error()
+	h.LastErrorTime = &t
+	h.ConsecutiveFailures++
+	if h.FailingSince == nil {
+		h.FailingSince = &t
+	}
+	return nil
+}
+
+func (s *memoryHealthStorage) get(clientID string) *Health {
+	if s.health[clientID] == nil {
+		s.health[clientID] = &Health{}
+	}
+	return s.health[clientID]
+}
+
+func TestHealthTracking(t *testing.T) {
+	mockClock := clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+
+	healthStorage := &memoryHealthStorage{health: map[string]*Health{}}
+	config := &Config{
+		ID:   babyapi.NewID(),
+		Name: "test",
+		Type: "fake",
+		Options: map[string]any{
+			"rain_mm":              25.4,
+			"rain_interval":        "24h",
+			"avg_high_temperature": 40,
+			"error":                "permanent error",
+			"error_count":          2,
+		},
+	}
+	client, err := NewClient(config, func(map[string]any) error { return nil }, WithHealthStorage(healthStorage))
+	require.NoError(t, err)
+
+	start := clock.Now()
+
+	_, err = client.GetTotalRain(context.Background(), 24*time.Hour)
+	require.Error(t, err)
+
+	mockClock.Add(time.Hour)
+	_, err = client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+	require.Error(t, err)
+
+	health := healthStorage.health[config.GetID()]
+	require.NotNil(t, health)
+	assert.True(t, health.Failing())
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, "permanent error", health.LastError)
+	assert.Equal(t, start, *health.FailingSince)
+	assert.Equal(t, time.Hour, health.FailingFor())
+	assert.Nil(t, health.LastSuccess)
+
+	mockClock.Add(time.Hour)
+	_, err = client.GetTotalRain(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+
+	assert.False(t, health.Failing())
+	assert.Equal(t, time.Duration(0), health.FailingFor())
+	assert.Equal(t, start.Add(2*time.Hour), *health.LastSuccess)
+	assert.Equal(t, "permanent error", health.LastError)
+
+	t.Run("CachedResponsesNotRecorded", func(t *testing.T) {
+		lastSuccess := *health.LastSuccess
+		mockClock.Add(time.Minute)
+
+		_, err = client.GetTotalRain(context.Background(), 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, lastSuccess, *health.LastSuccess)
+	})
+}
@@ -0,0 +1,280 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+)
+
+// Minimum intervals used when serving queries from daily observations. These match the minimums used by
+// the weather providers
+const (
+	minHistoryRainInterval               = 24 * time.Hour
+	minHistoryTemperatureInterval        = 72 * time.Hour
+	minHistoryEvapotranspirationInterval = 24 * time.Hour
+
+	// defaultSeriesDays is the minimum number of days included in a client's cached daily series. This
+	// covers the intervals that are commonly used by weather scalers
+	defaultSeriesDays = 7
+)
+
+// DailyObservation contains weather data for a single day
+type DailyObservation = weatherapi.DailyObservation
+
+// ErrHistoryNotSupported is returned when requesting daily history from a client that doesn't provide it
+var ErrHistoryNotSupported = errors.New("weather client does not support daily history")
+
+// DailyHistoryProvider is an optional capability interface for weather clients that are able to provide
+// daily observations for a range of dates
+type DailyHistoryProvider interface {
+	GetDailyObservations(ctx context.Context, start, end time.Time) ([]DailyObservation, error)
+}
+
+// HistoryStorage is used to persist daily observations so they don't need to be fetched from the
+// weather provider again
+type HistoryStorage interface {
+	ListDailyObservations(ctx context.Context, clientID string, start, end time.Time) ([]DailyObservation, error)
+	SetDailyObservations(ctx context.Context, clientID string, observations []DailyObservation) error
+}
+
+// WithHistoryStorage enables persisting daily observations for clients that support DailyHistoryProvider.
+// When enabled, the daily series is built from stored observations and only missing or incomplete days
+// are fetched from the provider
+func WithHistoryStorage(historyStorage HistoryStorage) ClientOption {
+	return func(o *clientOptions) {
+		o.historyStorage = historyStorage
+	}
+}
+
+// SupportsHistory returns true if the client is able to provide daily observations
+func SupportsHistory(client Client) bool {
+	if wrapper, ok := client.(*clientWrapper); ok {
+		client = wrapper.Client
+	}
+
+	_, ok := client.(DailyHistoryProvider)
+	return ok
+}
+
+// GetDailyObservations returns daily observations for each day between start and end. Stored observations are
+// used when available and the provider is only queried starting at the first missing or incomplete day. A day
+// is complete once it has ended, so today's observation is always refreshed
+func (c *clientWrapper) GetDailyObservations(ctx context.Context, start, end time.Time) ([]DailyObservation, error) {
+	provider, ok := c.Client.(DailyHistoryProvider)
+	if !ok {
+		return nil, ErrHistoryNotSupported
+	}
+
+	days := weatherapi.Days(start, end)
+	if len(days) == 0 {
+		return []DailyObservation{}, nil
+	}
+
+	stored := map[string]DailyObservation{}
+	if c.historyStorage != nil {
+		observations, err := c.historyStorage.ListDailyObservations(ctx, c.GetID(), start, end)
+		if err != nil {
+			return nil, fmt.Errorf("error getting stored observations: %w", err)
+		}
+		for _, o := range observations {
+			stored[o.Date] = o
+		}
+	}
+
+	fetchFrom := -1
+	for i, day := range days {
+		if o, ok := stored[day]; !ok || !o.Complete {
+			fetchFrom = i
+			break
+		}
+	}
+
+	if fetchFrom >= 0 {
+		fetchStart, err := time.ParseInLocation(weatherapi.DateLayout, days[fetchFrom], start.Location())
+		if err != nil {
+			return nil, err
+		}
+
+		fetched, err := withHealthTracking(ctx, c, func(ctx context.Context) ([]DailyObservation, error) {
+			return provider.GetDailyObservations(ctx, fetchStart, end)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		today := clock.Now().In(start.Location()).Format(weatherapi.DateLayout)
+		toStore := []DailyObservation{}
+		for _, o := range fetched {
+			if !slices.Contains(days, o.Date) {
+				continue
+			}
+			o.Complete = o.Date < today
+			stored[o.Date] = o
+			toStore = append(toStore, o)
+		}
+
+		if c.historyStorage != nil && len(toStore) > 0 {
+			err = c.historyStorage.SetDailyObservations(ctx, c.GetID(), toStore)
+			if err != nil {
+				return nil, fmt.Errorf("error storing observations: %w", err)
+			}
+		}
+	}
+
+	result := []DailyObservation{}
+	for _, day := range days {
+		if o, ok := stored[day]; ok {
+			result = append(result, o)
+		}
+	}
+
+	return result, nil
+}
+
+// useDailySeries returns true if the wrapper should serve interval queries from the daily series. This requires
+// history storage so enabling caching doesn't change the results from the provider's own interval queries
+func (c *clientWrapper) useDailySeries() bool {
+	if c.historyStorage == nil {
+		return false
+	}
+	_, ok := c.Client.(DailyHistoryProvider)
+	return ok
+}
+
+// dailySeries is the cached range of daily observations for a client
+type dailySeries struct {
+	start, end   time.Time
+	observations []DailyObservation
+}
+
+// covers returns true if the series includes every day between start and end
+func (s dailySeries) covers(start, end time.Time) bool {
+	return !s.start.After(start) && !s.end.Before(end)
+}
+
+// between returns the observations for days between start and end
+func (s dailySeries) between(start, end time.Time) []DailyObservation {
+	days := weatherapi.Days(start, end)
+
+	result := []DailyObservation{}
+	for _, o := range s.observations {
+		if slices.Contains(days, o.Date) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+// getDailySeries returns the client's daily series covering start through end. A single series ending today
+// is cached for each client so all intervals are derived from the same request. It covers at least
+// defaultSeriesDays and is only fetched again if it expires or a longer range is requested
+func (c *clientWrapper) getDailySeries(ctx context.Context, start, end time.Time) (dailySeries, error) {
+	cacheKey := "daily_series_" + c.GetID()
+
+	cachedData, found := responseCache.Get(cacheKey)
+	if found {
+		series := cachedData.(dailySeries)
+		if series.covers(start, end) {
+			return series, nil
+		}
+	}
+
+	_, today := historyRange(24*time.Hour, true)
+	numDays := max(len(weatherapi.Days(start, today)), defaultSeriesDays)
+	seriesStart := today.AddDate(0, 0, 1-numDays)
+
+	// The provider request goes through the same metrics, coalescing, retries, and health tracking as other
+	// requests. The result is also cached for the client so shorter ranges can use it
+	requestKey := fmt.Sprintf("%s_%d_%s", cacheKey, numDays, today.Format(weatherapi.DateLayout))
+	series, err := cachedRequest(ctx, c, "GetDailyObservations", requestKey, func(ctx context.Context) (dailySeries, error) {
+		observations, err := c.GetDailyObservations(ctx, seriesStart, today)
+		if err != nil {
+			return dailySeries{}, err
+		}
+		return dailySeries{seriesStart, today, observations}, nil
+	})
+	if err != nil {
+		return dailySeries{}, err
+	}
+
+	responseCache.Set(cacheKey, series, c.CacheTTL())
+	return series, nil
+}
+
+// historyRange returns the first and last day covering the duration. The range includes today when
+// includeToday is true, otherwise it ends yesterday
+func historyRange(since time.Duration, includeToday bool) (time.Time, time.Time) {
+	numDays := int(math.Ceil(since.Hours() / 24))
+	if numDays < 1 {
+		numDays = 1
+	}
+
+	now := clock.Now().In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	end := today
+	if !includeToday {
+		end = today.AddDate(0, 0, -1)
+	}
+
+	return end.AddDate(0, 0, 1-numDays), end
+}
+
+// rainHistoryRange returns the first and last day covering the duration ending now and the fraction of the first
+// day that is included. Today's observation only includes rain so far, so the rest of the duration comes from the
+// end of the first day. Daily observations don't include when rain fell, so it is prorated for the first day
+func rainHistoryRange(since time.Duration) (time.Time, time.Time, float64) {
+	now := clock.Now().In(time.Local)
+	start := now.Add(-since)
+
+	firstDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	nextDay := firstDay.AddDate(0, 0, 1)
+	firstDayFraction := nextDay.Sub(start).Hours() / nextDay.Sub(firstDay).Hours()
+
+	return firstDay, time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local), firstDayFraction
+}
+
+// aggregateDailySeries gets daily observations between start and end from the daily series and combines the
+// values selected by the value function. It returns false if any day is missing data
+func (c *clientWrapper) aggregateDailySeries(
+	ctx context.Context,
+	start, end time.Time,
+	value func(DailyObservation) *float32,
+	combine func([]float32) float32,
+) (float32, bool, error) {
+	series, err := c.getDailySeries(ctx, start, end)
+	if err != nil {
+		return 0, false, err
+	}
+
+	observations := series.between(start, end)
+	if len(observations) != len(weatherapi.Days(start, end)) {
+		return 0, false, nil
+	}
+
+	values := []float32{}
+	for _, o := range observations {
+		v := value(o)
+		if v == nil {
+			return 0, false, nil
+		}
+		values = append(values, *v)
+	}
+
+	return combine(values), true, nil
+}
+
+// sum adds the values and rounds the total to hundredths, so prorated values don't add rounding errors to it
+func sum(values []float32) float32 {
+	var total float64
+	for _, v := range values {
+		total += float64(v)
+	}
+	return float32(math.Round(total*100) / 100)
+}
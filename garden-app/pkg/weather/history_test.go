@@ -0,0 +1,296 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+	"github.com/calvinmclean/babyapi"
+	"github.com/rs/xid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryHistoryStorage struct {
+	observations map[string]map[string]DailyObservation
+}
+
+func (s *memoryHistoryStorage) ListDailyObservations(_ context.Context, clientID string, start, end time.Time) ([]DailyObservation, error) {
+	result := []DailyObservation{}
+	for _, day := range weatherapi.Days(start, end) {
+		if o, ok := s.observations[clientID][day]; ok {
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
+func (s *memoryHistoryStorage) SetDailyObservations(_ context.Context, clientID string, observations []DailyObservation) error {
+	if s.observations[clientID] == nil {
+		s.observations[clientID] = map[string]DailyObservation{}
+	}
+	for _, o := range observations {
+		s.observations[clientID][o.Date] = o
+	}
+	return nil
+}
+
+// historyProvider returns the same values for every day unless rain is set for a specific day. Like a real provider,
+// today's rain only includes the part of the day that has passed. It records the start of each requested range
+type historyProvider struct {
+	rain, temperature float32
+	rainByDay         map[string]float32
+	err               error
+	errCount          int
+	requests          []string
+}
+
+func (p *historyProvider) GetTotalRain(context.Context, time.Duration) (float32, error) {
+	return -1, nil
+}
+
+func (p *historyProvider) GetAverageHighTemperature(context.Context, time.Duration) (float32, error) {
+	return -1, nil
+}
+
+func (p *historyProvider) GetDailyObservations(_ context.Context, start, end time.Time) ([]DailyObservation, error) {
+	p.requests = append(p.requests, start.Format(weatherapi.DateLayout))
+	if p.err != nil && (p.errCount == 0 || len(p.requests) <= p.errCount) {
+		return nil, p.err
+	}
+
+	now := clock.Now().In(start.Location())
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	result := []DailyObservation{}
+	for _, day := range weatherapi.Days(start, end) {
+		rain, temperature := p.rain, p.temperature
+		if dayRain, ok := p.rainByDay[day]; ok {
+			rain = dayRain
+		} else if day == midnight.Format(weatherapi.DateLayout) {
+			rain *= float32(now.Sub(midnight).Hours() / 24)
+		}
+		result = append(result, DailyObservation{Date: day, RainMM: &rain, MaxTemperatureCelsius: &temperature})
+	}
+	return result, nil
+}
+
+func TestHistory(t *testing.T) {
+	mockClock := clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+
+	provider := &historyProvider{rain: 2, temperature: 30}
+	historyStorage := &memoryHistoryStorage{observations: map[string]map[string]DailyObservation{}}
+
+	client := newMetricsWrapperClient(provider, &Config{ID: babyapi.ID{ID: xid.New()}})
+	client.historyStorage = historyStorage
+
+	now := clock.Now().In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	date := func(t time.Time, daysAgo int) string {
+		return t.AddDate(0, 0, -daysAgo).Format(weatherapi.DateLayout)
+	}
+
+	t.Run("TotalRainFetchesDailySeries", func(t *testing.T) {
+		rain, err := client.GetTotalRain(context.Background(), 72*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 6, rain, 0.01)
+		assert.Equal(t, []string{date(today, defaultSeriesDays-1)}, provider.requests)
+
+		stored := historyStorage.observations[client.GetID()]
+		assert.True(t, stored[date(today, defaultSeriesDays-1)].Complete)
+		assert.True(t, stored[date(today, 1)].Complete)
+		assert.False(t, stored[date(today, 0)].Complete)
+	})
+
+	t.Run("IntervalsUseSameSeries", func(t *testing.T) {
+		provider.requests = nil
+
+		rain, err := client.GetTotalRain(context.Background(), 24*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 2, rain, 0.01)
+
+		// Temperature uses days ending yesterday
+		temp, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 30, temp, 0.01)
+
+		assert.Empty(t, provider.requests)
+	})
+
+	t.Run("OnlyIncompleteDaysAreFetched", func(t *testing.T) {
+		ResetCache()
+		provider.requests = nil
+
+		rain, err := client.GetTotalRain(context.Background(), 72*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 6, rain, 0.01)
+		assert.Equal(t, []string{date(today, 0)}, provider.requests)
+	})
+
+	t.Run("LongerIntervalExtendsSeries", func(t *testing.T) {
+		provider.requests = nil
+
+		rain, err := client.GetTotalRain(context.Background(), 240*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 20, rain, 0.01)
+		// The interval starts during the day 10 days ago, so that day is included
+		assert.Equal(t, []string{date(today, 10)}, provider.requests)
+
+		// The longer series is cached and covers shorter intervals
+		_, err = client.GetTotalRain(context.Background(), 48*time.Hour)
+		require.NoError(t, err)
+		assert.Len(t, provider.requests, 1)
+	})
+
+	t.Run("NextDayCompletesPreviousDay", func(t *testing.T) {
+		ResetCache()
+		provider.requests = nil
+		mockClock.Add(24 * time.Hour)
+		tomorrow := today.AddDate(0, 0, 1)
+
+		_, err := client.GetTotalRain(context.Background(), 24*time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, []string{date(tomorrow, 1)}, provider.requests)
+		assert.True(t, historyStorage.observations[client.GetID()][date(today, 0)].Complete)
+
+		_, err = client.GetTotalRain(context.Background(), 48*time.Hour)
+		require.NoError(t, err)
+		assert.Len(t, provider.requests, 1)
+	})
+
+	t.Run("MissingDataUsesProvider", func(t *testing.T) {
+		ResetCache()
+
+		// ET is not provided by history, so this falls back to the direct call
+		client.Client = &historyETProvider{provider}
+		defer func() { client.Client = provider }()
+
+		et, err := client.GetAverageEvapotranspiration(context.Background(), 24*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 4.5, et, 0.01)
+	})
+}
+
+type historyETProvider struct {
+	*historyProvider
+}
+
+func (p *historyETProvider) GetAverageEvapotranspiration(context.Context, time.Duration) (float32, error) {
+	return 4.5, nil
+}
+
+func TestHistoryWithoutStorage(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+
+	provider := &historyProvider{rain: 2, temperature: 30}
+	client := newMetricsWrapperClient(provider, &Config{ID: babyapi.ID{ID: xid.New()}})
+
+	// Without storage, intervals are requested from the provider directly instead of the daily series
+	rain, err := client.GetTotalRain(context.Background(), 48*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, -1, rain, 0.01)
+
+	temp, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, -1, temp, 0.01)
+
+	assert.Empty(t, provider.requests)
+}
+
+func TestHistoryRainProratesFirstDay(t *testing.T) {
+	mockClock := clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+
+	now := clock.Now().In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	mockClock.Set(today.Add(6 * time.Hour))
+
+	// The 24h interval includes 18 hours of yesterday, so 75% of its rain is included
+	provider := &historyProvider{rainByDay: map[string]float32{
+		today.AddDate(0, 0, -1).Format(weatherapi.DateLayout): 4,
+		today.Format(weatherapi.DateLayout):                   1,
+	}}
+	client := newMetricsWrapperClient(provider, &Config{ID: babyapi.ID{ID: xid.New()}})
+	client.historyStorage = &memoryHistoryStorage{observations: map[string]map[string]DailyObservation{}}
+
+	rain, err := client.GetTotalRain(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, 4, rain, 0.01)
+}
+
+func TestHistoryErrorUsesProvider(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+
+	provider := &historyProvider{err: errors.New("history unavailable")}
+	client := newMetricsWrapperClient(provider, &Config{ID: babyapi.ID{ID: xid.New()}})
+	client.historyStorage = &memoryHistoryStorage{observations: map[string]map[string]DailyObservation{}}
+
+	rain, err := client.GetTotalRain(context.Background(), 24*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, -1, rain, 0.01)
+
+	temp, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, -1, temp, 0.01)
+}
+
+func TestHistoryRetriesAndTracksHealth(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+	defer ResetCache()
+	restore := SetRetryDelaysForTest([]time.Duration{0, 0, 0, 0})
+	defer restore()
+
+	provider := &historyProvider{
+		rain:        2,
+		temperature: 30,
+		err:         &weatherapi.HTTPError{StatusCode: http.StatusServiceUnavailable},
+		errCount:    1,
+	}
+	healthStorage := &memoryHealthStorage{health: map[string]*Health{}}
+	client := newMetricsWrapperClient(provider, &Config{ID: babyapi.ID{ID: xid.New()}})
+	client.historyStorage = &memoryHistoryStorage{observations: map[string]map[string]DailyObservation{}}
+	client.healthStorage = healthStorage
+
+	// The daily series request is retried after the server error instead of falling back to the provider
+	temp, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, 30, temp, 0.01)
+	assert.Len(t, provider.requests, 2)
+
+	health := healthStorage.get(client.GetID())
+	assert.NotNil(t, health.LastSuccess)
+	assert.Equal(t, 0, health.ConsecutiveFailures)
+
+	// Errors that aren't retried are recorded and the interval is requested from the provider instead
+	ResetCache()
+	provider.err = errors.New("history unavailable")
+	provider.errCount = 0
+	provider.requests = nil
+
+	temp, err = client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+	require.NoError(t, err)
+	assert.InDelta(t, -1, temp, 0.01)
+	assert.Len(t, provider.requests, 1)
+	assert.Equal(t, "history unavailable", health.LastError)
+	assert.NotNil(t, health.LastErrorTime)
+}
+
+func TestHistoryNotSupported(t *testing.T) {
+	client := newMetricsWrapperClient(&compositeClient{}, &Config{})
+	assert.False(t, SupportsHistory(client))
+
+	_, err := client.GetDailyObservations(context.Background(), clock.Now(), clock.Now())
+	assert.ErrorIs(t, err, ErrHistoryNotSupported)
+}
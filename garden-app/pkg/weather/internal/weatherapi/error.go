@@ -1,7 +1,14 @@
 // Package weatherapi provides shared types for weather API clients.
 package weatherapi
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrReauthorizationRequired is returned by clients using OAuth when the stored tokens can no longer be refreshed
+// and the user needs to authorize the application again
+var ErrReauthorizationRequired = errors.New("re-authorization required")
 
 // HTTPError represents a non-2xx HTTP response from a weather API. It is used by
 // client implementations and the retry logic to decide whether a failure is
@@ -0,0 +1,44 @@
+package weatherapi
+
+import "time"
+
+// DateLayout is the format used for the Date of a DailyObservation
+const DateLayout = time.DateOnly
+
+// DailyObservation contains weather data for a single day. Values are nil if the data is not available
+// from the weather client
+type DailyObservation struct {
+	Date                  string   `json:"date"`
+	RainMM                *float32 `json:"rain_mm,omitempty"`
+	MaxTemperatureCelsius *float32 `json:"max_temperature_celsius,omitempty"`
+	EvapotranspirationMM  *float32 `json:"evapotranspiration_mm,omitempty"`
+
+	// Complete is true when the observation was recorded after the day ended, so it will not change
+	Complete bool `json:"complete"`
+}
+
+// Days returns the date strings for each day between start and end, inclusive
+func Days(start, end time.Time) []string {
+	result := []string{}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		result = append(result, d.Format(DateLayout))
+	}
+	return result
+}
+
+// DailyETInputs contains the daily measurements used to calculate reference evapotranspiration. Min and max
+// temperature are required. If humidity and solar radiation are also available, the more accurate FAO-56
+// Penman-Monteith equation can be used
+type DailyETInputs struct {
+	Date                  string
+	MinTemperatureCelsius *float32
+	MaxTemperatureCelsius *float32
+
+	// RelativeHumidity is the mean relative humidity in percent
+	RelativeHumidity *float32
+	// SolarRadiationMJ is the total incoming solar radiation in MJ/m²/day
+	SolarRadiationMJ *float32
+	// WindSpeedMPS is the mean wind speed at 2m in m/s
+	WindSpeedMPS *float32
+}
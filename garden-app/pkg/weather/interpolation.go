@@ -14,12 +14,14 @@ const (
 	EaseOut   InterpolationMode = "ease_out"
 	EaseInOut InterpolationMode = "ease_in_out"
 	Step      InterpolationMode = "step"
+	// Curve uses the WeatherScaler's Points instead of the min/max range
+	Curve InterpolationMode = "curve"
 )
 
 // IsValid checks if the interpolation mode is valid
 func (m InterpolationMode) IsValid() bool {
 	switch m {
-	case Linear, EaseIn, EaseOut, EaseInOut, Step:
+	case Linear, EaseIn, EaseOut, EaseInOut, Step, Curve:
 		return true
 	}
 	return false
@@ -217,16 +217,23 @@ func (c *Client) setDeviceIDs() error {
 }
 
 func (c *Client) refreshToken() error {
+	_, err := c.RefreshTokenBefore(0)
+	return err
+}
+
+// RefreshTokenBefore refreshes the access token if it expires within the window and returns the token's
+// expiration. If the refresh token is no longer valid, the error wraps weatherapi.ErrReauthorizationRequired
+func (c *Client) RefreshTokenBefore(window time.Duration) (time.Time, error) {
 	if c.Config.Authentication == nil {
-		return errors.New("authentication is required but not configured")
+		return time.Time{}, fmt.Errorf("%w: authentication is not configured", weatherapi.ErrReauthorizationRequired)
 	}
 
 	// It's safe to ignore the time.Parse error because knowing the expiration is an optional early exit
 	expiry, _ := time.Parse(time.RFC3339Nano, c.Config.Authentication.ExpirationDate)
 
-	// Exit early if token is not expired
-	if clock.Now().Before(expiry) {
-		return nil
+	// Exit early if token does not expire within the window
+	if clock.Now().Add(window).Before(expiry) {
+		return expiry, nil
 	}
 
 	// Use singleflight to deduplicate concurrent refresh requests using the same refresh token
@@ -234,8 +241,13 @@ func (c *Client) refreshToken() error {
 	_, err, _ := refreshFlight.Do(c.StationID, func() (interface{}, error) {
 		return nil, c.doRefreshToken()
 	})
+	if err != nil {
+		return time.Time{}, err
+	}
 
-	return err
+	// Error is ignored for the same reason as above
+	expiry, _ = time.Parse(time.RFC3339Nano, c.Config.Authentication.ExpirationDate)
+	return expiry, nil
 }
 
 func (c *Client) doRefreshToken() error {
@@ -264,7 +276,13 @@ func (c *Client) doRefreshToken() error {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return &weatherapi.HTTPError{StatusCode: resp.StatusCode, Body: string(respBody)}
+		httpErr := &weatherapi.HTTPError{StatusCode: resp.StatusCode, Body: string(respBody)}
+		// Netatmo responds with invalid_grant when the refresh token is revoked or expired
+		switch resp.StatusCode {
+		case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+			return fmt.Errorf("%w: %w", weatherapi.ErrReauthorizationRequired, httpErr)
+		}
+		return httpErr
 	}
 
 	err = json.Unmarshal(respBody, c.Authentication)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
 	"gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
@@ -102,6 +103,84 @@ func TestWeatherRequestMethods(t *testing.T) {
 				require.Equal(t, float32(48.066666), temp)
 			},
 		},
+		{
+			"GetAverageHumidity",
+			"testdata/fixtures/GetAverageHumidity",
+			clock.Now().Add(1 * time.Minute),
+			func(t *testing.T, client *Client) {
+				humidity, err := client.GetAverageHumidity(context.Background(), 72*time.Hour)
+				require.NoError(t, err)
+				require.InDelta(t, float32(56), humidity, 0.001)
+			},
+		},
+		{
+			"RefreshTokenBefore",
+			"testdata/fixtures/RefreshTokenBefore",
+			clock.Now().Add(1 * time.Minute),
+			func(t *testing.T, client *Client) {
+				// Token expires within the window, so it is refreshed early
+				expiration, err := client.RefreshTokenBefore(time.Hour)
+				require.NoError(t, err)
+				require.Equal(t, "NEW_REFRESH_TOKEN", client.Config.Authentication.RefreshToken)
+				require.WithinDuration(t, clock.Now().Add(10800*time.Second), expiration, time.Second)
+
+				// New token doesn't expire within the window, so no request is made
+				sameExpiration, err := client.RefreshTokenBefore(time.Hour)
+				require.NoError(t, err)
+				require.Equal(t, expiration, sameExpiration)
+			},
+		},
+		{
+			"RefreshTokenBefore_Revoked",
+			"testdata/fixtures/RefreshTokenBefore_Revoked",
+			clock.Now().Add(1 * time.Minute),
+			func(t *testing.T, client *Client) {
+				_, err := client.RefreshTokenBefore(time.Hour)
+				require.ErrorIs(t, err, weatherapi.ErrReauthorizationRequired)
+				require.Equal(t, "REFRESH_TOKEN", client.Config.Authentication.RefreshToken)
+			},
+		},
+		{
+			"GetDailyObservations",
+			"testdata/fixtures/GetDailyObservations",
+			clock.Now().Add(1 * time.Minute),
+			func(t *testing.T, client *Client) {
+				start := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+				end := time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC)
+
+				observations, err := client.GetDailyObservations(context.Background(), start, end)
+				require.NoError(t, err)
+				require.Len(t, observations, 3)
+
+				require.Equal(t, "2024-07-08", observations[0].Date)
+				require.Equal(t, float32(2.5), *observations[0].RainMM)
+				require.Equal(t, float32(31), *observations[0].MaxTemperatureCelsius)
+				require.Equal(t, "2024-07-10", observations[2].Date)
+				require.Equal(t, float32(1.2), *observations[2].RainMM)
+				require.Nil(t, observations[2].EvapotranspirationMM)
+			},
+		},
+		{
+			"GetDailyETInputs",
+			"testdata/fixtures/GetDailyETInputs",
+			clock.Now().Add(1 * time.Minute),
+			func(t *testing.T, client *Client) {
+				start := time.Date(2024, 7, 8, 0, 0, 0, 0, time.UTC)
+				end := time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC)
+
+				inputs, err := client.GetDailyETInputs(context.Background(), start, end)
+				require.NoError(t, err)
+				require.Len(t, inputs, 3)
+
+				require.Equal(t, "2024-07-08", inputs[0].Date)
+				require.Equal(t, float32(18), *inputs[0].MinTemperatureCelsius)
+				require.Equal(t, float32(31), *inputs[0].MaxTemperatureCelsius)
+				require.Equal(t, "2024-07-10", inputs[2].Date)
+				require.Equal(t, float32(17), *inputs[2].MinTemperatureCelsius)
+				require.Equal(t, float32(30), *inputs[2].MaxTemperatureCelsius)
+				require.Nil(t, inputs[2].RelativeHumidity)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,99 @@
+package netatmo
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+)
+
+// GetDailyObservations returns the daily rain total and high temperature for each day between start and end
+func (c *Client) GetDailyObservations(ctx context.Context, start, end time.Time) ([]weatherapi.DailyObservation, error) {
+	beginDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, end.Location())
+
+	rainData, err := c.getMeasure(ctx, "sum_rain", "1day", beginDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	temperatureData, err := c.getMeasure(ctx, "max_temp", "1day", beginDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	observations := map[string]*weatherapi.DailyObservation{}
+	getObservation := func(t time.Time) *weatherapi.DailyObservation {
+		date := t.In(start.Location()).Format(weatherapi.DateLayout)
+		o, ok := observations[date]
+		if !ok {
+			o = &weatherapi.DailyObservation{Date: date}
+			observations[date] = o
+		}
+		return o
+	}
+
+	for t, rain := range *rainData {
+		getObservation(t).RainMM = &rain
+	}
+	for t, temperature := range *temperatureData {
+		getObservation(t).MaxTemperatureCelsius = &temperature
+	}
+
+	result := []weatherapi.DailyObservation{}
+	for _, o := range observations {
+		result = append(result, *o)
+	}
+	slices.SortFunc(result, func(a, b weatherapi.DailyObservation) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return result, nil
+}
+
+// GetDailyETInputs returns the daily low and high temperature for each day between start and end. This allows
+// reference evapotranspiration to be calculated
+func (c *Client) GetDailyETInputs(ctx context.Context, start, end time.Time) ([]weatherapi.DailyETInputs, error) {
+	beginDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	endDate := time.Date(end.Year(), end.Month(), end.Day(), 23, 59, 59, 0, end.Location())
+
+	minTemperatureData, err := c.getMeasure(ctx, "min_temp", "1day", beginDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	maxTemperatureData, err := c.getMeasure(ctx, "max_temp", "1day", beginDate, &endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := map[string]*weatherapi.DailyETInputs{}
+	getInputs := func(t time.Time) *weatherapi.DailyETInputs {
+		date := t.In(start.Location()).Format(weatherapi.DateLayout)
+		in, ok := inputs[date]
+		if !ok {
+			in = &weatherapi.DailyETInputs{Date: date}
+			inputs[date] = in
+		}
+		return in
+	}
+
+	for t, temperature := range *minTemperatureData {
+		getInputs(t).MinTemperatureCelsius = &temperature
+	}
+	for t, temperature := range *maxTemperatureData {
+		getInputs(t).MaxTemperatureCelsius = &temperature
+	}
+
+	result := []weatherapi.DailyETInputs{}
+	for _, in := range inputs {
+		result = append(result, *in)
+	}
+	slices.SortFunc(result, func(a, b weatherapi.DailyETInputs) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package netatmo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+)
+
+const minHumidityInterval = 24 * time.Hour
+
+// GetAverageHumidity returns the average daily relative humidity from the outdoor module between the given time and
+// the end of yesterday
+func (c *Client) GetAverageHumidity(ctx context.Context, since time.Duration) (float32, error) {
+	// Time to check since must always include at least one complete day
+	if since < minHumidityInterval {
+		since = minHumidityInterval
+	}
+
+	now := clock.Now()
+	beginDate := now.Add(-since).Truncate(time.Hour)
+	beginDate = time.Date(beginDate.Year(), beginDate.Month(), beginDate.Day()-1, 23, 59, 59, 0, time.Local)
+	endDate := time.Date(now.Year(), now.Month(), now.Day()-1, 23, 59, 59, 0, time.Local)
+
+	humidityData, err := c.getMeasure(ctx, "humidity", "1day", beginDate, &endDate)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(*humidityData) == 0 {
+		return 0, errors.New("no humidity data returned")
+	}
+
+	return humidityData.Average(), nil
+}
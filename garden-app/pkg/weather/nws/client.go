@@ -0,0 +1,244 @@
+// Package nws provides a client for the National Weather Service API (api.weather.gov)
+package nws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	defaultBaseURL   = "https://api.weather.gov"
+	defaultUserAgent = "automated-garden (https://github.com/calvinmclean/automated-garden)"
+)
+
+// DefaultClient is the HTTP client used by NewClient and FindStations. It can be replaced for testing
+var DefaultClient = http.DefaultClient
+
+// Config is specific to the NWS API. Latitude and Longitude are required. If StationID is not provided, the
+// nearest observation station to the location is used. The NWS API requires a User-Agent to identify the
+// application, so a default is used if one is not provided
+type Config struct {
+	Latitude  float32 `json:"latitude" yaml:"latitude" mapstructure:"latitude"`
+	Longitude float32 `json:"longitude" yaml:"longitude" mapstructure:"longitude"`
+
+	StationID   string `json:"station_id,omitempty" yaml:"station_id,omitempty" mapstructure:"station_id,omitempty"`
+	StationName string `json:"station_name,omitempty" yaml:"station_name,omitempty" mapstructure:"station_name,omitempty"`
+
+	// GridDataURL is the gridpoint URL used for forecasts. It is looked up from the location if not provided
+	GridDataURL string `json:"grid_data_url,omitempty" yaml:"grid_data_url,omitempty" mapstructure:"grid_data_url,omitempty"`
+
+	UserAgent string `json:"user_agent,omitempty" yaml:"user_agent,omitempty" mapstructure:"user_agent,omitempty"`
+}
+
+// Client is used to interact with the NWS API
+type Client struct {
+	*Config
+	httpClient      *http.Client
+	baseURL         string
+	storageCallback func(map[string]any) error
+}
+
+// Station is an NWS observation station
+type Station struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewClient creates a new NWS API client from configuration. The storageCallback is used to save the
+// discovered station and gridpoint so they are only looked up once
+func NewClient(options map[string]any, storageCallback func(map[string]any) error) (*Client, error) {
+	return NewClientWithHTTPClient(options, storageCallback, DefaultClient)
+}
+
+// NewClientWithHTTPClient creates a new NWS API client with a custom HTTP client (used for testing)
+func NewClientWithHTTPClient(options map[string]any, storageCallback func(map[string]any) error, httpClient *http.Client) (*Client, error) {
+	client := &Client{
+		Config:          &Config{},
+		httpClient:      httpClient,
+		baseURL:         defaultBaseURL,
+		storageCallback: storageCallback,
+	}
+
+	err := mapstructure.WeakDecode(options, &client.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Latitude == 0 || client.Longitude == 0 {
+		return nil, errors.New("latitude and longitude must be provided")
+	}
+
+	if client.UserAgent == "" {
+		client.UserAgent = defaultUserAgent
+	}
+
+	return client, nil
+}
+
+// FindStations returns the observation stations for the location, ordered by distance
+func FindStations(ctx context.Context, latitude, longitude float32) ([]Station, error) {
+	client, err := NewClient(map[string]any{
+		"latitude":  latitude,
+		"longitude": longitude,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return client.FindStations(ctx)
+}
+
+type pointsResponse struct {
+	Properties struct {
+		ForecastGridData    string `json:"forecastGridData"`
+		ObservationStations string `json:"observationStations"`
+	} `json:"properties"`
+}
+
+type stationsResponse struct {
+	Features []struct {
+		Properties struct {
+			StationIdentifier string `json:"stationIdentifier"`
+			Name              string `json:"name"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+func (c *Client) getPoint(ctx context.Context) (pointsResponse, error) {
+	var point pointsResponse
+	err := c.get(ctx, fmt.Sprintf("%s/points/%.4f,%.4f", c.baseURL, c.Latitude, c.Longitude), nil, &point)
+	if err != nil {
+		return pointsResponse{}, fmt.Errorf("error getting point: %w", err)
+	}
+	return point, nil
+}
+
+// FindStations returns the observation stations for the client's location, ordered by distance
+func (c *Client) FindStations(ctx context.Context) ([]Station, error) {
+	point, err := c.getPoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.GridDataURL == "" {
+		c.GridDataURL = point.Properties.ForecastGridData
+	}
+
+	var stations stationsResponse
+	err = c.get(ctx, point.Properties.ObservationStations, nil, &stations)
+	if err != nil {
+		return nil, fmt.Errorf("error getting stations: %w", err)
+	}
+
+	result := []Station{}
+	for _, s := range stations.Features {
+		result = append(result, Station{
+			ID:   s.Properties.StationIdentifier,
+			Name: s.Properties.Name,
+		})
+	}
+
+	return result, nil
+}
+
+// setStation uses the nearest station and gridpoint for the location if they are not configured and saves
+// them using the storage callback
+func (c *Client) setStation(ctx context.Context) error {
+	if c.StationID != "" && c.GridDataURL != "" {
+		return nil
+	}
+
+	if c.StationID == "" {
+		stations, err := c.FindStations(ctx)
+		if err != nil {
+			return err
+		}
+		if len(stations) == 0 {
+			return errors.New("no observation stations found for location")
+		}
+		c.StationID = stations[0].ID
+		c.StationName = stations[0].Name
+	} else {
+		point, err := c.getPoint(ctx)
+		if err != nil {
+			return err
+		}
+		c.GridDataURL = point.Properties.ForecastGridData
+	}
+
+	if c.storageCallback == nil {
+		return nil
+	}
+
+	options := map[string]any{
+		"latitude":      c.Latitude,
+		"longitude":     c.Longitude,
+		"station_id":    c.StationID,
+		"station_name":  c.StationName,
+		"grid_data_url": c.GridDataURL,
+	}
+	if c.UserAgent != defaultUserAgent {
+		options["user_agent"] = c.UserAgent
+	}
+
+	err := c.storageCallback(options)
+	if err != nil {
+		return fmt.Errorf("error executing storage callback to store station: %w", err)
+	}
+
+	return nil
+}
+
+// get makes a GET request to the NWS API and parses the response into the result
+func (c *Client) get(ctx context.Context, rawURL string, query url.Values, result any) error {
+	if !strings.HasPrefix(rawURL, c.baseURL) {
+		return fmt.Errorf("unexpected URL: %s", rawURL)
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if query != nil {
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/geo+json")
+	req.Header.Add("User-Agent", c.UserAgent)
+
+	// nolint:gosec // URL is constructed from hardcoded base URL or URLs returned by the same API
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error making API request: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response body with status %d: %w", resp.StatusCode, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return &weatherapi.HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return fmt.Errorf("error parsing response: %w", err)
+	}
+
+	return nil
+}
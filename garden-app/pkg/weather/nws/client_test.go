@@ -0,0 +1,226 @@
+package nws
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
+)
+
+// matcher replaces the start and end query params with placeholders since they depend on the current time
+var matcher = func(r1 *http.Request, r2 cassette.Request) bool {
+	query := r1.URL.Query()
+	if query.Get("start") != "" {
+		query.Set("start", "START")
+	}
+	if query.Get("end") != "" {
+		query.Set("end", "END")
+	}
+	r1.URL.RawQuery = query.Encode()
+
+	return cassette.NewDefaultMatcher(cassette.WithIgnoreUserAgent(true))(r1, r2)
+}
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      map[string]any
+		errMsg    string
+		userAgent string
+	}{
+		{
+			"DefaultUserAgent",
+			map[string]any{"latitude": 37.7749, "longitude": -122.4194},
+			"",
+			defaultUserAgent,
+		},
+		{
+			"CustomUserAgent",
+			map[string]any{"latitude": 37.7749, "longitude": -122.4194, "user_agent": "my-garden (me@example.com)"},
+			"",
+			"my-garden (me@example.com)",
+		},
+		{
+			"MissingLatitude",
+			map[string]any{"longitude": -122.4194},
+			"latitude and longitude must be provided",
+			"",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts, nil)
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.userAgent, client.UserAgent)
+		})
+	}
+}
+
+func TestClientRequests(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
+	tests := []struct {
+		name            string
+		opts            map[string]any
+		exec            func(t *testing.T, client *Client)
+		expectedStorage map[string]any
+	}{
+		{
+			"FindStations",
+			map[string]any{},
+			func(t *testing.T, client *Client) {
+				stations, err := client.FindStations(context.Background())
+				require.NoError(t, err)
+				assert.Equal(t, []Station{
+					{"KSFO", "San Francisco, San Francisco International Airport"},
+					{"KOAK", "Oakland International Airport"},
+				}, stations)
+				assert.Equal(t, "https://api.weather.gov/gridpoints/MTR/85,105", client.GridDataURL)
+			},
+			nil,
+		},
+		{
+			"GetTotalRain_DiscoverStation",
+			map[string]any{},
+			func(t *testing.T, client *Client) {
+				rain, err := client.GetTotalRain(context.Background(), 72*time.Hour)
+				require.NoError(t, err)
+				// The 0.8mm special observation is ignored since it is in the same hour as another observation
+				assert.InDelta(t, 4.0, rain, 0.001)
+				assert.Equal(t, "KSFO", client.StationID)
+			},
+			map[string]any{
+				"latitude":      float32(37.7749),
+				"longitude":     float32(-122.4194),
+				"station_id":    "KSFO",
+				"station_name":  "San Francisco, San Francisco International Airport",
+				"grid_data_url": "https://api.weather.gov/gridpoints/MTR/85,105",
+			},
+		},
+		{
+			"GetAverageHighTemperature",
+			map[string]any{"station_id": "KSFO", "station_name": "San Francisco"},
+			func(t *testing.T, client *Client) {
+				temp, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+				require.NoError(t, err)
+				assert.InDelta(t, 26.667, temp, 0.001)
+			},
+			map[string]any{
+				"latitude":      float32(37.7749),
+				"longitude":     float32(-122.4194),
+				"station_id":    "KSFO",
+				"station_name":  "San Francisco",
+				"grid_data_url": "https://api.weather.gov/gridpoints/MTR/85,105",
+			},
+		},
+		{
+			"GetDailyObservations",
+			map[string]any{"station_id": "KSFO", "grid_data_url": "https://api.weather.gov/gridpoints/MTR/85,105"},
+			func(t *testing.T, client *Client) {
+				start := time.Date(2023, time.August, 20, 0, 0, 0, 0, time.UTC)
+				end := time.Date(2023, time.August, 22, 0, 0, 0, 0, time.UTC)
+
+				observations, err := client.GetDailyObservations(context.Background(), start, end)
+				require.NoError(t, err)
+				require.Len(t, observations, 3)
+
+				assert.Equal(t, "2023-08-20", observations[0].Date)
+				assert.InDelta(t, 2.0, *observations[0].RainMM, 0.001)
+				assert.InDelta(t, 27, *observations[0].MaxTemperatureCelsius, 0.001)
+				assert.Equal(t, "2023-08-21", observations[1].Date)
+				assert.InDelta(t, 0, *observations[1].RainMM, 0.001)
+				assert.InDelta(t, 30, *observations[1].MaxTemperatureCelsius, 0.001)
+				assert.Equal(t, "2023-08-22", observations[2].Date)
+				assert.InDelta(t, 2.0, *observations[2].RainMM, 0.001)
+				assert.InDelta(t, 23, *observations[2].MaxTemperatureCelsius, 0.001)
+			},
+			nil,
+		},
+		{
+			"GetForecast",
+			map[string]any{"station_id": "KSFO", "grid_data_url": "https://api.weather.gov/gridpoints/MTR/85,105"},
+			func(t *testing.T, client *Client) {
+				// Only 2 of the 6 hours in the first interval are in the future
+				rain, err := client.GetForecastTotalRain(context.Background(), 24*time.Hour)
+				require.NoError(t, err)
+				assert.InDelta(t, 5.0, rain, 0.001)
+
+				temp, err := client.GetForecastAverageHighTemperature(context.Background(), 48*time.Hour)
+				require.NoError(t, err)
+				assert.InDelta(t, 32.0, temp, 0.001)
+			},
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := recorder.New("testdata/fixtures/"+tt.name, recorder.WithMatcher(matcher))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, r.Stop())
+			}()
+
+			if r.Mode() != recorder.ModeRecordOnce {
+				t.Fatal("Recorder should be in ModeRecordOnce")
+			}
+
+			opts := map[string]any{
+				"latitude":  37.7749,
+				"longitude": -122.4194,
+			}
+			for k, v := range tt.opts {
+				opts[k] = v
+			}
+
+			var stored map[string]any
+			client, err := NewClientWithHTTPClient(opts, func(newOpts map[string]any) error {
+				stored = newOpts
+				return nil
+			}, r.GetDefaultClient())
+			require.NoError(t, err)
+
+			tt.exec(t, client)
+			assert.Equal(t, tt.expectedStorage, stored)
+		})
+	}
+}
+
+func TestParseValidTime(t *testing.T) {
+	tests := []struct {
+		input            string
+		expectedDuration time.Duration
+		expectedErr      string
+	}{
+		{"2023-08-23T14:00:00+00:00/PT13H", 13 * time.Hour, ""},
+		{"2023-08-23T14:00:00+00:00/P1DT6H", 30 * time.Hour, ""},
+		{"2023-08-23T14:00:00+00:00/PT30M", 30 * time.Minute, ""},
+		{"2023-08-23T14:00:00+00:00", 0, `invalid validTime "2023-08-23T14:00:00+00:00"`},
+		{"2023-08-23T14:00:00+00:00/1H", 0, `invalid validTime duration "1H"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			start, duration, err := parseValidTime(tt.input)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, time.Date(2023, time.August, 23, 14, 0, 0, 0, time.UTC), start.UTC())
+			assert.Equal(t, tt.expectedDuration, duration)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package nws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+)
+
+// validTimeDurationRegex matches the ISO 8601 durations used by gridpoint validTime, like P1DT6H or PT13H
+var validTimeDurationRegex = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?)?$`)
+
+type gridpointValue struct {
+	ValidTime string   `json:"validTime"`
+	Value     *float32 `json:"value"`
+}
+
+type gridpointLayer struct {
+	UOM    string           `json:"uom"`
+	Values []gridpointValue `json:"values"`
+}
+
+type gridpointResponse struct {
+	Properties struct {
+		MaxTemperature            gridpointLayer `json:"maxTemperature"`
+		QuantitativePrecipitation gridpointLayer `json:"quantitativePrecipitation"`
+	} `json:"properties"`
+}
+
+func (c *Client) getGridpoint(ctx context.Context) (*gridpointResponse, error) {
+	err := c.setStation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp gridpointResponse
+	err = c.get(ctx, c.GridDataURL, nil, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("error getting gridpoint forecast: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// GetForecastTotalRain returns the total forecasted precipitation in millimeters for the next period. Forecast
+// intervals that are partially within the period are prorated
+func (c *Client) GetForecastTotalRain(ctx context.Context, next time.Duration) (float32, error) {
+	gridpoint, err := c.getGridpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := clock.Now()
+	end := now.Add(next)
+
+	var total float32
+	for _, v := range gridpoint.Properties.QuantitativePrecipitation.Values {
+		if v.Value == nil {
+			continue
+		}
+
+		start, duration, err := parseValidTime(v.ValidTime)
+		if err != nil {
+			return 0, err
+		}
+
+		intervalStart, intervalEnd := start, start.Add(duration)
+		if intervalStart.Before(now) {
+			intervalStart = now
+		}
+		if intervalEnd.After(end) {
+			intervalEnd = end
+		}
+
+		overlap := intervalEnd.Sub(intervalStart)
+		if overlap <= 0 || duration <= 0 {
+			continue
+		}
+
+		total += *v.Value * float32(overlap) / float32(duration)
+	}
+
+	return total, nil
+}
+
+// GetForecastAverageHighTemperature returns the average forecasted daily high temperature for the next period
+func (c *Client) GetForecastAverageHighTemperature(ctx context.Context, next time.Duration) (float32, error) {
+	gridpoint, err := c.getGridpoint(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := clock.Now()
+	end := now.Add(next)
+	isFahrenheit := gridpoint.Properties.MaxTemperature.UOM == "wmoUnit:degF"
+
+	var sum float32
+	var count int
+	for _, v := range gridpoint.Properties.MaxTemperature.Values {
+		if v.Value == nil {
+			continue
+		}
+
+		start, duration, err := parseValidTime(v.ValidTime)
+		if err != nil {
+			return 0, err
+		}
+
+		if !start.Add(duration).After(now) || !start.Before(end) {
+			continue
+		}
+
+		value := *v.Value
+		if isFahrenheit {
+			value = (value - 32) * 5 / 9
+		}
+		sum += value
+		count++
+	}
+
+	if count == 0 {
+		return 0, errors.New("no forecast temperature data for the specified period")
+	}
+
+	return sum / float32(count), nil
+}
+
+// parseValidTime parses a gridpoint validTime, which is an ISO 8601 interval like 2024-01-01T14:00:00+00:00/PT13H
+func parseValidTime(validTime string) (time.Time, time.Duration, error) {
+	startStr, durationStr, found := strings.Cut(validTime, "/")
+	if !found {
+		return time.Time{}, 0, fmt.Errorf("invalid validTime %q", validTime)
+	}
+
+	start, err := time.Parse(time.RFC3339, startStr)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("invalid validTime %q: %w", validTime, err)
+	}
+
+	matches := validTimeDurationRegex.FindStringSubmatch(durationStr)
+	if matches == nil {
+		return time.Time{}, 0, fmt.Errorf("invalid validTime duration %q", durationStr)
+	}
+
+	var duration time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute} {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(matches[i+1])
+		if err != nil {
+			return time.Time{}, 0, err
+		}
+		duration += time.Duration(n) * unit
+	}
+
+	return start, duration, nil
+}
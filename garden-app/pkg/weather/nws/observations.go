@@ -0,0 +1,225 @@
+package nws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+)
+
+const (
+	minRainInterval        = 24 * time.Hour
+	minTemperatureInterval = 72 * time.Hour
+
+	// observationsLimit is the maximum number of observations allowed by the API in each page
+	observationsLimit = 500
+	// maxObservationPages prevents following pagination forever. Stations report a few times per hour, so this is
+	// enough for much longer periods than are requested
+	maxObservationPages = 50
+)
+
+type measurement struct {
+	UnitCode string   `json:"unitCode"`
+	Value    *float32 `json:"value"`
+}
+
+type observation struct {
+	Timestamp             time.Time   `json:"timestamp"`
+	Temperature           measurement `json:"temperature"`
+	PrecipitationLastHour measurement `json:"precipitationLastHour"`
+}
+
+type observationsResponse struct {
+	Features []struct {
+		Properties observation `json:"properties"`
+	} `json:"features"`
+	Pagination struct {
+		Next string `json:"next"`
+	} `json:"pagination"`
+}
+
+// getObservations returns the station's observations between start and end, following pagination when there are
+// more than fit in one page. Stations may report more than once per hour, so only the latest observation in each
+// hour is used to prevent counting precipitation twice
+func (c *Client) getObservations(ctx context.Context, start, end time.Time) ([]observation, error) {
+	err := c.setStation(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("start", start.UTC().Format(time.RFC3339))
+	query.Set("end", end.UTC().Format(time.RFC3339))
+	query.Set("limit", fmt.Sprintf("%d", observationsLimit))
+
+	latestByHour := map[time.Time]observation{}
+	pageURL := fmt.Sprintf("%s/stations/%s/observations", c.baseURL, url.PathEscape(c.StationID))
+	for page := 0; pageURL != ""; page++ {
+		if page == maxObservationPages {
+			return nil, fmt.Errorf("error getting observations: more than %d pages", maxObservationPages)
+		}
+
+		var resp observationsResponse
+		err = c.get(ctx, pageURL, query, &resp)
+		if err != nil {
+			return nil, fmt.Errorf("error getting observations: %w", err)
+		}
+
+		for _, f := range resp.Features {
+			hour := f.Properties.Timestamp.Truncate(time.Hour)
+			if existing, ok := latestByHour[hour]; !ok || f.Properties.Timestamp.After(existing.Timestamp) {
+				latestByHour[hour] = f.Properties
+			}
+		}
+
+		// the next page URL already has the query, and the last page is empty
+		query = nil
+		pageURL = resp.Pagination.Next
+		if len(resp.Features) == 0 {
+			pageURL = ""
+		}
+	}
+
+	result := []observation{}
+	for _, o := range latestByHour {
+		result = append(result, o)
+	}
+	slices.SortFunc(result, func(a, b observation) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	return result, nil
+}
+
+// GetTotalRain returns the sum of all precipitation in millimeters reported by the station in the given period
+func (c *Client) GetTotalRain(ctx context.Context, since time.Duration) (float32, error) {
+	// Time to check from must always be at least 24 hours to get valid data
+	if since < minRainInterval {
+		since = minRainInterval
+	}
+
+	now := clock.Now()
+	observations, err := c.getObservations(ctx, now.Add(-since), now)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(observations) == 0 {
+		return 0, errors.New("no observations returned")
+	}
+
+	var total float32
+	for _, o := range observations {
+		if o.PrecipitationLastHour.Value != nil {
+			total += *o.PrecipitationLastHour.Value
+		}
+	}
+
+	return total, nil
+}
+
+// GetAverageHighTemperature returns the average daily high temperature between the given time and the end of
+// yesterday (since daily high can be misleading if queried mid-day)
+func (c *Client) GetAverageHighTemperature(ctx context.Context, since time.Duration) (float32, error) {
+	// Time to check since must always be at least 3 days
+	if since < minTemperatureInterval {
+		since = minTemperatureInterval
+	}
+
+	now := clock.Now().In(time.Local)
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := startOfToday.Add(-since)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+
+	observations, err := c.getObservations(ctx, start, startOfToday)
+	if err != nil {
+		return 0, err
+	}
+
+	var sum float32
+	var count int
+	for _, o := range dailyObservations(observations) {
+		if o.MaxTemperatureCelsius != nil {
+			sum += *o.MaxTemperatureCelsius
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, errors.New("no valid temperature data for the specified period")
+	}
+
+	return sum / float32(count), nil
+}
+
+// GetDailyObservations returns the total rain and high temperature reported by the station for each day
+// between start and end
+func (c *Client) GetDailyObservations(ctx context.Context, start, end time.Time) ([]weatherapi.DailyObservation, error) {
+	beginDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	endDate := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, end.Location())
+	if now := clock.Now(); endDate.After(now) {
+		endDate = now
+	}
+
+	observations, err := c.getObservations(ctx, beginDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	return dailyObservations(observations), nil
+}
+
+// dailyObservations groups observations by local date
+func dailyObservations(observations []observation) []weatherapi.DailyObservation {
+	byDate := map[string]*weatherapi.DailyObservation{}
+	for _, o := range observations {
+		date := o.Timestamp.In(time.Local).Format(weatherapi.DateLayout)
+		daily, ok := byDate[date]
+		if !ok {
+			daily = &weatherapi.DailyObservation{Date: date}
+			byDate[date] = daily
+		}
+
+		if rain := o.PrecipitationLastHour.Value; rain != nil {
+			if daily.RainMM == nil {
+				daily.RainMM = new(float32)
+			}
+			*daily.RainMM += *rain
+		}
+
+		if temperature := o.Temperature.Celsius(); temperature != nil {
+			if daily.MaxTemperatureCelsius == nil || *temperature > *daily.MaxTemperatureCelsius {
+				daily.MaxTemperatureCelsius = temperature
+			}
+		}
+	}
+
+	result := []weatherapi.DailyObservation{}
+	for _, o := range byDate {
+		result = append(result, *o)
+	}
+	slices.SortFunc(result, func(a, b weatherapi.DailyObservation) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return result
+}
+
+// Celsius returns the temperature value in Celsius, converting it if the measurement uses Fahrenheit
+func (m measurement) Celsius() *float32 {
+	if m.Value == nil {
+		return nil
+	}
+
+	value := *m.Value
+	if m.UnitCode == "wmoUnit:degF" {
+		value = (value - 32) * 5 / 9
+	}
+	return &value
+}
@@ -33,6 +33,8 @@ const (
 	minRainInterval               = 24 * time.Hour
 	minTemperatureInterval        = 72 * time.Hour
 	minEvapotranspirationInterval = 24 * time.Hour
+	minHumidityInterval           = 24 * time.Hour
+	minSolarRadiationInterval     = 24 * time.Hour
 	defaultBaseURL                = "https://api.open-meteo.com"
 )
 
@@ -43,6 +45,19 @@ type openMeteoResponse struct {
 		Temperature2mMax         []float32 `json:"temperature_2m_max"`
 		PrecipitationSum         []float32 `json:"precipitation_sum"`
 		ET0FaoEvapotranspiration []float32 `json:"et0_fao_evapotranspiration"`
+		RelativeHumidity2mMean   []float32 `json:"relative_humidity_2m_mean"`
+		ShortwaveRadiationSum    []float32 `json:"shortwave_radiation_sum"`
+	} `json:"daily"`
+}
+
+// dailyObservationsResponse is used for daily history. Values are pointers because OpenMeteo returns null
+// for days without data
+type dailyObservationsResponse struct {
+	Daily struct {
+		Time                     []string   `json:"time"`
+		Temperature2mMax         []*float32 `json:"temperature_2m_max"`
+		PrecipitationSum         []*float32 `json:"precipitation_sum"`
+		ET0FaoEvapotranspiration []*float32 `json:"et0_fao_evapotranspiration"`
 	} `json:"daily"`
 }
 
@@ -73,15 +88,28 @@ func NewClientWithHTTPClient(options map[string]any, httpClient *http.Client) (*
 
 // fetchData makes the API request to OpenMeteo and returns the parsed response
 func (c *Client) fetchData(ctx context.Context, pastDays int, dailyVars ...string) (*openMeteoResponse, error) {
-	u, err := url.Parse(c.baseURL + "/v1/forecast")
+	q := url.Values{}
+	q.Set("past_days", fmt.Sprintf("%d", pastDays))
+
+	var data openMeteoResponse
+	err := c.doRequest(ctx, q, dailyVars, &data)
 	if err != nil {
 		return nil, err
 	}
 
-	q := u.Query()
+	return &data, nil
+}
+
+// doRequest adds the location and daily variables to the query, makes the API request, and parses the response
+// into the result
+func (c *Client) doRequest(ctx context.Context, q url.Values, dailyVars []string, result any) error {
+	u, err := url.Parse(c.baseURL + "/v1/forecast")
+	if err != nil {
+		return err
+	}
+
 	q.Set("latitude", fmt.Sprintf("%f", c.Latitude))
 	q.Set("longitude", fmt.Sprintf("%f", c.Longitude))
-	q.Set("past_days", fmt.Sprintf("%d", pastDays))
 	q.Set("timezone", "auto")
 
 	// Add daily variables
@@ -93,33 +121,32 @@ func (c *Client) fetchData(ctx context.Context, pastDays int, dailyVars ...strin
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// nolint:gosec // URL is constructed from hardcoded base URL with query params, not user input
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error making API request: %w", err)
+		return fmt.Errorf("error making API request: %w", err)
 	}
 	defer func() { _ = resp.Body.Close() }()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, &weatherapi.HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
+		return &weatherapi.HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return fmt.Errorf("error reading response body: %w", err)
 	}
 
-	var data openMeteoResponse
-	err = json.Unmarshal(body, &data)
+	err = json.Unmarshal(body, result)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing response: %w", err)
+		return fmt.Errorf("error parsing response: %w", err)
 	}
 
-	return &data, nil
+	return nil
 }
 
 // GetTotalRain returns the sum of all precipitation in millimeters in the given period
@@ -170,29 +197,12 @@ func (c *Client) GetAverageHighTemperature(ctx context.Context, since time.Durat
 		return 0, errors.New("no temperature data returned")
 	}
 
-	// Calculate average of daily max temperatures
-	now := clock.Now()
-	endOfYesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 23, 59, 59, 0, time.Local)
-
-	var sum float32
-	var count int
-	for i, t := range data.Daily.Time {
-		date, err := time.Parse("2006-01-02", t)
-		if err != nil {
-			continue
-		}
-		// Only include days up to end of yesterday
-		if date.Before(endOfYesterday) || date.Equal(endOfYesterday) {
-			sum += data.Daily.Temperature2mMax[i]
-			count++
-		}
-	}
-
-	if count == 0 {
+	avgTemp, ok := averageThroughYesterday(data.Daily.Time, data.Daily.Temperature2mMax)
+	if !ok {
 		return 0, errors.New("no valid temperature data for the specified period")
 	}
 
-	return sum / float32(count), nil
+	return avgTemp, nil
 }
 
 // GetAverageEvapotranspiration returns the average daily reference evapotranspiration (ET₀) over the given period
@@ -223,3 +233,114 @@ func (c *Client) GetAverageEvapotranspiration(ctx context.Context, since time.Du
 
 	return sum / float32(len(data.Daily.ET0FaoEvapotranspiration)), nil
 }
+
+// GetAverageHumidity returns the average of daily mean relative humidity between the given time and the end of
+// yesterday using OpenMeteo's relative_humidity_2m_mean parameter
+func (c *Client) GetAverageHumidity(ctx context.Context, since time.Duration) (float32, error) {
+	// Time to check from must always be at least 24 hours to include a complete day
+	if since < minHumidityInterval {
+		since = minHumidityInterval
+	}
+
+	// Calculate past days needed (round up)
+	pastDays := int(since.Hours()/24) + 1
+
+	data, err := c.fetchData(ctx, pastDays, "relative_humidity_2m_mean")
+	if err != nil {
+		return 0, fmt.Errorf("error fetching humidity data: %w", err)
+	}
+
+	avgHumidity, ok := averageThroughYesterday(data.Daily.Time, data.Daily.RelativeHumidity2mMean)
+	if !ok {
+		return 0, errors.New("no valid humidity data for the specified period")
+	}
+
+	return avgHumidity, nil
+}
+
+// GetAverageSolarRadiation returns the average daily solar radiation in MJ/m² between the given time and the end
+// of yesterday using OpenMeteo's shortwave_radiation_sum parameter
+func (c *Client) GetAverageSolarRadiation(ctx context.Context, since time.Duration) (float32, error) {
+	// Time to check from must always be at least 24 hours to include a complete day
+	if since < minSolarRadiationInterval {
+		since = minSolarRadiationInterval
+	}
+
+	// Calculate past days needed (round up)
+	pastDays := int(since.Hours()/24) + 1
+
+	data, err := c.fetchData(ctx, pastDays, "shortwave_radiation_sum")
+	if err != nil {
+		return 0, fmt.Errorf("error fetching solar radiation data: %w", err)
+	}
+
+	avgSolarRadiation, ok := averageThroughYesterday(data.Daily.Time, data.Daily.ShortwaveRadiationSum)
+	if !ok {
+		return 0, errors.New("no valid solar radiation data for the specified period")
+	}
+
+	return avgSolarRadiation, nil
+}
+
+// averageThroughYesterday returns the average of daily values up to the end of yesterday, since the current day is
+// incomplete and the response also includes forecasted days. It returns false if there are no values in that period
+func averageThroughYesterday(days []string, values []float32) (float32, bool) {
+	now := clock.Now()
+	endOfYesterday := time.Date(now.Year(), now.Month(), now.Day()-1, 23, 59, 59, 0, time.Local)
+
+	var sum float32
+	var count int
+	for i, t := range days {
+		if i >= len(values) {
+			break
+		}
+		date, err := time.Parse("2006-01-02", t)
+		if err != nil {
+			continue
+		}
+		// Only include days up to end of yesterday
+		if date.Before(endOfYesterday) || date.Equal(endOfYesterday) {
+			sum += values[i]
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+
+	return sum / float32(count), true
+}
+
+// GetDailyObservations returns the daily precipitation, high temperature, and ET₀ for each day between start
+// and end using the location's timezone
+func (c *Client) GetDailyObservations(ctx context.Context, start, end time.Time) ([]weatherapi.DailyObservation, error) {
+	q := url.Values{}
+	q.Set("start_date", start.Format(weatherapi.DateLayout))
+	q.Set("end_date", end.Format(weatherapi.DateLayout))
+
+	var data dailyObservationsResponse
+	err := c.doRequest(ctx, q, []string{"precipitation_sum", "temperature_2m_max", "et0_fao_evapotranspiration"}, &data)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching daily observations: %w", err)
+	}
+
+	result := []weatherapi.DailyObservation{}
+	for i, day := range data.Daily.Time {
+		result = append(result, weatherapi.DailyObservation{
+			Date:                  day,
+			RainMM:                valueAt(data.Daily.PrecipitationSum, i),
+			MaxTemperatureCelsius: valueAt(data.Daily.Temperature2mMax, i),
+			EvapotranspirationMM:  valueAt(data.Daily.ET0FaoEvapotranspiration, i),
+		})
+	}
+
+	return result, nil
+}
+
+func valueAt(values []*float32, i int) *float32 {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
@@ -418,6 +418,66 @@ func TestGetAverageEvapotranspiration_MinimumInterval(t *testing.T) {
 	_ = et
 }
 
+func TestGetAverageHumidityAndSolarRadiation(t *testing.T) {
+	clock.MockTime()
+	defer clock.Reset()
+
+	matcher := func(r1 *http.Request, r2 cassette.Request) bool {
+		u2, err := url.Parse(r2.URL)
+		if err != nil {
+			return false
+		}
+		return r1.URL.Query().Encode() == u2.Query().Encode()
+	}
+
+	tests := []struct {
+		name     string
+		fixture  string
+		get      func(*Client) (float32, error)
+		expected float32
+	}{
+		{
+			name:    "GetAverageHumidity",
+			fixture: "testdata/fixtures/GetAverageHumidity",
+			get: func(c *Client) (float32, error) {
+				return c.GetAverageHumidity(context.Background(), 72*time.Hour)
+			},
+			// Only includes 2023-08-19 through yesterday, 2023-08-22
+			expected: 54.75,
+		},
+		{
+			name:    "GetAverageSolarRadiation",
+			fixture: "testdata/fixtures/GetAverageSolarRadiation",
+			get: func(c *Client) (float32, error) {
+				return c.GetAverageSolarRadiation(context.Background(), 72*time.Hour)
+			},
+			expected: 24.55,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := map[string]any{
+				"latitude":  37.7749,
+				"longitude": -122.4194,
+			}
+
+			r, err := recorder.New(tt.fixture, recorder.WithMatcher(matcher))
+			require.NoError(t, err)
+			defer func() {
+				require.NoError(t, r.Stop())
+			}()
+
+			client, err := NewClientWithHTTPClient(opts, r.GetDefaultClient())
+			require.NoError(t, err)
+
+			result, err := tt.get(client)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.expected, result, 0.01)
+		})
+	}
+}
+
 func TestCalculatePastDays(t *testing.T) {
 	tests := []struct {
 		duration time.Duration
@@ -447,3 +507,52 @@ func TestEndOfYesterday(t *testing.T) {
 	assert.Equal(t, 59, endOfYesterday.Minute())
 	assert.Equal(t, 59, endOfYesterday.Second())
 }
+
+func TestGetDailyObservations(t *testing.T) {
+	matcher := func(r1 *http.Request, r2 cassette.Request) bool {
+		u2, err := url.Parse(r2.URL)
+		if err != nil {
+			return false
+		}
+
+		q1 := r1.URL.Query()
+		q2 := u2.Query()
+
+		return r1.URL.Path == u2.Path &&
+			q1.Get("start_date") == q2.Get("start_date") &&
+			q1.Get("end_date") == q2.Get("end_date")
+	}
+
+	r, err := recorder.New("testdata/fixtures/GetDailyObservations", recorder.WithMatcher(matcher))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, r.Stop())
+	}()
+
+	client, err := NewClientWithHTTPClient(map[string]any{
+		"latitude":  37.7749,
+		"longitude": -122.4194,
+	}, r.GetDefaultClient())
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	end := time.Date(2024, 1, 3, 0, 0, 0, 0, time.Local)
+
+	observations, err := client.GetDailyObservations(context.Background(), start, end)
+	require.NoError(t, err)
+	require.Len(t, observations, 3)
+
+	assert.Equal(t, "2024-01-01", observations[0].Date)
+	require.NotNil(t, observations[0].RainMM)
+	assert.InDelta(t, 5.2, *observations[0].RainMM, 0.01)
+	require.NotNil(t, observations[1].MaxTemperatureCelsius)
+	assert.InDelta(t, 20.1, *observations[1].MaxTemperatureCelsius, 0.01)
+	require.NotNil(t, observations[1].EvapotranspirationMM)
+	assert.InDelta(t, 2.4, *observations[1].EvapotranspirationMM, 0.01)
+
+	// Null values are returned for days without data
+	assert.Equal(t, "2024-01-03", observations[2].Date)
+	assert.Nil(t, observations[2].RainMM)
+	assert.Nil(t, observations[2].MaxTemperatureCelsius)
+	assert.Nil(t, observations[2].EvapotranspirationMM)
+}
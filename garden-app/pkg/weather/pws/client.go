@@ -0,0 +1,393 @@
+// Package pws provides a weather client that uses readings uploaded by a local personal weather station using
+// the Ecowitt or Weather Underground upload protocols
+package pws
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+	"github.com/mitchellh/mapstructure"
+)
+
+const (
+	minTemperatureInterval = 72 * time.Hour
+
+	// dateLayout is the format of the dateutc field sent by stations
+	dateLayout = "2006-01-02 15:04:05"
+)
+
+// ErrPasskeyRequired is returned when creating a client without a passkey
+var ErrPasskeyRequired = errors.New("passkey is required so uploads can be authenticated")
+
+// Reading is a single upload from a personal weather station. DailyRainMM is the station's rain accumulator,
+// which resets at midnight. TotalRainMM is a running total that is only sent by some stations, like Ecowitt. It is
+// used to include rain that fell between the day's last reading and midnight
+type Reading struct {
+	Timestamp          time.Time
+	TemperatureCelsius *float32
+	Humidity           *float32
+	DailyRainMM        *float32
+	SolarRadiation     *float32
+	TotalRainMM        *float32
+}
+
+// Storage provides access to readings that were uploaded by the station
+type Storage interface {
+	ListReadings(ctx context.Context, clientID string, start, end time.Time) ([]Reading, error)
+}
+
+// Config is specific to personal weather stations. Uploads must include the Passkey as the PASSKEY (Ecowitt) or
+// PASSWORD (Weather Underground) parameter
+type Config struct {
+	Passkey string `json:"passkey" yaml:"passkey" mapstructure:"passkey"`
+}
+
+// Client reads stored uploads from a personal weather station
+type Client struct {
+	*Config
+	id      string
+	storage Storage
+}
+
+// NewClient creates a new PWS client from configuration. The id is the WeatherClient ID used to store readings
+func NewClient(id string, options map[string]any, storage Storage) (*Client, error) {
+	if storage == nil {
+		return nil, errors.New("pws client requires access to reading storage")
+	}
+
+	client := &Client{
+		Config:  &Config{},
+		id:      id,
+		storage: storage,
+	}
+
+	err := mapstructure.WeakDecode(options, &client.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.Passkey == "" {
+		return nil, ErrPasskeyRequired
+	}
+
+	return client, nil
+}
+
+// Authenticate checks the upload's passkey against the configured one. Uploads are never accepted if a passkey
+// is not configured
+func (c *Config) Authenticate(values url.Values) bool {
+	if c.Passkey == "" {
+		return false
+	}
+
+	for _, key := range []string{"PASSKEY", "PASSWORD"} {
+		if subtle.ConstantTimeCompare([]byte(values.Get(key)), []byte(c.Passkey)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseReading parses the query or form values of an Ecowitt or Weather Underground upload and converts them
+// to metric units. Missing values are left nil
+func ParseReading(values url.Values) (Reading, error) {
+	reading := Reading{Timestamp: clock.Now()}
+
+	if date := values.Get("dateutc"); date != "" && date != "now" {
+		timestamp, err := time.ParseInLocation(dateLayout, date, time.UTC)
+		if err != nil {
+			return Reading{}, fmt.Errorf("invalid dateutc %q: %w", date, err)
+		}
+		reading.Timestamp = timestamp
+	}
+
+	fields := []struct {
+		keys    []string
+		target  **float32
+		convert func(float32) float32
+	}{
+		{[]string{"tempf"}, &reading.TemperatureCelsius, fahrenheitToCelsius},
+		{[]string{"humidity"}, &reading.Humidity, nil},
+		{[]string{"dailyrainin", "drain_piezo"}, &reading.DailyRainMM, inchesToMM},
+		{[]string{"solarradiation"}, &reading.SolarRadiation, nil},
+		{[]string{"totalrainin", "yearlyrainin"}, &reading.TotalRainMM, inchesToMM},
+	}
+
+	for _, f := range fields {
+		for _, key := range f.keys {
+			raw := values.Get(key)
+			if raw == "" {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(raw, 32)
+			if err != nil {
+				return Reading{}, fmt.Errorf("invalid %s %q: %w", key, raw, err)
+			}
+
+			result := float32(value)
+			if f.convert != nil {
+				result = f.convert(result)
+			}
+			*f.target = &result
+			break
+		}
+	}
+
+	if reading.TemperatureCelsius == nil && reading.Humidity == nil && reading.DailyRainMM == nil && reading.SolarRadiation == nil {
+		return Reading{}, errors.New("upload does not contain any supported measurements")
+	}
+
+	return reading, nil
+}
+
+// GetTotalRain returns the rain in millimeters measured by the station in the given period. This is calculated
+// from increases in the daily rain accumulator, which resets at midnight. When the station also sends a running
+// total, the increase in the total is used instead so rain that fell right before midnight is included
+func (c *Client) GetTotalRain(ctx context.Context, since time.Duration) (float32, error) {
+	now := clock.Now()
+	start := now.Add(-since)
+
+	// Readings from the previous day are included so the first increase in the period can be calculated
+	readings, err := c.storage.ListReadings(ctx, c.id, start.Add(-24*time.Hour), now)
+	if err != nil {
+		return 0, fmt.Errorf("error listing readings: %w", err)
+	}
+
+	var total float32
+	var previous *Reading
+	var found bool
+	for _, r := range readings {
+		if r.DailyRainMM == nil {
+			continue
+		}
+
+		if !r.Timestamp.Before(start) {
+			found = true
+			switch {
+			case previous == nil:
+			case totalRainIncreased(previous, &r):
+				total += *r.TotalRainMM - *previous.TotalRainMM
+			case *r.DailyRainMM < *previous.DailyRainMM || !sameDay(r.Timestamp, previous.Timestamp):
+				total += *r.DailyRainMM
+			default:
+				total += *r.DailyRainMM - *previous.DailyRainMM
+			}
+		}
+
+		previous = &r
+	}
+
+	if !found {
+		return 0, errors.New("no rain readings for the specified period")
+	}
+
+	return total, nil
+}
+
+// GetAverageHighTemperature returns the average daily high temperature between the given time and the end of
+// yesterday (since daily high can be misleading if queried mid-day)
+func (c *Client) GetAverageHighTemperature(ctx context.Context, since time.Duration) (float32, error) {
+	// Time to check since must always be at least 3 days
+	if since < minTemperatureInterval {
+		since = minTemperatureInterval
+	}
+
+	now := clock.Now().In(time.Local)
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := startOfToday.Add(-since)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+
+	readings, err := c.storage.ListReadings(ctx, c.id, start, startOfToday.Add(-time.Nanosecond))
+	if err != nil {
+		return 0, fmt.Errorf("error listing readings: %w", err)
+	}
+
+	var sum float32
+	var count int
+	for _, o := range dailyObservations(readings) {
+		if o.MaxTemperatureCelsius != nil {
+			sum += *o.MaxTemperatureCelsius
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, errors.New("no valid temperature data for the specified period")
+	}
+
+	return sum / float32(count), nil
+}
+
+// GetDailyObservations returns the total rain and high temperature measured by the station for each day
+// between start and end
+func (c *Client) GetDailyObservations(ctx context.Context, start, end time.Time) ([]weatherapi.DailyObservation, error) {
+	beginDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	endDate := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+
+	readings, err := c.storage.ListReadings(ctx, c.id, beginDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error listing readings: %w", err)
+	}
+
+	return dailyObservations(readings), nil
+}
+
+// GetDailyETInputs returns the temperature range, mean humidity, and solar radiation measured by the station for
+// each day between start and end. This allows reference evapotranspiration to be calculated
+func (c *Client) GetDailyETInputs(ctx context.Context, start, end time.Time) ([]weatherapi.DailyETInputs, error) {
+	beginDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	endDate := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, time.Local).Add(-time.Nanosecond)
+
+	readings, err := c.storage.ListReadings(ctx, c.id, beginDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error listing readings: %w", err)
+	}
+
+	type dailyTotals struct {
+		inputs                    weatherapi.DailyETInputs
+		humiditySum, solarSum     float32
+		humidityCount, solarCount int
+	}
+
+	byDate := map[string]*dailyTotals{}
+	for _, r := range readings {
+		date := r.Timestamp.In(time.Local).Format(weatherapi.DateLayout)
+		daily, ok := byDate[date]
+		if !ok {
+			daily = &dailyTotals{inputs: weatherapi.DailyETInputs{Date: date}}
+			byDate[date] = daily
+		}
+
+		daily.inputs.MaxTemperatureCelsius = maxValue(daily.inputs.MaxTemperatureCelsius, r.TemperatureCelsius)
+		daily.inputs.MinTemperatureCelsius = minValue(daily.inputs.MinTemperatureCelsius, r.TemperatureCelsius)
+		if r.Humidity != nil {
+			daily.humiditySum += *r.Humidity
+			daily.humidityCount++
+		}
+		if r.SolarRadiation != nil {
+			daily.solarSum += *r.SolarRadiation
+			daily.solarCount++
+		}
+	}
+
+	result := []weatherapi.DailyETInputs{}
+	for _, daily := range byDate {
+		if daily.humidityCount > 0 {
+			humidity := daily.humiditySum / float32(daily.humidityCount)
+			daily.inputs.RelativeHumidity = &humidity
+		}
+		if daily.solarCount > 0 {
+			// Mean irradiance in W/m² is converted to total daily radiation in MJ/m²/day
+			solarRadiation := daily.solarSum / float32(daily.solarCount) * 0.0864
+			daily.inputs.SolarRadiationMJ = &solarRadiation
+		}
+		result = append(result, daily.inputs)
+	}
+	slices.SortFunc(result, func(a, b weatherapi.DailyETInputs) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return result, nil
+}
+
+// dailyObservations groups readings by local date. Since the rain accumulator resets daily, the highest value
+// is the day's total. If the running total shows more rain than the accumulator after midnight, the rest fell
+// after the previous day's last reading and is added to that day
+func dailyObservations(readings []Reading) []weatherapi.DailyObservation {
+	byDate := map[string]*weatherapi.DailyObservation{}
+	lateRain := map[string]float32{}
+	var previous *Reading
+	for _, r := range readings {
+		date := r.Timestamp.In(time.Local).Format(weatherapi.DateLayout)
+		daily, ok := byDate[date]
+		if !ok {
+			daily = &weatherapi.DailyObservation{Date: date}
+			byDate[date] = daily
+		}
+
+		daily.RainMM = maxValue(daily.RainMM, r.DailyRainMM)
+		daily.MaxTemperatureCelsius = maxValue(daily.MaxTemperatureCelsius, r.TemperatureCelsius)
+
+		if r.DailyRainMM == nil {
+			continue
+		}
+		if previous != nil && !sameDay(r.Timestamp, previous.Timestamp) && totalRainIncreased(previous, &r) {
+			late := *r.TotalRainMM - *previous.TotalRainMM - *r.DailyRainMM
+			if late > 0 {
+				lateRain[previous.Timestamp.In(time.Local).Format(weatherapi.DateLayout)] += late
+			}
+		}
+		previous = &r
+	}
+
+	for date, late := range lateRain {
+		daily := byDate[date]
+		rain := late
+		if daily.RainMM != nil {
+			rain += *daily.RainMM
+		}
+		daily.RainMM = &rain
+	}
+
+	result := []weatherapi.DailyObservation{}
+	for _, o := range byDate {
+		result = append(result, *o)
+	}
+	slices.SortFunc(result, func(a, b weatherapi.DailyObservation) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return result
+}
+
+func maxValue(current, value *float32) *float32 {
+	if value == nil {
+		return current
+	}
+	if current == nil || *value > *current {
+		v := *value
+		return &v
+	}
+	return current
+}
+
+func minValue(current, value *float32) *float32 {
+	if value == nil {
+		return current
+	}
+	if current == nil || *value < *current {
+		v := *value
+		return &v
+	}
+	return current
+}
+
+// totalRainIncreased returns true if both readings have a running total that did not reset between them
+func totalRainIncreased(previous, current *Reading) bool {
+	return previous.TotalRainMM != nil && current.TotalRainMM != nil && *current.TotalRainMM >= *previous.TotalRainMM
+}
+
+func sameDay(a, b time.Time) bool {
+	a, b = a.In(time.Local), b.In(time.Local)
+	return a.Year() == b.Year() && a.YearDay() == b.YearDay()
+}
+
+func fahrenheitToCelsius(f float32) float32 {
+	return (f - 32) * 5 / 9
+}
+
+func inchesToMM(in float32) float32 {
+	return float32(math.Round(float64(in)*25.4*100) / 100)
+}
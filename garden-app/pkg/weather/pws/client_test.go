@@ -0,0 +1,265 @@
+package pws
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStorage []Reading
+
+func (s memoryStorage) ListReadings(_ context.Context, _ string, start, end time.Time) ([]Reading, error) {
+	result := []Reading{}
+	for _, r := range s {
+		if !r.Timestamp.Before(start) && !r.Timestamp.After(end) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func float(f float32) *float32 {
+	return &f
+}
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient("id", map[string]any{}, nil)
+	require.EqualError(t, err, "pws client requires access to reading storage")
+
+	_, err = NewClient("id", map[string]any{}, memoryStorage{})
+	require.EqualError(t, err, "passkey is required so uploads can be authenticated")
+
+	client, err := NewClient("id", map[string]any{"passkey": "secret"}, memoryStorage{})
+	require.NoError(t, err)
+	assert.Equal(t, "secret", client.Passkey)
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name     string
+		passkey  string
+		values   url.Values
+		expected bool
+	}{
+		{"NoPasskeyConfigured", "", url.Values{}, false},
+		{"NoPasskeyConfiguredEmptyPassword", "", url.Values{"PASSWORD": {""}}, false},
+		{"EcowittPasskey", "secret", url.Values{"PASSKEY": {"secret"}}, true},
+		{"WeatherUndergroundPassword", "secret", url.Values{"ID": {"station"}, "PASSWORD": {"secret"}}, true},
+		{"WrongPasskey", "secret", url.Values{"PASSKEY": {"wrong"}}, false},
+		{"MissingPasskey", "secret", url.Values{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{Passkey: tt.passkey}
+			assert.Equal(t, tt.expected, config.Authenticate(tt.values))
+		})
+	}
+}
+
+func TestParseReading(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
+	tests := []struct {
+		name        string
+		query       string
+		expected    Reading
+		expectedErr string
+	}{
+		{
+			"Ecowitt",
+			"PASSKEY=abc&stationtype=GW1100&dateutc=2023-08-23+09:30:00&tempf=77.0&humidity=40&dailyrainin=0.5&solarradiation=512.3&yearlyrainin=2&totalrainin=3",
+			Reading{
+				Timestamp:          time.Date(2023, time.August, 23, 9, 30, 0, 0, time.UTC),
+				TemperatureCelsius: float(25),
+				Humidity:           float(40),
+				DailyRainMM:        float(12.7),
+				SolarRadiation:     float(512.3),
+				TotalRainMM:        float(76.2),
+			},
+			"",
+		},
+		{
+			"EcowittPiezoRain",
+			"PASSKEY=abc&dateutc=2023-08-23+09:30:00&drain_piezo=1",
+			Reading{
+				Timestamp:   time.Date(2023, time.August, 23, 9, 30, 0, 0, time.UTC),
+				DailyRainMM: float(25.4),
+			},
+			"",
+		},
+		{
+			"WeatherUndergroundNow",
+			"ID=station&PASSWORD=abc&action=updateraw&dateutc=now&tempf=32&humidity=55",
+			Reading{
+				Timestamp:          clock.Now(),
+				TemperatureCelsius: float(0),
+				Humidity:           float(55),
+			},
+			"",
+		},
+		{
+			"ErrorInvalidDate",
+			"dateutc=yesterday&tempf=32",
+			Reading{},
+			`invalid dateutc "yesterday": parsing time "yesterday" as "2006-01-02 15:04:05": cannot parse "yesterday" as "2006"`,
+		},
+		{
+			"ErrorInvalidValue",
+			"tempf=hot",
+			Reading{},
+			`invalid tempf "hot": strconv.ParseFloat: parsing "hot": invalid syntax`,
+		},
+		{
+			"ErrorNoMeasurements",
+			"PASSKEY=abc&dateutc=now",
+			Reading{},
+			"upload does not contain any supported measurements",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+
+			reading, err := ParseReading(values)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, reading)
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
+	now := clock.Now().In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	at := func(daysAgo, hour int) time.Time {
+		return today.AddDate(0, 0, -daysAgo).Add(time.Duration(hour) * time.Hour)
+	}
+
+	storage := memoryStorage{
+		{Timestamp: at(3, 12), TemperatureCelsius: float(20), DailyRainMM: float(0), Humidity: float(40), SolarRadiation: float(300)},
+		{Timestamp: at(3, 13), Humidity: float(60), SolarRadiation: float(500)},
+		{Timestamp: at(3, 15), TemperatureCelsius: float(24), DailyRainMM: float(2)},
+		{Timestamp: at(2, 12), TemperatureCelsius: float(30), DailyRainMM: float(0)},
+		{Timestamp: at(1, 6), TemperatureCelsius: float(18), DailyRainMM: float(1)},
+		{Timestamp: at(1, 12), TemperatureCelsius: float(27), DailyRainMM: float(4)},
+		// Accumulator resets at midnight, so this is new rain
+		{Timestamp: at(0, 1), TemperatureCelsius: float(15), DailyRainMM: float(1)},
+		{Timestamp: at(0, 2), TemperatureCelsius: float(16), DailyRainMM: float(3)},
+	}
+
+	client, err := NewClient("id", map[string]any{"passkey": "secret"}, storage)
+	require.NoError(t, err)
+
+	t.Run("GetTotalRain", func(t *testing.T) {
+		// Readings from the previous day are only used to calculate the first increase
+		rain, err := client.GetTotalRain(context.Background(), clock.Now().Sub(at(1, 9)))
+		require.NoError(t, err)
+		assert.InDelta(t, 6, rain, 0.001)
+	})
+
+	t.Run("GetTotalRainNoReadings", func(t *testing.T) {
+		emptyClient, err := NewClient("id", map[string]any{"passkey": "secret"}, memoryStorage{})
+		require.NoError(t, err)
+
+		_, err = emptyClient.GetTotalRain(context.Background(), 24*time.Hour)
+		require.EqualError(t, err, "no rain readings for the specified period")
+	})
+
+	t.Run("GetAverageHighTemperature", func(t *testing.T) {
+		temp, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 27, temp, 0.001)
+	})
+
+	t.Run("GetDailyObservations", func(t *testing.T) {
+		observations, err := client.GetDailyObservations(context.Background(), at(2, 0), at(0, 0))
+		require.NoError(t, err)
+		require.Len(t, observations, 3)
+
+		assert.Equal(t, at(2, 0).Format(time.DateOnly), observations[0].Date)
+		assert.InDelta(t, 0, *observations[0].RainMM, 0.001)
+		assert.InDelta(t, 30, *observations[0].MaxTemperatureCelsius, 0.001)
+		assert.InDelta(t, 4, *observations[1].RainMM, 0.001)
+		assert.InDelta(t, 27, *observations[1].MaxTemperatureCelsius, 0.001)
+		assert.InDelta(t, 3, *observations[2].RainMM, 0.001)
+		assert.InDelta(t, 16, *observations[2].MaxTemperatureCelsius, 0.001)
+	})
+
+	t.Run("GetDailyETInputs", func(t *testing.T) {
+		inputs, err := client.GetDailyETInputs(context.Background(), at(3, 0), at(2, 0))
+		require.NoError(t, err)
+		require.Len(t, inputs, 2)
+
+		assert.InDelta(t, 20, *inputs[0].MinTemperatureCelsius, 0.001)
+		assert.InDelta(t, 24, *inputs[0].MaxTemperatureCelsius, 0.001)
+		assert.InDelta(t, 50, *inputs[0].RelativeHumidity, 0.001)
+		assert.InDelta(t, 34.56, *inputs[0].SolarRadiationMJ, 0.001)
+		assert.InDelta(t, 30, *inputs[1].MinTemperatureCelsius, 0.001)
+		assert.Nil(t, inputs[1].RelativeHumidity)
+	})
+}
+
+func TestClientRainBeforeMidnight(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
+	now := clock.Now().In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	at := func(daysAgo, hour int) time.Time {
+		return today.AddDate(0, 0, -daysAgo).Add(time.Duration(hour) * time.Hour)
+	}
+
+	storage := memoryStorage{
+		{Timestamp: at(1, 12), DailyRainMM: float(1), TotalRainMM: float(10)},
+		{Timestamp: at(1, 22), DailyRainMM: float(4), TotalRainMM: float(13)},
+		// 2mm fell after the last reading yesterday, which is only included in the running total
+		{Timestamp: at(0, 1), DailyRainMM: float(1), TotalRainMM: float(16)},
+		{Timestamp: at(0, 2), DailyRainMM: float(3), TotalRainMM: float(18)},
+	}
+
+	client, err := NewClient("id", map[string]any{"passkey": "secret"}, storage)
+	require.NoError(t, err)
+
+	t.Run("GetTotalRain", func(t *testing.T) {
+		rain, err := client.GetTotalRain(context.Background(), clock.Now().Sub(at(1, 20)))
+		require.NoError(t, err)
+		assert.InDelta(t, 8, rain, 0.001)
+	})
+
+	t.Run("GetDailyObservations", func(t *testing.T) {
+		observations, err := client.GetDailyObservations(context.Background(), at(1, 0), at(0, 0))
+		require.NoError(t, err)
+		require.Len(t, observations, 2)
+
+		assert.InDelta(t, 6, *observations[0].RainMM, 0.001)
+		assert.InDelta(t, 3, *observations[1].RainMM, 0.001)
+	})
+
+	t.Run("TotalReset", func(t *testing.T) {
+		resetClient, err := NewClient("id", map[string]any{"passkey": "secret"}, memoryStorage{
+			{Timestamp: at(1, 22), DailyRainMM: float(4), TotalRainMM: float(500)},
+			// The yearly total reset, so only the daily accumulator can be used
+			{Timestamp: at(0, 1), DailyRainMM: float(1), TotalRainMM: float(1)},
+		})
+		require.NoError(t, err)
+
+		rain, err := resetClient.GetTotalRain(context.Background(), clock.Now().Sub(at(1, 20)))
+		require.NoError(t, err)
+		assert.InDelta(t, 1, rain, 0.001)
+	})
+}
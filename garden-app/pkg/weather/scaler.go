@@ -8,7 +8,8 @@ import (
 )
 
 // WeatherScaler defines the configuration for weather-based duration scaling
-// using min/max input-output pairs with configurable interpolation
+// using min/max input-output pairs with configurable interpolation. When the
+// Interpolation is Curve, the ordered Points are used instead of the min/max range
 type WeatherScaler struct {
 	ClientID      xid.ID            `json:"client_id" yaml:"client_id"`
 	Interpolation InterpolationMode `json:"interpolation" yaml:"interpolation"`
@@ -16,6 +17,13 @@ type WeatherScaler struct {
 	InputMax      *float64          `json:"input_max" yaml:"input_max"`
 	FactorMin     *float64          `json:"factor_min" yaml:"factor_min"`
 	FactorMax     *float64          `json:"factor_max" yaml:"factor_max"`
+	Points        []CurvePoint      `json:"points,omitempty" yaml:"points,omitempty"`
+}
+
+// CurvePoint is a single (input, factor) pair of a piecewise-linear scaling curve
+type CurvePoint struct {
+	Input  float64 `json:"input" yaml:"input"`
+	Factor float64 `json:"factor" yaml:"factor"`
 }
 
 // Patch allows modifying the struct in-place with values from a different instance
@@ -38,10 +46,29 @@ func (ws *WeatherScaler) Patch(newScaler *WeatherScaler) {
 	if newScaler.FactorMax != nil {
 		ws.FactorMax = newScaler.FactorMax
 	}
+	if newScaler.Points != nil {
+		ws.Points = newScaler.Points
+	}
+}
+
+// IsCurve returns true if the WeatherScaler uses Points instead of the min/max range
+func (ws *WeatherScaler) IsCurve() bool {
+	return ws.Interpolation == Curve
 }
 
 // Validate checks that the WeatherScaler configuration is valid
 func (ws *WeatherScaler) Validate() error {
+	if ws.IsCurve() {
+		err := ws.validatePoints()
+		if err != nil {
+			return err
+		}
+		if ws.ClientID.IsNil() {
+			return errors.New("missing required field: client_id")
+		}
+		return nil
+	}
+
 	if ws.InputMin == nil {
 		return errors.New("missing required field: input_min")
 	}
@@ -69,10 +96,29 @@ func (ws *WeatherScaler) Validate() error {
 	return nil
 }
 
+func (ws *WeatherScaler) validatePoints() error {
+	if len(ws.Points) < 2 {
+		return errors.New("curve must have at least 2 points")
+	}
+	for i, p := range ws.Points {
+		if p.Factor < 0 {
+			return errors.New("factors must be non-negative")
+		}
+		if i > 0 && p.Input <= ws.Points[i-1].Input {
+			return fmt.Errorf("curve point inputs must be strictly increasing: %v is not greater than %v", p.Input, ws.Points[i-1].Input)
+		}
+	}
+	return nil
+}
+
 // Scale calculates the scale factor for the given input value.
 // It applies clamping for values outside the input range and
 // interpolates values within the range.
 func (ws *WeatherScaler) Scale(input float64) float64 {
+	if ws.IsCurve() {
+		return ws.scaleCurve(input)
+	}
+
 	// Handle nil pointers gracefully (should not happen after validation)
 	if ws.InputMin == nil || ws.InputMax == nil || ws.FactorMin == nil || ws.FactorMax == nil {
 		return 1.0
@@ -98,3 +144,28 @@ func (ws *WeatherScaler) Scale(input float64) float64 {
 	// Map interpolated value to output range
 	return *ws.FactorMin + (*ws.FactorMax-*ws.FactorMin)*interpolated
 }
+
+// scaleCurve linearly interpolates between the two Points surrounding the input.
+// Inputs outside the curve are clamped to the first or last Point's factor
+func (ws *WeatherScaler) scaleCurve(input float64) float64 {
+	if len(ws.Points) == 0 {
+		return 1.0
+	}
+
+	first := ws.Points[0]
+	if input <= first.Input {
+		return first.Factor
+	}
+
+	for i := 1; i < len(ws.Points); i++ {
+		lower, upper := ws.Points[i-1], ws.Points[i]
+		if input > upper.Input {
+			continue
+		}
+
+		t := (input - lower.Input) / (upper.Input - lower.Input)
+		return lower.Factor + (upper.Factor-lower.Factor)*t
+	}
+
+	return ws.Points[len(ws.Points)-1].Factor
+}
@@ -108,6 +108,7 @@ func TestInterpolationModeIsValid(t *testing.T) {
 		{EaseOut, true},
 		{EaseInOut, true},
 		{Step, true},
+		{Curve, true},
 		{"invalid", false},
 		{"", false},
 		{"LINEAR", false}, // case sensitive
@@ -270,6 +271,54 @@ func TestWeatherScalerValidate(t *testing.T) {
 			wantError: true,
 			errMsg:    "invalid interpolation mode",
 		},
+		{
+			name: "valid curve",
+			scaler: WeatherScaler{
+				ClientID:      xid.New(),
+				Interpolation: Curve,
+				Points:        []CurvePoint{{0, 1}, {5, 0.5}, {10, 0}},
+			},
+			wantError: false,
+		},
+		{
+			name: "curve with one point",
+			scaler: WeatherScaler{
+				ClientID:      xid.New(),
+				Interpolation: Curve,
+				Points:        []CurvePoint{{0, 1}},
+			},
+			wantError: true,
+			errMsg:    "curve must have at least 2 points",
+		},
+		{
+			name: "curve inputs not increasing",
+			scaler: WeatherScaler{
+				ClientID:      xid.New(),
+				Interpolation: Curve,
+				Points:        []CurvePoint{{0, 1}, {5, 0.5}, {5, 0}},
+			},
+			wantError: true,
+			errMsg:    "curve point inputs must be strictly increasing",
+		},
+		{
+			name: "curve negative factor",
+			scaler: WeatherScaler{
+				ClientID:      xid.New(),
+				Interpolation: Curve,
+				Points:        []CurvePoint{{0, 1}, {5, -0.5}},
+			},
+			wantError: true,
+			errMsg:    "factors must be non-negative",
+		},
+		{
+			name: "curve missing client_id",
+			scaler: WeatherScaler{
+				Interpolation: Curve,
+				Points:        []CurvePoint{{0, 1}, {5, 0.5}},
+			},
+			wantError: true,
+			errMsg:    "missing required field: client_id",
+		},
 	}
 
 	for _, tt := range tests {
@@ -285,6 +334,55 @@ func TestWeatherScalerValidate(t *testing.T) {
 	}
 }
 
+// Test piecewise-linear curve scaling
+func TestWeatherScalerCurve(t *testing.T) {
+	// Water normally until 25C, more until 35C, and much more above that
+	scaler := &WeatherScaler{
+		Interpolation: Curve,
+		Points: []CurvePoint{
+			{Input: 15, Factor: 0.5},
+			{Input: 25, Factor: 1},
+			{Input: 35, Factor: 1.2},
+			{Input: 40, Factor: 2},
+		},
+	}
+
+	tests := []struct {
+		input    float64
+		expected float64
+	}{
+		{0, 0.5},
+		{15, 0.5},
+		{20, 0.75},
+		{25, 1},
+		{30, 1.1},
+		{35, 1.2},
+		{37.5, 1.6},
+		{40, 2},
+		{50, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%v", tt.input), func(t *testing.T) {
+			assert.InDelta(t, tt.expected, scaler.Scale(tt.input), 0.0001)
+		})
+	}
+
+	t.Run("MinMaxFieldsIgnored", func(t *testing.T) {
+		withRange := *scaler
+		withRange.InputMin = float64Ptr(0)
+		withRange.InputMax = float64Ptr(100)
+		withRange.FactorMin = float64Ptr(0)
+		withRange.FactorMax = float64Ptr(10)
+		assert.InDelta(t, 0.75, withRange.Scale(20), 0.0001)
+	})
+
+	t.Run("NoPoints", func(t *testing.T) {
+		empty := &WeatherScaler{Interpolation: Curve}
+		assert.InDelta(t, 1.0, empty.Scale(20), 0.0001)
+	})
+}
+
 // Test edge cases
 func TestWeatherScalerEdgeCases(t *testing.T) {
 	t.Run("flat line - FactorMin equals FactorMax", func(t *testing.T) {
@@ -0,0 +1,188 @@
+// Package sensor provides a weather client that uses temperature readings from a garden-controller's sensor
+package sensor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/internal/weatherapi"
+	"github.com/mitchellh/mapstructure"
+)
+
+const minTemperatureInterval = 72 * time.Hour
+
+// ErrRainNotSupported is returned by GetTotalRain since none of the supported sensor types measure rain
+var ErrRainNotSupported = errors.New("sensor does not measure rain")
+
+// Reading is the range of temperatures measured by a sensor during a period starting at Time
+type Reading struct {
+	Time                  time.Time
+	MinTemperatureCelsius float32
+	MaxTemperatureCelsius float32
+}
+
+// Source provides stored readings from a Garden's sensor
+type Source interface {
+	GetSensorTemperatures(ctx context.Context, gardenID, sensorID string, start, end time.Time) ([]Reading, error)
+}
+
+// Config is specific to sensor weather clients. It identifies the Garden and the ID of one of the sensors in
+// its ControllerConfig
+type Config struct {
+	GardenID string `json:"garden_id" yaml:"garden_id" mapstructure:"garden_id"`
+	SensorID string `json:"sensor_id" yaml:"sensor_id" mapstructure:"sensor_id"`
+}
+
+// Client reads temperature history for a garden-controller sensor
+type Client struct {
+	*Config
+	source Source
+}
+
+// NewClient creates a new sensor client from configuration
+func NewClient(options map[string]any, source Source) (*Client, error) {
+	if source == nil {
+		return nil, errors.New("sensor client requires access to sensor readings")
+	}
+
+	client := &Client{
+		Config: &Config{},
+		source: source,
+	}
+
+	err := mapstructure.WeakDecode(options, &client.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if client.GardenID == "" || client.SensorID == "" {
+		return nil, errors.New("garden_id and sensor_id must be provided")
+	}
+
+	return client, nil
+}
+
+// GetTotalRain is not supported by sensors. It is only implemented to satisfy the weather.Client interface
+func (c *Client) GetTotalRain(context.Context, time.Duration) (float32, error) {
+	return 0, ErrRainNotSupported
+}
+
+// GetAverageHighTemperature returns the average daily high temperature between the given time and the end of
+// yesterday (since daily high can be misleading if queried mid-day)
+func (c *Client) GetAverageHighTemperature(ctx context.Context, since time.Duration) (float32, error) {
+	// Time to check since must always be at least 3 days
+	if since < minTemperatureInterval {
+		since = minTemperatureInterval
+	}
+
+	now := clock.Now().In(time.Local)
+	startOfToday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := startOfToday.Add(-since)
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+
+	readings, err := c.source.GetSensorTemperatures(ctx, c.GardenID, c.SensorID, start, startOfToday)
+	if err != nil {
+		return 0, fmt.Errorf("error getting sensor readings: %w", err)
+	}
+
+	observations := dailyObservations(readings)
+	if len(observations) == 0 {
+		return 0, errors.New("no valid temperature data for the specified period")
+	}
+
+	var sum float32
+	for _, o := range observations {
+		sum += *o.MaxTemperatureCelsius
+	}
+
+	return sum / float32(len(observations)), nil
+}
+
+// GetDailyObservations returns the high temperature measured by the sensor for each day between start and end
+func (c *Client) GetDailyObservations(ctx context.Context, start, end time.Time) ([]weatherapi.DailyObservation, error) {
+	beginDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	endDate := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, time.Local)
+	if now := clock.Now(); endDate.After(now) {
+		endDate = now
+	}
+
+	readings, err := c.source.GetSensorTemperatures(ctx, c.GardenID, c.SensorID, beginDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sensor readings: %w", err)
+	}
+
+	return dailyObservations(readings), nil
+}
+
+// GetDailyETInputs returns the temperature range measured by the sensor for each day between start and end.
+// This allows reference evapotranspiration to be calculated
+func (c *Client) GetDailyETInputs(ctx context.Context, start, end time.Time) ([]weatherapi.DailyETInputs, error) {
+	beginDate := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+	endDate := time.Date(end.Year(), end.Month(), end.Day()+1, 0, 0, 0, 0, time.Local)
+
+	readings, err := c.source.GetSensorTemperatures(ctx, c.GardenID, c.SensorID, beginDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sensor readings: %w", err)
+	}
+
+	byDate := map[string]*weatherapi.DailyETInputs{}
+	for _, r := range readings {
+		date := r.Time.In(time.Local).Format(weatherapi.DateLayout)
+		daily, ok := byDate[date]
+		if !ok {
+			minTemperature, maxTemperature := r.MinTemperatureCelsius, r.MaxTemperatureCelsius
+			byDate[date] = &weatherapi.DailyETInputs{
+				Date:                  date,
+				MinTemperatureCelsius: &minTemperature,
+				MaxTemperatureCelsius: &maxTemperature,
+			}
+			continue
+		}
+
+		*daily.MinTemperatureCelsius = min(*daily.MinTemperatureCelsius, r.MinTemperatureCelsius)
+		*daily.MaxTemperatureCelsius = max(*daily.MaxTemperatureCelsius, r.MaxTemperatureCelsius)
+	}
+
+	result := []weatherapi.DailyETInputs{}
+	for _, o := range byDate {
+		result = append(result, *o)
+	}
+	slices.SortFunc(result, func(a, b weatherapi.DailyETInputs) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return result, nil
+}
+
+// dailyObservations groups readings by local date and uses the highest temperature for each day
+func dailyObservations(readings []Reading) []weatherapi.DailyObservation {
+	byDate := map[string]*weatherapi.DailyObservation{}
+	for _, r := range readings {
+		date := r.Time.In(time.Local).Format(weatherapi.DateLayout)
+		daily, ok := byDate[date]
+		if !ok {
+			temperature := r.MaxTemperatureCelsius
+			byDate[date] = &weatherapi.DailyObservation{Date: date, MaxTemperatureCelsius: &temperature}
+			continue
+		}
+
+		if r.MaxTemperatureCelsius > *daily.MaxTemperatureCelsius {
+			*daily.MaxTemperatureCelsius = r.MaxTemperatureCelsius
+		}
+	}
+
+	result := []weatherapi.DailyObservation{}
+	for _, o := range byDate {
+		result = append(result, *o)
+	}
+	slices.SortFunc(result, func(a, b weatherapi.DailyObservation) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+
+	return result
+}
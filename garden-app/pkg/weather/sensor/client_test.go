@@ -0,0 +1,129 @@
+package sensor
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sourceFunc func(ctx context.Context, gardenID, sensorID string, start, end time.Time) ([]Reading, error)
+
+func (f sourceFunc) GetSensorTemperatures(ctx context.Context, gardenID, sensorID string, start, end time.Time) ([]Reading, error) {
+	return f(ctx, gardenID, sensorID, start, end)
+}
+
+func TestNewClient(t *testing.T) {
+	source := sourceFunc(func(context.Context, string, string, time.Time, time.Time) ([]Reading, error) {
+		return nil, nil
+	})
+
+	tests := []struct {
+		name   string
+		opts   map[string]any
+		source Source
+		errMsg string
+	}{
+		{"Successful", map[string]any{"garden_id": "garden", "sensor_id": "sensor"}, source, ""},
+		{"MissingSource", map[string]any{"garden_id": "garden", "sensor_id": "sensor"}, nil, "sensor client requires access to sensor readings"},
+		{"MissingSensorID", map[string]any{"garden_id": "garden"}, source, "garden_id and sensor_id must be provided"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(tt.opts, tt.source)
+			if tt.errMsg != "" {
+				require.EqualError(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "garden", client.GardenID)
+			assert.Equal(t, "sensor", client.SensorID)
+		})
+	}
+}
+
+func TestClient(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
+	now := clock.Now().In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	at := func(daysAgo, hour int) time.Time {
+		return today.AddDate(0, 0, -daysAgo).Add(time.Duration(hour) * time.Hour)
+	}
+
+	readings := []Reading{
+		{at(3, 13), 20, 25},
+		{at(3, 14), 23, 28},
+		{at(2, 14), 25, 30},
+		{at(1, 9), 15, 20},
+		{at(1, 15), 21, 26},
+		{at(0, 8), 13, 18},
+	}
+
+	var requested []time.Time
+	client, err := NewClient(map[string]any{"garden_id": "garden", "sensor_id": "sensor"}, sourceFunc(
+		func(_ context.Context, gardenID, sensorID string, start, end time.Time) ([]Reading, error) {
+			if gardenID != "garden" || sensorID != "sensor" {
+				return nil, errors.New("unexpected sensor")
+			}
+			requested = []time.Time{start, end}
+
+			result := []Reading{}
+			for _, r := range readings {
+				if !r.Time.Before(start) && r.Time.Before(end) {
+					result = append(result, r)
+				}
+			}
+			return result, nil
+		},
+	))
+	require.NoError(t, err)
+
+	t.Run("GetTotalRain", func(t *testing.T) {
+		_, err := client.GetTotalRain(context.Background(), 24*time.Hour)
+		require.ErrorIs(t, err, ErrRainNotSupported)
+	})
+
+	t.Run("GetAverageHighTemperature", func(t *testing.T) {
+		temp, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+		require.NoError(t, err)
+		assert.InDelta(t, 28, temp, 0.001)
+		assert.Equal(t, []time.Time{at(3, 0), at(0, 0)}, requested)
+	})
+
+	t.Run("GetDailyObservations", func(t *testing.T) {
+		observations, err := client.GetDailyObservations(context.Background(), at(1, 0), at(0, 0))
+		require.NoError(t, err)
+		require.Len(t, observations, 2)
+
+		assert.Equal(t, at(1, 0).Format(time.DateOnly), observations[0].Date)
+		assert.Nil(t, observations[0].RainMM)
+		assert.InDelta(t, 26, *observations[0].MaxTemperatureCelsius, 0.001)
+		assert.Equal(t, at(0, 0).Format(time.DateOnly), observations[1].Date)
+		assert.InDelta(t, 18, *observations[1].MaxTemperatureCelsius, 0.001)
+	})
+
+	t.Run("GetDailyETInputs", func(t *testing.T) {
+		inputs, err := client.GetDailyETInputs(context.Background(), at(3, 0), at(1, 0))
+		require.NoError(t, err)
+		require.Len(t, inputs, 3)
+
+		assert.Equal(t, at(3, 0).Format(time.DateOnly), inputs[0].Date)
+		assert.InDelta(t, 20, *inputs[0].MinTemperatureCelsius, 0.001)
+		assert.InDelta(t, 28, *inputs[0].MaxTemperatureCelsius, 0.001)
+		assert.InDelta(t, 15, *inputs[2].MinTemperatureCelsius, 0.001)
+		assert.InDelta(t, 26, *inputs[2].MaxTemperatureCelsius, 0.001)
+	})
+
+	t.Run("NoReadings", func(t *testing.T) {
+		readings = nil
+		_, err := client.GetAverageHighTemperature(context.Background(), 72*time.Hour)
+		require.EqualError(t, err, "no valid temperature data for the specified period")
+	})
+}
@@ -0,0 +1,209 @@
+package pkg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/calvinmclean/babyapi"
+)
+
+// WebhookEventType is a type of event that can be sent to a Webhook
+type WebhookEventType string
+
+const (
+	WebhookEventWaterSent         WebhookEventType = "water.sent"
+	WebhookEventWaterStarted      WebhookEventType = "water.started"
+	WebhookEventWaterCompleted    WebhookEventType = "water.completed"
+	WebhookEventWaterCancelled    WebhookEventType = "water.cancelled"
+	WebhookEventWaterSkipped      WebhookEventType = "water.skipped"
+	WebhookEventLightChanged      WebhookEventType = "light.changed"
+	WebhookEventControllerStartup WebhookEventType = "controller.startup"
+	WebhookEventControllerAlert   WebhookEventType = "controller.alert"
+	WebhookEventControllerDown    WebhookEventType = "controller.down"
+	WebhookEventFirmwareChanged   WebhookEventType = "firmware.changed"
+	WebhookEventTest              WebhookEventType = "test"
+)
+
+// WebhookEventTypes are all of the event types that a Webhook can subscribe to
+var WebhookEventTypes = []WebhookEventType{
+	WebhookEventWaterSent,
+	WebhookEventWaterStarted,
+	WebhookEventWaterCompleted,
+	WebhookEventWaterCancelled,
+	WebhookEventWaterSkipped,
+	WebhookEventLightChanged,
+	WebhookEventControllerStartup,
+	WebhookEventControllerAlert,
+	WebhookEventControllerDown,
+	WebhookEventFirmwareChanged,
+}
+
+const (
+	// WebhookSignatureHeader contains the hex-encoded HMAC-SHA256 signature of the request, prefixed with "sha256="
+	WebhookSignatureHeader = "X-Garden-Signature"
+	// WebhookTimestampHeader contains the Unix timestamp that is included in the signature
+	WebhookTimestampHeader = "X-Garden-Timestamp"
+	// WebhookEventHeader contains the WebhookEventType
+	WebhookEventHeader = "X-Garden-Event"
+	// WebhookDeliveryHeader contains the WebhookEvent ID, which is the same for every attempt
+	WebhookDeliveryHeader = "X-Garden-Delivery"
+)
+
+// Webhook sends signed JSON payloads to a URL when the subscribed events happen
+type Webhook struct {
+	ID         babyapi.ID         `json:"id" yaml:"id,omitempty"`
+	Name       string             `json:"name" yaml:"name"`
+	URL        string             `json:"url" yaml:"url"`
+	Secret     string             `json:"secret,omitempty" yaml:"secret,omitempty"`
+	EventTypes []WebhookEventType `json:"event_types" yaml:"event_types"`
+	Disabled   *bool              `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+func (wh *Webhook) GetID() string {
+	return wh.ID.String()
+}
+
+func (wh *Webhook) ParentID() string {
+	return ""
+}
+
+// IsDisabled returns true if the Webhook should not receive any events
+func (wh *Webhook) IsDisabled() bool {
+	return wh.Disabled != nil && *wh.Disabled
+}
+
+// Subscribed returns true if the Webhook is enabled and should receive the event type
+func (wh *Webhook) Subscribed(eventType WebhookEventType) bool {
+	if wh.IsDisabled() {
+		return false
+	}
+	return eventType == WebhookEventTest || slices.Contains(wh.EventTypes, eventType)
+}
+
+// Patch allows for easily updating individual fields of a Webhook by passing in a new Webhook containing
+// the desired values
+func (wh *Webhook) Patch(newWebhook *Webhook) *babyapi.ErrResponse {
+	if newWebhook.Name != "" {
+		wh.Name = newWebhook.Name
+	}
+	if newWebhook.URL != "" {
+		wh.URL = newWebhook.URL
+	}
+	if newWebhook.Secret != "" {
+		wh.Secret = newWebhook.Secret
+	}
+	if len(newWebhook.EventTypes) > 0 {
+		wh.EventTypes = newWebhook.EventTypes
+	}
+	if newWebhook.Disabled != nil {
+		wh.Disabled = newWebhook.Disabled
+	}
+
+	err := wh.validate()
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	return nil
+}
+
+func (wh *Webhook) Bind(r *http.Request) error {
+	if wh == nil {
+		return errors.New("missing required Webhook fields")
+	}
+
+	err := wh.ID.Bind(r)
+	if err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case http.MethodPost, http.MethodPut:
+		if wh.Name == "" {
+			return errors.New("missing required name field")
+		}
+		if wh.URL == "" {
+			return errors.New("missing required url field")
+		}
+		if len(wh.EventTypes) == 0 {
+			return errors.New("missing required event_types field")
+		}
+		return wh.validate()
+	}
+
+	return nil
+}
+
+func (wh *Webhook) validate() error {
+	u, err := url.Parse(wh.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid url %q: must be an http or https URL", wh.URL)
+	}
+
+	for _, eventType := range wh.EventTypes {
+		if !slices.Contains(WebhookEventTypes, eventType) {
+			return fmt.Errorf("invalid event type %q", eventType)
+		}
+	}
+
+	return nil
+}
+
+func (wh *Webhook) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
+// Sign returns the value for the WebhookSignatureHeader. The signature is an HMAC-SHA256 of the timestamp and body
+// joined by a "." using the Webhook's Secret
+func (wh *Webhook) Sign(timestamp time.Time, body []byte) string {
+	return SignWebhookPayload(wh.Secret, timestamp, body)
+}
+
+// SignWebhookPayload creates the value for the WebhookSignatureHeader. Receivers can use this to verify requests
+func SignWebhookPayload(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookEvent is the JSON payload sent to Webhooks
+type WebhookEvent struct {
+	ID         string           `json:"id"`
+	Type       WebhookEventType `json:"type"`
+	Timestamp  time.Time        `json:"timestamp"`
+	GardenID   string           `json:"garden_id,omitempty"`
+	GardenName string           `json:"garden_name,omitempty"`
+	Data       map[string]any   `json:"data,omitempty"`
+}
+
+// WebhookDelivery records an attempt to send a WebhookEvent to a Webhook
+type WebhookDelivery struct {
+	ID          int64            `json:"id"`
+	WebhookID   string           `json:"webhook_id"`
+	EventID     string           `json:"event_id"`
+	EventType   WebhookEventType `json:"event_type"`
+	Payload     string           `json:"payload"`
+	CreatedAt   time.Time        `json:"created_at"`
+	Attempts    int              `json:"attempts"`
+	StatusCode  int              `json:"status_code,omitempty"`
+	LastError   string           `json:"last_error,omitempty"`
+	DeliveredAt *time.Time       `json:"delivered_at,omitempty"`
+}
+
+// Delivered returns true if the WebhookEvent was successfully sent
+func (d *WebhookDelivery) Delivered() bool {
+	return d.DeliveredAt != nil
+}
@@ -13,7 +13,9 @@ import (
 	"github.com/calvinmclean/automated-garden/garden-app/clock"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt/broker"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
 	"github.com/calvinmclean/automated-garden/garden-app/server/vcr"
 	"github.com/calvinmclean/automated-garden/garden-app/worker"
 	"github.com/mark3labs/mcp-go/server"
@@ -37,6 +39,12 @@ type API struct {
 	waterRoutines       *WaterRoutineAPI
 	notes               *NotesAPI
 	settings            *SettingsAPI
+	commandQueue        *CommandQueueAPI
+	pendingDevices      *PendingDevicesAPI
+	webhooks            *WebhooksAPI
+	events              *EventsAPI
+
+	mqttClient mqtt.Client
 }
 
 // NewAPI intializes an API without any integrations or clients. Use api.Setup(...) before running
@@ -51,11 +59,16 @@ func NewAPI() *API {
 		waterRoutines:       NewWaterRoutineAPI(),
 		notes:               NewNotesAPI(),
 		settings:            NewSettingsAPI(),
+		commandQueue:        NewCommandQueueAPI(),
+		pendingDevices:      NewPendingDevicesAPI(),
+		webhooks:            NewWebhooksAPI(),
+		events:              NewEventsAPI(),
 	}
 	api.gardens.AddNestedAPI(api.zones)
 
 	api.API.
 		AddCustomRoute(http.MethodGet, "/metrics", promhttp.Handler()).
+		AddCustomRoute(http.MethodGet, "/health", babyapi.Handler(api.handleHealth)).
 		AddCustomRoute(http.MethodGet, "/", http.RedirectHandler("/gardens", http.StatusFound)).
 		AddCustomRoute(http.MethodGet, "/manifest.json", http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
 			// manifest.json enables PWA for mobile devices
@@ -71,9 +84,17 @@ func NewAPI() *API {
 		AddNestedAPI(api.waterSchedules).
 		AddNestedAPI(api.waterRoutines).
 		AddNestedAPI(api.notes).
+		AddNestedAPI(api.webhooks).
 		AddCustomRoute(http.MethodGet, "/settings/components", babyapi.Handler(api.settings.handleSettingsComponents)).
 		AddCustomRoute(http.MethodGet, "/user_settings/{key}", babyapi.Handler(api.settings.handleGetUserSetting)).
 		AddCustomRoute(http.MethodPut, "/user_settings/{key}", babyapi.Handler(api.settings.handleUpdateUserSetting)).
+		AddCustomRoute(http.MethodGet, "/command_queue", babyapi.Handler(api.commandQueue.handleList)).
+		AddCustomRoute(http.MethodGet, "/command_queue/{id}", babyapi.Handler(api.commandQueue.handleGet)).
+		AddCustomRoute(http.MethodDelete, "/command_queue/{id}", babyapi.Handler(api.commandQueue.handleDelete)).
+		AddCustomRoute(http.MethodGet, "/pending_devices", babyapi.Handler(api.pendingDevices.handleList)).
+		AddCustomRoute(http.MethodDelete, "/pending_devices/{topic_prefix}", babyapi.Handler(api.pendingDevices.handleDelete)).
+		AddCustomRoute(http.MethodPost, "/pending_devices/{topic_prefix}/adopt", babyapi.Handler(api.pendingDevices.handleAdopt)).
+		AddCustomRoute(http.MethodGet, "/events", http.HandlerFunc(api.events.handleStream)).
 		EnableMCP(babyapi.MCPPermNone).
 		AddMCPServerOptions(
 			server.WithInstructions(`
@@ -84,6 +105,7 @@ This server is the cloud backend for remote irrigation controllers. It has the f
   - WaterRoutines: group multiple zones for easy on-demand watering
   - Notes: user-created notes that can optionally be tagged with Gardens and Zones
   - NotificationClients: settings to enable notifications with an external provider
+  - Webhooks: URLs that receive signed JSON payloads when garden events happen
 `),
 			server.WithLogging(),
 		)
@@ -138,6 +160,21 @@ func (api *API) Setup(cfg Config, validateData bool) error {
 		}
 	}
 
+	// Start embedded MQTT broker
+	if cfg.MQTTConfig.Embedded {
+		logger.Debug("starting embedded MQTT broker")
+		var mqttBroker *broker.Broker
+		mqttBroker, cfg.MQTTConfig, err = cfg.MQTTConfig.StartEmbeddedBroker(logger.With("source", "mqtt_broker"))
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			<-api.Done()
+			_ = mqttBroker.Close()
+		}()
+	}
+
 	// Initialize MQTT Client
 	logger.With(
 		"client_id", cfg.MQTTConfig.ClientID,
@@ -148,6 +185,7 @@ func (api *API) Setup(cfg Config, validateData bool) error {
 	if err != nil {
 		return fmt.Errorf("unable to initialize MQTT client: %v", err)
 	}
+	api.mqttClient = mqttClient
 
 	// Initialize InfluxDB Client
 	logger.With(
@@ -156,10 +194,26 @@ func (api *API) Setup(cfg Config, validateData bool) error {
 		"bucket", cfg.InfluxDBConfig.Bucket,
 	).Debug("initializing InfluxDB client")
 	influxdbClient := influxdb.NewClient(cfg.InfluxDBConfig)
+	storageClient.SetInfluxDBClient(influxdbClient)
+
+	weather.ConfigureCache(cfg.WeatherCacheConfig)
 
 	// Initialize Scheduler
 	logger.Debug("initializing scheduler")
-	worker := worker.NewWorker(storageClient, influxdbClient, mqttClient, cfg.LogConfig.NewLogger())
+	worker := worker.NewWorker(
+		storageClient, influxdbClient, mqttClient, cfg.LogConfig.NewLogger(),
+		worker.WithWeatherAuthConfig(cfg.WeatherAuthConfig),
+		worker.WithWeatherHealthConfig(cfg.WeatherHealthConfig),
+		worker.WithMQTTHealthConfig(cfg.MQTTHealthConfig),
+		worker.WithCommandQueueConfig(cfg.CommandQueueConfig),
+		worker.WithWateringWatchdogConfig(cfg.WateringWatchdogConfig),
+		worker.WithDeviceStateConfig(cfg.DeviceStateConfig),
+		worker.WithHomeAssistantConfig(cfg.HomeAssistantConfig),
+		worker.WithConfigSyncConfig(cfg.ConfigSyncConfig),
+		worker.WithDesiredStateConfig(cfg.DesiredStateConfig),
+		worker.WithControllerScheduleConfig(cfg.ControllerScheduleConfig),
+		worker.WithWebhookConfig(cfg.WebhookConfig),
+	)
 
 	err = api.setup(cfg, storageClient, influxdbClient, worker)
 	if err != nil {
@@ -196,11 +250,15 @@ func (api *API) setup(cfg Config, storageClient *storage.Client, influxdbClient
 	}
 
 	api.zones.setup(storageClient, influxdbClient, worker)
-	api.weatherClients.setup(storageClient)
+	api.weatherClients.setup(storageClient, worker)
 	api.notificationClients.setup(storageClient)
 	api.waterRoutines.setup(storageClient, worker)
 	api.notes.setup(storageClient)
 	api.settings.Setup(storageClient)
+	api.commandQueue.setup(storageClient)
+	api.pendingDevices.setup(storageClient, worker)
+	api.webhooks.setup(storageClient, worker)
+	api.events.setup(storageClient, worker)
 
 	// Add units middleware to handle user unit preferences
 	api.AddMiddleware(unitsMiddleware(storageClient))
@@ -262,6 +320,19 @@ func validateAllStoredResources(storageClient *storage.Client) error {
 		}
 	}
 
+	for wh, err := range storageClient.Webhooks.Search(context.Background(), "", nil) {
+		if err != nil {
+			return fmt.Errorf("unable to get all Webhooks: %w", err)
+		}
+		if wh.ID.IsNil() {
+			return errors.New("invalid Webhook: missing required field 'id'")
+		}
+		err = wh.Bind(&http.Request{Method: http.MethodPut})
+		if err != nil {
+			return fmt.Errorf("invalid Webhook %q: %w", wh.ID, err)
+		}
+	}
+
 	for n, err := range storageClient.Notes.Search(context.Background(), "", nil) {
 		if err != nil {
 			return fmt.Errorf("unable to get all Notes: %w", err)
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/babyapi"
+	"github.com/go-chi/render"
+)
+
+// CommandQueueAPI allows inspecting and removing MQTT commands that are waiting to be published
+type CommandQueueAPI struct {
+	storageClient *storage.Client
+}
+
+// NewCommandQueueAPI creates a new CommandQueueAPI
+func NewCommandQueueAPI() *CommandQueueAPI {
+	return &CommandQueueAPI{}
+}
+
+func (api *CommandQueueAPI) setup(storageClient *storage.Client) {
+	api.storageClient = storageClient
+}
+
+// QueuedCommandsResponse is the response for listing queued commands
+type QueuedCommandsResponse struct {
+	Items []*pkg.QueuedCommand `json:"items"`
+}
+
+// Render is used to implement render.Renderer
+func (*QueuedCommandsResponse) Render(http.ResponseWriter, *http.Request) error {
+	return nil
+}
+
+// QueuedCommandResponse is the response for a single queued command
+type QueuedCommandResponse struct {
+	*pkg.QueuedCommand
+}
+
+// Render is used to implement render.Renderer
+func (*QueuedCommandResponse) Render(http.ResponseWriter, *http.Request) error {
+	return nil
+}
+
+// handleList returns queued commands in the order they will be published. They can be filtered using the
+// garden_id query parameter
+func (api *CommandQueueAPI) handleList(_ http.ResponseWriter, r *http.Request) render.Renderer {
+	commands, err := api.storageClient.CommandQueue.List(r.Context(), r.URL.Query().Get("garden_id"))
+	if err != nil {
+		return babyapi.InternalServerError(err)
+	}
+	return &QueuedCommandsResponse{Items: commands}
+}
+
+// handleGet returns a single queued command
+func (api *CommandQueueAPI) handleGet(_ http.ResponseWriter, r *http.Request) render.Renderer {
+	cmd, errResp := api.getQueuedCommand(r)
+	if errResp != nil {
+		return errResp
+	}
+	return &QueuedCommandResponse{cmd}
+}
+
+// handleDelete removes a command from the queue so it is never published
+func (api *CommandQueueAPI) handleDelete(w http.ResponseWriter, r *http.Request) render.Renderer {
+	cmd, errResp := api.getQueuedCommand(r)
+	if errResp != nil {
+		return errResp
+	}
+
+	err := api.storageClient.CommandQueue.Delete(r.Context(), cmd.ID)
+	if err != nil {
+		return babyapi.InternalServerError(fmt.Errorf("error deleting queued command: %w", err))
+	}
+
+	render.NoContent(w, r)
+	return nil
+}
+
+func (api *CommandQueueAPI) getQueuedCommand(r *http.Request) (*pkg.QueuedCommand, *babyapi.ErrResponse) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return nil, babyapi.ErrInvalidRequest(fmt.Errorf("invalid command ID: %w", err))
+	}
+
+	cmd, err := api.storageClient.CommandQueue.Get(r.Context(), id)
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+	if cmd == nil {
+		return nil, babyapi.ErrNotFoundResponse
+	}
+	return cmd, nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandQueueAPI(t *testing.T) {
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	garden := createExampleGarden()
+	require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+	queuedAt := time.Date(2023, time.August, 23, 10, 0, 0, 0, time.UTC)
+	expiresAt := queuedAt.Add(2 * time.Hour)
+	require.NoError(t, storageClient.CommandQueue.Add(context.Background(), &pkg.QueuedCommand{
+		GardenID:  garden.GetID(),
+		Type:      "water",
+		Topic:     "test-garden/command/water",
+		Payload:   `{"duration":1000}`,
+		QueuedAt:  queuedAt,
+		ExpiresAt: &expiresAt,
+		Attempts:  1,
+		LastError: "unable to connect to MQTT broker",
+	}))
+
+	api := NewCommandQueueAPI()
+	api.setup(storageClient)
+
+	expectedCommand := `{"id":1,"garden_id":"c5cvhpcbcv45e8bp16dg","type":"water","topic":"test-garden/command/water","payload":"{\"duration\":1000}","queued_at":"2023-08-23T10:00:00Z","expires_at":"2023-08-23T12:00:00Z","attempts":1,"last_error":"unable to connect to MQTT broker"}`
+
+	t.Run("List", func(t *testing.T) {
+		for query, expected := range map[string]string{
+			"":                                `{"items":[` + expectedCommand + `]}`,
+			"?garden_id=" + garden.GetID():    `{"items":[` + expectedCommand + `]}`,
+			"?garden_id=cqsnecmiuvoqlhrmf2jg": `{"items":[]}`,
+		} {
+			r := httptest.NewRequest(http.MethodGet, "/command_queue"+query, http.NoBody)
+			w := httptest.NewRecorder()
+			babyapi.Handler(api.handleList).ServeHTTP(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, expected, strings.TrimSpace(w.Body.String()))
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/command_queue/1", http.NoBody)
+		r.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleGet).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, expectedCommand, strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("GetInvalidID", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/command_queue/abc", http.NoBody)
+		r.SetPathValue("id", "abc")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleGet).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodDelete, "/command_queue/1", http.NoBody)
+		r.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleDelete).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = httptest.NewRecorder()
+		babyapi.Handler(api.handleDelete).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
 )
 
 // Config holds all the options and sub-configs for the server
@@ -13,6 +15,19 @@ type Config struct {
 	MQTTConfig     mqtt.Config     `mapstructure:"mqtt" yaml:"mqtt"`
 	StorageConfig  storage.Config  `mapstructure:"storage" yaml:"storage"`
 	LogConfig      LogConfig       `mapstructure:"log" yaml:"log"`
+
+	WeatherAuthConfig        worker.WeatherAuthConfig        `mapstructure:"weather_auth" yaml:"weather_auth"`
+	WeatherHealthConfig      worker.WeatherHealthConfig      `mapstructure:"weather_health" yaml:"weather_health"`
+	WeatherCacheConfig       weather.CacheConfig             `mapstructure:"weather_cache" yaml:"weather_cache"`
+	MQTTHealthConfig         worker.MQTTHealthConfig         `mapstructure:"mqtt_health" yaml:"mqtt_health"`
+	CommandQueueConfig       worker.CommandQueueConfig       `mapstructure:"command_queue" yaml:"command_queue"`
+	WateringWatchdogConfig   worker.WateringWatchdogConfig   `mapstructure:"watering_watchdog" yaml:"watering_watchdog"`
+	DeviceStateConfig        worker.DeviceStateConfig        `mapstructure:"device_state" yaml:"device_state"`
+	HomeAssistantConfig      worker.HomeAssistantConfig      `mapstructure:"home_assistant" yaml:"home_assistant"`
+	ConfigSyncConfig         worker.ConfigSyncConfig         `mapstructure:"config_sync" yaml:"config_sync"`
+	DesiredStateConfig       worker.DesiredStateConfig       `mapstructure:"desired_state" yaml:"desired_state"`
+	ControllerScheduleConfig worker.ControllerScheduleConfig `mapstructure:"controller_schedule" yaml:"controller_schedule"`
+	WebhookConfig            worker.WebhookConfig            `mapstructure:"webhooks" yaml:"webhooks"`
 }
 
 // WebConfig is used to allow reading the "web_server" section into the main Config struct
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
+	"github.com/calvinmclean/babyapi"
+	"github.com/go-chi/render"
+)
+
+const eventsKeepAliveInterval = 30 * time.Second
+
+// EventsAPI streams LiveEvents from controllers using Server-Sent Events
+type EventsAPI struct {
+	storageClient *storage.Client
+	worker        *worker.Worker
+
+	keepAliveInterval time.Duration
+}
+
+// NewEventsAPI creates a new EventsAPI
+func NewEventsAPI() *EventsAPI {
+	return &EventsAPI{
+		keepAliveInterval: eventsKeepAliveInterval,
+	}
+}
+
+func (api *EventsAPI) setup(storageClient *storage.Client, worker *worker.Worker) {
+	api.storageClient = storageClient
+	api.worker = worker
+}
+
+// handleStream streams LiveEvents until the client disconnects. The garden_id query parameter limits the stream to
+// a single Garden. When html=true, events that can update the UI directly are also sent as HTML fragments
+func (api *EventsAPI) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		_ = render.Render(w, r, babyapi.InternalServerError(errors.New("streaming is not supported")))
+		return
+	}
+
+	gardenID := r.URL.Query().Get("garden_id")
+	if gardenID != "" {
+		_, err := api.storageClient.Gardens.Get(r.Context(), gardenID)
+		switch {
+		case errors.Is(err, babyapi.ErrNotFound):
+			_ = render.Render(w, r, babyapi.ErrNotFoundResponse)
+			return
+		case err != nil:
+			_ = render.Render(w, r, babyapi.InternalServerError(fmt.Errorf("error getting Garden: %w", err)))
+			return
+		}
+	}
+
+	renderHTML := r.URL.Query().Get("html") == "true"
+
+	events, unsubscribe := api.worker.SubscribeLiveEvents(gardenID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Disable response buffering by nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(api.keepAliveInterval)
+	defer keepAlive.Stop()
+
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			_, err = io.WriteString(w, ": keep-alive\n\n")
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			err = writeLiveEvent(w, event)
+			if err == nil && renderHTML {
+				err = api.writeLiveEventHTML(w, r, event)
+			}
+		}
+		if err != nil {
+			logger.Debug("error writing live event", "error", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeLiveEvent writes the LiveEvent in the Server-Sent Events format. Events for a Zone are written again with
+// the Zone's event name so clients can listen for a single Zone
+func writeLiveEvent(w io.Writer, event pkg.LiveEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshalling event: %w", err)
+	}
+
+	err = writeServerSentEvent(w, event.Name(), data)
+	if err != nil || event.ZoneID == "" {
+		return err
+	}
+	return writeServerSentEvent(w, event.ZoneName(), data)
+}
+
+// writeLiveEventHTML writes an HTML fragment for events that are swapped directly into the UI. Sensor readings
+// replace the sensor's details so the rest of the Garden doesn't need to be fetched again
+func (api *EventsAPI) writeLiveEventHTML(w io.Writer, r *http.Request, event pkg.LiveEvent) error {
+	data, ok := event.Data.(pkg.SensorLiveEventData)
+	if !ok {
+		return nil
+	}
+
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	g, err := api.storageClient.Gardens.Get(r.Context(), event.GardenID)
+	if err != nil {
+		logger.Debug("unable to get Garden for live event", "garden_id", event.GardenID, "error", err)
+		return nil
+	}
+	if g.ControllerConfig == nil {
+		return nil
+	}
+
+	for _, sensor := range g.ControllerConfig.Sensors {
+		if sensor.ID != data.SensorID {
+			continue
+		}
+
+		fragment := sensorDetailsTemplate.Render(r, SensorData{
+			ID:                 sensor.ID,
+			Name:               sensor.Name,
+			Type:               sensor.Type,
+			TemperatureCelsius: data.Readings["temperature"],
+			HumidityPercentage: data.Readings["humidity"],
+		})
+		return writeServerSentEvent(w, event.Name()+":"+sensor.ID, []byte(fragment))
+	}
+	return nil
+}
+
+// writeServerSentEvent writes an event with each line of the data in a separate data field
+func writeServerSentEvent(w io.Writer, name string, data []byte) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "event: %s\n", name)
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
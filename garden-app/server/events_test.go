@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
+	babyhtml "github.com/calvinmclean/babyapi/html"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsAPI(t *testing.T) {
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	garden := createExampleGarden()
+	require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+	api := NewEventsAPI()
+	api.keepAliveInterval = 10 * time.Millisecond
+	api.setup(storageClient, worker.NewWorker(storageClient, nil, nil, slog.Default()))
+
+	server := httptest.NewServer(http.HandlerFunc(api.handleStream))
+	defer server.Close()
+
+	t.Run("GardenNotFound", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "?garden_id=doesnotexist")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Stream", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?garden_id="+garden.GetID(), http.NoBody)
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+		line, err := bufio.NewReader(resp.Body).ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, ": keep-alive\n", line)
+	})
+}
+
+func TestWriteLiveEvent(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeLiveEvent(&buf, pkg.LiveEvent{
+		Type:      pkg.LiveEventLight,
+		GardenID:  "c5cvhpcbcv45e8bp16dg",
+		Timestamp: time.Date(2023, time.August, 23, 10, 0, 0, 0, time.UTC),
+		Data:      map[string]any{"state": "ON"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"event: light:c5cvhpcbcv45e8bp16dg\n"+
+			`data: {"type":"light","garden_id":"c5cvhpcbcv45e8bp16dg","timestamp":"2023-08-23T10:00:00Z","data":{"state":"ON"}}`+"\n\n",
+		buf.String(),
+	)
+}
+
+func TestWriteLiveEventZone(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeLiveEvent(&buf, pkg.LiveEvent{
+		Type:      pkg.LiveEventWaterStatus,
+		GardenID:  "c5cvhpcbcv45e8bp16dg",
+		ZoneID:    "c5cvhpcbcv45e8bp16dh",
+		Timestamp: time.Date(2023, time.August, 23, 10, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	data := `data: {"type":"water_status","garden_id":"c5cvhpcbcv45e8bp16dg","zone_id":"c5cvhpcbcv45e8bp16dh","timestamp":"2023-08-23T10:00:00Z"}` + "\n\n"
+	assert.Equal(t,
+		"event: water_status:c5cvhpcbcv45e8bp16dg\n"+data+
+			"event: water_status:c5cvhpcbcv45e8bp16dg:c5cvhpcbcv45e8bp16dh\n"+data,
+		buf.String(),
+	)
+}
+
+func TestWriteServerSentEvent(t *testing.T) {
+	var buf bytes.Buffer
+	err := writeServerSentEvent(&buf, "sensor:c5cvhpcbcv45e8bp16dg:sensor", []byte("<span>\n    20.0\n</span>\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"event: sensor:c5cvhpcbcv45e8bp16dg:sensor\n"+
+			"data: <span>\n"+
+			"data:     20.0\n"+
+			"data: </span>\n\n",
+		buf.String(),
+	)
+}
+
+func TestWriteLiveEventHTML(t *testing.T) {
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	garden := createExampleGarden()
+	garden.ControllerConfig = &pkg.ControllerConfig{
+		Sensors: []pkg.SensorConfig{{ID: "sensor", Name: "Ambient", Type: "DHT22"}},
+	}
+	require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+	api := NewEventsAPI()
+	api.setup(storageClient, worker.NewWorker(storageClient, nil, nil, slog.Default()))
+
+	babyhtml.SetFS(templates, "templates/*")
+	babyhtml.SetFuncs(templateFuncs)
+
+	r := httptest.NewRequest(http.MethodGet, "/events?html=true", http.NoBody)
+
+	t.Run("Sensor", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := api.writeLiveEventHTML(&buf, r, pkg.LiveEvent{
+			Type:     pkg.LiveEventSensor,
+			GardenID: garden.GetID(),
+			Data: pkg.SensorLiveEventData{
+				SensorID: "sensor",
+				Readings: map[string]float64{"temperature": 20, "humidity": 45},
+			},
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, buf.String(), "event: sensor:"+garden.GetID()+":sensor\n")
+		assert.Contains(t, buf.String(), "20.0°C")
+		assert.Contains(t, buf.String(), "45%")
+	})
+
+	t.Run("UnknownSensor", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := api.writeLiveEventHTML(&buf, r, pkg.LiveEvent{
+			Type:     pkg.LiveEventSensor,
+			GardenID: garden.GetID(),
+			Data:     pkg.SensorLiveEventData{SensorID: "other"},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("NotHTML", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := api.writeLiveEventHTML(&buf, r, pkg.LiveEvent{
+			Type:     pkg.LiveEventLight,
+			GardenID: garden.GetID(),
+		})
+		require.NoError(t, err)
+		assert.Empty(t, buf.String())
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/calvinmclean/automated-garden/garden-app/clock"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/driver"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
@@ -61,6 +62,16 @@ func NewGardenAPI() *GardensAPI {
 	})
 
 	api.SetOnCreateOrUpdate(api.onCreateOrUpdate)
+	api.SetAfterCreateOrUpdate(func(_ http.ResponseWriter, r *http.Request, g *pkg.Garden) *babyapi.ErrResponse {
+		api.updateHomeAssistantDiscovery(r, g.GetID())
+
+		err := api.worker.UpdateControllerSchedule(r.Context(), g.GetID())
+		if err != nil {
+			logger, _ := babyapi.GetLoggerFromContext(r.Context())
+			logger.Error("unable to update controller schedule", "garden_id", g.GetID(), "error", err)
+		}
+		return nil
+	})
 
 	api.AddCustomIDRoute(http.MethodPost, "/action", api.GetRequestedResourceAndDo(api.gardenAction))
 	api.AddCustomIDRoute(http.MethodGet, "/water_history", api.GetRequestedResourceAndDo(api.gardenWaterHistory))
@@ -114,6 +125,16 @@ func NewGardenAPI() *GardensAPI {
 			logger.Error("unable to remove scheduled actions", "error", err)
 			return babyapi.InternalServerError(err)
 		}
+
+		// The Garden is only soft-deleted the first time, so it can still be used to clear the desired state
+		garden, err := api.storageClient.Gardens.Get(r.Context(), gardenID)
+		if err == nil {
+			if err := api.worker.ClearDesiredState(r.Context(), garden, true, true); err != nil {
+				logger.Error("unable to clear desired state for Garden", "error", err)
+			}
+		}
+
+		api.updateHomeAssistantDiscovery(r, gardenID)
 		return nil
 	})
 
@@ -124,6 +145,16 @@ func NewGardenAPI() *GardensAPI {
 	return api
 }
 
+// updateHomeAssistantDiscovery updates the Garden's Home Assistant entities. Errors are only logged because the
+// Garden was already saved
+func (api *GardensAPI) updateHomeAssistantDiscovery(r *http.Request, gardenID string) {
+	err := api.worker.UpdateHomeAssistantDiscovery(r.Context(), gardenID)
+	if err != nil {
+		logger, _ := babyapi.GetLoggerFromContext(r.Context())
+		logger.Error("unable to update Home Assistant discovery", "garden_id", gardenID, "error", err)
+	}
+}
+
 func (api *GardensAPI) gardenModalRenderer(ctx context.Context, g *pkg.Garden) render.Renderer {
 	notificationClients := make([]*notifications.Client, 0)
 	for nc, err := range api.storageClient.NotificationClientConfigs.Search(ctx, "", nil) {
@@ -205,6 +236,13 @@ func (api *GardensAPI) onCreateOrUpdate(_ http.ResponseWriter, r *http.Request,
 		}
 	}
 
+	if garden.Driver != nil {
+		err = driver.Validate(garden.Driver)
+		if err != nil {
+			return babyapi.ErrInvalidRequest(err)
+		}
+	}
+
 	// Validate NotificationClient exists
 	if garden.NotificationClientID != nil {
 		apiErr := checkNotificationClientExists(r.Context(), api.storageClient, *garden.NotificationClientID)
@@ -213,6 +251,20 @@ func (api *GardensAPI) onCreateOrUpdate(_ http.ResponseWriter, r *http.Request,
 		}
 	}
 
+	// The retained desired state is only cleared when a schedule is removed, so it isn't published again for every
+	// update
+	if r.Method != http.MethodPost {
+		existing, _ := api.GetRequestedResource(r)
+		clearLight := existing != nil && existing.LightSchedule != nil && garden.LightSchedule == nil
+		clearFan := existing != nil && existing.FanSchedule != nil && garden.FanSchedule == nil
+		if clearLight || clearFan {
+			logger.Debug("clearing desired state for removed schedules", "light", clearLight, "fan", clearFan)
+			if err := api.worker.ClearDesiredState(r.Context(), garden, clearLight, clearFan); err != nil {
+				logger.Error("unable to clear desired state for Garden", "error", err)
+			}
+		}
+	}
+
 	if garden.LightSchedule != nil {
 		// Update the light schedule for the Garden (if it exists)
 		logger.Debug("updating/resetting LightSchedule for Garden")
@@ -35,6 +35,7 @@ type GardenResponse struct {
 	NextLightAction *NextLightAction  `json:"next_light_action,omitempty"`
 	NextFanAction   *NextFanAction    `json:"next_fan_action,omitempty"`
 	Health          *pkg.GardenHealth `json:"health,omitempty"`
+	DeviceState     *pkg.DeviceState  `json:"device_state,omitempty"`
 	SensorsData     []SensorData      `json:"sensors_data,omitempty"`
 	NumZones        uint              `json:"num_zones"`
 	Links           []Link            `json:"links,omitempty"`
@@ -116,6 +117,8 @@ func (g *GardenResponse) Render(w http.ResponseWriter, r *http.Request) error {
 		},
 	)
 
+	g.DeviceState = g.api.worker.GetDeviceState(g.Garden)
+
 	logger, _ := babyapi.GetLoggerFromContext(ctx)
 
 	// By default, skip InfluxDB data fetching for fast page loads (lazy loading)
@@ -178,6 +178,27 @@ func TestCreateGarden(t *testing.T) {
 			`{"name":"test-garden","topic_prefix":"test-garden","id":"[0-9a-v]{20}","max_zones":2,"created_at":"2023-08-23T10:00:00Z","fan_schedule":{"duration":"30m","interval":"2h","power":50,"only_with_light":false},"next_fan_action":{"time":"2023-08-23T10:30:00Z","is_active":true},"health":{"status":"UP","details":"last contact from Garden was 0s ago","last_contact":"2023-08-23T10:00:00Z"},"num_zones":0,"links":\[{"rel":"self","href":"/gardens/[0-9a-v]{20}"},{"rel":"zones","href":"/gardens/[0-9a-v]{20}/zones"},{"rel":"action","href":"/gardens/[0-9a-v]{20}/action"},{"rel":"water_history","href":"/gardens/[0-9a-v]{20}/water_history"},{"rel":"controller_logs","href":"/gardens/[0-9a-v]{20}/controller-logs"}\]}`,
 			http.StatusCreated,
 		},
+		{
+			"SuccessfulWithDriver",
+			`{"name": "test-garden", "topic_prefix": "test-garden", "max_zones": 2, "driver": {"type": "http_relay", "options": {"url": "http://localhost", "zone_relays": [0, 1]}}}`,
+			false,
+			`{"name":"test-garden","topic_prefix":"test-garden","id":"[0-9a-v]{20}","max_zones":2,"created_at":"2023-08-23T10:00:00Z","driver":{"type":"http_relay","options":{"url":"http://localhost","zone_relays":\[0,1\]}},"health":{"status":"UP","details":"last contact from Garden was 0s ago","last_contact":"2023-08-23T10:00:00Z"},"num_zones":0,"links":\[{"rel":"self","href":"/gardens/[0-9a-v]{20}"},{"rel":"zones","href":"/gardens/[0-9a-v]{20}/zones"},{"rel":"action","href":"/gardens/[0-9a-v]{20}/action"},{"rel":"water_history","href":"/gardens/[0-9a-v]{20}/water_history"},{"rel":"controller_logs","href":"/gardens/[0-9a-v]{20}/controller-logs"}\]}`,
+			http.StatusCreated,
+		},
+		{
+			"ErrorInvalidDriverType",
+			`{"name": "test-garden", "topic_prefix": "test-garden", "max_zones": 2, "driver": {"type": "unknown"}}`,
+			false,
+			`{"status":"Invalid request.","error":"invalid driver.type \\"unknown\\""}`,
+			http.StatusBadRequest,
+		},
+		{
+			"ErrorInvalidDriverOptions",
+			`{"name": "test-garden", "topic_prefix": "test-garden", "max_zones": 2, "driver": {"type": "http_relay"}}`,
+			false,
+			`{"status":"Invalid request.","error":"invalid http_relay driver options: url must be provided"}`,
+			http.StatusBadRequest,
+		},
 		{
 			"ErrorNegativeMaxZones",
 			`{"name": "test-garden", "topic_prefix": "test-garden", "max_zones":-2, "light_schedule": {"duration": "15h", "start_time": "22:00:01-07:00"}}`,
@@ -680,6 +701,59 @@ func TestUpdateGarden(t *testing.T) {
 	}
 }
 
+func TestUpdateGardenClearsDesiredState(t *testing.T) {
+	_ = clock.MockTime()
+	t.Cleanup(clock.Reset)
+
+	lightTopic, err := mqtt.DesiredLightTopic("test-garden")
+	assert.NoError(t, err)
+
+	gardenWithoutLight := createExampleGarden()
+	gardenWithoutLight.LightSchedule = nil
+
+	tests := []struct {
+		name          string
+		garden        *pkg.Garden
+		body          string
+		status        int
+		expectCleared bool
+	}{
+		{"RemoveLightSchedule", createExampleGarden(), `{"light_schedule": {}}`, http.StatusOK, true},
+		{"UpdateWithoutSchedules", gardenWithoutLight, `{"name": "new name"}`, http.StatusOK, false},
+		{"InvalidRequest", createExampleGarden(), `{"light_schedule": {}, "driver": {"type": "unknown"}}`, http.StatusBadRequest, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			influxdbClient := new(influxdb.MockClient)
+			influxdbClient.On("GetLastContact", mock.Anything, "test-garden").Return(clock.Now(), nil)
+			mqttClient := new(mqtt.MockClient)
+			if tt.expectCleared {
+				mqttClient.On("PublishRetained", mock.Anything, lightTopic, []byte(nil)).Return(nil)
+			}
+
+			storageClient := setupZoneAndGardenStorage(t)
+			err := storageClient.Gardens.Set(context.Background(), tt.garden)
+			assert.NoError(t, err)
+
+			gr := NewGardenAPI()
+			w := worker.NewWorker(storageClient, influxdbClient, mqttClient, slog.Default(), worker.WithDesiredStateConfig(worker.DesiredStateConfig{Enable: true}))
+			err = gr.setup(Config{}, storageClient, influxdbClient, w)
+			assert.NoError(t, err)
+
+			r := httptest.NewRequest(http.MethodPatch, "/gardens/"+tt.garden.ID.String(), strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+			resp := babytest.TestRequest[*pkg.Garden](t, gr.API, r)
+
+			assert.Equal(t, tt.status, resp.Code, resp.Body.String())
+			mqttClient.AssertExpectations(t)
+			if !tt.expectCleared {
+				mqttClient.AssertNotCalled(t, "PublishRetained", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
 func TestGardenAction(t *testing.T) {
 	tests := []struct {
 		name      string
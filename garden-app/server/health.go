@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/go-chi/render"
+)
+
+const (
+	healthStatusOK       = "ok"
+	healthStatusDegraded = "degraded"
+)
+
+// HealthResponse is the response for the /health endpoint. The status is "degraded" if the server is not
+// connected to the MQTT broker or is missing subscriptions
+type HealthResponse struct {
+	Status string       `json:"status"`
+	MQTT   *mqtt.Status `json:"mqtt,omitempty"`
+}
+
+// Render responds with 503 Service Unavailable if the server is not healthy
+func (h *HealthResponse) Render(_ http.ResponseWriter, r *http.Request) error {
+	if h.Status != healthStatusOK {
+		render.Status(r, http.StatusServiceUnavailable)
+	}
+	return nil
+}
+
+func (api *API) handleHealth(_ http.ResponseWriter, _ *http.Request) render.Renderer {
+	response := &HealthResponse{Status: healthStatusOK}
+	if api.mqttClient == nil {
+		return response
+	}
+
+	status := api.mqttClient.Status()
+	response.MQTT = &status
+	if !status.Healthy() {
+		response.Status = healthStatusDegraded
+	}
+
+	return response
+}
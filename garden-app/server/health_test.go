@@ -0,0 +1,87 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	lastConnect := time.Date(2023, time.August, 23, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		mqttClient     func() mqtt.Client
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			"NoMQTTClient",
+			func() mqtt.Client { return nil },
+			http.StatusOK,
+			`{"status":"ok"}`,
+		},
+		{
+			"Healthy",
+			func() mqtt.Client {
+				mqttClient := new(mqtt.MockClient)
+				mqttClient.On("Status").Return(mqtt.Status{
+					Connected:     true,
+					LastConnect:   &lastConnect,
+					Subscriptions: []mqtt.SubscriptionStatus{{Topic: "+/data/water", Subscribed: true}},
+				})
+				return mqttClient
+			},
+			http.StatusOK,
+			`{"status":"ok","mqtt":{"connected":true,"last_connect":"2023-08-23T10:00:00Z","subscriptions":[{"topic":"+/data/water","subscribed":true}]}}`,
+		},
+		{
+			"SubscriptionFailed",
+			func() mqtt.Client {
+				mqttClient := new(mqtt.MockClient)
+				mqttClient.On("Status").Return(mqtt.Status{
+					Connected:     true,
+					LastConnect:   &lastConnect,
+					Subscriptions: []mqtt.SubscriptionStatus{{Topic: "+/data/water", Error: "not authorized"}},
+				})
+				return mqttClient
+			},
+			http.StatusServiceUnavailable,
+			`{"status":"degraded","mqtt":{"connected":true,"last_connect":"2023-08-23T10:00:00Z","subscriptions":[{"topic":"+/data/water","subscribed":false,"error":"not authorized"}]}}`,
+		},
+		{
+			"Disconnected",
+			func() mqtt.Client {
+				mqttClient := new(mqtt.MockClient)
+				mqttClient.On("Status").Return(mqtt.Status{
+					DisconnectedSince: &lastConnect,
+					LastError:         "connection refused",
+					Subscriptions:     []mqtt.SubscriptionStatus{},
+				})
+				return mqttClient
+			},
+			http.StatusServiceUnavailable,
+			`{"status":"degraded","mqtt":{"connected":false,"disconnected_since":"2023-08-23T10:00:00Z","last_error":"connection refused","subscriptions":[]}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := NewAPI()
+			api.mqttClient = tt.mqttClient()
+
+			r := httptest.NewRequest(http.MethodGet, "/health", http.NoBody)
+			w := httptest.NewRecorder()
+			babyapi.Handler(api.handleHealth).ServeHTTP(w, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedBody, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
@@ -63,7 +63,14 @@ func (o *OAuthStartResponse) Render(_ http.ResponseWriter, _ *http.Request) erro
 }
 
 // HTML implements the HTMLer interface for babyapi HTML rendering
-func (o *OAuthStartResponse) HTML(w http.ResponseWriter, _ *http.Request) string {
+func (o *OAuthStartResponse) HTML(w http.ResponseWriter, r *http.Request) string {
+	// Links from re-authorization notifications are opened directly in the browser instead of by HTMX, so
+	// they are redirected to Netatmo
+	if r.Header.Get("HX-Request") == "" {
+		http.Redirect(w, r, o.AuthURL, http.StatusFound)
+		return ""
+	}
+
 	// Set HX-Trigger header to trigger the OAuth popup via JavaScript
 	w.Header().Set("HX-Trigger", fmt.Sprintf(`{"openOAuthPopup": "%s"}`, o.AuthURL))
 	return ""
@@ -135,6 +142,11 @@ func (api *WeatherClientsAPI) handleOAuthCallback(_ http.ResponseWriter, r *http
 
 	logger.Debug("successfully authenticated with Netatmo", "weather_client_id", wcID)
 
+	// Check the new tokens right away so the re-authorization alert is cleared
+	if api.worker != nil {
+		api.worker.CheckWeatherClientAuth(r.Context(), wc)
+	}
+
 	return oauthCallbackTemplate.Renderer(&OAuthCallbackData{
 		Success: true,
 	})
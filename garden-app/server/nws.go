@@ -0,0 +1,38 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/nws"
+	"github.com/calvinmclean/babyapi"
+	"github.com/go-chi/render"
+)
+
+// getNWSStations finds the NWS observation stations nearest to the latitude and longitude from the request
+func (api *WeatherClientsAPI) getNWSStations(_ http.ResponseWriter, r *http.Request) render.Renderer {
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	latitude, err := strconv.ParseFloat(r.URL.Query().Get("Options.latitude"), 32)
+	if err != nil {
+		return babyapi.ErrInvalidRequest(errors.New("valid latitude is required"))
+	}
+
+	longitude, err := strconv.ParseFloat(r.URL.Query().Get("Options.longitude"), 32)
+	if err != nil {
+		return babyapi.ErrInvalidRequest(errors.New("valid longitude is required"))
+	}
+
+	stations, err := nws.FindStations(r.Context(), float32(latitude), float32(longitude))
+	if err != nil {
+		logger.Error("error fetching NWS stations", "error", err)
+		return babyapi.InternalServerError(fmt.Errorf("failed to fetch stations: %w", err))
+	}
+
+	return nwsStationsTemplate.Renderer(map[string]any{
+		"Stations":  stations,
+		"StationID": r.URL.Query().Get("Options.station_id"),
+	})
+}
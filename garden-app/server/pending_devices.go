@@ -0,0 +1,210 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
+	"github.com/calvinmclean/babyapi"
+	"github.com/go-chi/render"
+)
+
+// PendingDevicesAPI allows listing, dismissing, and adopting controllers that are publishing messages with a
+// topic prefix that doesn't belong to a Garden
+type PendingDevicesAPI struct {
+	storageClient *storage.Client
+	worker        *worker.Worker
+}
+
+// NewPendingDevicesAPI creates a new PendingDevicesAPI
+func NewPendingDevicesAPI() *PendingDevicesAPI {
+	return &PendingDevicesAPI{}
+}
+
+func (api *PendingDevicesAPI) setup(storageClient *storage.Client, worker *worker.Worker) {
+	api.storageClient = storageClient
+	api.worker = worker
+}
+
+// PendingDevicesResponse is the response for listing pending devices
+type PendingDevicesResponse struct {
+	Items []*pkg.PendingDevice `json:"items"`
+}
+
+// Render is used to implement render.Renderer
+func (*PendingDevicesResponse) Render(http.ResponseWriter, *http.Request) error {
+	return nil
+}
+
+// HTML renders the pending devices section of the Gardens page
+func (resp *PendingDevicesResponse) HTML(_ http.ResponseWriter, r *http.Request) string {
+	return pendingDevicesTemplate.Render(r, resp)
+}
+
+// AdoptPendingDeviceRequest allows overriding the defaults used when adopting a pending device. All fields are
+// optional
+type AdoptPendingDeviceRequest struct {
+	Name             string                `json:"name"`
+	MaxZones         *uint                 `json:"max_zones"`
+	ControllerConfig *pkg.ControllerConfig `json:"controller_config"`
+}
+
+// Bind is used to implement render.Binder
+func (*AdoptPendingDeviceRequest) Bind(*http.Request) error {
+	return nil
+}
+
+// AdoptPendingDeviceResponse is the response for adopting a pending device. It contains the new Garden
+type AdoptPendingDeviceResponse struct {
+	*pkg.Garden
+}
+
+// Render is used to implement render.Renderer
+func (*AdoptPendingDeviceResponse) Render(_ http.ResponseWriter, r *http.Request) error {
+	render.Status(r, http.StatusCreated)
+	return nil
+}
+
+// handleList returns all pending devices in the order they were first seen
+func (api *PendingDevicesAPI) handleList(_ http.ResponseWriter, r *http.Request) render.Renderer {
+	devices, err := api.storageClient.PendingDevices.List(r.Context())
+	if err != nil {
+		return babyapi.InternalServerError(err)
+	}
+	return &PendingDevicesResponse{Items: devices}
+}
+
+// handleDelete dismisses a pending device. It will be added again if the controller keeps publishing messages
+func (api *PendingDevicesAPI) handleDelete(w http.ResponseWriter, r *http.Request) render.Renderer {
+	device, errResp := api.getPendingDevice(r)
+	if errResp != nil {
+		return errResp
+	}
+
+	err := api.storageClient.PendingDevices.Delete(r.Context(), device.TopicPrefix)
+	if err != nil {
+		return babyapi.InternalServerError(fmt.Errorf("error deleting pending device: %w", err))
+	}
+
+	w.Header().Add("HX-Trigger", "pendingDevicesChanged")
+	render.NoContent(w, r)
+	return nil
+}
+
+// handleAdopt creates a new Garden for a pending device using its topic prefix and ControllerInfo. If a
+// ControllerConfig is provided, it is sent to the controller
+func (api *PendingDevicesAPI) handleAdopt(w http.ResponseWriter, r *http.Request) render.Renderer {
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	device, errResp := api.getPendingDevice(r)
+	if errResp != nil {
+		return errResp
+	}
+	logger = logger.With("topic_prefix", device.TopicPrefix)
+
+	req := &AdoptPendingDeviceRequest{}
+	if r.ContentLength > 0 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		err := render.Bind(r, req)
+		if err != nil {
+			return babyapi.ErrInvalidRequest(err)
+		}
+	}
+
+	_, err := api.storageClient.Gardens.GetByTopicPrefix(r.Context(), device.TopicPrefix)
+	switch {
+	case err == nil:
+		return babyapi.ErrInvalidRequest(fmt.Errorf("a Garden already exists with topic_prefix %q", device.TopicPrefix))
+	case !errors.Is(err, babyapi.ErrNotFound):
+		return babyapi.InternalServerError(err)
+	}
+
+	garden := newGardenForPendingDevice(device, req)
+	err = garden.Bind(&http.Request{Method: http.MethodPut})
+	if err != nil {
+		return babyapi.ErrInvalidRequest(err)
+	}
+
+	err = api.storageClient.Gardens.Set(r.Context(), garden)
+	if err != nil {
+		return babyapi.InternalServerError(fmt.Errorf("error storing Garden: %w", err))
+	}
+	logger = logger.With("garden_id", garden.GetID())
+	logger.Info("adopted pending device")
+
+	err = api.storageClient.ControllerInfo.Upsert(r.Context(), &pkg.ControllerInfo{
+		GardenID:        garden.GetID(),
+		MACAddress:      device.MACAddress,
+		IPAddress:       device.IPAddress,
+		FirmwareVersion: device.FirmwareVersion,
+		UpdatedAt:       device.LastSeen,
+	})
+	if err != nil {
+		return babyapi.InternalServerError(fmt.Errorf("error storing ControllerInfo: %w", err))
+	}
+
+	err = api.storageClient.PendingDevices.Delete(r.Context(), device.TopicPrefix)
+	if err != nil {
+		return babyapi.InternalServerError(fmt.Errorf("error deleting pending device: %w", err))
+	}
+
+	// The Garden is already created, so errors sending the config are only logged. It can be sent again using
+	// the Garden's update action
+	if garden.ControllerConfig != nil {
+		err = api.worker.ExecuteGardenAction(r.Context(), garden, &action.GardenAction{
+			Update: &action.UpdateAction{Config: true},
+		})
+		if err != nil {
+			logger.Error("unable to send config to adopted controller", "error", err)
+		}
+	}
+
+	err = api.worker.UpdateHomeAssistantDiscovery(r.Context(), garden.GetID())
+	if err != nil {
+		logger.Error("unable to update Home Assistant discovery", "error", err)
+	}
+
+	w.Header().Add("HX-Trigger", "newGarden")
+	return &AdoptPendingDeviceResponse{garden}
+}
+
+// newGardenForPendingDevice creates a Garden using the request's values. The name defaults to the topic prefix
+// and max_zones defaults to the number of valves in the ControllerConfig
+func newGardenForPendingDevice(device *pkg.PendingDevice, req *AdoptPendingDeviceRequest) *pkg.Garden {
+	garden := &pkg.Garden{
+		ID:               NewID(),
+		Name:             req.Name,
+		TopicPrefix:      device.TopicPrefix,
+		MaxZones:         req.MaxZones,
+		ControllerConfig: req.ControllerConfig,
+	}
+
+	if garden.Name == "" {
+		garden.Name = device.TopicPrefix
+	}
+
+	if garden.MaxZones == nil {
+		maxZones := uint(1)
+		if garden.ControllerConfig != nil && len(garden.ControllerConfig.ValvePins) > 0 {
+			maxZones = uint(len(garden.ControllerConfig.ValvePins))
+		}
+		garden.MaxZones = &maxZones
+	}
+
+	return garden
+}
+
+func (api *PendingDevicesAPI) getPendingDevice(r *http.Request) (*pkg.PendingDevice, *babyapi.ErrResponse) {
+	device, err := api.storageClient.PendingDevices.Get(r.Context(), r.PathValue("topic_prefix"))
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+	if device == nil {
+		return nil, babyapi.ErrNotFoundResponse
+	}
+	return device, nil
+}
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
+	"github.com/calvinmclean/babyapi"
+	babyhtml "github.com/calvinmclean/babyapi/html"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingDevicesAPI(t *testing.T) {
+	seenAt := time.Date(2023, time.August, 23, 10, 0, 0, 0, time.UTC)
+
+	setup := func(t *testing.T, mqttClient *mqtt.MockClient) (*PendingDevicesAPI, *storage.Client) {
+		t.Helper()
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, storageClient.PendingDevices.Upsert(context.Background(), &pkg.PendingDevice{
+			TopicPrefix:     "new-garden",
+			MACAddress:      "aa:bb:cc:dd:ee:ff",
+			IPAddress:       "192.168.1.42",
+			FirmwareVersion: "v1.0.0",
+			FirstSeen:       &seenAt,
+			LastSeen:        &seenAt,
+		}))
+
+		api := NewPendingDevicesAPI()
+		api.setup(storageClient, worker.NewWorker(storageClient, nil, mqttClient, slog.Default()))
+		return api, storageClient
+	}
+
+	t.Run("List", func(t *testing.T) {
+		api, _ := setup(t, nil)
+
+		r := httptest.NewRequest(http.MethodGet, "/pending_devices", http.NoBody)
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleList).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `{"items":[{"topic_prefix":"new-garden","mac_address":"aa:bb:cc:dd:ee:ff","ip_address":"192.168.1.42","firmware_version":"v1.0.0","first_seen":"2023-08-23T10:00:00Z","last_seen":"2023-08-23T10:00:00Z"}]}`, strings.TrimSpace(w.Body.String()))
+	})
+
+	t.Run("ListHTML", func(t *testing.T) {
+		babyapi.EnableHTMLRender()
+		babyhtml.SetFS(templates, "templates/*")
+		babyhtml.SetFuncs(templateFuncs)
+
+		api, _ := setup(t, nil)
+
+		r := httptest.NewRequest(http.MethodGet, "/pending_devices", http.NoBody)
+		r.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleList).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `hx-post="/pending_devices/new-garden/adopt"`)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		api, storageClient := setup(t, nil)
+
+		r := httptest.NewRequest(http.MethodDelete, "/pending_devices/new-garden", http.NoBody)
+		r.SetPathValue("topic_prefix", "new-garden")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleDelete).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		device, err := storageClient.PendingDevices.Get(context.Background(), "new-garden")
+		require.NoError(t, err)
+		assert.Nil(t, device)
+	})
+
+	t.Run("DeleteNotFound", func(t *testing.T) {
+		api, _ := setup(t, nil)
+
+		r := httptest.NewRequest(http.MethodDelete, "/pending_devices/other", http.NoBody)
+		r.SetPathValue("topic_prefix", "other")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleDelete).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("AdoptWithDefaults", func(t *testing.T) {
+		api, storageClient := setup(t, nil)
+
+		r := httptest.NewRequest(http.MethodPost, "/pending_devices/new-garden/adopt", http.NoBody)
+		r.SetPathValue("topic_prefix", "new-garden")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleAdopt).ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "newGarden", w.Header().Get("HX-Trigger"))
+
+		var resp pkg.Garden
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "new-garden", resp.Name)
+		assert.Equal(t, "new-garden", resp.TopicPrefix)
+		assert.Equal(t, uint(1), *resp.MaxZones)
+
+		garden, err := storageClient.Gardens.GetByTopicPrefix(context.Background(), "new-garden")
+		require.NoError(t, err)
+		require.NotNil(t, garden.ControllerInfo)
+		assert.Equal(t, "aa:bb:cc:dd:ee:ff", garden.ControllerInfo.MACAddress)
+		assert.Equal(t, "192.168.1.42", garden.ControllerInfo.IPAddress)
+		assert.Equal(t, "v1.0.0", garden.ControllerInfo.FirmwareVersion)
+
+		device, err := storageClient.PendingDevices.Get(context.Background(), "new-garden")
+		require.NoError(t, err)
+		assert.Nil(t, device)
+	})
+
+	t.Run("AdoptWithControllerConfig", func(t *testing.T) {
+		mqttClient := new(mqtt.MockClient)
+		mqttClient.On("Publish", mock.Anything, "new-garden/command/update_config", mock.Anything).Return(nil)
+		api, storageClient := setup(t, mqttClient)
+
+		body := `{"name":"Backyard","controller_config":{"valve_pins":[1,2,3],"pump_pins":[4,5,6]}}`
+		r := httptest.NewRequest(http.MethodPost, "/pending_devices/new-garden/adopt", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		r.SetPathValue("topic_prefix", "new-garden")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleAdopt).ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		garden, err := storageClient.Gardens.GetByTopicPrefix(context.Background(), "new-garden")
+		require.NoError(t, err)
+		assert.Equal(t, "Backyard", garden.Name)
+		assert.Equal(t, uint(3), *garden.MaxZones)
+		assert.Equal(t, []uint{1, 2, 3}, garden.ControllerConfig.ValvePins)
+
+		mqttClient.AssertExpectations(t)
+	})
+
+	t.Run("AdoptExistingTopicPrefix", func(t *testing.T) {
+		api, storageClient := setup(t, nil)
+
+		garden := createExampleGarden()
+		garden.TopicPrefix = "new-garden"
+		require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+		r := httptest.NewRequest(http.MethodPost, "/pending_devices/new-garden/adopt", http.NoBody)
+		r.SetPathValue("topic_prefix", "new-garden")
+		w := httptest.NewRecorder()
+		babyapi.Handler(api.handleAdopt).ServeHTTP(w, r)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, `{"status":"Invalid request.","error":"a Garden already exists with topic_prefix \"new-garden\""}`, strings.TrimSpace(w.Body.String()))
+	})
+}
@@ -0,0 +1,79 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/pws"
+	"github.com/calvinmclean/babyapi"
+	"github.com/go-chi/render"
+)
+
+var errInvalidPasskey = errors.New("invalid passkey")
+
+// ingestPWSReading stores a reading uploaded by a personal weather station. Weather Underground uploads use GET
+// with query parameters and Ecowitt uploads use POST with form values. Stations expect a plain text response
+func (api *WeatherClientsAPI) ingestPWSReading(w http.ResponseWriter, r *http.Request) {
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	weatherClient, httpErr := api.GetRequestedResource(r)
+	if httpErr != nil {
+		_ = render.Render(w, r, httpErr)
+		return
+	}
+
+	if !strings.EqualFold(weatherClient.Type, "pws") {
+		_ = render.Render(w, r, babyapi.ErrInvalidRequest(errors.New("WeatherClient is not a personal weather station")))
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		_ = render.Render(w, r, babyapi.ErrInvalidRequest(fmt.Errorf("error parsing upload: %w", err)))
+		return
+	}
+
+	client, err := pws.NewClient(weatherClient.GetID(), weatherClient.Options, api.storageClient.PWSReadings)
+	switch {
+	// Uploads are refused if the client's options don't have a passkey
+	case errors.Is(err, pws.ErrPasskeyRequired):
+		_ = render.Render(w, r, errUnauthorized(err))
+		return
+	case err != nil:
+		logger.Error("unable to create PWS client", "error", err)
+		_ = render.Render(w, r, babyapi.InternalServerError(err))
+		return
+	}
+
+	if !client.Authenticate(r.Form) {
+		_ = render.Render(w, r, errUnauthorized(errInvalidPasskey))
+		return
+	}
+
+	reading, err := pws.ParseReading(r.Form)
+	if err != nil {
+		_ = render.Render(w, r, babyapi.ErrInvalidRequest(err))
+		return
+	}
+
+	err = api.storageClient.PWSReadings.AddReading(r.Context(), weatherClient.GetID(), reading)
+	if err != nil {
+		logger.Error("unable to store PWS reading", "error", err)
+		_ = render.Render(w, r, babyapi.InternalServerError(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte("success"))
+}
+
+func errUnauthorized(err error) *babyapi.ErrResponse {
+	return &babyapi.ErrResponse{
+		HTTPStatusCode: http.StatusUnauthorized,
+		StatusText:     "Unauthorized",
+		ErrorText:      err.Error(),
+		Err:            err,
+	}
+}
@@ -26,7 +26,9 @@ const (
 	gardenCardTemplate                   html.Template = "GardenCard"
 	gardenDataSectionTemplate            html.Template = "gardenDataSection"
 	gardenSwapDataResponseTemplate       html.Template = "gardenSwapDataResponse"
+	sensorDetailsTemplate                html.Template = "sensorDetails"
 	gardenModalTemplate                  html.Template = "GardenModal"
+	pendingDevicesTemplate               html.Template = "PendingDevices"
 	zonesPageTemplate                    html.Template = "ZonesPage"
 	zonesTemplate                        html.Template = "Zones"
 	zoneCardTemplate                     html.Template = "ZoneCard"
@@ -45,6 +47,10 @@ const (
 	weatherClientNetatmoConfigTemplate   html.Template = "WeatherClientNetatmoConfig"
 	weatherClientFakeConfigTemplate      html.Template = "WeatherClientFakeConfig"
 	weatherClientOpenMeteoConfigTemplate html.Template = "WeatherClientOpenMeteoConfig"
+	weatherClientCompositeConfigTemplate html.Template = "WeatherClientCompositeConfig"
+	weatherClientNWSConfigTemplate       html.Template = "WeatherClientNWSConfig"
+	weatherClientPWSConfigTemplate       html.Template = "WeatherClientPWSConfig"
+	weatherClientSensorConfigTemplate    html.Template = "WeatherClientSensorConfig"
 	waterRoutinesPageTemplate            html.Template = "WaterRoutinesPage"
 	waterRoutinesTemplate                html.Template = "WaterRoutines"
 	waterRoutineModalTemplate            html.Template = "WaterRoutineModal"
@@ -64,6 +70,7 @@ const (
 	oauthCallbackTemplate   html.Template = "OAuthCallback"
 	netatmoStationsTemplate html.Template = "NetatmoStations"
 	netatmoModulesTemplate  html.Template = "NetatmoModules"
+	nwsStationsTemplate     html.Template = "NWSStations"
 
 	// Settings modal templates for notification clients
 	settingsModalTemplate             html.Template = "SettingsModal"
@@ -319,6 +326,22 @@ func templateFuncs(r *http.Request) map[string]any {
 			}
 			return result
 		},
+		"JoinList": func(input any) string {
+			switch v := input.(type) {
+			case []any:
+				result := make([]string, len(v))
+				for i, item := range v {
+					result[i] = fmt.Sprint(item)
+				}
+				return strings.Join(result, ",")
+			case []string:
+				return strings.Join(v, ",")
+			case nil:
+				return ""
+			default:
+				return fmt.Sprint(v)
+			}
+		},
 		"DerefUint": func(n *uint) uint {
 			if n == nil {
 				return 0
@@ -58,6 +58,18 @@ func NewWaterSchedulesAPI() *WaterSchedulesAPI {
 	})
 
 	api.SetOnCreateOrUpdate(api.onCreateOrUpdate)
+	api.SetAfterCreateOrUpdate(func(_ http.ResponseWriter, r *http.Request, ws *pkg.WaterSchedule) *babyapi.ErrResponse {
+		// A scale factor from previous WeatherControl shouldn't be used in ControllerSchedules
+		if !ws.HasWeatherControl() {
+			err := api.storageClient.WaterScaleFactors.Delete(r.Context(), ws.GetID())
+			if err != nil {
+				return babyapi.InternalServerError(fmt.Errorf("unable to delete weather scale factor: %w", err))
+			}
+		}
+
+		api.updateControllerSchedules(r, ws.GetID())
+		return nil
+	})
 
 	api.SetBeforeDelete(func(_ http.ResponseWriter, r *http.Request) *babyapi.ErrResponse {
 		id := api.GetIDParam(r)
@@ -85,6 +97,14 @@ func NewWaterSchedulesAPI() *WaterSchedulesAPI {
 			return babyapi.InternalServerError(fmt.Errorf("unable to remove scheduled WaterActions: %w", err))
 		}
 
+		err = api.storageClient.WaterScaleFactors.Delete(r.Context(), id)
+		if err != nil {
+			return babyapi.InternalServerError(fmt.Errorf("unable to delete weather scale factor: %w", err))
+		}
+
+		// Active Zones can't use a deleted WaterSchedule, but end-dated Zones might, so all Gardens are checked
+		api.worker.RefreshControllerSchedules()
+
 		return nil
 	})
 
@@ -144,6 +164,7 @@ func (api *WaterSchedulesAPI) waterScheduleModalRenderer(r *http.Request, ws *pk
 	slices.SortFunc(weatherClients, func(wc1 *weather.Config, wc2 *weather.Config) int {
 		return strings.Compare(wc1.Name, wc2.Name)
 	})
+	weather.ResolveCompositeMembers(weatherClients)
 
 	return waterScheduleModalTemplate.Renderer(struct {
 		*pkg.WaterSchedule
@@ -175,6 +196,32 @@ func (api *WaterSchedulesAPI) setup(storageClient *storage.Client, worker *worke
 	return nil
 }
 
+// updateControllerSchedules sends the ControllerSchedule to each Garden with Zones using the WaterSchedule. Errors are
+// only logged because the WaterSchedule was already saved
+func (api *WaterSchedulesAPI) updateControllerSchedules(r *http.Request, id string) {
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	zonesAndGardens, err := api.storageClient.GetZonesUsingWaterSchedule(id)
+	if err != nil {
+		logger.Error("unable to get Zones to update controller schedules", "error", err)
+		return
+	}
+
+	updated := map[string]bool{}
+	for _, zg := range zonesAndGardens {
+		gardenID := zg.Garden.GetID()
+		if updated[gardenID] {
+			continue
+		}
+		updated[gardenID] = true
+
+		err = api.worker.UpdateControllerSchedule(r.Context(), gardenID)
+		if err != nil {
+			logger.Error("unable to update controller schedule", "garden_id", gardenID, "error", err)
+		}
+	}
+}
+
 func (api *WaterSchedulesAPI) onCreateOrUpdate(_ http.ResponseWriter, r *http.Request, ws *pkg.WaterSchedule) *babyapi.ErrResponse {
 	// Validate the new WaterSchedule.WeatherControl
 	if ws.WeatherControl != nil {
@@ -189,22 +236,12 @@ func (api *WaterSchedulesAPI) onCreateOrUpdate(_ http.ResponseWriter, r *http.Re
 		// Convert imperial values to metric if user is using imperial units
 		if units.UnitSystem(getUnitsFromRequest(r)).IsImperial() {
 			if ws.WeatherControl.Rain != nil {
-				// Convert rain input range (inches to mm)
-				if ws.WeatherControl.Rain.InputMin != nil {
-					*ws.WeatherControl.Rain.InputMin = units.InchesToMm(*ws.WeatherControl.Rain.InputMin)
-				}
-				if ws.WeatherControl.Rain.InputMax != nil {
-					*ws.WeatherControl.Rain.InputMax = units.InchesToMm(*ws.WeatherControl.Rain.InputMax)
-				}
+				// Convert rain inputs (inches to mm)
+				convertScalerInputs(ws.WeatherControl.Rain, units.InchesToMm)
 			}
 			if ws.WeatherControl.Temperature != nil {
-				// Convert temperature input range (°F to °C)
-				if ws.WeatherControl.Temperature.InputMin != nil {
-					*ws.WeatherControl.Temperature.InputMin = units.FahrenheitToCelsius(*ws.WeatherControl.Temperature.InputMin)
-				}
-				if ws.WeatherControl.Temperature.InputMax != nil {
-					*ws.WeatherControl.Temperature.InputMax = units.FahrenheitToCelsius(*ws.WeatherControl.Temperature.InputMax)
-				}
+				// Convert temperature inputs (°F to °C)
+				convertScalerInputs(ws.WeatherControl.Temperature, units.FahrenheitToCelsius)
 			}
 		}
 
@@ -258,6 +295,20 @@ func (api *WaterSchedulesAPI) weatherClientsExist(ctx context.Context, ws *pkg.W
 		}
 	}
 
+	if ws.HasHumidityControl() {
+		err := api.weatherClientExists(ctx, ws.WeatherControl.Humidity.ClientID)
+		if err != nil {
+			return fmt.Errorf("error getting client for HumidityControl: %w", err)
+		}
+	}
+
+	if ws.HasSolarRadiationControl() {
+		err := api.weatherClientExists(ctx, ws.WeatherControl.SolarRadiation.ClientID)
+		if err != nil {
+			return fmt.Errorf("error getting client for SolarRadiationControl: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -316,55 +367,34 @@ func (api *WaterSchedulesAPI) scalingExample(_ http.ResponseWriter, r *http.Requ
 	}
 
 	// Parse rain scaling configuration
-	rainInputMin := parseFormFloat(r, "WeatherControl.Rain.InputMin")
-	rainInputMax := parseFormFloat(r, "WeatherControl.Rain.InputMax")
-	rainFactorMin := parseFormFloat(r, "WeatherControl.Rain.FactorMin")
-	rainFactorMax := parseFormFloat(r, "WeatherControl.Rain.FactorMax")
-	rainInterpolation := r.FormValue("WeatherControl.Rain.Interpolation")
-
-	// If rain scaling is configured, generate examples
-	if rainInputMin != nil && rainInputMax != nil && rainFactorMin != nil && rainFactorMax != nil {
+	rainScaler := parseFormScaler(r, "WeatherControl.Rain")
+	if rainScaler != nil {
 		// Convert imperial to metric if needed (form values are in user units)
 		if isImperial {
-			*rainInputMin = units.InchesToMm(*rainInputMin)
-			*rainInputMax = units.InchesToMm(*rainInputMax)
+			convertScalerInputs(rainScaler, units.InchesToMm)
 		}
-
-		scaler := &weather.WeatherScaler{
-			Interpolation: weather.InterpolationMode(rainInterpolation),
-			InputMin:      rainInputMin,
-			InputMax:      rainInputMax,
-			FactorMin:     rainFactorMin,
-			FactorMax:     rainFactorMax,
-		}
-
-		response.RainExamples = generateScalingExamples(scaler, effectiveBaseDuration, isImperial, true)
+		response.RainExamples = generateScalingExamples(rainScaler, effectiveBaseDuration, rainExampleUnits(isImperial))
 	}
 
 	// Parse temperature scaling configuration
-	tempInputMin := parseFormFloat(r, "WeatherControl.Temperature.InputMin")
-	tempInputMax := parseFormFloat(r, "WeatherControl.Temperature.InputMax")
-	tempFactorMin := parseFormFloat(r, "WeatherControl.Temperature.FactorMin")
-	tempFactorMax := parseFormFloat(r, "WeatherControl.Temperature.FactorMax")
-	tempInterpolation := r.FormValue("WeatherControl.Temperature.Interpolation")
-
-	// If temperature scaling is configured, generate examples
-	if tempInputMin != nil && tempInputMax != nil && tempFactorMin != nil && tempFactorMax != nil {
+	tempScaler := parseFormScaler(r, "WeatherControl.Temperature")
+	if tempScaler != nil {
 		// Convert imperial to metric if needed (form values are in user units)
 		if isImperial {
-			*tempInputMin = units.FahrenheitToCelsius(*tempInputMin)
-			*tempInputMax = units.FahrenheitToCelsius(*tempInputMax)
+			convertScalerInputs(tempScaler, units.FahrenheitToCelsius)
 		}
+		response.TemperatureExamples = generateScalingExamples(tempScaler, effectiveBaseDuration, temperatureExampleUnits(isImperial))
+	}
 
-		scaler := &weather.WeatherScaler{
-			Interpolation: weather.InterpolationMode(tempInterpolation),
-			InputMin:      tempInputMin,
-			InputMax:      tempInputMax,
-			FactorMin:     tempFactorMin,
-			FactorMax:     tempFactorMax,
-		}
+	// Humidity and solar radiation use the same units regardless of the unit system
+	humidityScaler := parseFormScaler(r, "WeatherControl.Humidity")
+	if humidityScaler != nil {
+		response.HumidityExamples = generateScalingExamples(humidityScaler, effectiveBaseDuration, fixedExampleUnits("%"))
+	}
 
-		response.TemperatureExamples = generateScalingExamples(scaler, effectiveBaseDuration, isImperial, false)
+	solarRadiationScaler := parseFormScaler(r, "WeatherControl.SolarRadiation")
+	if solarRadiationScaler != nil {
+		response.SolarRadiationExamples = generateScalingExamples(solarRadiationScaler, effectiveBaseDuration, fixedExampleUnits("MJ/m²"))
 	}
 
 	return response
@@ -410,6 +440,59 @@ func (api *WaterSchedulesAPI) calculateETDuration(ctx context.Context, etClientI
 	return etDuration, etValue
 }
 
+// parseFormScaler parses a WeatherScaler from form data using the field prefix.
+// It returns nil if the scaling configuration is incomplete
+func parseFormScaler(r *http.Request, prefix string) *weather.WeatherScaler {
+	scaler := &weather.WeatherScaler{
+		Interpolation: weather.InterpolationMode(r.FormValue(prefix + ".Interpolation")),
+	}
+
+	if scaler.IsCurve() {
+		scaler.Points = parseFormCurvePoints(r, prefix+".Points")
+		if len(scaler.Points) == 0 {
+			return nil
+		}
+		return scaler
+	}
+
+	scaler.InputMin = parseFormFloat(r, prefix+".InputMin")
+	scaler.InputMax = parseFormFloat(r, prefix+".InputMax")
+	scaler.FactorMin = parseFormFloat(r, prefix+".FactorMin")
+	scaler.FactorMax = parseFormFloat(r, prefix+".FactorMax")
+	if scaler.InputMin == nil || scaler.InputMax == nil || scaler.FactorMin == nil || scaler.FactorMax == nil {
+		return nil
+	}
+	return scaler
+}
+
+// parseFormCurvePoints parses indexed curve points (prefix.0.Input, prefix.0.Factor, ...) from form data.
+// Parsing stops at the first index that is missing or incomplete
+func parseFormCurvePoints(r *http.Request, prefix string) []weather.CurvePoint {
+	var points []weather.CurvePoint
+	for i := 0; ; i++ {
+		input := parseFormFloat(r, fmt.Sprintf("%s.%d.Input", prefix, i))
+		factor := parseFormFloat(r, fmt.Sprintf("%s.%d.Factor", prefix, i))
+		if input == nil || factor == nil {
+			return points
+		}
+		points = append(points, weather.CurvePoint{Input: *input, Factor: *factor})
+	}
+}
+
+// convertScalerInputs converts the input range and curve point inputs of a WeatherScaler from
+// the user's units to metric
+func convertScalerInputs(scaler *weather.WeatherScaler, convert func(float64) float64) {
+	if scaler.InputMin != nil {
+		*scaler.InputMin = convert(*scaler.InputMin)
+	}
+	if scaler.InputMax != nil {
+		*scaler.InputMax = convert(*scaler.InputMax)
+	}
+	for i := range scaler.Points {
+		scaler.Points[i].Input = convert(scaler.Points[i].Input)
+	}
+}
+
 // parseFormFloat parses a float64 from form data
 func parseFormFloat(r *http.Request, name string) *float64 {
 	valueStr := r.FormValue(name)
@@ -437,40 +520,21 @@ func parseFormDuration(r *http.Request, name string, defaultValue time.Duration)
 	return value
 }
 
-// generateScalingExamples creates 5 sample points across the input range
-func generateScalingExamples(scaler *weather.WeatherScaler, baseDuration time.Duration, isImperial bool, isRain bool) []ScalingExamplePoint {
-	if scaler.InputMin == nil || scaler.InputMax == nil {
+// generateScalingExamples creates 5 sample points across the input range. Curves use each
+// point and the midpoint between each pair of points instead
+func generateScalingExamples(scaler *weather.WeatherScaler, baseDuration time.Duration, displayUnits scalingExampleUnits) []ScalingExamplePoint {
+	inputValues := scalingExampleInputs(scaler)
+	if len(inputValues) == 0 {
 		return nil
 	}
 
-	inputMin := *scaler.InputMin
-	inputMax := *scaler.InputMax
-	inputRange := inputMax - inputMin
-
-	// Generate 5 sample points: min, 25%, 50%, 75%, max
-	percentages := []float64{0.0, 0.25, 0.5, 0.75, 1.0}
-	examples := make([]ScalingExamplePoint, len(percentages))
+	examples := make([]ScalingExamplePoint, len(inputValues))
 
-	for i, pct := range percentages {
-		inputValue := inputMin + (inputRange * pct)
+	for i, inputValue := range inputValues {
 		scaleFactor := scaler.Scale(inputValue)
 
 		// Convert to user units for display
-		displayValue := inputValue
-		unit := "mm"
-		if isRain {
-			if isImperial {
-				displayValue = units.MmToInches(inputValue)
-				unit = "in"
-			}
-		} else {
-			if isImperial {
-				displayValue = units.CelsiusToFahrenheit(inputValue)
-				unit = "°F"
-			} else {
-				unit = "°C"
-			}
-		}
+		displayValue, unit := displayUnits(inputValue)
 
 		examples[i] = ScalingExamplePoint{
 			InputValue:  displayValue,
@@ -487,3 +551,60 @@ func generateScalingExamples(scaler *weather.WeatherScaler, baseDuration time.Du
 
 	return examples
 }
+
+// scalingExampleUnits converts a metric scaler input to the value and unit displayed to the user
+type scalingExampleUnits func(float64) (float64, string)
+
+func rainExampleUnits(isImperial bool) scalingExampleUnits {
+	return func(mm float64) (float64, string) {
+		if isImperial {
+			return units.MmToInches(mm), "in"
+		}
+		return mm, "mm"
+	}
+}
+
+func temperatureExampleUnits(isImperial bool) scalingExampleUnits {
+	return func(celsius float64) (float64, string) {
+		if isImperial {
+			return units.CelsiusToFahrenheit(celsius), "°F"
+		}
+		return celsius, "°C"
+	}
+}
+
+// fixedExampleUnits is used for inputs that are the same in metric and imperial units
+func fixedExampleUnits(unit string) scalingExampleUnits {
+	return func(value float64) (float64, string) {
+		return value, unit
+	}
+}
+
+// scalingExampleInputs returns the metric input values used to generate scaling examples
+func scalingExampleInputs(scaler *weather.WeatherScaler) []float64 {
+	if scaler.IsCurve() {
+		var inputs []float64
+		for i, p := range scaler.Points {
+			if i > 0 {
+				inputs = append(inputs, (scaler.Points[i-1].Input+p.Input)/2)
+			}
+			inputs = append(inputs, p.Input)
+		}
+		return inputs
+	}
+
+	if scaler.InputMin == nil || scaler.InputMax == nil {
+		return nil
+	}
+
+	inputMin := *scaler.InputMin
+	inputRange := *scaler.InputMax - inputMin
+
+	// Generate 5 sample points: min, 25%, 50%, 75%, max
+	percentages := []float64{0.0, 0.25, 0.5, 0.75, 1.0}
+	inputs := make([]float64, len(percentages))
+	for i, pct := range percentages {
+		inputs[i] = inputMin + (inputRange * pct)
+	}
+	return inputs
+}
@@ -127,14 +127,16 @@ type ScalingExamplePoint struct {
 	Duration    string  `json:"duration,omitempty"`
 }
 
-// ScalingExampleResponse contains the scaling preview data for both rain and temperature
+// ScalingExampleResponse contains the scaling preview data for each configured weather scaler
 type ScalingExampleResponse struct {
-	RainExamples        []ScalingExamplePoint `json:"rain_examples,omitempty"`
-	TemperatureExamples []ScalingExamplePoint `json:"temperature_examples,omitempty"`
-	BaseDuration        string                `json:"base_duration"`
-	ETDuration          string                `json:"et_duration,omitempty"`
-	ETValue             float32               `json:"et_value,omitempty"`
-	ETConfigured        bool                  `json:"et_configured"`
+	RainExamples           []ScalingExamplePoint `json:"rain_examples,omitempty"`
+	TemperatureExamples    []ScalingExamplePoint `json:"temperature_examples,omitempty"`
+	HumidityExamples       []ScalingExamplePoint `json:"humidity_examples,omitempty"`
+	SolarRadiationExamples []ScalingExamplePoint `json:"solar_radiation_examples,omitempty"`
+	BaseDuration           string                `json:"base_duration"`
+	ETDuration             string                `json:"et_duration,omitempty"`
+	ETValue                float32               `json:"et_value,omitempty"`
+	ETConfigured           bool                  `json:"et_configured"`
 }
 
 func (ser ScalingExampleResponse) Render(w http.ResponseWriter, r *http.Request) error {
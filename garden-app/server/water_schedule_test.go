@@ -16,6 +16,7 @@ import (
 	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/units"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
 	"github.com/calvinmclean/automated-garden/garden-app/worker"
 	"github.com/calvinmclean/babyapi"
@@ -944,3 +945,122 @@ func TestStartDateBackwardCompatibility(t *testing.T) {
 		assert.Contains(t, string(output), `"start_date":"2024-06-20"`)
 	})
 }
+
+func TestScalingExample(t *testing.T) {
+	tests := []struct {
+		name                string
+		form                string
+		imperial            bool
+		expectedRain        []ScalingExamplePoint
+		expectedTemperature []ScalingExamplePoint
+	}{
+		{
+			"RainRange",
+			"Duration=1h&WeatherControl.Rain.Interpolation=linear&WeatherControl.Rain.InputMin=0&WeatherControl.Rain.InputMax=20&WeatherControl.Rain.FactorMin=1&WeatherControl.Rain.FactorMax=0",
+			false,
+			[]ScalingExamplePoint{
+				{InputValue: 0, InputUnit: "mm", ScaleFactor: 1, Duration: "1h"},
+				{InputValue: 5, InputUnit: "mm", ScaleFactor: 0.75, Duration: "45m"},
+				{InputValue: 10, InputUnit: "mm", ScaleFactor: 0.5, Duration: "30m"},
+				{InputValue: 15, InputUnit: "mm", ScaleFactor: 0.25, Duration: "15m"},
+				{InputValue: 20, InputUnit: "mm", ScaleFactor: 0, Duration: "0s"},
+			},
+			nil,
+		},
+		{
+			"TemperatureCurve",
+			"Duration=1h&WeatherControl.Temperature.Interpolation=curve" +
+				"&WeatherControl.Temperature.Points.0.Input=20&WeatherControl.Temperature.Points.0.Factor=0.5" +
+				"&WeatherControl.Temperature.Points.1.Input=30&WeatherControl.Temperature.Points.1.Factor=1" +
+				"&WeatherControl.Temperature.Points.2.Input=40&WeatherControl.Temperature.Points.2.Factor=2",
+			false,
+			nil,
+			[]ScalingExamplePoint{
+				{InputValue: 20, InputUnit: "°C", ScaleFactor: 0.5, Duration: "30m"},
+				{InputValue: 25, InputUnit: "°C", ScaleFactor: 0.75, Duration: "45m"},
+				{InputValue: 30, InputUnit: "°C", ScaleFactor: 1, Duration: "1h"},
+				{InputValue: 35, InputUnit: "°C", ScaleFactor: 1.5, Duration: "1h30m"},
+				{InputValue: 40, InputUnit: "°C", ScaleFactor: 2, Duration: "2h"},
+			},
+		},
+		{
+			"RainCurveImperial",
+			"WeatherControl.Rain.Interpolation=curve" +
+				"&WeatherControl.Rain.Points.0.Input=0&WeatherControl.Rain.Points.0.Factor=1" +
+				"&WeatherControl.Rain.Points.1.Input=1&WeatherControl.Rain.Points.1.Factor=0",
+			true,
+			[]ScalingExamplePoint{
+				{InputValue: 0, InputUnit: "in", ScaleFactor: 1},
+				{InputValue: 0.5, InputUnit: "in", ScaleFactor: 0.5},
+				{InputValue: 1, InputUnit: "in", ScaleFactor: 0},
+			},
+			nil,
+		},
+		{
+			"IncompleteCurve",
+			"WeatherControl.Rain.Interpolation=curve&WeatherControl.Rain.Points.0.Input=0",
+			false,
+			nil,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/water_schedules/scaling_example", strings.NewReader(tt.form))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if tt.imperial {
+				r = r.WithContext(context.WithValue(r.Context(), unitsContextKey{}, string(units.Imperial)))
+			}
+
+			api := &WaterSchedulesAPI{}
+			response, ok := api.scalingExample(nil, r).(ScalingExampleResponse)
+			require.True(t, ok)
+
+			assertScalingExamples(t, tt.expectedRain, response.RainExamples)
+			assertScalingExamples(t, tt.expectedTemperature, response.TemperatureExamples)
+		})
+	}
+}
+
+func TestScalingExampleHumidityAndSolarRadiation(t *testing.T) {
+	form := "Duration=1h" +
+		"&WeatherControl.Humidity.Interpolation=linear&WeatherControl.Humidity.InputMin=20&WeatherControl.Humidity.InputMax=80" +
+		"&WeatherControl.Humidity.FactorMin=1.5&WeatherControl.Humidity.FactorMax=0.5" +
+		"&WeatherControl.SolarRadiation.Interpolation=curve" +
+		"&WeatherControl.SolarRadiation.Points.0.Input=10&WeatherControl.SolarRadiation.Points.0.Factor=0.5" +
+		"&WeatherControl.SolarRadiation.Points.1.Input=30&WeatherControl.SolarRadiation.Points.1.Factor=1.5"
+
+	r := httptest.NewRequest(http.MethodPost, "/water_schedules/scaling_example", strings.NewReader(form))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// Imperial units do not change humidity or solar radiation inputs
+	r = r.WithContext(context.WithValue(r.Context(), unitsContextKey{}, string(units.Imperial)))
+
+	api := &WaterSchedulesAPI{}
+	response, ok := api.scalingExample(nil, r).(ScalingExampleResponse)
+	require.True(t, ok)
+
+	assertScalingExamples(t, []ScalingExamplePoint{
+		{InputValue: 20, InputUnit: "%", ScaleFactor: 1.5, Duration: "1h30m"},
+		{InputValue: 35, InputUnit: "%", ScaleFactor: 1.25, Duration: "1h15m"},
+		{InputValue: 50, InputUnit: "%", ScaleFactor: 1, Duration: "1h"},
+		{InputValue: 65, InputUnit: "%", ScaleFactor: 0.75, Duration: "45m"},
+		{InputValue: 80, InputUnit: "%", ScaleFactor: 0.5, Duration: "30m"},
+	}, response.HumidityExamples)
+	assertScalingExamples(t, []ScalingExamplePoint{
+		{InputValue: 10, InputUnit: "MJ/m²", ScaleFactor: 0.5, Duration: "30m"},
+		{InputValue: 20, InputUnit: "MJ/m²", ScaleFactor: 1, Duration: "1h"},
+		{InputValue: 30, InputUnit: "MJ/m²", ScaleFactor: 1.5, Duration: "1h30m"},
+	}, response.SolarRadiationExamples)
+}
+
+func assertScalingExamples(t *testing.T, expected, actual []ScalingExamplePoint) {
+	t.Helper()
+	require.Len(t, actual, len(expected))
+	for i := range expected {
+		assert.InDelta(t, expected[i].InputValue, actual[i].InputValue, 0.0001)
+		assert.InDelta(t, expected[i].ScaleFactor, actual[i].ScaleFactor, 0.0001)
+		assert.Equal(t, expected[i].InputUnit, actual[i].InputUnit)
+		assert.Equal(t, expected[i].Duration, actual[i].Duration)
+	}
+}
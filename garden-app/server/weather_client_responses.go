@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/units"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
@@ -14,16 +15,62 @@ import (
 
 type WeatherClientTestResponse struct {
 	WeatherData
+
+	// Responders has the IDs of the member clients that provided each value for composite clients
+	Responders *WeatherDataResponders `json:"responders,omitempty"`
+}
+
+// WeatherDataResponders has the IDs of the composite member clients that provided each value in WeatherData
+type WeatherDataResponders struct {
+	Rain               []string `json:"rain,omitempty"`
+	Temperature        []string `json:"temperature,omitempty"`
+	Evapotranspiration []string `json:"evapotranspiration,omitempty"`
+	Humidity           []string `json:"humidity,omitempty"`
+	SolarRadiation     []string `json:"solar_radiation,omitempty"`
+}
+
+// newWeatherDataResponders gets the composite member clients that provided the most recent responses for
+// the duration. It returns nil for other client types
+func newWeatherDataResponders(weatherClient *weather.Config, duration time.Duration) *WeatherDataResponders {
+	if len(weather.CompositeMemberIDs(weatherClient)) == 0 {
+		return nil
+	}
+
+	return &WeatherDataResponders{
+		Rain:               weather.CompositeResponders(weatherClient, "GetTotalRain", duration),
+		Temperature:        weather.CompositeResponders(weatherClient, "GetAverageHighTemperature", duration),
+		Evapotranspiration: weather.CompositeResponders(weatherClient, "GetAverageEvapotranspiration", duration),
+		Humidity:           weather.CompositeResponders(weatherClient, "GetAverageHumidity", duration),
+		SolarRadiation:     weather.CompositeResponders(weatherClient, "GetAverageSolarRadiation", duration),
+	}
 }
 
 func (resp *WeatherClientTestResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
 	return nil
 }
 
+// WeatherHistoryResponse contains the daily weather observations for a WeatherClient
+type WeatherHistoryResponse struct {
+	WeatherClientID string                     `json:"weather_client_id"`
+	Start           string                     `json:"start"`
+	End             string                     `json:"end"`
+	Observations    []weather.DailyObservation `json:"observations"`
+}
+
+func (resp *WeatherHistoryResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	return nil
+}
+
 type WeatherClientResponse struct {
 	*weather.Config
 	WeatherData *WeatherData `json:"weather_data,omitempty"`
 
+	// AuthStatus is the result of the last background token check for clients that use OAuth
+	AuthStatus *weather.AuthStatus `json:"auth_status,omitempty"`
+
+	// Health contains the recent results of requests to the weather client's API
+	Health *weather.Health `json:"health,omitempty"`
+
 	Links []Link `json:"links,omitempty"`
 
 	api *WeatherClientsAPI
@@ -47,6 +94,14 @@ func (resp *WeatherClientResponse) Render(w http.ResponseWriter, r *http.Request
 	swapData := r.URL.Query().Get("swap_data") == "true"
 	shouldFetchWeather := !isHTML || swapData
 
+	if resp.api != nil && resp.api.storageClient != nil && resp.Config != nil && resp.HasTokenRefresh() {
+		authStatus, err := resp.api.storageClient.WeatherClientHealth.GetAuthStatus(r.Context(), resp.GetID())
+		if err != nil {
+			return fmt.Errorf("error getting weather client auth status: %w", err)
+		}
+		resp.AuthStatus = authStatus
+	}
+
 	if resp.api != nil && resp.Config != nil && shouldFetchWeather {
 		units := getUnitsFromRequest(r)
 		duration := getDurationFromRequest(r)
@@ -56,6 +111,15 @@ func (resp *WeatherClientResponse) Render(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	// Health is checked after getting weather data so it includes the result of this request
+	if resp.api != nil && resp.api.storageClient != nil && resp.Config != nil {
+		health, err := resp.api.storageClient.WeatherClientHealth.Get(r.Context(), resp.GetID())
+		if err != nil {
+			return fmt.Errorf("error getting weather client health: %w", err)
+		}
+		resp.Health = health
+	}
+
 	if isHTML && r.Method == http.MethodPut {
 		w.Header().Add("HX-Trigger", "newWeatherClient")
 	}
@@ -70,6 +134,8 @@ func (resp *WeatherClientResponse) HTML(_ http.ResponseWriter, r *http.Request)
 	data := map[string]any{
 		"Config":      resp.Config,
 		"WeatherData": resp.WeatherData,
+		"AuthStatus":  resp.AuthStatus,
+		"Health":      resp.Health,
 		"Units":       userUnits,
 		"Duration":    duration,
 		"IsMetric":    units.UnitSystem(userUnits).IsMetric(),
@@ -5,12 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"iter"
+	"maps"
 	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
 	unitspkg "github.com/calvinmclean/automated-garden/garden-app/pkg/units"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
 	"github.com/calvinmclean/babyapi"
 	"github.com/calvinmclean/babyapi/extensions"
 	"github.com/go-chi/render"
@@ -19,6 +26,9 @@ import (
 const (
 	weatherClientsBasePath  = "/weather_clients"
 	weatherClientIDLogField = "weather_client_id"
+
+	defaultWeatherHistoryDays = 30
+	maxWeatherHistoryDays     = 366
 )
 
 // WeatherClientsAPI encapsulates the structs and dependencies necessary for the WeatherClients API
@@ -27,6 +37,7 @@ type WeatherClientsAPI struct {
 	*babyapi.API[*weather.Config]
 
 	storageClient   *storage.Client
+	worker          *worker.Worker
 	oauthStateCache *OAuthStateCache
 }
 
@@ -54,6 +65,11 @@ func NewWeatherClientsAPI() *WeatherClientsAPI {
 	})
 
 	api.AddCustomIDRoute(http.MethodGet, "/test", babyapi.Handler(api.testWeatherClient))
+	api.AddCustomIDRoute(http.MethodGet, "/history", api.GetRequestedResourceAndDo(api.getWeatherHistory))
+
+	// Upload routes for personal weather stations using the Weather Underground (GET) or Ecowitt (POST) protocol
+	api.AddCustomIDRoute(http.MethodGet, "/pws", http.HandlerFunc(api.ingestPWSReading))
+	api.AddCustomIDRoute(http.MethodPost, "/pws", http.HandlerFunc(api.ingestPWSReading))
 
 	// OAuth routes for Netatmo
 	api.AddCustomIDRoute(http.MethodGet, "/netatmo/oauth/start", api.GetRequestedResourceAndDo(api.startOAuth))
@@ -63,6 +79,10 @@ func NewWeatherClientsAPI() *WeatherClientsAPI {
 	api.AddCustomIDRoute(http.MethodGet, "/netatmo/stations", api.GetRequestedResourceAndDo(api.getNetatmoStations))
 	api.AddCustomIDRoute(http.MethodGet, "/netatmo/modules", api.GetRequestedResourceAndDo(api.getNetatmoModules))
 
+	// NWS station selection endpoint. This doesn't use an ID because stations are selected before creating
+	// the WeatherClient
+	api.AddCustomRoute(http.MethodGet, "/nws/stations", babyapi.Handler(api.getNWSStations))
+
 	api.AddCustomRoute(http.MethodGet, "/components", babyapi.Handler(func(_ http.ResponseWriter, r *http.Request) render.Renderer {
 		switch r.URL.Query().Get("type") {
 		case "create_modal":
@@ -87,6 +107,26 @@ func NewWeatherClientsAPI() *WeatherClientsAPI {
 					Type:    "fake",
 					Options: map[string]any{},
 				})
+			case "composite":
+				return weatherClientCompositeConfigTemplate.Renderer(&weather.Config{
+					Type:    "composite",
+					Options: map[string]any{},
+				})
+			case "nws":
+				return weatherClientNWSConfigTemplate.Renderer(&weather.Config{
+					Type:    "nws",
+					Options: map[string]any{},
+				})
+			case "pws":
+				return weatherClientPWSConfigTemplate.Renderer(&weather.Config{
+					Type:    "pws",
+					Options: map[string]any{},
+				})
+			case "sensor":
+				return weatherClientSensorConfigTemplate.Renderer(&weather.Config{
+					Type:    "sensor",
+					Options: map[string]any{},
+				})
 			default:
 				return babyapi.ErrInvalidRequest(fmt.Errorf("invalid Type: %s", weatherType))
 			}
@@ -108,6 +148,14 @@ func NewWeatherClientsAPI() *WeatherClientsAPI {
 				return weatherClientFakeConfigTemplate.Renderer(wc), nil
 			case "openmeteo":
 				return weatherClientOpenMeteoConfigTemplate.Renderer(wc), nil
+			case "composite":
+				return weatherClientCompositeConfigTemplate.Renderer(wc), nil
+			case "nws":
+				return weatherClientNWSConfigTemplate.Renderer(wc), nil
+			case "pws":
+				return weatherClientPWSConfigTemplate.Renderer(wc), nil
+			case "sensor":
+				return weatherClientSensorConfigTemplate.Renderer(wc), nil
 			default:
 				return nil, babyapi.ErrInvalidRequest(fmt.Errorf("invalid Type: %s", wc.Type))
 			}
@@ -128,6 +176,23 @@ func NewWeatherClientsAPI() *WeatherClientsAPI {
 			return babyapi.ErrInvalidRequest(fmt.Errorf("unable to delete WeatherClient used by %d WaterSchedules", len(waterSchedules)))
 		}
 
+		composites, err := api.compositesUsingWeatherClient(r.Context(), id)
+		if err != nil {
+			return babyapi.InternalServerError(fmt.Errorf("unable to get composite WeatherClients using WeatherClient %q: %w", id, err))
+		}
+
+		if len(composites) > 0 {
+			return babyapi.ErrInvalidRequest(fmt.Errorf("unable to delete WeatherClient used by composite WeatherClients: %s", strings.Join(composites, ", ")))
+		}
+
+		return nil
+	})
+
+	api.SetAfterDelete(func(_ http.ResponseWriter, r *http.Request) *babyapi.ErrResponse {
+		err := api.clearWeatherHistory(r.Context(), api.GetIDParam(r))
+		if err != nil {
+			return babyapi.InternalServerError(err)
+		}
 		return nil
 	})
 
@@ -138,8 +203,23 @@ func NewWeatherClientsAPI() *WeatherClientsAPI {
 	return api
 }
 
-func (api *WeatherClientsAPI) setup(storageClient *storage.Client) {
+// compositesUsingWeatherClient returns the names of composite WeatherClients that include the WeatherClient
+func (api *WeatherClientsAPI) compositesUsingWeatherClient(ctx context.Context, id string) ([]string, error) {
+	names := []string{}
+	for wc, err := range api.storageClient.WeatherClientConfigs.Search(ctx, "", nil) {
+		if err != nil {
+			return nil, err
+		}
+		if slices.Contains(weather.CompositeMemberIDs(wc), id) {
+			names = append(names, wc.Name)
+		}
+	}
+	return names, nil
+}
+
+func (api *WeatherClientsAPI) setup(storageClient *storage.Client, worker *worker.Worker) {
 	api.storageClient = storageClient
+	api.worker = worker
 	api.oauthStateCache = NewOAuthStateCache()
 
 	api.SetStorage(api.storageClient.WeatherClientConfigs)
@@ -212,11 +292,48 @@ func (api *WeatherClientsAPI) onCreateOrUpdate(_ http.ResponseWriter, r *http.Re
 	}
 
 	// make sure a valid WeatherClient can still be created
-	_, err := weather.NewClient(wc, func(map[string]any) error { return nil })
+	_, err := weather.NewClient(wc, func(map[string]any) error { return nil }, api.storageClient.WeatherClientOptions()...)
 	if err != nil {
 		return babyapi.ErrInvalidRequest(fmt.Errorf("invalid request to update WeatherClient: %w", err))
 	}
 
+	// Stored history and cached responses are for the previous location or station, so they are cleared
+	if r.Method != http.MethodPost {
+		existing, _ := api.GetRequestedResource(r)
+		if existing != nil && weatherSourceChanged(existing, wc) {
+			logger.Debug("clearing weather history for updated WeatherClient", "id", wc.GetID())
+			err = api.clearWeatherHistory(r.Context(), wc.GetID())
+			if err != nil {
+				logger.Error("unable to clear weather history", "error", err)
+				return babyapi.InternalServerError(err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// weatherSourceChanged returns true if the WeatherClient's type or options changed. Authentication is ignored since
+// it is refreshed without changing where the data comes from
+func weatherSourceChanged(existing, updated *weather.Config) bool {
+	withoutAuth := func(options map[string]any) map[string]any {
+		result := maps.Clone(options)
+		delete(result, "authentication")
+		return result
+	}
+
+	return !strings.EqualFold(existing.Type, updated.Type) ||
+		!reflect.DeepEqual(withoutAuth(existing.Options), withoutAuth(updated.Options))
+}
+
+// clearWeatherHistory removes stored daily observations and cached responses for the WeatherClient
+func (api *WeatherClientsAPI) clearWeatherHistory(ctx context.Context, id string) error {
+	weather.ResetClientCache(id)
+
+	err := api.storageClient.WeatherHistory.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("error deleting weather history: %w", err)
+	}
 	return nil
 }
 
@@ -238,14 +355,62 @@ func (api *WeatherClientsAPI) testWeatherClient(_ http.ResponseWriter, r *http.R
 		return InternalServerError(err)
 	}
 
-	return &WeatherClientTestResponse{WeatherData: weatherData}
+	return &WeatherClientTestResponse{
+		WeatherData: weatherData,
+		Responders:  newWeatherDataResponders(weatherClient, duration),
+	}
+}
+
+// getWeatherHistory returns daily observations for the number of days requested with the "days" query
+// parameter, including today
+func (api *WeatherClientsAPI) getWeatherHistory(_ http.ResponseWriter, r *http.Request, weatherClient *weather.Config) (render.Renderer, *babyapi.ErrResponse) {
+	logger, _ := babyapi.GetLoggerFromContext(r.Context())
+
+	days := defaultWeatherHistoryDays
+	if daysParam := r.URL.Query().Get("days"); daysParam != "" {
+		var err error
+		days, err = strconv.Atoi(daysParam)
+		if err != nil {
+			return nil, babyapi.ErrInvalidRequest(fmt.Errorf("invalid days: %w", err))
+		}
+		if days < 1 || days > maxWeatherHistoryDays {
+			return nil, babyapi.ErrInvalidRequest(fmt.Errorf("days must be between 1 and %d", maxWeatherHistoryDays))
+		}
+	}
+
+	wc, err := api.storageClient.GetWeatherClient(weatherClient.ID.ID)
+	if err != nil {
+		logger.Error("unable to get weather client", "error", err)
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	if !weather.SupportsHistory(wc) {
+		return nil, babyapi.ErrInvalidRequest(weather.ErrHistoryNotSupported)
+	}
+
+	now := clock.Now().In(time.Local)
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	start := end.AddDate(0, 0, 1-days)
+
+	observations, err := wc.(weather.DailyHistoryProvider).GetDailyObservations(r.Context(), start, end)
+	if err != nil {
+		logger.Error("unable to get weather history", "error", err)
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	return &WeatherHistoryResponse{
+		WeatherClientID: weatherClient.GetID(),
+		Start:           start.Format(time.DateOnly),
+		End:             end.Format(time.DateOnly),
+		Observations:    observations,
+	}, nil
 }
 
 func (api *WeatherClientsAPI) getWeatherData(ctx context.Context, weatherClient *weather.Config, units string, duration time.Duration) (WeatherData, error) {
 	wc, err := weather.NewClient(weatherClient, func(weatherClientOptions map[string]any) error {
 		weatherClient.Options = weatherClientOptions
 		return api.storageClient.WeatherClientConfigs.Set(ctx, weatherClient)
-	})
+	}, api.storageClient.WeatherClientOptions()...)
 	if err != nil {
 		return WeatherData{}, fmt.Errorf("error getting weather client: %w", err)
 	}
@@ -286,5 +451,19 @@ func (api *WeatherClientsAPI) getWeatherData(ctx context.Context, weatherClient
 		}
 	}
 
+	if weather.SupportsHumidity(wc) {
+		humidity, err := wc.(weather.HumidityProvider).GetAverageHumidity(ctx, duration)
+		if err == nil {
+			result.Humidity = &HumidityData{Percent: humidity}
+		}
+	}
+
+	if weather.SupportsSolarRadiation(wc) {
+		solarRadiation, err := wc.(weather.SolarRadiationProvider).GetAverageSolarRadiation(ctx, duration)
+		if err == nil {
+			result.SolarRadiation = &SolarRadiationData{MJPerSquareMeter: solarRadiation}
+		}
+	}
+
 	return result, nil
 }
@@ -2,17 +2,26 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather/nws"
 	"github.com/calvinmclean/babyapi"
+	babyhtml "github.com/calvinmclean/babyapi/html"
 	babytest "github.com/calvinmclean/babyapi/test"
 	"github.com/rs/xid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/cassette"
+	"gopkg.in/dnaeon/go-vcr.v4/pkg/recorder"
 )
 
 func createExampleWeatherClientConfig() *weather.Config {
@@ -29,6 +38,9 @@ func createExampleWeatherClientConfig() *weather.Config {
 }
 
 func TestUpdateWeatherClient(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
 	tests := []struct {
 		name     string
 		body     string
@@ -38,7 +50,7 @@ func TestUpdateWeatherClient(t *testing.T) {
 		{
 			"Successful",
 			`{"options": {"avg_high_temperature": 81}}`,
-			`{"id":"c5cvhpcbcv45e8bp16dg","name":"Example Weather Client","type":"fake","options":{"avg_high_temperature":81,"rain_interval":"24h","rain_mm":25.4},"weather_data":{"rain":{"mm":76.2},"temperature":{"celsius":81}},"links":[{"rel":"self","href":"/weather_clients/c5cvhpcbcv45e8bp16dg"}]}`,
+			`{"id":"c5cvhpcbcv45e8bp16dg","name":"Example Weather Client","type":"fake","options":{"avg_high_temperature":81,"rain_interval":"24h","rain_mm":25.4},"weather_data":{"rain":{"mm":76.2},"temperature":{"celsius":81},"humidity":{"percent":0},"solar_radiation":{"mj_per_square_meter":0}},"health":{"last_success":"2023-08-23T10:00:00Z","consecutive_failures":0},"links":[{"rel":"self","href":"/weather_clients/c5cvhpcbcv45e8bp16dg"}]}`,
 			http.StatusOK,
 		},
 		{
@@ -63,13 +75,15 @@ func TestUpdateWeatherClient(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			weather.ResetCache()
+
 			storageClient, err := storage.NewClient(storage.Config{
 				ConnectionString: ":memory:",
 			})
 			assert.NoError(t, err)
 
 			wcr := NewWeatherClientsAPI()
-			wcr.setup(storageClient)
+			wcr.setup(storageClient, nil)
 
 			err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), createExampleWeatherClientConfig())
 			assert.NoError(t, err)
@@ -85,7 +99,63 @@ func TestUpdateWeatherClient(t *testing.T) {
 	}
 }
 
+func TestUpdateWeatherClientClearsHistory(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedRain float32
+	}{
+		// The response gets weather data, so the cleared day is fetched again using the new options
+		{"OptionsChanged", `{"options": {"rain_mm": 10}}`, 10},
+		{"NameChanged", `{"name": "New Name"}`, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weather.ResetCache()
+
+			storageClient, err := storage.NewClient(storage.Config{
+				ConnectionString: ":memory:",
+			})
+			require.NoError(t, err)
+
+			wcr := NewWeatherClientsAPI()
+			wcr.setup(storageClient, nil)
+
+			config := createExampleWeatherClientConfig()
+			require.NoError(t, storageClient.WeatherClientConfigs.Set(context.Background(), config))
+
+			// Store the completed days in the weather client's daily series so only today is fetched
+			yesterday := clock.Now().In(time.Local).AddDate(0, 0, -1)
+			rain, temperature := float32(5), float32(30)
+			stored := []weather.DailyObservation{}
+			for daysAgo := range 7 {
+				date := yesterday.AddDate(0, 0, -daysAgo).Format(time.DateOnly)
+				stored = append(stored, weather.DailyObservation{Date: date, RainMM: &rain, MaxTemperatureCelsius: &temperature, Complete: true})
+			}
+			require.NoError(t, storageClient.WeatherHistory.SetDailyObservations(context.Background(), config.GetID(), stored))
+
+			r := httptest.NewRequest(http.MethodPatch, "/weather_clients/c5cvhpcbcv45e8bp16dg", strings.NewReader(tt.body))
+			r.Header.Add("Content-Type", "application/json")
+
+			w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			observations, err := storageClient.WeatherHistory.ListDailyObservations(context.Background(), config.GetID(), yesterday, yesterday)
+			require.NoError(t, err)
+			require.Len(t, observations, 1)
+			assert.InDelta(t, tt.expectedRain, *observations[0].RainMM, 0.001)
+		})
+	}
+}
+
 func TestGetWeatherClient(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
 	tests := []struct {
 		name          string
 		id            string
@@ -97,7 +167,7 @@ func TestGetWeatherClient(t *testing.T) {
 			"Successful",
 			id.String(),
 			createExampleWeatherClientConfig(),
-			`{"id":"c5cvhpcbcv45e8bp16dg","name":"Example Weather Client","type":"fake","options":{"avg_high_temperature":80,"rain_interval":"24h","rain_mm":25.4},"weather_data":{"rain":{"mm":76.2},"temperature":{"celsius":81}},"links":[{"rel":"self","href":"/weather_clients/c5cvhpcbcv45e8bp16dg"}]}`,
+			`{"id":"c5cvhpcbcv45e8bp16dg","name":"Example Weather Client","type":"fake","options":{"avg_high_temperature":80,"rain_interval":"24h","rain_mm":25.4},"weather_data":{"rain":{"mm":76.2},"temperature":{"celsius":80},"humidity":{"percent":0},"solar_radiation":{"mj_per_square_meter":0}},"health":{"last_success":"2023-08-23T10:00:00Z","consecutive_failures":0},"links":[{"rel":"self","href":"/weather_clients/c5cvhpcbcv45e8bp16dg"}]}`,
 			http.StatusOK,
 		},
 		{
@@ -111,13 +181,15 @@ func TestGetWeatherClient(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			weather.ResetCache()
+
 			storageClient, err := storage.NewClient(storage.Config{
 				ConnectionString: ":memory:",
 			})
 			assert.NoError(t, err)
 
 			wcr := NewWeatherClientsAPI()
-			wcr.setup(storageClient)
+			wcr.setup(storageClient, nil)
 
 			err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), createExampleWeatherClientConfig())
 			assert.NoError(t, err)
@@ -196,6 +268,21 @@ func TestDeleteWeatherClient(t *testing.T) {
 	err = storageClient.WeatherClientConfigs.Set(context.Background(), weatherClientWithWS)
 	assert.NoError(t, err)
 
+	compositeMember := createExampleWeatherClientConfig()
+	compositeMember.ID = babyapi.NewID()
+	err = storageClient.WeatherClientConfigs.Set(context.Background(), compositeMember)
+	assert.NoError(t, err)
+
+	err = storageClient.WeatherClientConfigs.Set(context.Background(), &weather.Config{
+		ID:   babyapi.NewID(),
+		Name: "Composite",
+		Type: "composite",
+		Options: map[string]any{
+			"client_ids": compositeMember.GetID(),
+		},
+	})
+	assert.NoError(t, err)
+
 	tests := []struct {
 		name          string
 		id            string
@@ -214,7 +301,14 @@ func TestDeleteWeatherClient(t *testing.T) {
 			"UnableToDeleteUsedByWaterSchedules",
 			id2.String(),
 			createExampleWeatherClientConfig(),
-			`{"status":"Invalid request.","error":"unable to delete WeatherClient used by 2 WaterSchedules"}`,
+			`{"status":"Invalid request.","error":"unable to delete WeatherClient used by 1 WaterSchedules"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"UnableToDeleteUsedByComposite",
+			compositeMember.GetID(),
+			createExampleWeatherClientConfig(),
+			`{"status":"Invalid request.","error":"unable to delete WeatherClient used by composite WeatherClients: Composite"}`,
 			http.StatusBadRequest,
 		},
 	}
@@ -222,7 +316,7 @@ func TestDeleteWeatherClient(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			wcr := NewWeatherClientsAPI()
-			wcr.setup(storageClient)
+			wcr.setup(storageClient, nil)
 
 			r := httptest.NewRequest("DELETE", "/weather_clients/"+tt.id, http.NoBody)
 			r.Header.Add("Content-Type", "application/json")
@@ -230,11 +324,17 @@ func TestDeleteWeatherClient(t *testing.T) {
 			w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
 
 			assert.Equal(t, tt.code, w.Code)
+			if tt.expected != "" {
+				assert.Equal(t, tt.expected, strings.TrimSpace(w.Body.String()))
+			}
 		})
 	}
 }
 
 func TestGetAllWeatherClients(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+
 	tests := []struct {
 		name           string
 		expected       string
@@ -242,20 +342,22 @@ func TestGetAllWeatherClients(t *testing.T) {
 	}{
 		{
 			"Successful",
-			`{"items":[{"id":"c5cvhpcbcv45e8bp16dg","name":"Example Weather Client","type":"fake","options":{"avg_high_temperature":80,"rain_interval":"24h","rain_mm":25.4},"weather_data":{"rain":{"mm":76.2},"temperature":{"celsius":81}},"links":[{"rel":"self","href":"/weather_clients/c5cvhpcbcv45e8bp16dg"}]}]}`,
+			`{"items":[{"id":"c5cvhpcbcv45e8bp16dg","name":"Example Weather Client","type":"fake","options":{"avg_high_temperature":80,"rain_interval":"24h","rain_mm":25.4},"weather_data":{"rain":{"mm":76.2},"temperature":{"celsius":80},"humidity":{"percent":0},"solar_radiation":{"mj_per_square_meter":0}},"health":{"last_success":"2023-08-23T10:00:00Z","consecutive_failures":0},"links":[{"rel":"self","href":"/weather_clients/c5cvhpcbcv45e8bp16dg"}]}]}`,
 			http.StatusOK,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			weather.ResetCache()
+
 			storageClient, err := storage.NewClient(storage.Config{
 				ConnectionString: ":memory:",
 			})
 			assert.NoError(t, err)
 
 			wcr := NewWeatherClientsAPI()
-			wcr.setup(storageClient)
+			wcr.setup(storageClient, nil)
 
 			err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), createExampleWeatherClientConfig())
 			assert.NoError(t, err)
@@ -280,7 +382,7 @@ func TestCreateWeatherClient(t *testing.T) {
 		{
 			"Successful",
 			`{"name":"Test Client","type":"fake","options":{"avg_high_temperature":80,"rain_interval":"24h","rain_mm":25.4}}`,
-			`{"id":"[0-9a-v]{20}","name":"Test Client","type":"fake","options":{"avg_high_temperature":80,"rain_interval":"24h","rain_mm":25.4},"weather_data":\{"rain":\{"mm":[0-9.]+\},"temperature":\{"celsius":[0-9.]+\}\},"links":\[{"rel":"self","href":"/weather_clients/[0-9a-v]{20}"}\]}`,
+			`{"id":"[0-9a-v]{20}","name":"Test Client","type":"fake","options":{"avg_high_temperature":80,"rain_interval":"24h","rain_mm":25.4},"weather_data":\{"rain":\{"mm":[0-9.]+\},"temperature":\{"celsius":[0-9.]+\},"humidity":\{"percent":0\},"solar_radiation":\{"mj_per_square_meter":0\}\},"health":\{"last_success":"[^"]+","consecutive_failures":0\},"links":\[{"rel":"self","href":"/weather_clients/[0-9a-v]{20}"}\]}`,
 			http.StatusCreated,
 		},
 		{
@@ -311,7 +413,7 @@ func TestCreateWeatherClient(t *testing.T) {
 			assert.NoError(t, err)
 
 			wcr := NewWeatherClientsAPI()
-			wcr.setup(storageClient)
+			wcr.setup(storageClient, nil)
 
 			r := httptest.NewRequest("POST", "/weather_clients", strings.NewReader(tt.body))
 			r.Header.Add("Content-Type", "application/json")
@@ -369,7 +471,7 @@ func TestUpdateWeatherClientPUT(t *testing.T) {
 			assert.NoError(t, err)
 
 			wcr := NewWeatherClientsAPI()
-			wcr.setup(storageClient)
+			wcr.setup(storageClient, nil)
 
 			r := httptest.NewRequest(http.MethodPut, "/weather_clients/"+wc.ID.String(), strings.NewReader(tt.body))
 			r.Header.Add("Content-Type", "application/json")
@@ -400,49 +502,85 @@ func TestOAuthStart(t *testing.T) {
 	assert.NoError(t, err)
 
 	wcr := NewWeatherClientsAPI()
-	wcr.setup(storageClient)
+	wcr.setup(storageClient, nil)
+
+	t.Run("HTMX", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/weather_clients/"+wc.ID.String()+"/netatmo/oauth/start", nil)
+		r.Header.Add("Accept", "text/html")
+		r.Header.Add("HX-Request", "true")
+
+		w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
 
-	// Test with HTML Accept header
-	r := httptest.NewRequest(http.MethodGet, "/weather_clients/"+wc.ID.String()+"/netatmo/oauth/start", nil)
-	r.Header.Add("Accept", "text/html")
+		// Should return 200 OK
+		assert.Equal(t, http.StatusOK, w.Code)
+		// Should have HX-Trigger header with openOAuthPopup event
+		hxTrigger := w.Header().Get("HX-Trigger")
+		assert.Contains(t, hxTrigger, "openOAuthPopup")
+		assert.Contains(t, hxTrigger, "api.netatmo.com/oauth2/authorize")
+	})
+
+	t.Run("BrowserRedirect", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/weather_clients/"+wc.ID.String()+"/netatmo/oauth/start", nil)
+		r.Header.Add("Accept", "text/html")
 
-	w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
+		w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
 
-	// Should return 200 OK
-	assert.Equal(t, http.StatusOK, w.Code)
-	// Should have HX-Trigger header with openOAuthPopup event
-	hxTrigger := w.Header().Get("HX-Trigger")
-	assert.Contains(t, hxTrigger, "openOAuthPopup")
-	assert.Contains(t, hxTrigger, "api.netatmo.com/oauth2/authorize")
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Contains(t, w.Header().Get("Location"), "https://api.netatmo.com/oauth2/authorize?client_id=test_client_id")
+		assert.Empty(t, w.Header().Get("HX-Trigger"))
+	})
 }
 
 func TestTestWeatherClient(t *testing.T) {
+	composite := &weather.Config{
+		ID:      babyapi.ID{ID: id2},
+		Name:    "Composite",
+		Type:    "composite",
+		Options: map[string]any{"client_ids": id.String()},
+	}
+
 	tests := []struct {
 		name           string
+		weatherClient  *weather.Config
 		expected       string
 		expectedStatus int
 	}{
 		{
 			"Successful",
-			`{"rain":{"mm":76.2},"temperature":{"celsius":81}}`,
+			createExampleWeatherClientConfig(),
+			`{"rain":{"mm":76.2},"temperature":{"celsius":80},"humidity":{"percent":0},"solar_radiation":{"mj_per_square_meter":0}}`,
+			http.StatusOK,
+		},
+		{
+			"CompositeIncludesResponders",
+			composite,
+			fmt.Sprintf(
+				`{"rain":{"mm":76.2},"temperature":{"celsius":80},"humidity":{"percent":0},"solar_radiation":{"mj_per_square_meter":0},`+
+					`"responders":{"rain":["%[1]s"],"temperature":["%[1]s"],"humidity":["%[1]s"],"solar_radiation":["%[1]s"]}}`,
+				id,
+			),
 			http.StatusOK,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			weather.ResetCache()
+
 			storageClient, err := storage.NewClient(storage.Config{
 				ConnectionString: ":memory:",
 			})
 			assert.NoError(t, err)
 
 			wcr := NewWeatherClientsAPI()
-			wcr.setup(storageClient)
+			wcr.setup(storageClient, nil)
 
 			err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), createExampleWeatherClientConfig())
 			assert.NoError(t, err)
+			err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), tt.weatherClient)
+			assert.NoError(t, err)
 
-			r := httptest.NewRequest("GET", "/weather_clients/c5cvhpcbcv45e8bp16dg/test", http.NoBody)
+			r := httptest.NewRequest("GET", "/weather_clients/"+tt.weatherClient.GetID()+"/test", http.NoBody)
 			w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
 
 			// check HTTP response status code
@@ -552,3 +690,271 @@ func TestUpdateWeatherClientRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestGetWeatherClientHistory(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+	defer weather.ResetCache()
+
+	now := clock.Now().In(time.Local)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	date := func(daysAgo int) string {
+		return today.AddDate(0, 0, -daysAgo).Format(time.DateOnly)
+	}
+	// Today only includes the rain so far
+	todayRain := float32(now.Sub(today).Hours() / 24 * 25.4)
+
+	tests := []struct {
+		name           string
+		query          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			"Successful",
+			"?days=3",
+			`{"weather_client_id":"c5cvhpcbcv45e8bp16dg","start":"` + date(2) + `","end":"` + date(0) + `","observations":[` +
+				`{"date":"` + date(2) + `","rain_mm":25.4,"max_temperature_celsius":80,"complete":true},` +
+				`{"date":"` + date(1) + `","rain_mm":25.4,"max_temperature_celsius":80,"complete":true},` +
+				`{"date":"` + date(0) + `","rain_mm":` + strconv.FormatFloat(float64(todayRain), 'f', -1, 32) + `,"max_temperature_celsius":80,"complete":false}]}`,
+			http.StatusOK,
+		},
+		{
+			"ErrorInvalidDays",
+			"?days=abc",
+			`{"status":"Invalid request.","error":"invalid days: strconv.Atoi: parsing \"abc\": invalid syntax"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"ErrorDaysOutOfRange",
+			"?days=0",
+			`{"status":"Invalid request.","error":"days must be between 1 and 366"}`,
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageClient, err := storage.NewClient(storage.Config{
+				ConnectionString: ":memory:",
+			})
+			assert.NoError(t, err)
+
+			wcr := NewWeatherClientsAPI()
+			wcr.setup(storageClient, nil)
+
+			err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), createExampleWeatherClientConfig())
+			assert.NoError(t, err)
+
+			r := httptest.NewRequest("GET", "/weather_clients/c5cvhpcbcv45e8bp16dg/history"+tt.query, http.NoBody)
+			w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expected, strings.TrimSpace(w.Body.String()))
+		})
+	}
+
+	t.Run("StoredObservations", func(t *testing.T) {
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		assert.NoError(t, err)
+
+		wcr := NewWeatherClientsAPI()
+		wcr.setup(storageClient, nil)
+
+		err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), createExampleWeatherClientConfig())
+		assert.NoError(t, err)
+
+		r := httptest.NewRequest("GET", "/weather_clients/c5cvhpcbcv45e8bp16dg/history?days=2", http.NoBody)
+		w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		stored, err := storageClient.WeatherHistory.ListDailyObservations(context.Background(), "c5cvhpcbcv45e8bp16dg", today.AddDate(0, 0, -1), today)
+		assert.NoError(t, err)
+		assert.Len(t, stored, 2)
+		assert.True(t, stored[0].Complete)
+		assert.False(t, stored[1].Complete)
+	})
+}
+
+func TestGetNWSStations(t *testing.T) {
+	babyhtml.SetFS(templates, "templates/*")
+	babyhtml.SetFuncs(templateFuncs)
+
+	tests := []struct {
+		name           string
+		query          string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			"Successful",
+			"?Options.latitude=37.7749&Options.longitude=-122.4194&Options.station_id=KOAK",
+			`<option value="" >Nearest Station</option>
+<option value="KSFO" data-name="San Francisco, San Francisco International Airport" >KSFO - San Francisco, San Francisco International Airport</option>
+<option value="KOAK" data-name="Oakland International Airport" selected>KOAK - Oakland International Airport</option>`,
+			http.StatusOK,
+		},
+		{
+			"ErrorMissingLatitude",
+			"?Options.longitude=-122.4194",
+			`{"status":"Invalid request.","error":"valid latitude is required"}`,
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := recorder.New("testdata/fixtures/NWSStations", recorder.WithMatcher(
+				cassette.NewDefaultMatcher(cassette.WithIgnoreUserAgent(true)),
+			))
+			assert.NoError(t, err)
+			defer func() {
+				assert.NoError(t, r.Stop())
+			}()
+
+			nws.DefaultClient = r.GetDefaultClient()
+			defer func() { nws.DefaultClient = http.DefaultClient }()
+
+			storageClient, err := storage.NewClient(storage.Config{
+				ConnectionString: ":memory:",
+			})
+			assert.NoError(t, err)
+
+			wcr := NewWeatherClientsAPI()
+			wcr.setup(storageClient, nil)
+
+			req := httptest.NewRequest("GET", "/weather_clients/nws/stations"+tt.query, http.NoBody)
+			if tt.expectedStatus == http.StatusOK {
+				req.Header.Add("Accept", "text/html")
+			}
+			w := babytest.TestRequest[*weather.Config](t, wcr.API, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expected, strings.TrimSpace(w.Body.String()))
+		})
+	}
+}
+
+func TestIngestPWSReading(t *testing.T) {
+	_ = clock.MockTime()
+	defer clock.Reset()
+	defer weather.ResetCache()
+
+	pwsConfig := &weather.Config{
+		ID:      babyapi.ID{ID: id},
+		Name:    "Backyard Station",
+		Type:    "pws",
+		Options: map[string]any{"passkey": "secret"},
+	}
+
+	tests := []struct {
+		name           string
+		config         *weather.Config
+		method         string
+		query          string
+		body           string
+		expected       string
+		expectedStatus int
+	}{
+		{
+			"SuccessfulWeatherUnderground",
+			pwsConfig,
+			http.MethodGet,
+			"?ID=station&PASSWORD=secret&action=updateraw&dateutc=now&tempf=77&dailyrainin=1",
+			"",
+			"success",
+			http.StatusOK,
+		},
+		{
+			"SuccessfulEcowitt",
+			pwsConfig,
+			http.MethodPost,
+			"",
+			"PASSKEY=secret&stationtype=GW1100&dateutc=2023-08-23+09:59:00&tempf=77&dailyrainin=1",
+			"success",
+			http.StatusOK,
+		},
+		{
+			"ErrorInvalidPasskey",
+			pwsConfig,
+			http.MethodPost,
+			"",
+			"PASSKEY=wrong&tempf=77",
+			`{"status":"Unauthorized","error":"invalid passkey"}`,
+			http.StatusUnauthorized,
+		},
+		{
+			"ErrorPasskeyNotConfigured",
+			&weather.Config{
+				ID:      babyapi.ID{ID: id},
+				Name:    "Backyard Station",
+				Type:    "pws",
+				Options: map[string]any{},
+			},
+			http.MethodPost,
+			"",
+			"PASSKEY=&tempf=77",
+			`{"status":"Unauthorized","error":"passkey is required so uploads can be authenticated"}`,
+			http.StatusUnauthorized,
+		},
+		{
+			"ErrorInvalidReading",
+			pwsConfig,
+			http.MethodPost,
+			"",
+			"PASSKEY=secret",
+			`{"status":"Invalid request.","error":"upload does not contain any supported measurements"}`,
+			http.StatusBadRequest,
+		},
+		{
+			"ErrorNotPWS",
+			createExampleWeatherClientConfig(),
+			http.MethodGet,
+			"?tempf=77",
+			"",
+			`{"status":"Invalid request.","error":"WeatherClient is not a personal weather station"}`,
+			http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storageClient, err := storage.NewClient(storage.Config{
+				ConnectionString: ":memory:",
+			})
+			assert.NoError(t, err)
+
+			wcr := NewWeatherClientsAPI()
+			wcr.setup(storageClient, nil)
+
+			err = wcr.storageClient.WeatherClientConfigs.Set(context.Background(), tt.config)
+			assert.NoError(t, err)
+
+			r := httptest.NewRequest(tt.method, "/weather_clients/c5cvhpcbcv45e8bp16dg/pws"+tt.query, strings.NewReader(tt.body))
+			if tt.body != "" {
+				r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			w := babytest.TestRequest[*weather.Config](t, wcr.API, r)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expected, strings.TrimSpace(w.Body.String()))
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			wc, err := storageClient.GetWeatherClient(id)
+			assert.NoError(t, err)
+
+			now := clock.Now().In(time.Local)
+			today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+			observations, err := wc.(weather.DailyHistoryProvider).GetDailyObservations(context.Background(), today, today)
+			require.NoError(t, err)
+			require.Len(t, observations, 1)
+			require.NotNil(t, observations[0].RainMM)
+			assert.InDelta(t, 25.4, *observations[0].RainMM, 0.001)
+		})
+	}
+}
@@ -24,6 +24,8 @@ type WeatherData struct {
 	Rain               *RainData               `json:"rain,omitempty"`
 	Temperature        *TemperatureData        `json:"temperature,omitempty"`
 	Evapotranspiration *EvapotranspirationData `json:"evapotranspiration,omitempty"`
+	Humidity           *HumidityData           `json:"humidity,omitempty"`
+	SolarRadiation     *SolarRadiationData     `json:"solar_radiation,omitempty"`
 }
 
 // RainData shows the total rain in both metric and imperial units
@@ -44,6 +46,16 @@ type EvapotranspirationData struct {
 	Inches *float32 `json:"inches,omitempty"`
 }
 
+// HumidityData shows the average relative humidity as a percentage
+type HumidityData struct {
+	Percent float32 `json:"percent"`
+}
+
+// SolarRadiationData shows the average daily solar radiation in MJ/m²
+type SolarRadiationData struct {
+	MJPerSquareMeter float32 `json:"mj_per_square_meter"`
+}
+
 func getWeatherData(ctx context.Context, ws *pkg.WaterSchedule, storageClient *storage.Client, logger *slog.Logger) *WeatherData {
 	weatherData := &WeatherData{}
 
@@ -108,6 +120,36 @@ func getWeatherData(ctx context.Context, ws *pkg.WaterSchedule, storageClient *s
 				return nil
 			},
 		},
+		{
+			Name: "humidity-data",
+			Fn: func(taskCtx context.Context) error {
+				if !ws.HasHumidityControl() {
+					return nil
+				}
+				logger.Debug("getting average humidity for WaterSchedule")
+				percent, err := getHumidityData(taskCtx, ws, storageClient)
+				if err != nil || percent == nil {
+					return err
+				}
+				weatherData.Humidity = &HumidityData{Percent: *percent}
+				return nil
+			},
+		},
+		{
+			Name: "solar-radiation-data",
+			Fn: func(taskCtx context.Context) error {
+				if !ws.HasSolarRadiationControl() {
+					return nil
+				}
+				logger.Debug("getting average solar radiation for WaterSchedule")
+				mj, err := getSolarRadiationData(taskCtx, ws, storageClient)
+				if err != nil || mj == nil {
+					return err
+				}
+				weatherData.SolarRadiation = &SolarRadiationData{MJPerSquareMeter: *mj}
+				return nil
+			},
+		},
 	}
 
 	// Execute weather data fetching concurrently with timeout
@@ -164,6 +206,40 @@ func getEvapotranspirationData(ctx context.Context, ws *pkg.WaterSchedule, stora
 	return &avgET, nil
 }
 
+func getHumidityData(ctx context.Context, ws *pkg.WaterSchedule, storageClient *storage.Client) (*float32, error) {
+	weatherClient, err := storageClient.GetWeatherClient(ws.WeatherControl.Humidity.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting WeatherClient for HumidityControl: %w", err)
+	}
+
+	if !weather.SupportsHumidity(weatherClient) {
+		return nil, nil
+	}
+
+	avgHumidity, err := weatherClient.(weather.HumidityProvider).GetAverageHumidity(ctx, ws.Interval.Duration)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get average humidity from weather client %q: %w", ws.WeatherControl.Humidity.ClientID, err)
+	}
+	return &avgHumidity, nil
+}
+
+func getSolarRadiationData(ctx context.Context, ws *pkg.WaterSchedule, storageClient *storage.Client) (*float32, error) {
+	weatherClient, err := storageClient.GetWeatherClient(ws.WeatherControl.SolarRadiation.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting WeatherClient for SolarRadiationControl: %w", err)
+	}
+
+	if !weather.SupportsSolarRadiation(weatherClient) {
+		return nil, nil
+	}
+
+	avgSolarRadiation, err := weatherClient.(weather.SolarRadiationProvider).GetAverageSolarRadiation(ctx, ws.Interval.Duration)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get average solar radiation from weather client %q: %w", ws.WeatherControl.SolarRadiation.ClientID, err)
+	}
+	return &avgSolarRadiation, nil
+}
+
 func getDurationFromRequest(r *http.Request) time.Duration {
 	durationStr := r.URL.Query().Get("duration")
 	if durationStr == "" {
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"strconv"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
+	"github.com/calvinmclean/babyapi"
+	"github.com/go-chi/render"
+)
+
+const (
+	webhooksBasePath = "/webhooks"
+
+	defaultWebhookDeliveriesLimit = 50
+)
+
+// WebhooksAPI encapsulates the structs and dependencies necessary for the "/webhooks" API
+type WebhooksAPI struct {
+	*babyapi.API[*pkg.Webhook]
+
+	storageClient *storage.Client
+	worker        *worker.Worker
+}
+
+// NewWebhooksAPI creates a new WebhooksAPI
+func NewWebhooksAPI() *WebhooksAPI {
+	api := &WebhooksAPI{}
+
+	api.API = babyapi.NewAPI("Webhooks", webhooksBasePath, func() *pkg.Webhook { return &pkg.Webhook{} })
+
+	api.SetOnCreateOrUpdate(api.onCreateOrUpdate)
+
+	api.SetResponseWrapper(func(wh *pkg.Webhook) render.Renderer {
+		return NewWebhookResponse(wh)
+	})
+
+	api.SetSearchResponseWrapper(func(webhooks iter.Seq2[*pkg.Webhook, error]) render.Renderer {
+		result := make([]*WebhookResponse, 0)
+		for wh, err := range webhooks {
+			if err != nil {
+				continue
+			}
+			result = append(result, NewWebhookResponse(wh))
+		}
+		return AllWebhooksResponse{
+			ResourceList: babyapi.ResourceList[*WebhookResponse]{Items: result},
+		}
+	})
+
+	api.AddCustomIDRoute(http.MethodGet, "/deliveries", api.GetRequestedResourceAndDo(api.listDeliveries))
+	api.AddCustomIDRoute(http.MethodPost, "/test", api.GetRequestedResourceAndDo(api.testWebhook))
+
+	api.EnableMCP(babyapi.MCPPermRead)
+
+	return api
+}
+
+func (api *WebhooksAPI) setup(storageClient *storage.Client, worker *worker.Worker) {
+	api.storageClient = storageClient
+	api.worker = worker
+
+	api.SetStorage(api.storageClient.Webhooks)
+}
+
+// onCreateOrUpdate keeps the existing Secret when a Webhook is replaced without one since the Secret is never
+// included in responses
+func (api *WebhooksAPI) onCreateOrUpdate(_ http.ResponseWriter, r *http.Request, wh *pkg.Webhook) *babyapi.ErrResponse {
+	if r.Method != http.MethodPut || wh.Secret != "" {
+		return nil
+	}
+
+	existing, err := api.storageClient.Webhooks.Get(r.Context(), wh.GetID())
+	switch {
+	case errors.Is(err, babyapi.ErrNotFound):
+		return nil
+	case err != nil:
+		return babyapi.InternalServerError(fmt.Errorf("error getting existing Webhook: %w", err))
+	}
+
+	wh.Secret = existing.Secret
+	return nil
+}
+
+// listDeliveries returns the newest deliveries for the Webhook. The number of results can be set with the limit
+// query parameter
+func (api *WebhooksAPI) listDeliveries(_ http.ResponseWriter, r *http.Request, wh *pkg.Webhook) (render.Renderer, *babyapi.ErrResponse) {
+	limit := defaultWebhookDeliveriesLimit
+	if limitParam := r.URL.Query().Get("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 {
+			return nil, babyapi.ErrInvalidRequest(fmt.Errorf("invalid limit: %q", limitParam))
+		}
+	}
+
+	deliveries, err := api.storageClient.WebhookDeliveries.List(r.Context(), wh.GetID(), limit)
+	if err != nil {
+		return nil, babyapi.InternalServerError(err)
+	}
+
+	return &WebhookDeliveriesResponse{Items: deliveries}, nil
+}
+
+// testWebhook sends a test event to the Webhook and responds with the result
+func (api *WebhooksAPI) testWebhook(_ http.ResponseWriter, r *http.Request, wh *pkg.Webhook) (render.Renderer, *babyapi.ErrResponse) {
+	delivery, err := api.worker.SendTestWebhookEvent(r.Context(), wh)
+	if err != nil {
+		return nil, babyapi.InternalServerError(fmt.Errorf("error sending test event: %w", err))
+	}
+
+	return &WebhookDeliveryResponse{delivery}, nil
+}
+
+// WebhookResponse is used to render a Webhook without its Secret
+type WebhookResponse struct {
+	*pkg.Webhook
+
+	// Secret hides the Webhook's Secret
+	Secret    string `json:"secret,omitempty"`
+	HasSecret bool   `json:"has_secret"`
+
+	Links []Link `json:"links,omitempty"`
+}
+
+// NewWebhookResponse creates a WebhookResponse
+func NewWebhookResponse(wh *pkg.Webhook) *WebhookResponse {
+	return &WebhookResponse{
+		Webhook:   wh,
+		HasSecret: wh.Secret != "",
+	}
+}
+
+// Render adds links to the response
+func (resp *WebhookResponse) Render(_ http.ResponseWriter, _ *http.Request) error {
+	if resp != nil {
+		resp.Links = append(resp.Links,
+			Link{
+				Rel:  "self",
+				HRef: fmt.Sprintf("%s/%s", webhooksBasePath, resp.ID),
+			},
+			Link{
+				Rel:  "deliveries",
+				HRef: fmt.Sprintf("%s/%s/deliveries", webhooksBasePath, resp.ID),
+			},
+		)
+	}
+	return nil
+}
+
+// AllWebhooksResponse wraps a list of Webhooks
+type AllWebhooksResponse struct {
+	babyapi.ResourceList[*WebhookResponse]
+}
+
+// Render delegates to ResourceList's Render
+func (resp AllWebhooksResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	return resp.ResourceList.Render(w, r)
+}
+
+// WebhookDeliveriesResponse is the response for listing a Webhook's deliveries
+type WebhookDeliveriesResponse struct {
+	Items []*pkg.WebhookDelivery `json:"items"`
+}
+
+// Render is used to implement render.Renderer
+func (*WebhookDeliveriesResponse) Render(http.ResponseWriter, *http.Request) error {
+	return nil
+}
+
+// WebhookDeliveryResponse is the response for a single delivery
+type WebhookDeliveryResponse struct {
+	*pkg.WebhookDelivery
+}
+
+// Render is used to implement render.Renderer
+func (*WebhookDeliveryResponse) Render(http.ResponseWriter, *http.Request) error {
+	return nil
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/worker"
+	"github.com/calvinmclean/babyapi"
+	babytest "github.com/calvinmclean/babyapi/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhooksAPI(t *testing.T) {
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	var received []*http.Request
+	var receivedBodies []string
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, r)
+		receivedBodies = append(receivedBodies, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	api := NewWebhooksAPI()
+	api.setup(storageClient, worker.NewWorker(storageClient, nil, nil, slog.Default()))
+
+	webhookRegexp := func(extra string) string {
+		return fmt.Sprintf(
+			`{"id":"[0-9a-v]{20}","name":"receiver","url":"%s","event_types":\["water.completed"\]%s,"has_secret":true,"links":\[{"rel":"self","href":"/webhooks/[0-9a-v]{20}"},{"rel":"deliveries","href":"/webhooks/[0-9a-v]{20}/deliveries"}\]}`,
+			receiver.URL, extra,
+		)
+	}
+
+	babytest.RunTableTest(t, api.API, []babytest.TestCase[*babyapi.AnyResource]{
+		{
+			Name: "Create",
+			Test: babytest.RequestTest[*babyapi.AnyResource]{
+				Method: http.MethodPost,
+				Body:   fmt.Sprintf(`{"name":"receiver","url":"%s","secret":"abc","event_types":["water.completed"]}`, receiver.URL),
+			},
+			ExpectedResponse: babytest.ExpectedResponse{
+				Status:     http.StatusCreated,
+				BodyRegexp: webhookRegexp(""),
+			},
+		},
+		{
+			Name: "PutKeepsSecret",
+			Test: babytest.RequestTest[*babyapi.AnyResource]{
+				Method: http.MethodPut,
+				IDFunc: func(getResponse babytest.PreviousResponseGetter) string {
+					return getResponse("Create").Data.GetID()
+				},
+				BodyFunc: func(getResponse babytest.PreviousResponseGetter) string {
+					return fmt.Sprintf(
+						`{"id":"%s","name":"receiver","url":"%s","event_types":["water.completed"],"disabled":true}`,
+						getResponse("Create").Data.GetID(), receiver.URL,
+					)
+				},
+			},
+			ExpectedResponse: babytest.ExpectedResponse{
+				Status:     http.StatusOK,
+				BodyRegexp: webhookRegexp(`,"disabled":true`),
+			},
+		},
+		{
+			Name: "CreateErrorInvalidEventType",
+			Test: babytest.RequestTest[*babyapi.AnyResource]{
+				Method: http.MethodPost,
+				Body:   `{"name":"receiver","url":"http://example.com","event_types":["water.unknown"]}`,
+			},
+			ExpectedResponse: babytest.ExpectedResponse{
+				Status: http.StatusBadRequest,
+				Error:  `error posting resource: unexpected response with text: Invalid request.`,
+				Body:   `{"status":"Invalid request.","error":"invalid event type \"water.unknown\""}`,
+			},
+		},
+		{
+			Name: "CreateErrorInvalidURL",
+			Test: babytest.RequestTest[*babyapi.AnyResource]{
+				Method: http.MethodPost,
+				Body:   `{"name":"receiver","url":"ftp://example.com","event_types":["water.completed"]}`,
+			},
+			ExpectedResponse: babytest.ExpectedResponse{
+				Status: http.StatusBadRequest,
+				Error:  `error posting resource: unexpected response with text: Invalid request.`,
+				Body:   `{"status":"Invalid request.","error":"invalid url \"ftp://example.com\": must be an http or https URL"}`,
+			},
+		},
+		{
+			Name: "CreateErrorNoEventTypes",
+			Test: babytest.RequestTest[*babyapi.AnyResource]{
+				Method: http.MethodPost,
+				Body:   `{"name":"receiver","url":"http://example.com"}`,
+			},
+			ExpectedResponse: babytest.ExpectedResponse{
+				Status: http.StatusBadRequest,
+				Error:  `error posting resource: unexpected response with text: Invalid request.`,
+				Body:   `{"status":"Invalid request.","error":"missing required event_types field"}`,
+			},
+		},
+	})
+
+	t.Run("TestAndListDeliveries", func(t *testing.T) {
+		var webhook pkg.Webhook
+		r := httptest.NewRequest(
+			http.MethodPost,
+			"/webhooks",
+			strings.NewReader(fmt.Sprintf(`{"name":"receiver","url":"%s","secret":"abc","event_types":["water.completed"]}`, receiver.URL)),
+		)
+		r.Header.Add("Content-Type", "application/json")
+		w := babytest.TestRequest(t, api.API, r)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&webhook))
+
+		r = httptest.NewRequest(http.MethodPost, fmt.Sprintf("/webhooks/%s/test", webhook.GetID()), http.NoBody)
+		w = babytest.TestRequest(t, api.API, r)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var delivery pkg.WebhookDelivery
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&delivery))
+		assert.Equal(t, pkg.WebhookEventTest, delivery.EventType)
+		assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+		assert.True(t, delivery.Delivered())
+
+		require.Len(t, received, 1)
+		assert.Equal(t, "test", received[0].Header.Get(pkg.WebhookEventHeader))
+		assert.Equal(t, delivery.Payload, receivedBodies[0])
+
+		r = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/webhooks/%s/deliveries", webhook.GetID()), http.NoBody)
+		w = babytest.TestRequest(t, api.API, r)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var deliveries WebhookDeliveriesResponse
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&deliveries))
+		require.Len(t, deliveries.Items, 1)
+		assert.Equal(t, delivery.ID, deliveries.Items[0].ID)
+	})
+}
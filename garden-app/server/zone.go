@@ -59,6 +59,16 @@ func NewZonesAPI() *ZonesAPI {
 	})
 
 	api.SetOnCreateOrUpdate(api.onCreateOrUpdate)
+	api.SetAfterCreateOrUpdate(func(_ http.ResponseWriter, r *http.Request, _ *pkg.Zone) *babyapi.ErrResponse {
+		api.updateHomeAssistantDiscovery(r)
+		api.updateControllerSchedule(r)
+		return nil
+	})
+	api.SetAfterDelete(func(_ http.ResponseWriter, r *http.Request) *babyapi.ErrResponse {
+		api.updateHomeAssistantDiscovery(r)
+		api.updateControllerSchedule(r)
+		return nil
+	})
 
 	api.AddCustomRoute(http.MethodGet, "/components", babyapi.Handler(func(_ http.ResponseWriter, r *http.Request) render.Renderer {
 		switch r.URL.Query().Get("type") {
@@ -106,6 +116,28 @@ func (api *ZonesAPI) setup(storageClient *storage.Client, influxdbClient influxd
 	api.SetStorage(api.storageClient.Zones)
 }
 
+// updateHomeAssistantDiscovery updates the Home Assistant entities for the Zone's Garden. Errors are only logged
+// because the Zone was already saved
+func (api *ZonesAPI) updateHomeAssistantDiscovery(r *http.Request) {
+	gardenID := api.GetParentIDParam(r)
+	err := api.worker.UpdateHomeAssistantDiscovery(r.Context(), gardenID)
+	if err != nil {
+		logger, _ := babyapi.GetLoggerFromContext(r.Context())
+		logger.Error("unable to update Home Assistant discovery", "garden_id", gardenID, "error", err)
+	}
+}
+
+// updateControllerSchedule sends the Garden's ControllerSchedule since it includes the Zones. Errors are only logged
+// because the Zone was already saved
+func (api *ZonesAPI) updateControllerSchedule(r *http.Request) {
+	gardenID := api.GetParentIDParam(r)
+	err := api.worker.UpdateControllerSchedule(r.Context(), gardenID)
+	if err != nil {
+		logger, _ := babyapi.GetLoggerFromContext(r.Context())
+		logger.Error("unable to update controller schedule", "garden_id", gardenID, "error", err)
+	}
+}
+
 func (api *ZonesAPI) createModal(r *http.Request, zone *pkg.Zone) (render.Renderer, *babyapi.ErrResponse) {
 	waterSchedules := make([]*pkg.WaterSchedule, 0)
 	for ws, err := range api.storageClient.WaterSchedules.Search(r.Context(), "", nil) {
@@ -23,6 +23,9 @@ type ZoneResponse struct {
 	NextWater   NextWaterDetails `json:"next_water,omitzero"`
 	Links       []Link           `json:"links,omitempty"`
 
+	// WateringStatus is the watchdog's status for the most recent watering
+	WateringStatus *pkg.WateringStatus `json:"watering_status,omitempty"`
+
 	// Progress is only used in HTML responses and is excluded from JSON
 	Progress *pkg.WaterHistoryProgress `json:"-"`
 
@@ -98,6 +101,8 @@ func (zr *ZoneResponse) Render(w http.ResponseWriter, r *http.Request) error {
 		return nil
 	}
 
+	zr.WateringStatus = zr.api.worker.GetWateringStatus(zr.Zone.GetID())
+
 	zr.Links = append(zr.Links,
 		Link{
 			"action",
@@ -0,0 +1,280 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Command types are used to choose the CommandPolicy for a queued command
+const (
+	CommandTypeWater    = "water"
+	CommandTypeStop     = "stop"
+	CommandTypeStopAll  = "stop_all"
+	CommandTypeLight    = "light"
+	CommandTypeFan      = "fan"
+	CommandTypeUpdate   = "update"
+	CommandTypeSchedule = "schedule"
+)
+
+const defaultCommandQueueFlushInterval = 10 * time.Second
+
+// defaultCommandPolicies are used for command types that are not configured. A watering that is too late could be
+// harmful, so it expires. Stops are kept as long as the waterings they cancel. The latest light state and controller
+// config are always useful
+var defaultCommandPolicies = map[string]CommandPolicy{
+	CommandTypeWater:    {MaxAge: 2 * time.Hour},
+	CommandTypeStop:     {MaxAge: 2 * time.Hour},
+	CommandTypeStopAll:  {MaxAge: 2 * time.Hour},
+	CommandTypeLight:    {LatestOnly: true},
+	CommandTypeFan:      {MaxAge: 10 * time.Minute, LatestOnly: true},
+	CommandTypeUpdate:   {LatestOnly: true},
+	CommandTypeSchedule: {LatestOnly: true},
+}
+
+var commandQueueExpired = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "garden_app",
+	Name:      "command_queue_expired_total",
+	Help:      "count of queued commands that expired before they could be published",
+}, []string{"type"})
+
+// CommandPolicy controls how a type of command is handled while it is queued
+type CommandPolicy struct {
+	// MaxAge is how long the command can wait in the queue before it is dropped. If it is 0, the command never
+	// expires
+	MaxAge time.Duration `mapstructure:"max_age" yaml:"max_age"`
+	// LatestOnly replaces any queued command of the same type for the Garden, so only the latest is published
+	LatestOnly bool `mapstructure:"latest_only" yaml:"latest_only"`
+}
+
+// CommandQueueConfig configures the queue that stores commands when the MQTT broker is unavailable
+type CommandQueueConfig struct {
+	// Disabled will return an error for commands that can't be published instead of queueing them
+	Disabled bool `mapstructure:"disabled" yaml:"disabled"`
+	// FlushInterval is how often the queue is checked and published if the client is connected. Defaults to
+	// 10 seconds
+	FlushInterval time.Duration `mapstructure:"flush_interval" yaml:"flush_interval"`
+	// Policies overrides the default policy for each command type
+	Policies map[string]CommandPolicy `mapstructure:"policies" yaml:"policies"`
+}
+
+func (c CommandQueueConfig) flushInterval() time.Duration {
+	if c.FlushInterval <= 0 {
+		return defaultCommandQueueFlushInterval
+	}
+	return c.FlushInterval
+}
+
+func (c CommandQueueConfig) policy(commandType string) CommandPolicy {
+	policy, ok := c.Policies[commandType]
+	if !ok {
+		policy = defaultCommandPolicies[commandType]
+	}
+
+	// Stops must not expire before the waterings they cancel
+	if commandType == CommandTypeStop || commandType == CommandTypeStopAll {
+		waterMaxAge := c.policy(CommandTypeWater).MaxAge
+		if policy.MaxAge > 0 && (waterMaxAge == 0 || waterMaxAge > policy.MaxAge) {
+			policy.MaxAge = waterMaxAge
+		}
+	}
+
+	return policy
+}
+
+// WithCommandQueueConfig configures the outbound command queue
+func WithCommandQueueConfig(cfg CommandQueueConfig) WorkerOption {
+	return func(w *Worker) {
+		w.commandQueueConfig = cfg
+	}
+}
+
+// publishCommand publishes a command to the Garden's controller. If the client can't connect to the broker, the
+// command is queued and published later. Queued commands are published first so the controller receives all
+// commands in order
+func (w *Worker) publishCommand(ctx context.Context, g *pkg.Garden, commandType, topic string, msg []byte) error {
+	_, err := w.publishOrQueueCommand(ctx, g, commandType, topic, msg)
+	return err
+}
+
+// publishOrQueueCommand is the implementation of publishCommand and also returns true if the command was queued
+func (w *Worker) publishOrQueueCommand(ctx context.Context, g *pkg.Garden, commandType, topic string, msg []byte) (bool, error) {
+	if w.storageClient == nil || w.commandQueueConfig.Disabled {
+		return false, w.mqttClient.Publish(ctx, topic, msg)
+	}
+
+	w.commandQueueMutex.Lock()
+	defer w.commandQueueMutex.Unlock()
+
+	err := w.flushCommandQueue(ctx)
+	if err == nil {
+		err = w.mqttClient.Publish(ctx, topic, msg)
+	}
+	if !errors.Is(err, mqtt.ErrNotConnected) {
+		return false, err
+	}
+
+	return true, w.enqueueCommand(ctx, g, commandType, topic, msg, err)
+}
+
+func (w *Worker) enqueueCommand(ctx context.Context, g *pkg.Garden, commandType, topic string, msg []byte, publishErr error) error {
+	policy := w.commandQueueConfig.policy(commandType)
+
+	cmd := &pkg.QueuedCommand{
+		GardenID:  g.GetID(),
+		Type:      commandType,
+		Topic:     topic,
+		Payload:   string(msg),
+		QueuedAt:  clock.Now(),
+		Attempts:  1,
+		LastError: publishErr.Error(),
+	}
+	if policy.MaxAge > 0 {
+		expiresAt := cmd.QueuedAt.Add(policy.MaxAge)
+		cmd.ExpiresAt = &expiresAt
+	}
+
+	if policy.LatestOnly {
+		err := w.storageClient.CommandQueue.DeleteByType(ctx, cmd.GardenID, commandType)
+		if err != nil {
+			return fmt.Errorf("error replacing queued commands: %w", err)
+		}
+	}
+
+	cancelled, err := w.cancelQueuedWaterings(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	if cancelled {
+		return nil
+	}
+
+	err = w.storageClient.CommandQueue.Add(ctx, cmd)
+	if err != nil {
+		return fmt.Errorf("unable to queue command after publish failed: %w", errors.Join(publishErr, err))
+	}
+
+	w.logger.With(
+		"garden_id", cmd.GardenID,
+		"command_type", commandType,
+		"command_id", cmd.ID,
+		"error", publishErr,
+	).Warn("queued command until the MQTT broker is available")
+
+	return nil
+}
+
+// cancelQueuedWaterings removes queued waterings that a stop command would cancel. A stop_all removes all queued
+// waterings and stops for the Garden, but is still queued to stop a watering that is already running. A stop removes
+// the newest queued watering instead of being queued. It returns true if the command doesn't need to be queued
+func (w *Worker) cancelQueuedWaterings(ctx context.Context, cmd *pkg.QueuedCommand) (bool, error) {
+	logger := w.logger.With("garden_id", cmd.GardenID, "command_type", cmd.Type)
+
+	switch cmd.Type {
+	case CommandTypeStopAll:
+		for _, commandType := range []string{CommandTypeWater, CommandTypeStop} {
+			err := w.storageClient.CommandQueue.DeleteByType(ctx, cmd.GardenID, commandType)
+			if err != nil {
+				return false, fmt.Errorf("error removing queued %s commands: %w", commandType, err)
+			}
+		}
+		logger.Info("removed queued waterings")
+	case CommandTypeStop:
+		deleted, err := w.storageClient.CommandQueue.DeleteNewestByType(ctx, cmd.GardenID, CommandTypeWater)
+		if err != nil {
+			return false, fmt.Errorf("error removing queued watering: %w", err)
+		}
+		if deleted {
+			logger.Info("removed newest queued watering instead of queueing stop")
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// FlushCommandQueue publishes queued commands in order. Expired commands are dropped. It stops and returns an error
+// if the client is not connected
+func (w *Worker) FlushCommandQueue(ctx context.Context) error {
+	if w.storageClient == nil {
+		return nil
+	}
+
+	w.commandQueueMutex.Lock()
+	defer w.commandQueueMutex.Unlock()
+
+	return w.flushCommandQueue(ctx)
+}
+
+// flushCommandQueue is the implementation of FlushCommandQueue and expects the caller to hold commandQueueMutex
+func (w *Worker) flushCommandQueue(ctx context.Context) error {
+	commands, err := w.storageClient.CommandQueue.List(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	for _, cmd := range commands {
+		logger := w.logger.With("garden_id", cmd.GardenID, "command_type", cmd.Type, "command_id", cmd.ID)
+
+		if cmd.Expired() {
+			logger.Warn("dropping expired command", "queued_at", cmd.QueuedAt)
+			commandQueueExpired.WithLabelValues(cmd.Type).Inc()
+		} else {
+			err = w.mqttClient.Publish(ctx, cmd.Topic, []byte(cmd.Payload))
+			if errors.Is(err, mqtt.ErrNotConnected) {
+				if recordErr := w.storageClient.CommandQueue.RecordAttempt(ctx, cmd.ID, err); recordErr != nil {
+					logger.Error("error recording queued command attempt", "error", recordErr)
+				}
+				return err
+			}
+
+			if err != nil {
+				logger.Error("dropping queued command that failed to publish", "error", err)
+				schedulerErrors.WithLabelValues("command_queue", cmd.GardenID).Inc()
+			} else {
+				logger.Info("published queued command", "queued_for", clock.Since(cmd.QueuedAt))
+				if cmd.Type == CommandTypeWater {
+					w.watchQueuedWatering(ctx, cmd)
+				}
+			}
+		}
+
+		err = w.storageClient.CommandQueue.Delete(ctx, cmd.ID)
+		if err != nil {
+			return fmt.Errorf("error removing command from queue: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *Worker) scheduleCommandQueueFlush() error {
+	if _, isMock := w.mqttClient.(*mqtt.MockClient); isMock || w.mqttClient == nil {
+		return nil
+	}
+	if w.storageClient == nil || w.commandQueueConfig.Disabled {
+		return nil
+	}
+
+	_, err := w.scheduler.
+		Every(w.commandQueueConfig.flushInterval()).
+		Tag("command_queue").
+		Do(func() {
+			if !w.mqttClient.Status().Connected {
+				return
+			}
+			if err := w.FlushCommandQueue(context.Background()); err != nil {
+				w.logger.Error("error flushing command queue", "error", err)
+			}
+		})
+	if err != nil {
+		return fmt.Errorf("error scheduling command queue flush: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandQueue(t *testing.T) {
+	mockClock := clock.MockTime()
+	defer clock.Reset()
+
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	garden := createExampleGarden()
+	require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+	zone := createExampleZone()
+
+	connected := false
+	published := []string{}
+	mqttClient := new(mqtt.MockClient)
+	mqttClient.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, topic string, msg []byte) error {
+		if !connected {
+			return fmt.Errorf("%w: connection refused", mqtt.ErrNotConnected)
+		}
+		published = append(published, fmt.Sprintf("%s %s", topic, msg))
+		return nil
+	})
+
+	w := NewWorker(storageClient, nil, mqttClient, slog.Default())
+
+	queuedTypes := func(t *testing.T) []string {
+		t.Helper()
+		commands, err := storageClient.CommandQueue.List(context.Background(), garden.GetID())
+		require.NoError(t, err)
+
+		result := []string{}
+		for _, cmd := range commands {
+			result = append(result, cmd.Type)
+		}
+		return result
+	}
+
+	t.Run("QueuedWhenDisconnected", func(t *testing.T) {
+		err := w.ExecuteWaterAction(context.Background(), garden, zone, &action.WaterAction{
+			Duration: &pkg.Duration{Duration: time.Minute},
+		})
+		require.NoError(t, err)
+
+		err = w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: pkg.LightStateOn})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{CommandTypeWater, CommandTypeLight}, queuedTypes(t))
+		assert.Empty(t, published)
+	})
+
+	t.Run("LatestLightStateReplacesQueued", func(t *testing.T) {
+		err := w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: pkg.LightStateOff})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{CommandTypeWater, CommandTypeLight}, queuedTypes(t))
+
+		commands, err := storageClient.CommandQueue.List(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, `{"state":"OFF"}`, commands[1].Payload)
+		assert.Nil(t, commands[1].ExpiresAt)
+		assert.Equal(t, 3, commands[0].Attempts)
+		assert.Contains(t, commands[0].LastError, "connection refused")
+	})
+
+	t.Run("FlushWhileDisconnected", func(t *testing.T) {
+		err := w.FlushCommandQueue(context.Background())
+		require.ErrorIs(t, err, mqtt.ErrNotConnected)
+		assert.Equal(t, []string{CommandTypeWater, CommandTypeLight}, queuedTypes(t))
+	})
+
+	t.Run("FlushDropsExpiredCommands", func(t *testing.T) {
+		mockClock.Add(3 * time.Hour)
+		connected = true
+
+		err := w.FlushCommandQueue(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{`test-garden/command/light {"state":"OFF"}`}, published)
+		assert.Empty(t, queuedTypes(t))
+	})
+
+	t.Run("QueuedCommandsPublishedFirst", func(t *testing.T) {
+		published = []string{}
+		connected = false
+
+		err := w.ExecuteStopAction(context.Background(), garden, &action.StopAction{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{CommandTypeStop}, queuedTypes(t))
+
+		connected = true
+		err = w.ExecuteStopAction(context.Background(), garden, &action.StopAction{All: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"test-garden/command/stop no message",
+			"test-garden/command/stop_all no message",
+		}, published)
+		assert.Empty(t, queuedTypes(t))
+	})
+
+	t.Run("StopRemovesNewestQueuedWatering", func(t *testing.T) {
+		connected = false
+
+		for _, duration := range []time.Duration{time.Minute, 2 * time.Minute} {
+			err := w.ExecuteWaterAction(context.Background(), garden, zone, &action.WaterAction{
+				Duration: &pkg.Duration{Duration: duration},
+			})
+			require.NoError(t, err)
+		}
+		waterCommands, err := storageClient.CommandQueue.List(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		require.Len(t, waterCommands, 2)
+
+		err = w.ExecuteStopAction(context.Background(), garden, &action.StopAction{})
+		require.NoError(t, err)
+
+		commands, err := storageClient.CommandQueue.List(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		require.Len(t, commands, 1)
+		assert.Equal(t, waterCommands[0].ID, commands[0].ID)
+	})
+
+	t.Run("StopAllRemovesQueuedWaterings", func(t *testing.T) {
+		published = []string{}
+
+		err := w.ExecuteStopAction(context.Background(), garden, &action.StopAction{All: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{CommandTypeStopAll}, queuedTypes(t))
+
+		// Without a queued watering, the stop is queued for the watering that is already running
+		err = w.ExecuteStopAction(context.Background(), garden, &action.StopAction{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{CommandTypeStopAll, CommandTypeStop}, queuedTypes(t))
+
+		commands, err := storageClient.CommandQueue.List(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		require.NotNil(t, commands[1].ExpiresAt)
+		assert.Equal(t, commands[1].QueuedAt.Add(2*time.Hour), *commands[1].ExpiresAt)
+
+		connected = true
+		err = w.FlushCommandQueue(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"test-garden/command/stop_all no message",
+			"test-garden/command/stop no message",
+		}, published)
+		assert.Empty(t, queuedTypes(t))
+	})
+
+	t.Run("OtherErrorsNotQueued", func(t *testing.T) {
+		otherClient := new(mqtt.MockClient)
+		otherClient.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("publish error"))
+
+		w := NewWorker(storageClient, nil, otherClient, slog.Default())
+		err := w.ExecuteStopAction(context.Background(), garden, &action.StopAction{})
+		require.EqualError(t, err, "publish error")
+		assert.Empty(t, queuedTypes(t))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		connected = false
+
+		w := NewWorker(storageClient, nil, mqttClient, slog.Default(), WithCommandQueueConfig(CommandQueueConfig{Disabled: true}))
+		err := w.ExecuteStopAction(context.Background(), garden, &action.StopAction{})
+		require.ErrorIs(t, err, mqtt.ErrNotConnected)
+		assert.Empty(t, queuedTypes(t))
+	})
+}
+
+func TestCommandQueueConfigPolicy(t *testing.T) {
+	cfg := CommandQueueConfig{
+		Policies: map[string]CommandPolicy{
+			CommandTypeWater: {MaxAge: 30 * time.Minute},
+		},
+	}
+
+	assert.Equal(t, CommandPolicy{MaxAge: 30 * time.Minute}, cfg.policy(CommandTypeWater))
+	assert.Equal(t, CommandPolicy{LatestOnly: true}, cfg.policy(CommandTypeLight))
+	assert.Equal(t, CommandPolicy{}, cfg.policy("unknown"))
+	assert.Equal(t, defaultCommandQueueFlushInterval, cfg.flushInterval())
+
+	t.Run("StopsDoNotExpireBeforeWaterings", func(t *testing.T) {
+		cfg := CommandQueueConfig{
+			Policies: map[string]CommandPolicy{
+				CommandTypeStop: {MaxAge: 10 * time.Minute},
+			},
+		}
+		assert.Equal(t, CommandPolicy{MaxAge: 2 * time.Hour}, cfg.policy(CommandTypeStop))
+
+		cfg.Policies[CommandTypeWater] = CommandPolicy{}
+		assert.Equal(t, CommandPolicy{}, cfg.policy(CommandTypeStop))
+		assert.Equal(t, CommandPolicy{}, cfg.policy(CommandTypeStopAll))
+	})
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+)
+
+const defaultConfigSyncCooldown = 10 * time.Minute
+
+// ConfigSyncConfig configures automatically sending the ControllerConfig when a controller reports that it is
+// using a different config
+type ConfigSyncConfig struct {
+	// Disable disables automatically sending the ControllerConfig. The sync status is still shown
+	Disable bool `mapstructure:"disable" yaml:"disable"`
+	// Cooldown is the minimum time between sending the config to the same controller. This prevents a controller
+	// that is unable to apply the config from rebooting repeatedly. Defaults to 10 minutes
+	Cooldown time.Duration `mapstructure:"cooldown" yaml:"cooldown"`
+}
+
+func (c ConfigSyncConfig) cooldown() time.Duration {
+	if c.Cooldown <= 0 {
+		return defaultConfigSyncCooldown
+	}
+	return c.Cooldown
+}
+
+// WithConfigSyncConfig configures automatically sending the ControllerConfig to controllers
+func WithConfigSyncConfig(cfg ConfigSyncConfig) WorkerOption {
+	return func(w *Worker) {
+		w.configSyncConfig = cfg
+	}
+}
+
+// syncControllerConfig sends the Garden's ControllerConfig when the hash reported by the controller doesn't match.
+// A nil hash is ignored because older firmware doesn't report it, but an empty hash means the controller doesn't
+// have a config (for example after its storage was erased) so the config is sent. It returns true if the config was sent
+func (w *Worker) syncControllerConfig(ctx context.Context, g *pkg.Garden, reportedHash *string) (bool, error) {
+	if w.configSyncConfig.Disable || g.ControllerConfig == nil || reportedHash == nil {
+		return false, nil
+	}
+
+	// Other drivers don't use the garden-controller firmware so they can't report a config
+	if g.Driver.GetType() != pkg.DriverTypeMQTT {
+		return false, nil
+	}
+
+	expectedHash := g.ControllerConfig.ToMessage().ConfigHash
+	if *reportedHash == expectedHash {
+		return false, nil
+	}
+
+	logger := w.logger.With("garden_id", g.GetID(), "reported_hash", *reportedHash, "expected_hash", expectedHash)
+
+	now := clock.Now()
+	w.configSyncMutex.Lock()
+	lastSync, ok := w.configSyncs[g.GetID()]
+	if ok && now.Sub(lastSync) < w.configSyncConfig.cooldown() {
+		w.configSyncMutex.Unlock()
+		logger.Warn("controller config is out of sync, but it was sent recently")
+		return false, nil
+	}
+	w.configSyncs[g.GetID()] = now
+	w.configSyncMutex.Unlock()
+
+	logger.Info("controller config is out of sync, sending current config")
+	err := w.ExecuteUpdateAction(ctx, g, &action.UpdateAction{Config: true})
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigSync(t *testing.T) {
+	setup := func(t *testing.T, cfg ConfigSyncConfig) (*Worker, *mqtt.MockClient, *storage.Client, *pkg.Garden) {
+		t.Helper()
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		garden := createExampleGarden()
+		garden.LightSchedule = nil
+		garden.ControllerConfig = &pkg.ControllerConfig{
+			ValvePins: []uint{1, 2},
+			PumpPins:  []uint{3, 4},
+		}
+		require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+		mqttClient := new(mqtt.MockClient)
+		w := NewWorker(storageClient, nil, mqttClient, slog.Default(), WithConfigSyncConfig(cfg))
+		return w, mqttClient, storageClient, garden
+	}
+
+	infoMessage := func(hash string) string {
+		return `info mac="aa:bb:cc:dd:ee:ff",ip="192.168.1.42",version="v1.0.0",config_hash="` + hash + `"`
+	}
+
+	t.Run("OutOfSyncSendsConfig", func(t *testing.T) {
+		c := clock.MockTime()
+		defer clock.Reset()
+
+		w, mqttClient, storageClient, garden := setup(t, ConfigSyncConfig{})
+		mqttClient.On("Publish", mock.Anything, "test-garden/command/update_config", mock.Anything).Return(nil).Once()
+
+		err := w.getGardenAndSaveControllerInfo("test-garden/data/info", infoMessage("abc"))
+		require.NoError(t, err)
+		mqttClient.AssertExpectations(t)
+
+		info, err := storageClient.ControllerInfo.Get(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		require.NotNil(t, info.ConfigHash)
+		assert.Equal(t, "abc", *info.ConfigHash)
+
+		g, err := storageClient.Gardens.Get(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, pkg.ConfigSyncStatusOutOfSync, g.ConfigSyncStatus())
+
+		t.Run("CooldownPreventsResend", func(t *testing.T) {
+			err := w.getGardenAndSaveControllerInfo("test-garden/data/info", infoMessage("abc"))
+			require.NoError(t, err)
+			mqttClient.AssertNumberOfCalls(t, "Publish", 1)
+		})
+
+		t.Run("ResendAfterCooldown", func(t *testing.T) {
+			c.Add(defaultConfigSyncCooldown + time.Second)
+
+			mqttClient.On("Publish", mock.Anything, "test-garden/command/update_config", mock.Anything).Return(nil).Once()
+
+			err := w.getGardenAndSaveControllerInfo("test-garden/data/info", infoMessage("abc"))
+			require.NoError(t, err)
+			mqttClient.AssertNumberOfCalls(t, "Publish", 2)
+		})
+	})
+
+	t.Run("SyncedDoesNothing", func(t *testing.T) {
+		w, mqttClient, storageClient, garden := setup(t, ConfigSyncConfig{})
+
+		err := w.getGardenAndSaveControllerInfo("test-garden/data/info", infoMessage(garden.ControllerConfig.ToMessage().ConfigHash))
+		require.NoError(t, err)
+		mqttClient.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+
+		g, err := storageClient.Gardens.Get(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, pkg.ConfigSyncStatusSynced, g.ConfigSyncStatus())
+	})
+
+	t.Run("MissingHashIsIgnored", func(t *testing.T) {
+		w, mqttClient, storageClient, garden := setup(t, ConfigSyncConfig{})
+
+		err := w.getGardenAndSaveControllerInfo("test-garden/data/info", `info mac="aa:bb:cc:dd:ee:ff"`)
+		require.NoError(t, err)
+		mqttClient.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+
+		g, err := storageClient.Gardens.Get(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, pkg.ConfigSyncStatusUnknown, g.ConfigSyncStatus())
+	})
+
+	t.Run("EmptyHashSendsConfig", func(t *testing.T) {
+		w, mqttClient, storageClient, garden := setup(t, ConfigSyncConfig{})
+		mqttClient.On("Publish", mock.Anything, "test-garden/command/update_config", mock.Anything).Return(nil).Once()
+
+		err := w.getGardenAndSaveControllerInfo("test-garden/data/info", infoMessage(""))
+		require.NoError(t, err)
+		mqttClient.AssertExpectations(t)
+
+		g, err := storageClient.Gardens.Get(context.Background(), garden.GetID())
+		require.NoError(t, err)
+		assert.Equal(t, pkg.ConfigSyncStatusOutOfSync, g.ConfigSyncStatus())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		w, mqttClient, _, _ := setup(t, ConfigSyncConfig{Disable: true})
+
+		err := w.getGardenAndSaveControllerInfo("test-garden/data/info", infoMessage("abc"))
+		require.NoError(t, err)
+		mqttClient.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("StartupLogSendsConfig", func(t *testing.T) {
+		w, mqttClient, _, _ := setup(t, ConfigSyncConfig{})
+		mqttClient.On("Publish", mock.Anything, "test-garden/command/update_config", mock.Anything).Return(nil).Once()
+
+		err := w.getGardenAndHandleLogMessage(
+			"test-garden/data/logs",
+			`logs,level=info,source=startup message="garden-controller setup complete"`,
+		)
+		require.NoError(t, err)
+		mqttClient.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+
+		err = w.getGardenAndHandleLogMessage(
+			"test-garden/data/logs",
+			`logs,level=info,source=startup message="garden-controller setup complete",config_hash="abc"`,
+		)
+		require.NoError(t, err)
+		mqttClient.AssertExpectations(t)
+	})
+}
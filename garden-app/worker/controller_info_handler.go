@@ -29,6 +29,9 @@ func (w *Worker) getGardenAndSaveControllerInfo(topic string, payload string) er
 
 	garden, err := w.getGardenForTopic(topic)
 	if err != nil {
+		if w.recordPendingDevice(topic, info, err) {
+			return nil
+		}
 		return err
 	}
 
@@ -41,14 +44,25 @@ func (w *Worker) getGardenAndSaveControllerInfo(topic string, payload string) er
 	ctx := context.Background()
 	w.notifyFirmwareVersionChange(ctx, garden, info, logger)
 
-	return w.storageClient.ControllerInfo.Upsert(ctx, info)
-}
+	err = w.storageClient.ControllerInfo.Upsert(ctx, info)
+	if err != nil {
+		return err
+	}
 
-func (w *Worker) notifyFirmwareVersionChange(ctx context.Context, garden *pkg.Garden, info *pkg.ControllerInfo, logger *slog.Logger) {
-	if !garden.GetNotificationSettings().FirmwareChanged {
-		return
+	_, err = w.syncControllerConfig(ctx, garden, info.ConfigHash)
+	if err != nil {
+		logger.Error("unable to sync controller config", "error", err)
+	}
+
+	_, err = w.syncControllerSchedule(ctx, garden, info.ScheduleVersion)
+	if err != nil {
+		logger.Error("unable to sync controller schedule", "error", err)
 	}
 
+	return nil
+}
+
+func (w *Worker) notifyFirmwareVersionChange(ctx context.Context, garden *pkg.Garden, info *pkg.ControllerInfo, logger *slog.Logger) {
 	prevVersion := ""
 	if garden.ControllerInfo != nil {
 		prevVersion = garden.ControllerInfo.FirmwareVersion
@@ -58,6 +72,15 @@ func (w *Worker) notifyFirmwareVersionChange(ctx context.Context, garden *pkg.Ga
 		return
 	}
 
+	w.SendWebhookEvent(ctx, pkg.WebhookEventFirmwareChanged, garden, map[string]any{
+		"previous_version": prevVersion,
+		"version":          info.FirmwareVersion,
+	})
+
+	if !garden.GetNotificationSettings().FirmwareChanged {
+		return
+	}
+
 	prevDisplay := prevVersion
 	if prevDisplay == "" {
 		prevDisplay = "<none>"
@@ -70,7 +93,7 @@ func (w *Worker) notifyFirmwareVersionChange(ctx context.Context, garden *pkg.Ga
 }
 
 // parseControllerInfoMessage parses an InfluxDB line protocol message with the
-// measurement "info" and string fields "mac", "ip", and "version".
+// measurement "info" and string fields "mac", "ip", "version", "config_hash", and "schedule_version".
 func parseControllerInfoMessage(msg string) (*pkg.ControllerInfo, error) {
 	handler := lineprotocol.NewMetricHandler()
 	parser := lineprotocol.NewParser(handler)
@@ -97,6 +120,10 @@ func parseControllerInfoMessage(msg string) (*pkg.ControllerInfo, error) {
 				info.IPAddress = s
 			case "version":
 				info.FirmwareVersion = s
+			case "config_hash":
+				info.ConfigHash = &s
+			case "schedule_version":
+				info.ScheduleVersion = s
 			}
 		}
 	}
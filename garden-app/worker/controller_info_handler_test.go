@@ -110,10 +110,14 @@ func TestGetGardenAndSaveControllerInfo(t *testing.T) {
 		assert.WithinDuration(t, time.Now(), *info.UpdatedAt, 5*time.Second)
 	})
 
-	t.Run("UnknownTopicPrefix", func(t *testing.T) {
+	t.Run("UnknownTopicPrefixRecordsPendingDevice", func(t *testing.T) {
 		err := w.getGardenAndSaveControllerInfo("unknown/data/info", `info mac="aa:bb:cc:dd:ee:ff"`)
-		require.Error(t, err)
-		assert.Contains(t, err.Error(), "topic-prefix")
+		require.NoError(t, err)
+
+		device, err := storageClient.PendingDevices.Get(context.Background(), "unknown")
+		require.NoError(t, err)
+		require.NotNil(t, device)
+		assert.Equal(t, "aa:bb:cc:dd:ee:ff", device.MACAddress)
 	})
 
 	t.Run("UnexpectedMessage", func(t *testing.T) {
@@ -0,0 +1,310 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+)
+
+const defaultControllerScheduleRefreshInterval = time.Hour
+
+// ControllerScheduleConfig configures sending a fallback ControllerSchedule to controllers so they can keep watering
+// when the garden-app or MQTT broker is unavailable
+type ControllerScheduleConfig struct {
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// RefreshInterval is how often the schedules are compiled and sent to controllers if they changed. This keeps
+	// the weather scaling and changes to WaterSchedules up-to-date. Defaults to 1 hour
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" yaml:"refresh_interval"`
+}
+
+func (c ControllerScheduleConfig) refreshInterval() time.Duration {
+	if c.RefreshInterval <= 0 {
+		return defaultControllerScheduleRefreshInterval
+	}
+	return c.RefreshInterval
+}
+
+// WithControllerScheduleConfig configures sending fallback schedules to controllers
+func WithControllerScheduleConfig(cfg ControllerScheduleConfig) WorkerOption {
+	return func(w *Worker) {
+		w.controllerScheduleConfig = cfg
+	}
+}
+
+// controllerScheduleEnabled returns true if a ControllerSchedule should be sent to the Garden. Other drivers don't
+// use the garden-controller firmware so they can't keep a schedule
+func (w *Worker) controllerScheduleEnabled(g *pkg.Garden) bool {
+	return w.controllerScheduleConfig.Enable && w.mqttClient != nil && g.Driver.GetType() == pkg.DriverTypeMQTT
+}
+
+// recordWaterScaleFactor stores the most recent weather scale factor for a WaterSchedule so it can be used in the
+// ControllerSchedule. It is stored so the ControllerSchedule is still scaled after a restart
+func (w *Worker) recordWaterScaleFactor(ctx context.Context, ws *pkg.WaterSchedule, scaledDuration time.Duration) error {
+	if ws.Duration == nil || ws.Duration.Duration == 0 {
+		return nil
+	}
+
+	scaleFactor := float64(scaledDuration) / float64(ws.Duration.Duration)
+	return w.storageClient.WaterScaleFactors.Set(ctx, ws.GetID(), scaleFactor, clock.Now())
+}
+
+// waterScaleFactor returns the last-known weather scale factor for a WaterSchedule, or 1 if it has not been used yet
+func (w *Worker) waterScaleFactor(ctx context.Context, ws *pkg.WaterSchedule) (float64, error) {
+	scaleFactor, ok, err := w.storageClient.WaterScaleFactors.Get(ctx, ws.GetID())
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 1, nil
+	}
+	return scaleFactor, nil
+}
+
+// CompileControllerSchedule creates a ControllerSchedule from the Garden's LightSchedule, FanSchedule, and the
+// WaterSchedules used by its active Zones. WaterSchedules that use a cron interval or are not scheduled are skipped
+// because the controller only supports fixed intervals
+func (w *Worker) CompileControllerSchedule(ctx context.Context, g *pkg.Garden) (*pkg.ControllerSchedule, error) {
+	schedule := &pkg.ControllerSchedule{}
+
+	waterSchedules := map[string]*pkg.ControllerWaterSchedule{}
+	for z, err := range w.storageClient.Zones.Search(ctx, g.GetID(), nil) {
+		if err != nil {
+			return nil, fmt.Errorf("error getting Zones: %w", err)
+		}
+		if z.Position == nil {
+			continue
+		}
+
+		for _, wsID := range z.WaterScheduleIDs {
+			cws, ok := waterSchedules[wsID.String()]
+			if !ok {
+				cws, err = w.compileWaterSchedule(ctx, wsID.String())
+				if err != nil {
+					return nil, err
+				}
+				waterSchedules[wsID.String()] = cws
+			}
+			if cws == nil {
+				continue
+			}
+
+			cws.Zones = append(cws.Zones, pkg.ControllerZoneTarget{
+				Position: *z.Position,
+				ZoneID:   z.GetID(),
+			})
+		}
+	}
+
+	for _, cws := range waterSchedules {
+		if cws == nil {
+			continue
+		}
+		slices.SortFunc(cws.Zones, func(a, b pkg.ControllerZoneTarget) int {
+			return strings.Compare(a.ZoneID, b.ZoneID)
+		})
+		schedule.Water = append(schedule.Water, *cws)
+	}
+	slices.SortFunc(schedule.Water, func(a, b pkg.ControllerWaterSchedule) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	if g.LightSchedule != nil {
+		onTime := g.LightSchedule.StartTime.OnDate(clock.Now()).UTC()
+		midnight := time.Date(onTime.Year(), onTime.Month(), onTime.Day(), 0, 0, 0, 0, time.UTC)
+		schedule.Light = &pkg.ControllerLightSchedule{
+			Start:    int64(onTime.Sub(midnight).Seconds()),
+			Duration: int64(g.LightSchedule.Duration.Duration.Seconds()),
+		}
+	}
+
+	if g.FanSchedule != nil && g.FanSchedule.CycleDuration() > 0 {
+		schedule.Fan = &pkg.ControllerFanSchedule{
+			Duration:      int64(g.FanSchedule.Duration.Duration.Seconds()),
+			Interval:      int64(g.FanSchedule.Interval.Duration.Seconds()),
+			Power:         g.FanSchedule.PowerToPWM(),
+			OnlyWithLight: g.FanSchedule.OnlyWithLight && g.LightSchedule != nil,
+		}
+	}
+
+	schedule.Version = schedule.Hash()
+	return schedule, nil
+}
+
+// compileWaterSchedule returns nil if the WaterSchedule can't be used by the controller
+func (w *Worker) compileWaterSchedule(ctx context.Context, id string) (*pkg.ControllerWaterSchedule, error) {
+	ws, err := w.storageClient.WaterSchedules.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting WaterSchedule %q: %w", id, err)
+	}
+	if ws == nil || ws.EndDated() || ws.Interval == nil || ws.Interval.Cron != "" || ws.Interval.Duration <= 0 {
+		return nil, nil
+	}
+
+	nextWaterTime := w.GetNextWaterTime(ws)
+	if nextWaterTime == nil {
+		return nil, nil
+	}
+
+	interval := int64(ws.Interval.Duration.Seconds())
+	if interval == 0 {
+		return nil, nil
+	}
+
+	// Use the earliest watering time so the Start is the same after each watering
+	start := nextWaterTime.Unix() % interval
+
+	scaleFactor, err := w.waterScaleFactor(ctx, ws)
+	if err != nil {
+		return nil, fmt.Errorf("error getting scale factor for WaterSchedule %q: %w", id, err)
+	}
+	duration := time.Duration(float64(ws.Duration.Duration) * scaleFactor)
+
+	cws := &pkg.ControllerWaterSchedule{
+		ID:       ws.GetID(),
+		Start:    start,
+		Interval: interval,
+		Duration: duration.Milliseconds(),
+	}
+
+	if ws.ActivePeriod != nil {
+		startMonth, endMonth, err := ws.ActivePeriod.Months()
+		if err != nil {
+			return nil, fmt.Errorf("invalid ActivePeriod for WaterSchedule %q: %w", id, err)
+		}
+		cws.StartMonth = uint(startMonth)
+		cws.EndMonth = uint(endMonth)
+	}
+
+	return cws, nil
+}
+
+// UpdateControllerSchedule compiles and sends the ControllerSchedule to the Garden's controller. Nothing is sent if it
+// is the same as the last one that was sent
+func (w *Worker) UpdateControllerSchedule(ctx context.Context, gardenID string) error {
+	if !w.controllerScheduleConfig.Enable {
+		return nil
+	}
+
+	g, err := w.storageClient.Gardens.Get(ctx, gardenID)
+	if err != nil {
+		return fmt.Errorf("error getting Garden: %w", err)
+	}
+
+	return w.updateControllerSchedule(ctx, g)
+}
+
+func (w *Worker) updateControllerSchedule(ctx context.Context, g *pkg.Garden) error {
+	if !w.controllerScheduleEnabled(g) || g.EndDated() {
+		return nil
+	}
+
+	schedule, err := w.CompileControllerSchedule(ctx, g)
+	if err != nil {
+		return err
+	}
+
+	w.controllerScheduleMutex.Lock()
+	sentVersion := w.controllerScheduleVersions[g.GetID()]
+	w.controllerScheduleMutex.Unlock()
+
+	if sentVersion == schedule.Version {
+		return nil
+	}
+
+	return w.sendControllerSchedule(ctx, g, schedule)
+}
+
+// syncControllerSchedule sends the ControllerSchedule when the version reported by the controller doesn't match. An
+// empty version is ignored because older firmware doesn't report it. It returns true if the schedule was sent
+func (w *Worker) syncControllerSchedule(ctx context.Context, g *pkg.Garden, reportedVersion string) (bool, error) {
+	if !w.controllerScheduleEnabled(g) || reportedVersion == "" {
+		return false, nil
+	}
+
+	schedule, err := w.CompileControllerSchedule(ctx, g)
+	if err != nil {
+		return false, err
+	}
+
+	if schedule.Version == reportedVersion {
+		return false, nil
+	}
+
+	w.contextLogger(g, nil, nil).Info(
+		"controller schedule is out of date, sending current schedule",
+		"reported_version", reportedVersion,
+		"version", schedule.Version,
+	)
+	err = w.sendControllerSchedule(ctx, g, schedule)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (w *Worker) sendControllerSchedule(ctx context.Context, g *pkg.Garden, schedule *pkg.ControllerSchedule) error {
+	msg, err := json.Marshal(schedule)
+	if err != nil {
+		return fmt.Errorf("unable to marshal ControllerSchedule to JSON: %w", err)
+	}
+
+	topic, err := mqtt.ScheduleTopic(g.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %w", err)
+	}
+
+	err = w.publishCommand(ctx, g, CommandTypeSchedule, topic, msg)
+	if err != nil {
+		return fmt.Errorf("unable to publish ControllerSchedule: %w", err)
+	}
+
+	w.controllerScheduleMutex.Lock()
+	w.controllerScheduleVersions[g.GetID()] = schedule.Version
+	w.controllerScheduleMutex.Unlock()
+
+	return nil
+}
+
+// scheduleControllerScheduleRefresh creates the background job that keeps controller schedules up-to-date
+func (w *Worker) scheduleControllerScheduleRefresh() error {
+	if !w.controllerScheduleConfig.Enable {
+		return nil
+	}
+
+	_, err := w.scheduler.
+		Every(w.controllerScheduleConfig.refreshInterval()).
+		Tag("controller_schedule").
+		Do(w.RefreshControllerSchedules)
+	if err != nil {
+		return fmt.Errorf("error scheduling controller schedule refresh: %w", err)
+	}
+	return nil
+}
+
+// RefreshControllerSchedules sends the ControllerSchedule to every Garden's controller if it changed
+func (w *Worker) RefreshControllerSchedules() {
+	if w.storageClient == nil || !w.controllerScheduleConfig.Enable {
+		return
+	}
+
+	ctx := context.Background()
+	for g, err := range w.storageClient.Gardens.Search(ctx, "", nil) {
+		if err != nil {
+			w.logger.Error("error getting garden for controller schedule refresh", "error", err)
+			continue
+		}
+
+		err := w.updateControllerSchedule(ctx, g)
+		if err != nil {
+			w.contextLogger(g, nil, nil).Error("error updating controller schedule", "error", err)
+		}
+	}
+}
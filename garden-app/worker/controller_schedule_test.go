@@ -0,0 +1,164 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestControllerSchedule(t *testing.T) {
+	c := clock.MockTime()
+	defer clock.Reset()
+
+	setup := func(t *testing.T) (*Worker, *mqtt.MockClient, *pkg.Garden, *pkg.WaterSchedule) {
+		t.Helper()
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		power := uint(50)
+		garden := createExampleGarden()
+		garden.FanSchedule = &pkg.FanSchedule{
+			Duration: &pkg.Duration{Duration: 10 * time.Minute},
+			Interval: &pkg.Duration{Duration: 20 * time.Minute},
+			Power:    &power,
+		}
+		require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+		ws := createExampleWaterSchedule()
+		ws.StartTime = pkg.NewStartTime(c.Now().Add(-1 * time.Hour))
+		startDate := pkg.NewDate(c.Now())
+		ws.StartDate = &startDate
+		ws.ActivePeriod = &pkg.ActivePeriod{StartMonth: "April", EndMonth: "October"}
+		require.NoError(t, storageClient.WaterSchedules.Set(context.Background(), ws))
+
+		require.NoError(t, storageClient.Zones.Set(context.Background(), createExampleZone()))
+
+		mqttClient := new(mqtt.MockClient)
+		w := NewWorker(storageClient, nil, mqttClient, slog.Default(), WithControllerScheduleConfig(ControllerScheduleConfig{Enable: true}))
+		w.scheduler.StartAsync()
+		t.Cleanup(w.scheduler.Stop)
+
+		require.NoError(t, w.ScheduleWaterAction(ws))
+
+		return w, mqttClient, garden, ws
+	}
+
+	t.Run("Compile", func(t *testing.T) {
+		w, _, garden, ws := setup(t)
+		require.NoError(t, w.recordWaterScaleFactor(context.Background(), ws, 500*time.Millisecond))
+
+		schedule, err := w.CompileControllerSchedule(context.Background(), garden)
+		require.NoError(t, err)
+
+		assert.Equal(t, []pkg.ControllerWaterSchedule{{
+			ID:         ws.GetID(),
+			Start:      9 * 60 * 60,
+			Interval:   24 * 60 * 60,
+			Duration:   500,
+			StartMonth: 4,
+			EndMonth:   10,
+			Zones:      []pkg.ControllerZoneTarget{{Position: 0, ZoneID: createExampleZone().GetID()}},
+		}}, schedule.Water)
+		assert.Equal(t, &pkg.ControllerLightSchedule{Start: 5*60*60 + 1, Duration: 15 * 60 * 60}, schedule.Light)
+		assert.Equal(t, &pkg.ControllerFanSchedule{Duration: 600, Interval: 1200, Power: 127}, schedule.Fan)
+		assert.Equal(t, schedule.Hash(), schedule.Version)
+
+		t.Run("ScaleFactorIsStored", func(t *testing.T) {
+			restarted := NewWorker(w.storageClient, nil, new(mqtt.MockClient), slog.Default(), WithControllerScheduleConfig(ControllerScheduleConfig{Enable: true}))
+			restarted.scheduler.StartAsync()
+			defer restarted.scheduler.Stop()
+			require.NoError(t, restarted.ScheduleWaterAction(ws))
+
+			next, err := restarted.CompileControllerSchedule(context.Background(), garden)
+			require.NoError(t, err)
+			require.Len(t, next.Water, 1)
+			assert.Equal(t, int64(500), next.Water[0].Duration)
+		})
+
+		t.Run("VersionIsStableAfterWatering", func(t *testing.T) {
+			c.Add(24 * time.Hour)
+			defer c.Add(-24 * time.Hour)
+
+			next, err := w.CompileControllerSchedule(context.Background(), garden)
+			require.NoError(t, err)
+			assert.Equal(t, schedule.Version, next.Version)
+		})
+	})
+
+	t.Run("UpdateOnlySendsChanges", func(t *testing.T) {
+		w, mqttClient, garden, _ := setup(t)
+		mqttClient.On("Publish", mock.Anything, "test-garden/command/schedule", mock.Anything).Return(nil).Once()
+
+		err := w.UpdateControllerSchedule(context.Background(), garden.GetID())
+		require.NoError(t, err)
+
+		err = w.UpdateControllerSchedule(context.Background(), garden.GetID())
+		require.NoError(t, err)
+
+		mqttClient.AssertExpectations(t)
+		mqttClient.AssertNumberOfCalls(t, "Publish", 1)
+
+		var sent pkg.ControllerSchedule
+		require.NoError(t, json.Unmarshal(mqttClient.Calls[0].Arguments.Get(2).([]byte), &sent))
+		assert.Equal(t, sent.Hash(), sent.Version)
+	})
+
+	t.Run("Sync", func(t *testing.T) {
+		w, _, garden, _ := setup(t)
+		schedule, err := w.CompileControllerSchedule(context.Background(), garden)
+		require.NoError(t, err)
+
+		tests := []struct {
+			name            string
+			reportedVersion string
+			expectSent      bool
+		}{
+			{"NotSupported", "", false},
+			{"UpToDate", schedule.Version, false},
+			{"NoSchedule", pkg.ControllerScheduleVersionNone, true},
+			{"OutOfDate", "abc", true},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mqttClient := new(mqtt.MockClient)
+				mqttClient.On("Publish", mock.Anything, "test-garden/command/schedule", mock.Anything).Return(nil)
+				w.mqttClient = mqttClient
+
+				sent, err := w.syncControllerSchedule(context.Background(), garden, tt.reportedVersion)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectSent, sent)
+
+				if tt.expectSent {
+					mqttClient.AssertNumberOfCalls(t, "Publish", 1)
+				} else {
+					mqttClient.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+				}
+			})
+		}
+	})
+
+	t.Run("OtherDriverDoesNotSend", func(t *testing.T) {
+		w, mqttClient, garden, _ := setup(t)
+		garden.Driver = &pkg.DriverConfig{Type: pkg.DriverTypeHTTPRelay}
+
+		sent, err := w.syncControllerSchedule(context.Background(), garden, "abc")
+		require.NoError(t, err)
+		assert.False(t, sent)
+
+		mqttClient.AssertNotCalled(t, "Publish", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+)
+
+// DesiredStateConfig configures publishing retained desired-state messages for the light and fan. The broker keeps
+// these messages so a controller gets the correct state as soon as it subscribes, even if the server is down
+type DesiredStateConfig struct {
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+}
+
+// WithDesiredStateConfig configures publishing retained desired-state messages
+func WithDesiredStateConfig(cfg DesiredStateConfig) WorkerOption {
+	return func(w *Worker) {
+		w.desiredStateConfig = cfg
+	}
+}
+
+// desiredLightState is the retained message published to the desired light topic
+type desiredLightState struct {
+	State pkg.LightState `json:"state"`
+}
+
+// desiredFanState is the retained message published to the desired fan topic. Until is a Unix timestamp in seconds
+// and is zero when the fan should be off
+type desiredFanState struct {
+	Power uint8 `json:"power"`
+	Until int64 `json:"until"`
+}
+
+// desiredStateEnabled returns true if desired-state messages should be published for the Garden. Other drivers
+// don't use the garden-controller firmware so they can't use the retained messages
+func (w *Worker) desiredStateEnabled(g *pkg.Garden) bool {
+	return w.desiredStateConfig.Enable && w.mqttClient != nil && g.Driver.GetType() == pkg.DriverTypeMQTT
+}
+
+// publishDesiredLightState publishes the expected light state after a LightAction is executed. Nothing is published
+// if the state is unknown, which happens when toggling a light that has not reported its state
+func (w *Worker) publishDesiredLightState(ctx context.Context, g *pkg.Garden) error {
+	if !w.desiredStateEnabled(g) {
+		return nil
+	}
+
+	w.deviceStateMutex.Lock()
+	state := w.getDeviceState(g.GetID()).expectedLightState(g, clock.Now())
+	w.deviceStateMutex.Unlock()
+
+	if state == nil {
+		return nil
+	}
+
+	topic, err := mqtt.DesiredLightTopic(g.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %w", err)
+	}
+
+	return w.publishDesiredState(ctx, topic, desiredLightState{State: *state})
+}
+
+// publishDesiredFanState publishes the fan power and the time it should run until after a FanAction is executed
+func (w *Worker) publishDesiredFanState(ctx context.Context, g *pkg.Garden) error {
+	if !w.desiredStateEnabled(g) {
+		return nil
+	}
+
+	w.deviceStateMutex.Lock()
+	override := w.getDeviceState(g.GetID()).fanOverride
+	w.deviceStateMutex.Unlock()
+
+	if override == nil {
+		return nil
+	}
+
+	msg := desiredFanState{}
+	if override.power > 0 {
+		msg.Power = override.power
+		msg.Until = override.until.Unix()
+	}
+
+	topic, err := mqtt.DesiredFanTopic(g.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %w", err)
+	}
+
+	return w.publishDesiredState(ctx, topic, msg)
+}
+
+func (w *Worker) publishDesiredState(ctx context.Context, topic string, state any) error {
+	msg, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("unable to marshal desired state to JSON: %w", err)
+	}
+
+	err = w.mqttClient.PublishRetained(ctx, topic, msg)
+	if err != nil {
+		return fmt.Errorf("unable to publish desired state: %w", err)
+	}
+	return nil
+}
+
+// ClearDesiredState removes the retained desired-state messages for the light and/or fan. This is used when a
+// schedule is removed or the Garden is deleted so the controller doesn't keep using an old state
+func (w *Worker) ClearDesiredState(ctx context.Context, g *pkg.Garden, light, fan bool) error {
+	if !w.desiredStateEnabled(g) {
+		return nil
+	}
+
+	var topicFuncs []func(string) (string, error)
+	if light {
+		topicFuncs = append(topicFuncs, mqtt.DesiredLightTopic)
+	}
+	if fan {
+		topicFuncs = append(topicFuncs, mqtt.DesiredFanTopic)
+	}
+
+	var errs []error
+	for _, topicFunc := range topicFuncs {
+		topic, err := topicFunc(g.TopicPrefix)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to fill MQTT topic template: %w", err))
+			continue
+		}
+
+		// an empty retained message removes it from the broker
+		err = w.mqttClient.PublishRetained(ctx, topic, nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to clear desired state %q: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,118 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDesiredState(t *testing.T) {
+	c := clock.MockTime()
+	defer clock.Reset()
+
+	garden := createExampleGarden()
+
+	t.Run("DisabledDoesNotPublish", func(t *testing.T) {
+		mqttClient := new(mqtt.MockClient)
+		mqttClient.On("Publish", mock.Anything, "test-garden/command/light", mock.Anything).Return(nil)
+
+		w := NewWorker(nil, nil, mqttClient, slog.Default())
+		err := w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: pkg.LightStateOn})
+		require.NoError(t, err)
+
+		mqttClient.AssertExpectations(t)
+		mqttClient.AssertNotCalled(t, "PublishRetained", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	tests := []struct {
+		name          string
+		execute       func(*Worker) error
+		expectedTopic string
+		expectedMsg   string
+	}{
+		{
+			"LightOn",
+			func(w *Worker) error {
+				return w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: pkg.LightStateOn})
+			},
+			"test-garden/desired/light",
+			`{"state":"ON"}`,
+		},
+		{
+			"LightToggleWithUnknownState",
+			func(w *Worker) error {
+				return w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: pkg.LightStateToggle})
+			},
+			"",
+			"",
+		},
+		{
+			"FanOn",
+			func(w *Worker) error {
+				return w.ExecuteFanAction(context.Background(), garden, &action.FanAction{Duration: 60000, Power: 255})
+			},
+			"test-garden/desired/fan",
+			fmt.Sprintf(`{"power":255,"until":%d}`, c.Now().Add(time.Minute).Unix()),
+		},
+		{
+			"FanOff",
+			func(w *Worker) error {
+				return w.ExecuteFanAction(context.Background(), garden, &action.FanAction{Power: 0})
+			},
+			"test-garden/desired/fan",
+			`{"power":0,"until":0}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mqttClient := new(mqtt.MockClient)
+			mqttClient.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			if tt.expectedTopic != "" {
+				mqttClient.On("PublishRetained", mock.Anything, tt.expectedTopic, []byte(tt.expectedMsg)).Return(nil)
+			}
+
+			w := NewWorker(nil, nil, mqttClient, slog.Default(), WithDesiredStateConfig(DesiredStateConfig{Enable: true}))
+			err := tt.execute(w)
+			require.NoError(t, err)
+
+			mqttClient.AssertExpectations(t)
+			if tt.expectedTopic == "" {
+				mqttClient.AssertNotCalled(t, "PublishRetained", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+
+	t.Run("Clear", func(t *testing.T) {
+		mqttClient := new(mqtt.MockClient)
+		mqttClient.On("PublishRetained", mock.Anything, "test-garden/desired/light", []byte(nil)).Return(nil)
+		mqttClient.On("PublishRetained", mock.Anything, "test-garden/desired/fan", []byte(nil)).Return(nil)
+
+		w := NewWorker(nil, nil, mqttClient, slog.Default(), WithDesiredStateConfig(DesiredStateConfig{Enable: true}))
+		err := w.ClearDesiredState(context.Background(), garden, true, true)
+		require.NoError(t, err)
+
+		mqttClient.AssertExpectations(t)
+	})
+
+	t.Run("OtherDriverDoesNotPublish", func(t *testing.T) {
+		g := createExampleGarden()
+		g.Driver = &pkg.DriverConfig{Type: pkg.DriverTypeHTTPRelay}
+
+		mqttClient := new(mqtt.MockClient)
+		w := NewWorker(nil, nil, mqttClient, slog.Default(), WithDesiredStateConfig(DesiredStateConfig{Enable: true}))
+		err := w.ClearDesiredState(context.Background(), g, true, true)
+		require.NoError(t, err)
+
+		mqttClient.AssertNotCalled(t, "PublishRetained", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,436 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	lineprotocol "github.com/influxdata/line-protocol"
+)
+
+const (
+	defaultDeviceStateResendCooldown = time.Minute
+	// deviceStateTransitionTolerance ignores mismatches that are reported right around a scheduled change since
+	// the controller and server clocks are not exactly in sync
+	deviceStateTransitionTolerance = 10 * time.Second
+)
+
+// DeviceStateConfig configures how the Worker reacts when a controller reports a light or fan state that does not
+// match the expected state
+type DeviceStateConfig struct {
+	// DisableResend disables re-sending light and fan commands when the reported state does not match
+	DisableResend bool `mapstructure:"disable_resend" yaml:"disable_resend"`
+	// ResendCooldown is the minimum time between re-sending commands to the same device. This prevents a controller
+	// that is unable to change state from receiving endless commands. Defaults to 1 minute
+	ResendCooldown time.Duration `mapstructure:"resend_cooldown" yaml:"resend_cooldown"`
+}
+
+func (c DeviceStateConfig) resendCooldown() time.Duration {
+	if c.ResendCooldown <= 0 {
+		return defaultDeviceStateResendCooldown
+	}
+	return c.ResendCooldown
+}
+
+// WithDeviceStateConfig configures how light and fan state mismatches are handled
+func WithDeviceStateConfig(cfg DeviceStateConfig) WorkerOption {
+	return func(w *Worker) {
+		w.deviceStateConfig = cfg
+	}
+}
+
+// deviceState is the state reported by a Garden's controller and the most recent light and fan commands
+type deviceState struct {
+	light           *pkg.LightState
+	lightReportedAt time.Time
+	lightOverride   *lightOverride
+	lightResentAt   time.Time
+
+	fanPower      *uint8
+	fanReportedAt time.Time
+	fanOverride   *fanOverride
+	fanResentAt   time.Time
+}
+
+// lightOverride is the state from a LightAction, which is expected instead of the LightSchedule until its next change
+type lightOverride struct {
+	// state is nil when the action toggled the light and the previous state was unknown
+	state *pkg.LightState
+	// until is zero when there is no LightSchedule to return to
+	until time.Time
+}
+
+// fanOverride is the power from a FanAction, which is expected instead of the FanSchedule until it ends
+type fanOverride struct {
+	power uint8
+	// until is zero when the fan was turned off and there is no FanSchedule to return to
+	until time.Time
+}
+
+func (o *lightOverride) activeAt(now time.Time) bool {
+	return o != nil && (o.until.IsZero() || now.Before(o.until))
+}
+
+func (o *fanOverride) activeAt(now time.Time) bool {
+	return o != nil && (o.until.IsZero() || now.Before(o.until))
+}
+
+// getDeviceState returns the deviceState for a Garden and creates it if it doesn't exist. The caller must hold
+// deviceStateMutex
+func (w *Worker) getDeviceState(gardenID string) *deviceState {
+	state, ok := w.deviceStates[gardenID]
+	if !ok {
+		state = &deviceState{}
+		w.deviceStates[gardenID] = state
+	}
+	return state
+}
+
+// GetDeviceState returns the actual light and fan state reported by the Garden's controller along with the
+// expected state. It returns nil if the controller has not reported any state
+func (w *Worker) GetDeviceState(g *pkg.Garden) *pkg.DeviceState {
+	now := clock.Now()
+
+	w.deviceStateMutex.Lock()
+	state, ok := w.deviceStates[g.GetID()]
+	if !ok {
+		w.deviceStateMutex.Unlock()
+		return nil
+	}
+	result := &pkg.DeviceState{
+		Light: state.lightDeviceState(g, now),
+		Fan:   state.fanDeviceState(g, now),
+	}
+	w.deviceStateMutex.Unlock()
+
+	if result.Light == nil && result.Fan == nil {
+		return nil
+	}
+	return result
+}
+
+func (s *deviceState) lightDeviceState(g *pkg.Garden, now time.Time) *pkg.LightDeviceState {
+	if s.light == nil {
+		return nil
+	}
+
+	actual := *s.light
+	reportedAt := s.lightReportedAt
+	return &pkg.LightDeviceState{
+		Actual:     &actual,
+		Expected:   s.expectedLightState(g, now),
+		ReportedAt: &reportedAt,
+	}
+}
+
+func (s *deviceState) fanDeviceState(g *pkg.Garden, now time.Time) *pkg.FanDeviceState {
+	if s.fanPower == nil {
+		return nil
+	}
+
+	power := *s.fanPower
+	reportedAt := s.fanReportedAt
+	return &pkg.FanDeviceState{
+		ActualPower:    &power,
+		ExpectedActive: s.expectedFanActive(g, now),
+		ReportedAt:     &reportedAt,
+	}
+}
+
+// expectedLightState uses the most recent LightAction until the LightSchedule's next change. Otherwise, it
+// uses the LightSchedule. It returns nil if the expected state is unknown
+func (s *deviceState) expectedLightState(g *pkg.Garden, now time.Time) *pkg.LightState {
+	if s.lightOverride.activeAt(now) {
+		return s.lightOverride.state
+	}
+	if g.LightSchedule == nil {
+		return nil
+	}
+	state := g.LightSchedule.ExpectedStateAtTime(now)
+	return &state
+}
+
+// expectedFanActive uses the most recent FanAction until it ends. Otherwise, it uses the FanSchedule. It returns
+// nil if the expected state is unknown
+func (s *deviceState) expectedFanActive(g *pkg.Garden, now time.Time) *bool {
+	if s.fanOverride.activeAt(now) {
+		active := s.fanOverride.power > 0
+		return &active
+	}
+	if g.FanSchedule == nil {
+		return nil
+	}
+
+	active := g.FanSchedule.IsActiveAtTime(now)
+	if active && g.FanSchedule.OnlyWithLight && g.LightSchedule != nil {
+		active = g.LightSchedule.ExpectedStateAtTime(now) == pkg.LightStateOn
+	}
+	return &active
+}
+
+// nearLightTransition returns true if the expected light state changes within the tolerance of now
+func (s *deviceState) nearLightTransition(g *pkg.Garden, now time.Time) bool {
+	if s.lightOverride != nil && !s.lightOverride.until.IsZero() && withinTolerance(s.lightOverride.until, now) {
+		return true
+	}
+	if g.LightSchedule == nil {
+		return false
+	}
+	nextChange, _ := g.LightSchedule.NextChange(now.Add(-deviceStateTransitionTolerance))
+	return withinTolerance(nextChange, now)
+}
+
+// nearFanTransition returns true if the expected fan state changes within the tolerance of now
+func (s *deviceState) nearFanTransition(g *pkg.Garden, now time.Time) bool {
+	if s.fanOverride != nil && !s.fanOverride.until.IsZero() && withinTolerance(s.fanOverride.until, now) {
+		return true
+	}
+	if s.nearLightTransition(g, now) && g.FanSchedule != nil && g.FanSchedule.OnlyWithLight {
+		return true
+	}
+	if g.FanSchedule == nil {
+		return false
+	}
+	nextChange, _ := g.FanSchedule.NextChange(now.Add(-deviceStateTransitionTolerance))
+	return withinTolerance(nextChange, now)
+}
+
+func withinTolerance(t, now time.Time) bool {
+	return !t.IsZero() && t.Sub(now).Abs() <= deviceStateTransitionTolerance
+}
+
+// recordLightAction keeps track of the light state that was sent to the controller so it is expected instead of
+// the LightSchedule
+func (w *Worker) recordLightAction(g *pkg.Garden, input *action.LightAction) {
+	now := clock.Now()
+
+	w.deviceStateMutex.Lock()
+	defer w.deviceStateMutex.Unlock()
+
+	state := w.getDeviceState(g.GetID())
+
+	override := &lightOverride{}
+	switch {
+	case input.State != pkg.LightStateToggle:
+		expected := input.State
+		override.state = &expected
+	case state.light != nil:
+		expected := *state.light ^ 1
+		override.state = &expected
+	}
+	if g.LightSchedule != nil {
+		override.until, _ = g.LightSchedule.NextChange(now)
+	}
+
+	state.lightOverride = override
+}
+
+// recordFanAction keeps track of the fan power that was sent to the controller so it is expected instead of
+// the FanSchedule
+func (w *Worker) recordFanAction(g *pkg.Garden, input *action.FanAction) {
+	now := clock.Now()
+
+	w.deviceStateMutex.Lock()
+	defer w.deviceStateMutex.Unlock()
+
+	override := &fanOverride{power: input.Power}
+	switch {
+	case input.Power > 0:
+		override.until = now.Add(time.Duration(input.Duration) * time.Millisecond)
+	case g.FanSchedule != nil:
+		override.until, _ = g.FanSchedule.NextChange(now)
+	}
+
+	w.getDeviceState(g.GetID()).fanOverride = override
+}
+
+func (w *Worker) handleLightStateMessage(_ mqtt.Client, msg mqtt.Message) {
+	err := w.getGardenAndHandleLightState(msg.Topic(), string(msg.Payload()))
+	if err != nil {
+		w.logger.With("topic", msg.Topic(), "error", err).Error("error handling light state message")
+	}
+}
+
+func (w *Worker) getGardenAndHandleLightState(topic string, payload string) error {
+	logger := w.logger.With("topic", topic)
+
+	value, err := parseDeviceStateMessage(payload, "light", "state")
+	if err != nil {
+		logger.Warn("unexpected light state message", "message", payload, "error", err)
+		return nil
+	}
+
+	garden, err := w.getGardenForTopic(topic)
+	if err != nil {
+		return err
+	}
+	logger = logger.With("garden_id", garden.GetID())
+
+	actual := pkg.LightStateOff
+	if value > 0 {
+		actual = pkg.LightStateOn
+	}
+
+	now := clock.Now()
+
+	w.deviceStateMutex.Lock()
+	state := w.getDeviceState(garden.GetID())
+	state.light = &actual
+	state.lightReportedAt = now
+
+	expected := state.expectedLightState(garden, now)
+	resend := expected != nil && *expected != actual && w.shouldResend(state.lightResentAt, now) &&
+		!state.nearLightTransition(garden, now)
+	if resend {
+		state.lightResentAt = now
+	}
+	w.deviceStateMutex.Unlock()
+
+	logger.Debug("received light state", "actual", actual.String())
+	w.publishLiveEvent(pkg.LiveEventLight, garden, map[string]any{"state": actual.String()})
+	w.publishHomeAssistantLightState(garden, actual)
+	if !resend {
+		return nil
+	}
+
+	logger.Warn("light state does not match expected state, re-sending LightAction", "actual", actual.String(), "expected", expected.String())
+	return w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: *expected})
+}
+
+func (w *Worker) handleFanStateMessage(_ mqtt.Client, msg mqtt.Message) {
+	err := w.getGardenAndHandleFanState(msg.Topic(), string(msg.Payload()))
+	if err != nil {
+		w.logger.With("topic", msg.Topic(), "error", err).Error("error handling fan state message")
+	}
+}
+
+func (w *Worker) getGardenAndHandleFanState(topic string, payload string) error {
+	logger := w.logger.With("topic", topic)
+
+	value, err := parseDeviceStateMessage(payload, "fan", "power")
+	if err != nil {
+		logger.Warn("unexpected fan state message", "message", payload, "error", err)
+		return nil
+	}
+	if value < 0 || value > 255 {
+		logger.Warn("unexpected fan power", "power", value)
+		return nil
+	}
+
+	garden, err := w.getGardenForTopic(topic)
+	if err != nil {
+		return err
+	}
+	logger = logger.With("garden_id", garden.GetID())
+
+	power := uint8(value)
+	now := clock.Now()
+
+	w.deviceStateMutex.Lock()
+	state := w.getDeviceState(garden.GetID())
+	state.fanPower = &power
+	state.fanReportedAt = now
+
+	var input *action.FanAction
+	expected := state.expectedFanActive(garden, now)
+	mismatch := expected != nil && *expected != (power > 0)
+	if mismatch && w.shouldResend(state.fanResentAt, now) && !state.nearFanTransition(garden, now) {
+		input = state.expectedFanAction(garden, now)
+	}
+	if input != nil {
+		state.fanResentAt = now
+	}
+	w.deviceStateMutex.Unlock()
+
+	logger.Debug("received fan state", "power", power)
+	w.publishLiveEvent(pkg.LiveEventFan, garden, map[string]any{"power": power})
+	w.publishHomeAssistantFanState(garden, power)
+	if input == nil {
+		return nil
+	}
+
+	logger.Warn("fan state does not match expected state, re-sending FanAction", "power", power, "expected_active", *expected)
+	return w.ExecuteFanAction(context.Background(), garden, input)
+}
+
+// expectedFanAction creates the FanAction that puts the fan into its expected state. It returns nil if the
+// remaining duration is unknown
+func (s *deviceState) expectedFanAction(g *pkg.Garden, now time.Time) *action.FanAction {
+	if !*s.expectedFanActive(g, now) {
+		return &action.FanAction{Power: 0}
+	}
+
+	var until time.Time
+	var power uint8
+	switch {
+	case s.fanOverride.activeAt(now):
+		until, power = s.fanOverride.until, s.fanOverride.power
+	case g.FanSchedule != nil:
+		until, _ = g.FanSchedule.NextChange(now)
+		power = g.FanSchedule.PowerToPWM()
+	}
+
+	remaining := until.Sub(now)
+	if until.IsZero() || remaining <= 0 || power == 0 {
+		return nil
+	}
+
+	return &action.FanAction{
+		Duration: remaining.Milliseconds(),
+		Power:    power,
+	}
+}
+
+func (w *Worker) shouldResend(lastResent time.Time, now time.Time) bool {
+	if w.deviceStateConfig.DisableResend {
+		return false
+	}
+	return lastResent.IsZero() || now.Sub(lastResent) >= w.deviceStateConfig.resendCooldown()
+}
+
+// parseDeviceStateMessage parses an InfluxDB line protocol message from the controller with the expected
+// measurement and returns the numeric value of the field
+func parseDeviceStateMessage(msg string, measurement string, field string) (int64, error) {
+	handler := lineprotocol.NewMetricHandler()
+	parser := lineprotocol.NewParser(handler)
+	metrics, err := parser.Parse([]byte(msg))
+	if err != nil {
+		return 0, fmt.Errorf("error parsing line protocol: %w", err)
+	}
+	if len(metrics) != 1 {
+		return 0, fmt.Errorf("expected 1 metric, got %d", len(metrics))
+	}
+
+	m := metrics[0]
+	if m.Name() != measurement {
+		return 0, fmt.Errorf("unexpected measurement %q", m.Name())
+	}
+
+	for _, f := range m.FieldList() {
+		if f.Key != field {
+			continue
+		}
+
+		switch v := f.Value.(type) {
+		case float64:
+			return int64(v), nil
+		case int64:
+			return v, nil
+		case uint64:
+			//nolint:gosec
+			return int64(v), nil
+		case bool:
+			if v {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return 0, fmt.Errorf("unexpected type %T for field %q", f.Value, field)
+	}
+
+	return 0, fmt.Errorf("missing field %q", field)
+}
@@ -0,0 +1,221 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDeviceStateMessage(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		measurement string
+		field       string
+		expected    int64
+		wantErr     bool
+	}{
+		{"LightOn", `light,garden="test-garden" state=1`, "light", "state", 1, false},
+		{"LightOff", `light,garden="test-garden" state=0`, "light", "state", 0, false},
+		{"FanPower", `fan,garden="test-garden" power=127`, "fan", "power", 127, false},
+		{"IntegerValue", `fan,garden="test-garden" power=255i`, "fan", "power", 255, false},
+		{"WrongMeasurement", `fan,garden="test-garden" power=255`, "light", "state", 0, true},
+		{"MissingField", `light,garden="test-garden" power=1`, "light", "state", 0, true},
+		{"StringField", `light,garden="test-garden" state="ON"`, "light", "state", 0, true},
+		{"EmptyMessage", ``, "light", "state", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := parseDeviceStateMessage(tt.input, tt.measurement, tt.field)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestDeviceState(t *testing.T) {
+	setup := func(t *testing.T, cfg DeviceStateConfig) (*Worker, *pkg.Garden, func(time.Duration), func() []string) {
+		t.Helper()
+
+		mockClock := clock.MockTime()
+		t.Cleanup(clock.Reset)
+		// Light is ON until 20:00:01 and fan is ON until 10:30
+		mockClock.Add(5 * time.Minute)
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		power := uint(50)
+		garden := createExampleGarden()
+		garden.FanSchedule = &pkg.FanSchedule{
+			Duration: &pkg.Duration{Duration: 30 * time.Minute},
+			Interval: &pkg.Duration{Duration: 30 * time.Minute},
+			Power:    &power,
+		}
+		require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+		published := []string{}
+		mqttClient := new(mqtt.MockClient)
+		mqttClient.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, topic string, msg []byte) error {
+			published = append(published, fmt.Sprintf("%s %s", topic, msg))
+			return nil
+		})
+
+		w := NewWorker(storageClient, nil, mqttClient, slog.Default(), WithDeviceStateConfig(cfg))
+		return w, garden, mockClock.Add, func() []string {
+			result := published
+			published = []string{}
+			return result
+		}
+	}
+
+	t.Run("NoReportedState", func(t *testing.T) {
+		w, garden, _, _ := setup(t, DeviceStateConfig{})
+		assert.Nil(t, w.GetDeviceState(garden))
+	})
+
+	t.Run("LightMatches", func(t *testing.T) {
+		w, garden, _, published := setup(t, DeviceStateConfig{})
+
+		require.NoError(t, w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=1`))
+		assert.Empty(t, published())
+
+		state := w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		require.NotNil(t, state.Light)
+		assert.Nil(t, state.Fan)
+		assert.Equal(t, pkg.LightStateOn, *state.Light.Actual)
+		assert.Equal(t, pkg.LightStateOn, *state.Light.Expected)
+		assert.False(t, state.Light.Mismatch())
+	})
+
+	t.Run("LightMismatchResends", func(t *testing.T) {
+		w, garden, advance, published := setup(t, DeviceStateConfig{})
+
+		require.NoError(t, w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=0`))
+		assert.Equal(t, []string{`test-garden/command/light {"state":"ON"}`}, published())
+
+		state := w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		assert.Equal(t, pkg.LightStateOff, *state.Light.Actual)
+		assert.Equal(t, pkg.LightStateOn, *state.Light.Expected)
+		assert.True(t, state.Light.Mismatch())
+
+		// another mismatch during the cooldown is not re-sent
+		require.NoError(t, w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=0`))
+		assert.Empty(t, published())
+
+		advance(time.Minute)
+		require.NoError(t, w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=0`))
+		assert.Equal(t, []string{`test-garden/command/light {"state":"ON"}`}, published())
+	})
+
+	t.Run("LightActionOverridesSchedule", func(t *testing.T) {
+		w, garden, advance, published := setup(t, DeviceStateConfig{})
+
+		require.NoError(t, w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: pkg.LightStateOff}))
+		assert.Equal(t, []string{`test-garden/command/light {"state":"OFF"}`}, published())
+
+		require.NoError(t, w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=0`))
+		assert.Empty(t, published())
+
+		state := w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		assert.Equal(t, pkg.LightStateOff, *state.Light.Expected)
+		assert.False(t, state.Light.Mismatch())
+
+		// the schedule is expected again after its next change
+		advance(24 * time.Hour)
+		state = w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		assert.Equal(t, pkg.LightStateOn, *state.Light.Expected)
+	})
+
+	t.Run("LightToggleWithUnknownState", func(t *testing.T) {
+		w, garden, _, published := setup(t, DeviceStateConfig{})
+
+		require.NoError(t, w.ExecuteLightAction(context.Background(), garden, &action.LightAction{State: pkg.LightStateToggle}))
+		published()
+
+		require.NoError(t, w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=0`))
+		assert.Empty(t, published())
+
+		state := w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		assert.Nil(t, state.Light.Expected)
+		assert.False(t, state.Light.Mismatch())
+	})
+
+	t.Run("FanMismatchResendsRemainingDuration", func(t *testing.T) {
+		w, garden, _, published := setup(t, DeviceStateConfig{})
+
+		require.NoError(t, w.getGardenAndHandleFanState("test-garden/data/fan", `fan,garden="test-garden" power=0`))
+		assert.Equal(t, []string{`test-garden/command/fan {"duration":1500000,"power":127}`}, published())
+
+		state := w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		require.NotNil(t, state.Fan)
+		assert.Equal(t, uint8(0), *state.Fan.ActualPower)
+		assert.True(t, *state.Fan.ExpectedActive)
+		assert.True(t, state.Fan.Mismatch())
+	})
+
+	t.Run("FanMismatchTurnsOff", func(t *testing.T) {
+		w, garden, advance, published := setup(t, DeviceStateConfig{})
+		advance(30 * time.Minute)
+
+		require.NoError(t, w.getGardenAndHandleFanState("test-garden/data/fan", `fan,garden="test-garden" power=127`))
+		assert.Equal(t, []string{`test-garden/command/fan {"duration":0,"power":0}`}, published())
+
+		state := w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		assert.True(t, state.Fan.ActualActive())
+		assert.False(t, *state.Fan.ExpectedActive)
+	})
+
+	t.Run("FanMismatchNearScheduledChange", func(t *testing.T) {
+		w, _, advance, published := setup(t, DeviceStateConfig{})
+		advance(25*time.Minute - time.Second)
+
+		require.NoError(t, w.getGardenAndHandleFanState("test-garden/data/fan", `fan,garden="test-garden" power=0`))
+		assert.Empty(t, published())
+	})
+
+	t.Run("ResendDisabled", func(t *testing.T) {
+		w, garden, _, published := setup(t, DeviceStateConfig{DisableResend: true})
+
+		require.NoError(t, w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=0`))
+		require.NoError(t, w.getGardenAndHandleFanState("test-garden/data/fan", `fan,garden="test-garden" power=0`))
+		assert.Empty(t, published())
+
+		state := w.GetDeviceState(garden)
+		require.NotNil(t, state)
+		assert.True(t, state.Light.Mismatch())
+		assert.True(t, state.Fan.Mismatch())
+	})
+
+	t.Run("UnknownTopicPrefix", func(t *testing.T) {
+		w, _, _, _ := setup(t, DeviceStateConfig{})
+		err := w.getGardenAndHandleLightState("unknown/data/light", `light,garden="unknown" state=0`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "topic-prefix")
+	})
+}
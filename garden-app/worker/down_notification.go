@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
 )
@@ -25,10 +26,16 @@ func (w *Worker) handleHealthMessage(topic, payload string) {
 
 	garden, err := w.getGardenForTopic(topic)
 	if err != nil {
+		if w.recordPendingDevice(topic, nil, err) {
+			return
+		}
 		logger.Error("error getting Garden for health topic", "error", err)
 		return
 	}
 
+	w.publishLiveEvent(pkg.LiveEventHealth, garden, nil)
+	w.publishHomeAssistantHealth(garden)
+
 	downtime := garden.GetNotificationSettings().Downtime
 	if downtime == nil || downtime.Duration <= 0 {
 		return
@@ -73,6 +80,10 @@ func (w *Worker) handleDowntimeNotification(topic string) error {
 		return nil
 	}
 
+	w.SendWebhookEvent(context.Background(), pkg.WebhookEventControllerDown, garden, map[string]any{
+		"downtime": downtime.String(),
+	})
+
 	title := fmt.Sprintf("%s is down", garden.Name)
 	msg := fmt.Sprintf("Garden has been down for > %s", downtime.String())
 
@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/driver"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+)
+
+// driverRequestTimeout limits how long requests to devices controlled by drivers can take
+const driverRequestTimeout = 10 * time.Second
+
+// gardenDriver creates the driver configured for the Garden
+func (w *Worker) gardenDriver(g *pkg.Garden) (driver.Driver, error) {
+	d, err := driver.New(
+		g.Driver,
+		driver.WithMQTTDriver(mqttDriver{w}),
+		driver.WithHTTPClient(w.driverHTTPClient()),
+		driver.WithPublisher(w.mqttClient),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create %s driver: %w", g.Driver.GetType(), err)
+	}
+	return d, nil
+}
+
+// driverHTTPClient copies the worker's HTTP client and adds a timeout. Devices controlled by drivers should respond
+// quickly, but the worker's client doesn't have a timeout since it is also used to upload firmware
+func (w *Worker) driverHTTPClient() *http.Client {
+	client := *w.httpClient
+	if client.Timeout == 0 {
+		client.Timeout = driverRequestTimeout
+	}
+	return &client
+}
+
+// mqttDriver is the default driver. It uses the MQTT protocol implemented by the garden-controller firmware and
+// queues commands when the broker is unavailable
+type mqttDriver struct {
+	w *Worker
+}
+
+var _ driver.Driver = mqttDriver{}
+
+func (d mqttDriver) Water(ctx context.Context, g *pkg.Garden, msg action.WaterMessage) error {
+	_, err := d.water(ctx, g, msg)
+	return err
+}
+
+// water publishes the WaterMessage and also returns true if it was queued
+func (d mqttDriver) water(ctx context.Context, g *pkg.Garden, waterMessage action.WaterMessage) (bool, error) {
+	msg, err := json.Marshal(waterMessage)
+	if err != nil {
+		return false, fmt.Errorf("unable to marshal WaterMessage to JSON: %w", err)
+	}
+
+	topic, err := mqtt.WaterTopic(g.TopicPrefix)
+	if err != nil {
+		return false, fmt.Errorf("unable to fill MQTT topic template: %w", err)
+	}
+
+	return d.w.publishOrQueueCommand(ctx, g, CommandTypeWater, topic, msg)
+}
+
+func (d mqttDriver) Stop(ctx context.Context, g *pkg.Garden, all bool) error {
+	topicFunc := mqtt.StopTopic
+	commandType := CommandTypeStop
+	if all {
+		topicFunc = mqtt.StopAllTopic
+		commandType = CommandTypeStopAll
+	}
+	topic, err := topicFunc(g.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+	}
+
+	return d.w.publishCommand(ctx, g, commandType, topic, []byte("no message"))
+}
+
+func (d mqttDriver) Light(ctx context.Context, g *pkg.Garden, input *action.LightAction) error {
+	msg, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("unable to marshal LightAction to JSON: %v", err)
+	}
+
+	topic, err := mqtt.LightTopic(g.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+	}
+
+	return d.w.publishCommand(ctx, g, CommandTypeLight, topic, msg)
+}
+
+func (d mqttDriver) Fan(ctx context.Context, g *pkg.Garden, input *action.FanAction) error {
+	msg, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("unable to marshal FanAction to JSON: %v", err)
+	}
+
+	topic, err := mqtt.FanTopic(g.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+	}
+
+	return d.w.publishCommand(ctx, g, CommandTypeFan, topic, msg)
+}
+
+func (d mqttDriver) UpdateConfig(ctx context.Context, g *pkg.Garden, cfg *pkg.ControllerConfig) error {
+	msg, err := json.Marshal(cfg.ToMessage())
+	if err != nil {
+		return fmt.Errorf("unable to marshal ControllerConfig to JSON: %v", err)
+	}
+
+	topic, err := mqtt.UpdateTopic(g.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+	}
+
+	return d.w.publishCommand(ctx, g, CommandTypeUpdate, topic, msg)
+}
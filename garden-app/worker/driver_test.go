@@ -0,0 +1,355 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGardenDriver(t *testing.T) {
+	t.Run("HTTPRelay", func(t *testing.T) {
+		requests := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.String())
+		}))
+		defer server.Close()
+
+		garden := createExampleGarden()
+		garden.Driver = &pkg.DriverConfig{
+			Type: pkg.DriverTypeHTTPRelay,
+			Options: map[string]any{
+				"url":         server.URL,
+				"zone_relays": []any{0, 1},
+				"light_relay": 2,
+			},
+		}
+
+		// nothing is published since the worker handles the water status itself
+		mqttClient := new(mqtt.MockClient)
+		w := NewWorker(nil, nil, mqttClient, slog.Default())
+
+		err := w.ExecuteZoneAction(context.Background(), garden, createExampleZone(), &action.ZoneAction{
+			Water: &action.WaterAction{Duration: &pkg.Duration{Duration: time.Minute}},
+		})
+		require.NoError(t, err)
+		t.Cleanup(w.stopDriverWaterings)
+
+		err = w.ExecuteGardenAction(context.Background(), garden, &action.GardenAction{
+			Light: &action.LightAction{State: pkg.LightStateOn},
+		})
+		require.NoError(t, err)
+
+		err = w.ExecuteGardenAction(context.Background(), garden, &action.GardenAction{
+			Stop: &action.StopAction{All: true},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"/relay/0?timer=60&turn=on",
+			"/relay/2?turn=on",
+			"/relay/0?turn=off",
+			"/relay/1?turn=off",
+		}, requests)
+
+		mqttClient.AssertExpectations(t)
+	})
+
+	t.Run("Zigbee2MQTT", func(t *testing.T) {
+		garden := createExampleGarden()
+		garden.Driver = &pkg.DriverConfig{
+			Type: pkg.DriverTypeZigbee2MQTT,
+			Options: map[string]any{
+				"zone_devices": []any{"valve"},
+			},
+		}
+
+		mqttClient := new(mqtt.MockClient)
+		mqttClient.On("Publish", mock.Anything, "zigbee2mqtt/valve/set", []byte(`{"state":"ON","on_time":60}`)).Return(nil)
+		w := NewWorker(nil, nil, mqttClient, slog.Default())
+		t.Cleanup(w.stopDriverWaterings)
+
+		err := w.ExecuteZoneAction(context.Background(), garden, createExampleZone(), &action.ZoneAction{
+			Water: &action.WaterAction{Duration: &pkg.Duration{Duration: time.Minute}},
+		})
+		require.NoError(t, err)
+		mqttClient.AssertExpectations(t)
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		garden := createExampleGarden()
+		garden.Driver = &pkg.DriverConfig{Type: pkg.DriverTypeHTTPRelay}
+
+		w := NewWorker(nil, nil, new(mqtt.MockClient), slog.Default())
+
+		err := w.ExecuteGardenAction(context.Background(), garden, &action.GardenAction{
+			Stop: &action.StopAction{},
+		})
+		require.EqualError(t, err, "unable to execute StopAction: unable to create http_relay driver: url must be provided")
+	})
+
+	t.Run("UpdateConfigNotSupported", func(t *testing.T) {
+		garden := createExampleGarden()
+		garden.ControllerConfig = &pkg.ControllerConfig{}
+		garden.Driver = &pkg.DriverConfig{
+			Type:    pkg.DriverTypeZigbee2MQTT,
+			Options: map[string]any{},
+		}
+
+		w := NewWorker(nil, nil, new(mqtt.MockClient), slog.Default())
+
+		err := w.ExecuteGardenAction(context.Background(), garden, &action.GardenAction{
+			Update: &action.UpdateAction{Config: true},
+		})
+		require.EqualError(t, err, "unable to execute UpdateAction: unable to publish UpdateAction: controller config is not supported by the zigbee2mqtt driver")
+	})
+}
+
+func TestDriverWaterings(t *testing.T) {
+	setup := func(t *testing.T) (*Worker, *pkg.Garden, *pkg.Zone, *pkg.Zone, func() []string) {
+		t.Helper()
+
+		var mu sync.Mutex
+		requests := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/relay/3" && r.URL.Query().Get("turn") == "on" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, r.URL.String())
+		}))
+		t.Cleanup(server.Close)
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		garden := createExampleGarden()
+		garden.Driver = &pkg.DriverConfig{
+			Type: pkg.DriverTypeHTTPRelay,
+			Options: map[string]any{
+				"url":         server.URL,
+				"zone_relays": []any{0, 1, 3},
+			},
+		}
+		require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+		zone1 := createExampleZone()
+		zone2 := createExampleZone()
+		zone2.ID = babyapi.NewID()
+		one := uint(1)
+		zone2.Position = &one
+
+		w := NewWorker(storageClient, nil, nil, slog.Default(), WithWateringWatchdogConfig(WateringWatchdogConfig{}))
+		t.Cleanup(w.stopWateringWatches)
+		t.Cleanup(w.stopDriverWaterings)
+
+		return w, garden, zone1, zone2, func() []string {
+			mu.Lock()
+			defer mu.Unlock()
+			return slices.Clone(requests)
+		}
+	}
+
+	water := func(t *testing.T, w *Worker, g *pkg.Garden, z *pkg.Zone) error {
+		t.Helper()
+		return w.ExecuteWaterAction(context.Background(), g, z, &action.WaterAction{Duration: &pkg.Duration{Duration: time.Minute}})
+	}
+
+	// waitForCurrentZone waits for the timer callback to finish updating the queue
+	waitForCurrentZone := func(t *testing.T, w *Worker, g *pkg.Garden, zoneID string) {
+		t.Helper()
+		require.Eventually(t, func() bool {
+			w.driverWateringsMutex.Lock()
+			defer w.driverWateringsMutex.Unlock()
+
+			queue, ok := w.driverWaterings[g.GetID()]
+			if !ok || queue.current == nil {
+				return zoneID == ""
+			}
+			return queue.current.zone.GetID() == zoneID
+		}, time.Second, 10*time.Millisecond)
+	}
+
+	t.Run("OneZoneAtATime", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+
+		w, garden, zone1, zone2, getRequests := setup(t)
+
+		require.NoError(t, water(t, w, garden, zone1))
+		require.NoError(t, water(t, w, garden, zone2))
+
+		assert.Equal(t, []string{"/relay/0?timer=60&turn=on"}, getRequests())
+		assert.Equal(t, pkg.WateringStateStarted, w.GetWateringStatus(zone1.GetID()).State)
+		assert.Equal(t, pkg.WateringStateSent, w.GetWateringStatus(zone2.GetID()).State)
+
+		mockClock.Add(time.Minute)
+		waitForCurrentZone(t, w, garden, zone2.GetID())
+
+		assert.Equal(t, []string{"/relay/0?timer=60&turn=on", "/relay/1?timer=60&turn=on"}, getRequests())
+		assert.Equal(t, pkg.WateringStateCompleted, w.GetWateringStatus(zone1.GetID()).State)
+		assert.Equal(t, pkg.WateringStateStarted, w.GetWateringStatus(zone2.GetID()).State)
+
+		mockClock.Add(time.Minute)
+		waitForCurrentZone(t, w, garden, "")
+
+		assert.Equal(t, pkg.WateringStateCompleted, w.GetWateringStatus(zone2.GetID()).State)
+	})
+
+	t.Run("StopStartsNextWatering", func(t *testing.T) {
+		_ = clock.MockTime()
+		defer clock.Reset()
+
+		w, garden, zone1, zone2, getRequests := setup(t)
+
+		require.NoError(t, water(t, w, garden, zone1))
+		require.NoError(t, water(t, w, garden, zone2))
+
+		err := w.ExecuteStopAction(context.Background(), garden, &action.StopAction{})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{
+			"/relay/0?timer=60&turn=on",
+			"/relay/0?turn=off",
+			"/relay/1?turn=off",
+			"/relay/3?turn=off",
+			"/relay/1?timer=60&turn=on",
+		}, getRequests())
+		assert.Equal(t, pkg.WateringStateCancelled, w.GetWateringStatus(zone1.GetID()).State)
+		assert.Equal(t, pkg.WateringStateStarted, w.GetWateringStatus(zone2.GetID()).State)
+	})
+
+	t.Run("StopAllCancelsPendingWaterings", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+
+		w, garden, zone1, zone2, getRequests := setup(t)
+
+		require.NoError(t, water(t, w, garden, zone1))
+		require.NoError(t, water(t, w, garden, zone2))
+
+		err := w.ExecuteStopAction(context.Background(), garden, &action.StopAction{All: true})
+		require.NoError(t, err)
+
+		mockClock.Add(time.Hour)
+
+		assert.NotContains(t, getRequests(), "/relay/1?timer=60&turn=on")
+		assert.Equal(t, pkg.WateringStateCancelled, w.GetWateringStatus(zone1.GetID()).State)
+		assert.Equal(t, pkg.WateringStateCancelled, w.GetWateringStatus(zone2.GetID()).State)
+	})
+
+	t.Run("DriverError", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+
+		w, garden, zone1, zone2, getRequests := setup(t)
+
+		// the third relay returns an error when it is turned on
+		zone3 := createExampleZone()
+		zone3.ID = babyapi.NewID()
+		two := uint(2)
+		zone3.Position = &two
+
+		err := water(t, w, garden, zone3)
+		require.Error(t, err)
+		assert.Equal(t, pkg.WateringStateNotStarted, w.GetWateringStatus(zone3.GetID()).State)
+
+		// a failed watering that was queued doesn't block the next one
+		require.NoError(t, water(t, w, garden, zone1))
+		require.NoError(t, water(t, w, garden, zone3))
+		require.NoError(t, water(t, w, garden, zone2))
+
+		mockClock.Add(time.Minute)
+		waitForCurrentZone(t, w, garden, zone2.GetID())
+
+		assert.Equal(t, []string{"/relay/0?timer=60&turn=on", "/relay/1?timer=60&turn=on"}, getRequests())
+		assert.Equal(t, pkg.WateringStateNotStarted, w.GetWateringStatus(zone3.GetID()).State)
+		assert.Equal(t, pkg.WateringStateStarted, w.GetWateringStatus(zone2.GetID()).State)
+	})
+
+	t.Run("ContinueAfterRestart", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+
+		w, garden, zone1, zone2, getRequests := setup(t)
+		require.NoError(t, w.storageClient.Zones.Set(context.Background(), zone1))
+		require.NoError(t, w.storageClient.Zones.Set(context.Background(), zone2))
+
+		require.NoError(t, water(t, w, garden, zone1))
+		require.NoError(t, water(t, w, garden, zone2))
+
+		mockClock.Add(30 * time.Second)
+		// stop the first worker like it was restarted
+		w.stopDriverWaterings()
+		w.stopWateringWatches()
+
+		restarted := NewWorker(w.storageClient, nil, nil, slog.Default(), WithWateringWatchdogConfig(WateringWatchdogConfig{}))
+		t.Cleanup(restarted.stopWateringWatches)
+		t.Cleanup(restarted.stopDriverWaterings)
+		restarted.restoreDriverWaterings()
+
+		// the current watering is not started again
+		assert.Equal(t, []string{"/relay/0?timer=60&turn=on"}, getRequests())
+		assert.Equal(t, pkg.WateringStateStarted, restarted.GetWateringStatus(zone1.GetID()).State)
+		assert.Equal(t, pkg.WateringStateSent, restarted.GetWateringStatus(zone2.GetID()).State)
+
+		mockClock.Add(30 * time.Second)
+		waitForCurrentZone(t, restarted, garden, zone2.GetID())
+
+		assert.Equal(t, []string{"/relay/0?timer=60&turn=on", "/relay/1?timer=60&turn=on"}, getRequests())
+		assert.Equal(t, pkg.WateringStateCompleted, restarted.GetWateringStatus(zone1.GetID()).State)
+		assert.Equal(t, pkg.WateringStateStarted, restarted.GetWateringStatus(zone2.GetID()).State)
+
+		mockClock.Add(time.Minute)
+		waitForCurrentZone(t, restarted, garden, "")
+
+		stored, err := w.storageClient.DriverWaterings.List(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, stored)
+	})
+
+	t.Run("CompleteWhileStopped", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+
+		w, garden, zone1, zone2, getRequests := setup(t)
+		require.NoError(t, w.storageClient.Zones.Set(context.Background(), zone1))
+		require.NoError(t, w.storageClient.Zones.Set(context.Background(), zone2))
+
+		require.NoError(t, water(t, w, garden, zone1))
+		require.NoError(t, water(t, w, garden, zone2))
+
+		// stop the first worker like it was restarted
+		w.stopDriverWaterings()
+		w.stopWateringWatches()
+		mockClock.Add(2 * time.Minute)
+
+		restarted := NewWorker(w.storageClient, nil, nil, slog.Default(), WithWateringWatchdogConfig(WateringWatchdogConfig{}))
+		t.Cleanup(restarted.stopWateringWatches)
+		t.Cleanup(restarted.stopDriverWaterings)
+		restarted.restoreDriverWaterings()
+
+		// the relay's timer turned off the first Zone, so the next one is started right away
+		assert.Equal(t, []string{"/relay/0?timer=60&turn=on", "/relay/1?timer=60&turn=on"}, getRequests())
+		assert.Equal(t, pkg.WateringStateCompleted, restarted.GetWateringStatus(zone1.GetID()).State)
+		assert.Equal(t, pkg.WateringStateStarted, restarted.GetWateringStatus(zone2.GetID()).State)
+	})
+}
@@ -0,0 +1,336 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/driver"
+)
+
+// driverWatering is a watering for a Garden that uses a driver without its own queue
+type driverWatering struct {
+	garden               *pkg.Garden
+	zone                 *pkg.Zone
+	driver               driver.Driver
+	message              action.WaterMessage
+	notificationClientID string
+	startedAt            time.Time
+}
+
+// driverWateringQueue waters one Zone at a time for a Garden, like the garden-controller does
+type driverWateringQueue struct {
+	current *driverWatering
+	timer   clock.Timer
+	pending []*driverWatering
+}
+
+// waterWithDriver waters using a driver that doesn't have its own queue. These drivers turn on every valve they are
+// asked to, so the worker only starts the next watering for the Garden after the current one completes. Waterings
+// are stored until they are done so the queue continues after restarting. The drivers don't report anything, so the
+// worker handles the water status when it starts and completes watering instead of waiting for a controller
+func (w *Worker) waterWithDriver(ctx context.Context, watering *driverWatering) error {
+	w.driverWateringsMutex.Lock()
+	defer w.driverWateringsMutex.Unlock()
+
+	// watch before starting since the start is handled right away
+	w.watchWatering(watering.garden, watering.zone, watering.message, watering.notificationClientID)
+	w.storeDriverWatering(ctx, watering)
+
+	queue, ok := w.driverWaterings[watering.garden.GetID()]
+	if !ok {
+		queue = &driverWateringQueue{}
+		w.driverWaterings[watering.garden.GetID()] = queue
+	}
+
+	if queue.current != nil {
+		queue.pending = append(queue.pending, watering)
+		return nil
+	}
+
+	err := w.startDriverWatering(ctx, queue, watering, time.Duration(watering.message.Duration)*time.Millisecond)
+	if err != nil {
+		w.deleteDriverWatering(ctx, watering)
+		w.failWatering(watering.message.EventID, err)
+		return err
+	}
+	w.handleDriverWaterStatus(watering, pkg.WaterStatusStarted, 0)
+	return nil
+}
+
+// startDriverWatering uses the driver to start watering and completes it after the duration. It expects the caller
+// to hold driverWateringsMutex
+func (w *Worker) startDriverWatering(ctx context.Context, queue *driverWateringQueue, watering *driverWatering, duration time.Duration) error {
+	err := watering.driver.Water(ctx, watering.garden, watering.message)
+	if err != nil {
+		return err
+	}
+
+	watering.startedAt = clock.Now()
+	w.setDriverWateringStarted(ctx, watering)
+	w.continueDriverWatering(queue, watering, duration)
+	return nil
+}
+
+// continueDriverWatering sets the watering as the current one for the Garden and completes it after the duration.
+// It expects the caller to hold driverWateringsMutex
+func (w *Worker) continueDriverWatering(queue *driverWateringQueue, watering *driverWatering, duration time.Duration) {
+	gardenID := watering.garden.GetID()
+	eventID := watering.message.EventID
+
+	queue.current = watering
+	queue.timer = clock.AfterFunc(duration, func() { w.completeDriverWatering(gardenID, eventID) })
+}
+
+// completeDriverWatering handles the completed watering and starts the next one. The drivers turn valves on with the
+// device's own timer, so the valve is already off when the duration is over
+func (w *Worker) completeDriverWatering(gardenID, eventID string) {
+	w.driverWateringsMutex.Lock()
+	defer w.driverWateringsMutex.Unlock()
+
+	queue, ok := w.driverWaterings[gardenID]
+	if !ok || queue.current == nil || queue.current.message.EventID != eventID {
+		return
+	}
+
+	w.deleteDriverWatering(context.Background(), queue.current)
+	w.handleDriverWaterStatus(queue.current, pkg.WaterStatusCompleted, queue.current.message.Duration)
+	queue.current = nil
+	w.startNextDriverWatering(queue)
+}
+
+// startNextDriverWatering starts the first pending watering that the driver is able to start. It expects the caller
+// to hold driverWateringsMutex
+func (w *Worker) startNextDriverWatering(queue *driverWateringQueue) {
+	for len(queue.pending) > 0 {
+		watering := queue.pending[0]
+		queue.pending = queue.pending[1:]
+
+		err := w.startDriverWatering(context.Background(), queue, watering, time.Duration(watering.message.Duration)*time.Millisecond)
+		if err == nil {
+			w.handleDriverWaterStatus(watering, pkg.WaterStatusStarted, 0)
+			return
+		}
+
+		logger := w.contextLogger(watering.garden, watering.zone, nil).With("event_id", watering.message.EventID)
+		logger.Error("unable to start queued watering", "error", err)
+		schedulerErrors.WithLabelValues(zoneLabels(watering.zone)...).Inc()
+
+		w.deleteDriverWatering(context.Background(), watering)
+		watch := w.failWatering(watering.message.EventID, err)
+		if watch != nil {
+			w.sendWateringErrorNotification(
+				watch,
+				fmt.Sprintf("%s: Watering Not Started", watering.zone.Name),
+				fmt.Sprintf("The %s driver was unable to start watering: %v\nGarden: %s", watering.garden.Driver.GetType(), err, watering.garden.Name),
+				logger,
+			)
+		}
+	}
+}
+
+// cancelDriverWaterings handles the cancelled watering after the driver stopped it. The next watering is started
+// unless all waterings are stopped
+func (w *Worker) cancelDriverWaterings(ctx context.Context, g *pkg.Garden, all bool) {
+	w.driverWateringsMutex.Lock()
+	defer w.driverWateringsMutex.Unlock()
+
+	queue, ok := w.driverWaterings[g.GetID()]
+	if !ok {
+		return
+	}
+
+	if queue.current != nil {
+		queue.timer.Stop()
+		w.deleteDriverWatering(ctx, queue.current)
+		w.handleDriverWaterStatus(queue.current, pkg.WaterStatusCancelled, clock.Since(queue.current.startedAt).Milliseconds())
+		queue.current = nil
+	}
+
+	if !all {
+		w.startNextDriverWatering(queue)
+		return
+	}
+
+	for _, watering := range queue.pending {
+		w.deleteDriverWatering(ctx, watering)
+		w.handleDriverWaterStatus(watering, pkg.WaterStatusCancelled, 0)
+	}
+	delete(w.driverWaterings, g.GetID())
+}
+
+// handleDriverWaterStatus handles the same water status that the garden-controller reports, so the watchdog, live
+// events, webhooks, and notifications work the same for all drivers
+func (w *Worker) handleDriverWaterStatus(watering *driverWatering, status pkg.WaterStatus, millis int64) {
+	event := action.WaterStatusEvent{
+		Duration: millis,
+		ZoneID:   watering.message.ZoneID,
+		Position: watering.message.Position,
+		EventID:  watering.message.EventID,
+		Status:   status,
+	}
+
+	logger := w.contextLogger(watering.garden, watering.zone, nil).With("event_id", event.EventID, "status", status)
+
+	w.handleWateringStatusEvent(event)
+	err := w.handleGardenWaterStatus(watering.garden, event, logger)
+	if err != nil {
+		logger.Error("error handling water status", "error", err)
+	}
+}
+
+// restoreDriverWaterings continues the stored queues after restarting. A watering that should have completed while
+// the worker was stopped is completed right away since the device's timer already turned the valve off
+func (w *Worker) restoreDriverWaterings() {
+	if w.storageClient == nil {
+		return
+	}
+
+	ctx := context.Background()
+	stored, err := w.storageClient.DriverWaterings.List(ctx)
+	if err != nil {
+		w.logger.Error("unable to get stored driver waterings", "error", err)
+		return
+	}
+
+	w.driverWateringsMutex.Lock()
+	defer w.driverWateringsMutex.Unlock()
+
+	for _, sw := range stored {
+		logger := w.logger.With("garden_id", sw.GardenID, "zone_id", sw.ZoneID, "event_id", sw.EventID)
+
+		watering, err := w.loadDriverWatering(ctx, sw)
+		if err != nil {
+			logger.Error("unable to restore driver watering", "error", err)
+			err = w.storageClient.DriverWaterings.Delete(ctx, sw.EventID)
+			if err != nil {
+				logger.Error("unable to delete driver watering", "error", err)
+			}
+			continue
+		}
+
+		w.watchWatering(watering.garden, watering.zone, watering.message, watering.notificationClientID)
+
+		queue, ok := w.driverWaterings[sw.GardenID]
+		if !ok {
+			queue = &driverWateringQueue{}
+			w.driverWaterings[sw.GardenID] = queue
+		}
+
+		if sw.StartedAt == nil || queue.current != nil {
+			queue.pending = append(queue.pending, watering)
+			continue
+		}
+
+		watering.startedAt = *sw.StartedAt
+		w.handleWateringStatusEvent(action.WaterStatusEvent{EventID: sw.EventID, Status: pkg.WaterStatusStarted})
+
+		remaining := sw.Duration - clock.Since(*sw.StartedAt)
+		if remaining > 0 {
+			logger.Info("continuing driver watering after restart", "remaining", remaining)
+			w.continueDriverWatering(queue, watering, remaining)
+			continue
+		}
+
+		w.deleteDriverWatering(ctx, watering)
+		w.handleDriverWaterStatus(watering, pkg.WaterStatusCompleted, watering.message.Duration)
+	}
+
+	for _, queue := range w.driverWaterings {
+		if queue.current == nil {
+			w.startNextDriverWatering(queue)
+		}
+	}
+}
+
+// loadDriverWatering gets the Garden, Zone, and driver for a stored watering
+func (w *Worker) loadDriverWatering(ctx context.Context, sw *pkg.DriverWatering) (*driverWatering, error) {
+	g, err := w.storageClient.Gardens.Get(ctx, sw.GardenID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Garden: %w", err)
+	}
+	z, err := w.storageClient.Zones.Get(ctx, sw.ZoneID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting Zone: %w", err)
+	}
+
+	d, err := w.gardenDriver(g)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.(mqttDriver); ok {
+		return nil, fmt.Errorf("garden uses the %s driver now", g.Driver.GetType())
+	}
+
+	return &driverWatering{
+		garden: g,
+		zone:   z,
+		driver: d,
+		message: action.WaterMessage{
+			Duration: sw.Duration.Milliseconds(),
+			ZoneID:   sw.ZoneID,
+			Position: sw.Position,
+			EventID:  sw.EventID,
+			Source:   action.Source(sw.Source),
+		},
+		notificationClientID: sw.NotificationClientID,
+	}, nil
+}
+
+// storeDriverWatering stores the watering so it is restored after restarting
+func (w *Worker) storeDriverWatering(ctx context.Context, watering *driverWatering) {
+	if w.storageClient == nil {
+		return
+	}
+
+	err := w.storageClient.DriverWaterings.Add(ctx, &pkg.DriverWatering{
+		GardenID:             watering.garden.GetID(),
+		ZoneID:               watering.message.ZoneID,
+		Position:             watering.message.Position,
+		EventID:              watering.message.EventID,
+		Source:               string(watering.message.Source),
+		Duration:             time.Duration(watering.message.Duration) * time.Millisecond,
+		NotificationClientID: watering.notificationClientID,
+		QueuedAt:             clock.Now(),
+	})
+	if err != nil {
+		w.contextLogger(watering.garden, watering.zone, nil).Error("unable to store driver watering", "event_id", watering.message.EventID, "error", err)
+	}
+}
+
+func (w *Worker) setDriverWateringStarted(ctx context.Context, watering *driverWatering) {
+	if w.storageClient == nil {
+		return
+	}
+
+	err := w.storageClient.DriverWaterings.SetStarted(ctx, watering.message.EventID, watering.startedAt)
+	if err != nil {
+		w.contextLogger(watering.garden, watering.zone, nil).Error("unable to store driver watering start", "event_id", watering.message.EventID, "error", err)
+	}
+}
+
+func (w *Worker) deleteDriverWatering(ctx context.Context, watering *driverWatering) {
+	if w.storageClient == nil {
+		return
+	}
+
+	err := w.storageClient.DriverWaterings.Delete(ctx, watering.message.EventID)
+	if err != nil {
+		w.contextLogger(watering.garden, watering.zone, nil).Error("unable to delete driver watering", "event_id", watering.message.EventID, "error", err)
+	}
+}
+
+// stopDriverWaterings stops the timers for current waterings. Stored waterings are continued after restarting
+func (w *Worker) stopDriverWaterings() {
+	w.driverWateringsMutex.Lock()
+	defer w.driverWateringsMutex.Unlock()
+
+	for _, queue := range w.driverWaterings {
+		if queue.timer != nil {
+			queue.timer.Stop()
+		}
+	}
+}
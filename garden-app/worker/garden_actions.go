@@ -19,7 +19,6 @@ import (
 	"github.com/calvinmclean/automated-garden/garden-app/clock"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
-	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
 )
 
 // ErrFirmwareUpdateInProgress is returned when a firmware update is already running for a Garden.
@@ -95,61 +94,72 @@ func (w *Worker) sendFirmwareUpdateFailedNotification(ctx context.Context, g *pk
 	return w.sendNotificationForGarden(ctx, g, title, msg)
 }
 
-// ExecuteStopAction sends the message over MQTT to the embedded garden controller
+// ExecuteStopAction uses the Garden's driver to stop watering
 func (w *Worker) ExecuteStopAction(ctx context.Context, g *pkg.Garden, input *action.StopAction) error {
-	topicFunc := mqtt.StopTopic
-	if input.All {
-		topicFunc = mqtt.StopAllTopic
+	d, err := w.gardenDriver(g)
+	if err != nil {
+		return err
 	}
-	topic, err := topicFunc(g.TopicPrefix)
+
+	err = d.Stop(ctx, g, input.All)
 	if err != nil {
-		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+		return err
 	}
 
-	return w.mqttClient.Publish(ctx, topic, []byte("no message"))
+	if _, ok := d.(mqttDriver); !ok {
+		w.cancelDriverWaterings(ctx, g, input.All)
+	}
+	return nil
 }
 
-// ExecuteLightAction sends an MQTT message to the garden controller to change the state of the light
+// ExecuteLightAction uses the Garden's driver to change the state of the light
 func (w *Worker) ExecuteLightAction(ctx context.Context, g *pkg.Garden, input *action.LightAction) error {
-	msg, err := json.Marshal(input)
+	d, err := w.gardenDriver(g)
 	if err != nil {
-		return fmt.Errorf("unable to marshal LightAction to JSON: %v", err)
+		return err
 	}
 
-	topic, err := mqtt.LightTopic(g.TopicPrefix)
+	err = d.Light(ctx, g, input)
 	if err != nil {
-		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+		return fmt.Errorf("unable to publish LightAction: %v", err)
 	}
+	w.recordLightAction(g, input)
+	w.SendWebhookEvent(ctx, pkg.WebhookEventLightChanged, g, map[string]any{
+		"state": input.State.String(),
+	})
 
-	err = w.mqttClient.Publish(ctx, topic, msg)
+	// the action was already sent, so an error publishing the desired state is only logged
+	err = w.publishDesiredLightState(ctx, g)
 	if err != nil {
-		return fmt.Errorf("unable to publish LightAction: %v", err)
+		w.contextLogger(g, nil, nil).Error("unable to publish desired light state", "error", err)
 	}
 
 	return nil
 }
 
-// ExecuteFanAction sends an MQTT message to the garden controller to turn on the fan for a duration
+// ExecuteFanAction uses the Garden's driver to turn on the fan for a duration
 func (w *Worker) ExecuteFanAction(ctx context.Context, g *pkg.Garden, input *action.FanAction) error {
-	msg, err := json.Marshal(input)
+	d, err := w.gardenDriver(g)
 	if err != nil {
-		return fmt.Errorf("unable to marshal FanAction to JSON: %v", err)
+		return err
 	}
 
-	topic, err := mqtt.FanTopic(g.TopicPrefix)
+	err = d.Fan(ctx, g, input)
 	if err != nil {
-		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+		return fmt.Errorf("unable to publish FanAction: %v", err)
 	}
+	w.recordFanAction(g, input)
 
-	err = w.mqttClient.Publish(ctx, topic, msg)
+	// the action was already sent, so an error publishing the desired state is only logged
+	err = w.publishDesiredFanState(ctx, g)
 	if err != nil {
-		return fmt.Errorf("unable to publish FanAction: %v", err)
+		w.contextLogger(g, nil, nil).Error("unable to publish desired fan state", "error", err)
 	}
 
 	return nil
 }
 
-// ExecuteUpdateAction sends an MQTT message to the garden controller with the current configuration
+// ExecuteUpdateAction uses the Garden's driver to send the current configuration to the controller
 func (w *Worker) ExecuteUpdateAction(ctx context.Context, g *pkg.Garden, input *action.UpdateAction) error {
 	if !input.Config {
 		return errors.New("update action must have config=true")
@@ -158,17 +168,12 @@ func (w *Worker) ExecuteUpdateAction(ctx context.Context, g *pkg.Garden, input *
 		return errors.New("ControllerConfig is nil")
 	}
 
-	msg, err := json.Marshal(g.ControllerConfig.ToMessage())
-	if err != nil {
-		return fmt.Errorf("unable to marshal ControllerConfig to JSON: %v", err)
-	}
-
-	topic, err := mqtt.UpdateTopic(g.TopicPrefix)
+	d, err := w.gardenDriver(g)
 	if err != nil {
-		return fmt.Errorf("unable to fill MQTT topic template: %v", err)
+		return err
 	}
 
-	err = w.mqttClient.Publish(ctx, topic, msg)
+	err = d.UpdateConfig(ctx, g, g.ControllerConfig)
 	if err != nil {
 		return fmt.Errorf("unable to publish UpdateAction: %v", err)
 	}
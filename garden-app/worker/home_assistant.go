@@ -0,0 +1,515 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/homeassistant"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/babyapi"
+	paho "github.com/eclipse/paho.mqtt.golang"
+	lineprotocol "github.com/influxdata/line-protocol"
+)
+
+const (
+	defaultHomeAssistantDiscoveryPrefix = "homeassistant"
+	defaultHomeAssistantTopicPrefix     = "garden-app"
+	defaultHomeAssistantWaterDuration   = 15 * time.Minute
+	defaultHomeAssistantFanDuration     = time.Hour
+)
+
+// HomeAssistantConfig configures publishing Home Assistant MQTT discovery configs so Gardens and Zones can be
+// controlled from Home Assistant
+type HomeAssistantConfig struct {
+	Enabled bool `mapstructure:"enabled" yaml:"enabled"`
+	// DiscoveryPrefix is the prefix Home Assistant uses for discovery. Defaults to "homeassistant"
+	DiscoveryPrefix string `mapstructure:"discovery_prefix" yaml:"discovery_prefix"`
+	// TopicPrefix is the prefix for command and state topics used by Home Assistant. Defaults to "garden-app"
+	TopicPrefix string `mapstructure:"topic_prefix" yaml:"topic_prefix"`
+	// ZoneComponent is the type of entity used for Zones: "valve" (default) or "switch"
+	ZoneComponent string `mapstructure:"zone_component" yaml:"zone_component"`
+	// WaterDuration is used when a Zone is started before its duration is set in Home Assistant. Defaults to 15 minutes
+	WaterDuration time.Duration `mapstructure:"water_duration" yaml:"water_duration"`
+	// FanDuration is how long the fan runs when it is turned on from Home Assistant. Defaults to 1 hour
+	FanDuration time.Duration `mapstructure:"fan_duration" yaml:"fan_duration"`
+}
+
+func (c HomeAssistantConfig) options() homeassistant.Options {
+	opts := homeassistant.Options{
+		DiscoveryPrefix: c.DiscoveryPrefix,
+		TopicPrefix:     c.TopicPrefix,
+		ZoneComponent:   homeassistant.ComponentValve,
+	}
+	if opts.DiscoveryPrefix == "" {
+		opts.DiscoveryPrefix = defaultHomeAssistantDiscoveryPrefix
+	}
+	if opts.TopicPrefix == "" {
+		opts.TopicPrefix = defaultHomeAssistantTopicPrefix
+	}
+	if c.ZoneComponent == string(homeassistant.ComponentSwitch) {
+		opts.ZoneComponent = homeassistant.ComponentSwitch
+	}
+	return opts
+}
+
+func (c HomeAssistantConfig) waterDuration() time.Duration {
+	if c.WaterDuration <= 0 {
+		return defaultHomeAssistantWaterDuration
+	}
+	return c.WaterDuration
+}
+
+func (c HomeAssistantConfig) fanDuration() time.Duration {
+	if c.FanDuration <= 0 {
+		return defaultHomeAssistantFanDuration
+	}
+	return c.FanDuration
+}
+
+// WithHomeAssistantConfig configures the Home Assistant MQTT discovery integration
+func WithHomeAssistantConfig(cfg HomeAssistantConfig) WorkerOption {
+	return func(w *Worker) {
+		w.homeAssistantConfig = cfg
+	}
+}
+
+func (w *Worker) homeAssistantEnabled() bool {
+	return w.homeAssistantConfig.Enabled && w.mqttClient != nil
+}
+
+func (w *Worker) setupHomeAssistant() {
+	if !w.homeAssistantEnabled() {
+		return
+	}
+
+	opts := w.homeAssistantConfig.options()
+	w.mqttClient.AddHandler(mqtt.TopicHandler{
+		Topic:   opts.TopicPrefix + "/#",
+		Handler: w.handleHomeAssistantMessage,
+	})
+	w.mqttClient.AddHandler(mqtt.TopicHandler{
+		Topic:   opts.StatusTopic(),
+		Handler: w.handleHomeAssistantStatusMessage,
+	})
+}
+
+// handleHomeAssistantStatusMessage publishes all discovery configs when Home Assistant starts in case they were
+// not retained by the broker
+func (w *Worker) handleHomeAssistantStatusMessage(_ paho.Client, msg paho.Message) {
+	if string(msg.Payload()) != "online" {
+		return
+	}
+	w.logger.Info("Home Assistant is online, publishing discovery configs")
+	w.publishHomeAssistantDiscoveryAllGardens()
+}
+
+func (w *Worker) publishHomeAssistantDiscoveryAllGardens() {
+	if !w.homeAssistantEnabled() || w.storageClient == nil {
+		return
+	}
+
+	ctx := context.Background()
+	gardenIDs := []string{}
+	for g, err := range w.storageClient.Gardens.Search(ctx, "", nil) {
+		if err != nil {
+			w.logger.Error("unable to get Gardens for Home Assistant discovery", "error", err)
+			return
+		}
+		gardenIDs = append(gardenIDs, g.GetID())
+	}
+
+	// end-dated and deleted Gardens can still have discovery configs that need to be removed
+	publishedGardenIDs, err := w.storageClient.HomeAssistantTopics.GardenIDs(ctx)
+	if err != nil {
+		w.logger.Error("unable to get published Home Assistant discovery topics", "error", err)
+		return
+	}
+	for _, gardenID := range publishedGardenIDs {
+		if !slices.Contains(gardenIDs, gardenID) {
+			gardenIDs = append(gardenIDs, gardenID)
+		}
+	}
+
+	for _, gardenID := range gardenIDs {
+		if err := w.UpdateHomeAssistantDiscovery(ctx, gardenID); err != nil {
+			w.logger.Error("unable to publish Home Assistant discovery configs", "garden_id", gardenID, "error", err)
+		}
+	}
+}
+
+// UpdateHomeAssistantDiscovery publishes the discovery configs for a Garden and its Zones. Configs that were
+// previously published for removed Zones and sensors, or for an end-dated or deleted Garden, are removed. Published
+// topics are stored so they are also removed after a restart
+func (w *Worker) UpdateHomeAssistantDiscovery(ctx context.Context, gardenID string) error {
+	if !w.homeAssistantEnabled() {
+		return nil
+	}
+
+	entities, err := w.homeAssistantEntities(ctx, gardenID)
+	if err != nil {
+		return err
+	}
+
+	w.homeAssistantMutex.Lock()
+	defer w.homeAssistantMutex.Unlock()
+
+	previousTopics, err := w.storageClient.HomeAssistantTopics.List(ctx, gardenID)
+	if err != nil {
+		return err
+	}
+
+	opts := w.homeAssistantConfig.options()
+	topics := make([]string, 0, len(entities))
+	var errs []error
+	for _, e := range entities {
+		topic := opts.DiscoveryTopic(gardenID, e)
+		topics = append(topics, topic)
+
+		// the topic is stored before publishing so it is still removed later if publishing fails
+		if err := w.storageClient.HomeAssistantTopics.Add(ctx, gardenID, topic); err != nil {
+			errs = append(errs, fmt.Errorf("error storing discovery topic %q: %w", topic, err))
+			continue
+		}
+
+		msg, err := json.Marshal(e.Config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error marshalling discovery config for %q: %w", e.ObjectID, err))
+			continue
+		}
+		if err := w.mqttClient.PublishRetained(ctx, topic, msg); err != nil {
+			errs = append(errs, fmt.Errorf("error publishing discovery config for %q: %w", e.ObjectID, err))
+		}
+	}
+
+	for _, topic := range previousTopics {
+		if slices.Contains(topics, topic) {
+			continue
+		}
+		// an empty retained message removes the entity from Home Assistant
+		if err := w.mqttClient.PublishRetained(ctx, topic, nil); err != nil {
+			errs = append(errs, fmt.Errorf("error removing discovery config %q: %w", topic, err))
+			continue
+		}
+		if err := w.storageClient.HomeAssistantTopics.Delete(ctx, gardenID, topic); err != nil {
+			errs = append(errs, fmt.Errorf("error deleting discovery topic %q: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// homeAssistantEntities returns the entities for a Garden and its active Zones. It returns no entities if the
+// Garden is deleted or end-dated
+func (w *Worker) homeAssistantEntities(ctx context.Context, gardenID string) ([]homeassistant.Entity, error) {
+	g, err := w.storageClient.Gardens.Get(ctx, gardenID)
+	if errors.Is(err, babyapi.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting Garden: %w", err)
+	}
+	if g.EndDated() {
+		return nil, nil
+	}
+
+	zones := []*pkg.Zone{}
+	for z, err := range w.storageClient.Zones.Search(ctx, gardenID, nil) {
+		if err != nil {
+			return nil, fmt.Errorf("error getting Zones: %w", err)
+		}
+		if z.EndDated() {
+			continue
+		}
+		zones = append(zones, z)
+	}
+
+	return w.homeAssistantConfig.options().GardenEntities(g, zones), nil
+}
+
+func (w *Worker) handleHomeAssistantMessage(_ paho.Client, msg paho.Message) {
+	err := w.doHomeAssistantMessage(context.Background(), msg.Topic(), string(msg.Payload()))
+	if err != nil {
+		w.logger.With("topic", msg.Topic(), "error", err).Error("error handling Home Assistant command")
+	}
+}
+
+func (w *Worker) doHomeAssistantMessage(ctx context.Context, topic string, payload string) error {
+	opts := w.homeAssistantConfig.options()
+	cmd, ok := opts.ParseTopic(topic)
+	if !ok {
+		// state topics published by the garden-app are also received and ignored here
+		return nil
+	}
+
+	logger := w.logger.With("topic", topic, "garden_id", cmd.GardenID, "target", cmd.Target)
+	logger.Debug("received Home Assistant command", "payload", payload)
+
+	if cmd.Target == homeassistant.TargetZoneDuration {
+		return w.setHomeAssistantZoneDuration(ctx, cmd, payload)
+	}
+
+	g, err := w.storageClient.Gardens.Get(ctx, cmd.GardenID)
+	if err != nil {
+		return fmt.Errorf("error getting Garden: %w", err)
+	}
+
+	switch cmd.Target {
+	case homeassistant.TargetLight:
+		state := pkg.LightStateOff
+		if payload == homeassistant.PayloadOn {
+			state = pkg.LightStateOn
+		}
+		return w.ExecuteGardenAction(ctx, g, &action.GardenAction{
+			Light: &action.LightAction{State: state},
+		})
+	case homeassistant.TargetFan:
+		power := uint8(0)
+		if payload == homeassistant.PayloadOn {
+			power = 255
+			if g.FanSchedule != nil && g.FanSchedule.PowerToPWM() > 0 {
+				power = g.FanSchedule.PowerToPWM()
+			}
+		}
+		return w.executeHomeAssistantFanAction(ctx, g, power)
+	case homeassistant.TargetFanPercentage:
+		percentage, err := strconv.ParseUint(strings.TrimSpace(payload), 10, 8)
+		if err != nil || percentage > 100 {
+			return fmt.Errorf("invalid fan percentage %q", payload)
+		}
+		//nolint:gosec
+		return w.executeHomeAssistantFanAction(ctx, g, uint8(percentage*255/100))
+	case homeassistant.TargetZone:
+		z, err := w.storageClient.Zones.Get(ctx, cmd.ZoneID)
+		if err != nil {
+			return fmt.Errorf("error getting Zone: %w", err)
+		}
+		if z.GardenID.String() != g.GetID() {
+			return fmt.Errorf("zone %q does not belong to Garden %q", cmd.ZoneID, g.GetID())
+		}
+
+		switch payload {
+		case homeassistant.PayloadOpen, homeassistant.PayloadOn:
+			return w.ExecuteZoneAction(ctx, g, z, &action.ZoneAction{
+				Water: &action.WaterAction{
+					Duration: &pkg.Duration{Duration: w.homeAssistantZoneDuration(cmd.ZoneID)},
+					Source:   action.SourceCommand,
+				},
+			})
+		case homeassistant.PayloadClose, homeassistant.PayloadOff:
+			return w.stopHomeAssistantZone(ctx, g, z)
+		default:
+			return fmt.Errorf("unexpected Zone command %q", payload)
+		}
+	}
+
+	return nil
+}
+
+func (w *Worker) executeHomeAssistantFanAction(ctx context.Context, g *pkg.Garden, power uint8) error {
+	input := &action.FanAction{Power: power}
+	if power > 0 {
+		input.Duration = w.homeAssistantConfig.fanDuration().Milliseconds()
+	}
+	return w.ExecuteGardenAction(ctx, g, &action.GardenAction{Fan: input})
+}
+
+// setHomeAssistantZoneDuration stores the duration set in Home Assistant and publishes it as the retained state.
+// The retained state is received again when the garden-app starts, which restores the durations
+func (w *Worker) setHomeAssistantZoneDuration(ctx context.Context, cmd homeassistant.Command, payload string) error {
+	minutes, err := strconv.ParseFloat(strings.TrimSpace(payload), 64)
+	if err != nil || minutes <= 0 {
+		return fmt.Errorf("invalid Zone duration %q", payload)
+	}
+	duration := time.Duration(minutes * float64(time.Minute))
+
+	w.homeAssistantMutex.Lock()
+	_, exists := w.homeAssistantZoneDurations[cmd.ZoneID]
+	if !cmd.State || !exists {
+		w.homeAssistantZoneDurations[cmd.ZoneID] = duration
+	}
+	w.homeAssistantMutex.Unlock()
+
+	if cmd.State {
+		return nil
+	}
+
+	topic := w.homeAssistantConfig.options().ZoneDurationStateTopic(cmd.GardenID, cmd.ZoneID)
+	return w.mqttClient.PublishRetained(ctx, topic, []byte(strings.TrimSpace(payload)))
+}
+
+func (w *Worker) homeAssistantZoneDuration(zoneID string) time.Duration {
+	w.homeAssistantMutex.Lock()
+	defer w.homeAssistantMutex.Unlock()
+
+	duration, ok := w.homeAssistantZoneDurations[zoneID]
+	if !ok {
+		return w.homeAssistantConfig.waterDuration()
+	}
+	return duration
+}
+
+// publishHomeAssistantState publishes a state update if the Home Assistant integration is enabled. Errors are
+// only logged since the state is not important enough to interrupt handling the controller's message
+func (w *Worker) publishHomeAssistantState(topic string, payload string, retained bool) {
+	if !w.homeAssistantEnabled() {
+		return
+	}
+
+	publish := w.mqttClient.Publish
+	if retained {
+		publish = w.mqttClient.PublishRetained
+	}
+
+	err := publish(context.Background(), topic, []byte(payload))
+	if err != nil {
+		w.logger.Error("unable to publish Home Assistant state", "topic", topic, "error", err)
+	}
+}
+
+func (w *Worker) publishHomeAssistantLightState(g *pkg.Garden, state pkg.LightState) {
+	w.publishHomeAssistantState(w.homeAssistantConfig.options().LightStateTopic(g.GetID()), state.String(), true)
+}
+
+func (w *Worker) publishHomeAssistantFanState(g *pkg.Garden, power uint8) {
+	opts := w.homeAssistantConfig.options()
+
+	state := homeassistant.PayloadOff
+	if power > 0 {
+		state = homeassistant.PayloadOn
+	}
+	w.publishHomeAssistantState(opts.FanStateTopic(g.GetID()), state, true)
+
+	percentage := (int(power)*100 + 127) / 255
+	w.publishHomeAssistantState(opts.FanPercentageStateTopic(g.GetID()), strconv.Itoa(percentage), true)
+}
+
+func (w *Worker) publishHomeAssistantHealth(g *pkg.Garden) {
+	w.publishHomeAssistantState(w.homeAssistantConfig.options().HealthStateTopic(g.GetID()), homeassistant.PayloadOn, false)
+}
+
+func (w *Worker) publishHomeAssistantZoneState(g *pkg.Garden, event action.WaterStatusEvent) {
+	w.homeAssistantMutex.Lock()
+	switch {
+	case event.Status == pkg.WaterStatusStarted:
+		w.homeAssistantWateringZones[g.GetID()] = event.ZoneID
+	case w.homeAssistantWateringZones[g.GetID()] == event.ZoneID:
+		delete(w.homeAssistantWateringZones, g.GetID())
+	}
+	w.homeAssistantMutex.Unlock()
+
+	opts := w.homeAssistantConfig.options()
+
+	watering, notWatering := opts.ZoneStates()
+	state := notWatering
+	if event.Status == pkg.WaterStatusStarted {
+		state = watering
+	}
+	w.publishHomeAssistantState(opts.ZoneStateTopic(g.GetID(), event.ZoneID), state, true)
+}
+
+// stopHomeAssistantZone stops watering if the Zone is the one that the Garden is watering. The controller can only
+// stop the current watering, so stopping another Zone would stop the wrong one. Otherwise, the Zone's state is
+// published again so Home Assistant shows that it is not watering
+func (w *Worker) stopHomeAssistantZone(ctx context.Context, g *pkg.Garden, z *pkg.Zone) error {
+	w.homeAssistantMutex.Lock()
+	watering := w.homeAssistantWateringZones[g.GetID()] == z.GetID()
+	w.homeAssistantMutex.Unlock()
+
+	if watering {
+		return w.ExecuteGardenAction(ctx, g, &action.GardenAction{Stop: &action.StopAction{}})
+	}
+
+	w.logger.Debug("ignoring Home Assistant stop since the Zone is not watering", "garden_id", g.GetID(), "zone_id", z.GetID())
+
+	opts := w.homeAssistantConfig.options()
+	_, notWatering := opts.ZoneStates()
+	w.publishHomeAssistantState(opts.ZoneStateTopic(g.GetID(), z.GetID()), notWatering, true)
+	return nil
+}
+
+func (w *Worker) handleSensorDataMessage(_ paho.Client, msg paho.Message) {
+	err := w.doSensorDataMessage(msg.Topic(), string(msg.Payload()))
+	if err != nil {
+		w.logger.With("topic", msg.Topic(), "error", err).Error("error handling sensor data message")
+	}
+}
+
+// doSensorDataMessage streams the sensor's readings and publishes them as JSON for Home Assistant
+func (w *Worker) doSensorDataMessage(topic string, payload string) error {
+	sensorID, readings, err := parseSensorDataMessage(payload)
+	if err != nil {
+		w.logger.Warn("unexpected sensor data message", "topic", topic, "message", payload, "error", err)
+		return nil
+	}
+
+	g, err := w.getGardenForTopic(topic)
+	if err != nil {
+		return err
+	}
+
+	w.publishLiveEvent(pkg.LiveEventSensor, g, pkg.SensorLiveEventData{
+		SensorID: sensorID,
+		Readings: readings,
+	})
+
+	if !w.homeAssistantEnabled() {
+		return nil
+	}
+
+	msg, err := json.Marshal(readings)
+	if err != nil {
+		return fmt.Errorf("error marshalling sensor readings: %w", err)
+	}
+
+	w.publishHomeAssistantState(w.homeAssistantConfig.options().SensorStateTopic(g.GetID(), sensorID), string(msg), false)
+	return nil
+}
+
+// parseSensorDataMessage parses an InfluxDB line protocol message with the measurement "sensor", the tag
+// "sensor_id", and numeric fields for each reading
+func parseSensorDataMessage(msg string) (string, map[string]float64, error) {
+	handler := lineprotocol.NewMetricHandler()
+	parser := lineprotocol.NewParser(handler)
+	metrics, err := parser.Parse([]byte(msg))
+	if err != nil {
+		return "", nil, fmt.Errorf("error parsing line protocol: %w", err)
+	}
+	if len(metrics) != 1 {
+		return "", nil, fmt.Errorf("expected 1 metric, got %d", len(metrics))
+	}
+
+	m := metrics[0]
+	if m.Name() != "sensor" {
+		return "", nil, fmt.Errorf("unexpected measurement %q", m.Name())
+	}
+
+	var sensorID string
+	for _, tag := range m.TagList() {
+		if tag.Key == "sensor_id" {
+			sensorID = tag.Value
+		}
+	}
+	if sensorID == "" {
+		return "", nil, errors.New("missing sensor_id tag")
+	}
+
+	readings := map[string]float64{}
+	for _, field := range m.FieldList() {
+		switch v := field.Value.(type) {
+		case float64:
+			readings[field.Key] = v
+		case int64:
+			readings[field.Key] = float64(v)
+		}
+	}
+
+	return sensorID, readings, nil
+}
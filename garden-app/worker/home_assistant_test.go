@@ -0,0 +1,252 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomeAssistant(t *testing.T) {
+	setup := func(t *testing.T, cfg HomeAssistantConfig) (*Worker, *storage.Client, func() []string, func() []string) {
+		t.Helper()
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, storageClient.Gardens.Set(context.Background(), createExampleGarden()))
+		require.NoError(t, storageClient.Zones.Set(context.Background(), createExampleZone()))
+
+		published := []string{}
+		retained := []string{}
+		mqttClient := new(mqtt.MockClient)
+		mqttClient.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, topic string, msg []byte) error {
+			published = append(published, fmt.Sprintf("%s %s", topic, msg))
+			return nil
+		})
+		mqttClient.On("PublishRetained", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, topic string, msg []byte) error {
+			retained = append(retained, fmt.Sprintf("%s %s", topic, msg))
+			return nil
+		})
+
+		w := NewWorker(storageClient, nil, mqttClient, slog.Default(), WithHomeAssistantConfig(cfg))
+
+		reset := func(s *[]string) func() []string {
+			return func() []string {
+				result := *s
+				*s = []string{}
+				return result
+			}
+		}
+		return w, storageClient, reset(&published), reset(&retained)
+	}
+
+	gardenID := id.String()
+	zoneID := id.String()
+
+	t.Run("Disabled", func(t *testing.T) {
+		w, _, published, retained := setup(t, HomeAssistantConfig{})
+
+		require.NoError(t, w.UpdateHomeAssistantDiscovery(context.Background(), gardenID))
+		w.publishHomeAssistantHealth(createExampleGarden())
+		assert.Empty(t, published())
+		assert.Empty(t, retained())
+	})
+
+	t.Run("UpdateDiscovery", func(t *testing.T) {
+		w, storageClient, _, retained := setup(t, HomeAssistantConfig{Enabled: true})
+
+		require.NoError(t, w.UpdateHomeAssistantDiscovery(context.Background(), gardenID))
+
+		topics := []string{}
+		for _, msg := range retained() {
+			topic, _, _ := strings.Cut(msg, " ")
+			topics = append(topics, topic)
+		}
+		assert.Equal(t, []string{
+			"homeassistant/binary_sensor/garden_app_c5cvhpcbcv45e8bp16dg/health/config",
+			"homeassistant/switch/garden_app_c5cvhpcbcv45e8bp16dg/light/config",
+			"homeassistant/valve/garden_app_c5cvhpcbcv45e8bp16dg/zone_c5cvhpcbcv45e8bp16dg/config",
+			"homeassistant/number/garden_app_c5cvhpcbcv45e8bp16dg/zone_c5cvhpcbcv45e8bp16dg_duration/config",
+		}, topics)
+
+		// end-dating the Zone removes its entities
+		zone := createExampleZone()
+		zone.SetEndDate(clock.Now())
+		require.NoError(t, storageClient.Zones.Set(context.Background(), zone))
+
+		require.NoError(t, w.UpdateHomeAssistantDiscovery(context.Background(), gardenID))
+		msgs := retained()
+		require.Len(t, msgs, 4)
+		assert.Equal(t, "homeassistant/number/garden_app_c5cvhpcbcv45e8bp16dg/zone_c5cvhpcbcv45e8bp16dg_duration/config ", msgs[2])
+		assert.Equal(t, "homeassistant/valve/garden_app_c5cvhpcbcv45e8bp16dg/zone_c5cvhpcbcv45e8bp16dg/config ", msgs[3])
+
+		// deleting the Garden removes everything, even after a restart
+		restarted := NewWorker(storageClient, nil, w.mqttClient, slog.Default(), WithHomeAssistantConfig(HomeAssistantConfig{Enabled: true}))
+		require.NoError(t, storageClient.Gardens.Delete(context.Background(), gardenID))
+		restarted.publishHomeAssistantDiscoveryAllGardens()
+		assert.Equal(t, []string{
+			"homeassistant/binary_sensor/garden_app_c5cvhpcbcv45e8bp16dg/health/config ",
+			"homeassistant/switch/garden_app_c5cvhpcbcv45e8bp16dg/light/config ",
+		}, retained())
+	})
+
+	t.Run("Commands", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			topic    string
+			payload  string
+			expected []string
+		}{
+			{
+				"LightOn",
+				"garden-app/%s/light/set",
+				"ON",
+				[]string{`test-garden/command/light {"state":"ON"}`},
+			},
+			{
+				"LightOff",
+				"garden-app/%s/light/set",
+				"OFF",
+				[]string{`test-garden/command/light {"state":"OFF"}`},
+			},
+			{
+				"FanOn",
+				"garden-app/%s/fan/set",
+				"ON",
+				[]string{`test-garden/command/fan {"duration":3600000,"power":255}`},
+			},
+			{
+				"FanOff",
+				"garden-app/%s/fan/set",
+				"OFF",
+				[]string{`test-garden/command/fan {"duration":0,"power":0}`},
+			},
+			{
+				"FanPercentage",
+				"garden-app/%s/fan/percentage/set",
+				"50",
+				[]string{`test-garden/command/fan {"duration":3600000,"power":127}`},
+			},
+			{
+				"ZoneCloseNotWatering",
+				"garden-app/%s/zone/" + zoneID + "/set",
+				"CLOSE",
+				[]string{},
+			},
+			{
+				"IgnoredStateTopic",
+				"garden-app/%s/light/state",
+				"ON",
+				[]string{},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				w, _, published, _ := setup(t, HomeAssistantConfig{Enabled: true})
+
+				err := w.doHomeAssistantMessage(context.Background(), fmt.Sprintf(tt.topic, gardenID), tt.payload)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, published())
+			})
+		}
+	})
+
+	t.Run("ZoneCloseOnlyStopsWateringZone", func(t *testing.T) {
+		w, _, published, retained := setup(t, HomeAssistantConfig{Enabled: true})
+		ctx := context.Background()
+		garden := createExampleGarden()
+		topic := "garden-app/" + gardenID + "/zone/" + zoneID + "/set"
+
+		w.publishHomeAssistantZoneState(garden, action.WaterStatusEvent{ZoneID: zoneID, Status: pkg.WaterStatusStarted})
+		retained()
+
+		require.NoError(t, w.doHomeAssistantMessage(ctx, topic, "CLOSE"))
+		assert.Equal(t, []string{"test-garden/command/stop no message"}, published())
+
+		// after another Zone starts, closing this one doesn't stop it
+		w.publishHomeAssistantZoneState(garden, action.WaterStatusEvent{ZoneID: zoneID, Status: pkg.WaterStatusCancelled})
+		w.publishHomeAssistantZoneState(garden, action.WaterStatusEvent{ZoneID: "other", Status: pkg.WaterStatusStarted})
+		retained()
+
+		require.NoError(t, w.doHomeAssistantMessage(ctx, topic, "CLOSE"))
+		assert.Empty(t, published())
+		assert.Equal(t, []string{"garden-app/" + gardenID + "/zone/" + zoneID + "/state closed"}, retained())
+	})
+
+	t.Run("InvalidFanPercentage", func(t *testing.T) {
+		w, _, _, _ := setup(t, HomeAssistantConfig{Enabled: true})
+
+		err := w.doHomeAssistantMessage(context.Background(), "garden-app/"+gardenID+"/fan/percentage/set", "101")
+		require.Error(t, err)
+	})
+
+	t.Run("ZoneDuration", func(t *testing.T) {
+		w, _, published, retained := setup(t, HomeAssistantConfig{Enabled: true})
+		ctx := context.Background()
+
+		assert.Equal(t, 15*time.Minute, w.homeAssistantZoneDuration(zoneID))
+
+		// retained state restores the duration
+		require.NoError(t, w.doHomeAssistantMessage(ctx, "garden-app/"+gardenID+"/zone/"+zoneID+"/duration/state", "5"))
+		assert.Equal(t, 5*time.Minute, w.homeAssistantZoneDuration(zoneID))
+		assert.Empty(t, retained())
+
+		require.NoError(t, w.doHomeAssistantMessage(ctx, "garden-app/"+gardenID+"/zone/"+zoneID+"/duration/set", "10"))
+		assert.Equal(t, 10*time.Minute, w.homeAssistantZoneDuration(zoneID))
+		assert.Equal(t, []string{"garden-app/" + gardenID + "/zone/" + zoneID + "/duration/state 10"}, retained())
+
+		// an old retained state does not replace the duration
+		require.NoError(t, w.doHomeAssistantMessage(ctx, "garden-app/"+gardenID+"/zone/"+zoneID+"/duration/state", "5"))
+		assert.Equal(t, 10*time.Minute, w.homeAssistantZoneDuration(zoneID))
+
+		require.NoError(t, w.doHomeAssistantMessage(ctx, "garden-app/"+gardenID+"/zone/"+zoneID+"/set", "OPEN"))
+		msgs := published()
+		require.Len(t, msgs, 1)
+		assert.Contains(t, msgs[0], `test-garden/command/water {"duration":600000,"zone_id":"c5cvhpcbcv45e8bp16dg","position":0`)
+
+		require.Error(t, w.doHomeAssistantMessage(ctx, "garden-app/"+gardenID+"/zone/"+zoneID+"/duration/set", "abc"))
+	})
+
+	t.Run("States", func(t *testing.T) {
+		w, _, published, retained := setup(t, HomeAssistantConfig{Enabled: true, ZoneComponent: "switch"})
+		garden := createExampleGarden()
+
+		w.publishHomeAssistantLightState(garden, pkg.LightStateOn)
+		w.publishHomeAssistantFanState(garden, 127)
+		w.publishHomeAssistantZoneState(garden, action.WaterStatusEvent{ZoneID: zoneID, Status: pkg.WaterStatusStarted})
+		w.publishHomeAssistantHealth(garden)
+
+		assert.Equal(t, []string{
+			"garden-app/" + gardenID + "/light/state ON",
+			"garden-app/" + gardenID + "/fan/state ON",
+			"garden-app/" + gardenID + "/fan/percentage/state 50",
+			"garden-app/" + gardenID + "/zone/" + zoneID + "/state ON",
+		}, retained())
+		assert.Equal(t, []string{"garden-app/" + gardenID + "/health/state ON"}, published())
+	})
+
+	t.Run("SensorData", func(t *testing.T) {
+		w, _, published, _ := setup(t, HomeAssistantConfig{Enabled: true})
+
+		require.NoError(t, w.doSensorDataMessage("test-garden/data/sensor", "sensor,sensor_id=abc temperature=21.50,humidity=40.00"))
+		assert.Equal(t, []string{`garden-app/` + gardenID + `/sensor/abc/state {"humidity":40,"temperature":21.5}`}, published())
+
+		require.NoError(t, w.doSensorDataMessage("test-garden/data/sensor", "sensor temperature=21.50"))
+		assert.Empty(t, published())
+	})
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+)
+
+// liveEventBufferSize is the number of LiveEvents that can be waiting for each subscriber. Events are dropped for
+// subscribers that are not keeping up instead of blocking the MQTT handlers
+const liveEventBufferSize = 32
+
+type liveEventSubscription struct {
+	gardenID string
+	events   chan pkg.LiveEvent
+}
+
+// SubscribeLiveEvents returns a channel that receives LiveEvents as messages are received from controllers. If the
+// gardenID is set, only events for that Garden are received. The returned function must be called to unsubscribe.
+// The channel is closed after unsubscribing or when the Worker is stopped
+func (w *Worker) SubscribeLiveEvents(gardenID string) (<-chan pkg.LiveEvent, func()) {
+	sub := &liveEventSubscription{
+		gardenID: gardenID,
+		events:   make(chan pkg.LiveEvent, liveEventBufferSize),
+	}
+
+	w.liveEventMutex.Lock()
+	defer w.liveEventMutex.Unlock()
+
+	if w.liveEventsStopped {
+		close(sub.events)
+		return sub.events, func() {}
+	}
+	w.liveEventSubscriptions[sub] = struct{}{}
+
+	return sub.events, func() {
+		w.liveEventMutex.Lock()
+		defer w.liveEventMutex.Unlock()
+
+		if _, ok := w.liveEventSubscriptions[sub]; ok {
+			delete(w.liveEventSubscriptions, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// publishLiveEvent sends a LiveEvent for the Garden to all subscribers without blocking
+func (w *Worker) publishLiveEvent(eventType pkg.LiveEventType, g *pkg.Garden, data any) {
+	w.publishZoneLiveEvent(eventType, g, "", data)
+}
+
+// publishZoneLiveEvent sends a LiveEvent for one of the Garden's Zones so clients can refresh only that Zone
+func (w *Worker) publishZoneLiveEvent(eventType pkg.LiveEventType, g *pkg.Garden, zoneID string, data any) {
+	event := pkg.LiveEvent{
+		Type:      eventType,
+		GardenID:  g.GetID(),
+		ZoneID:    zoneID,
+		Timestamp: clock.Now().UTC(),
+		Data:      data,
+	}
+
+	w.liveEventMutex.Lock()
+	defer w.liveEventMutex.Unlock()
+
+	for sub := range w.liveEventSubscriptions {
+		if sub.gardenID != "" && sub.gardenID != event.GardenID {
+			continue
+		}
+
+		select {
+		case sub.events <- event:
+		default:
+			w.logger.Debug("dropping live event for slow subscriber", "garden_id", event.GardenID, "event_type", eventType)
+		}
+	}
+}
+
+// stopLiveEvents closes all subscriptions so streams end when the Worker is stopped
+func (w *Worker) stopLiveEvents() {
+	w.liveEventMutex.Lock()
+	defer w.liveEventMutex.Unlock()
+
+	w.liveEventsStopped = true
+	for sub := range w.liveEventSubscriptions {
+		delete(w.liveEventSubscriptions, sub)
+		close(sub.events)
+	}
+}
@@ -0,0 +1,174 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLiveEvents(t *testing.T) {
+	c := clock.MockTime()
+	defer clock.Reset()
+
+	setup := func(t *testing.T) (*Worker, *pkg.Garden) {
+		t.Helper()
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		garden := createExampleGarden()
+		garden.LightSchedule = nil
+		require.NoError(t, storageClient.Gardens.Set(context.Background(), garden))
+
+		return NewWorker(storageClient, nil, nil, slog.Default()), garden
+	}
+
+	t.Run("FilterByGarden", func(t *testing.T) {
+		w, garden := setup(t)
+
+		all, unsubscribeAll := w.SubscribeLiveEvents("")
+		defer unsubscribeAll()
+		same, unsubscribeSame := w.SubscribeLiveEvents(garden.GetID())
+		defer unsubscribeSame()
+		other, unsubscribeOther := w.SubscribeLiveEvents("other")
+		defer unsubscribeOther()
+
+		w.publishLiveEvent(pkg.LiveEventHealth, garden, nil)
+
+		expected := pkg.LiveEvent{
+			Type:      pkg.LiveEventHealth,
+			GardenID:  garden.GetID(),
+			Timestamp: c.Now().UTC(),
+		}
+		assert.Equal(t, expected, <-all)
+		assert.Equal(t, expected, <-same)
+		assert.Empty(t, other)
+	})
+
+	t.Run("DropWhenFull", func(t *testing.T) {
+		w, garden := setup(t)
+
+		events, unsubscribe := w.SubscribeLiveEvents("")
+		defer unsubscribe()
+
+		for range liveEventBufferSize + 1 {
+			w.publishLiveEvent(pkg.LiveEventHealth, garden, nil)
+		}
+		assert.Len(t, events, liveEventBufferSize)
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		w, garden := setup(t)
+
+		events, unsubscribe := w.SubscribeLiveEvents("")
+		unsubscribe()
+		unsubscribe()
+
+		w.publishLiveEvent(pkg.LiveEventHealth, garden, nil)
+		_, ok := <-events
+		assert.False(t, ok)
+	})
+
+	t.Run("StopClosesSubscriptions", func(t *testing.T) {
+		w, _ := setup(t)
+
+		events, unsubscribe := w.SubscribeLiveEvents("")
+		defer unsubscribe()
+
+		w.stopLiveEvents()
+		_, ok := <-events
+		assert.False(t, ok)
+
+		events, _ = w.SubscribeLiveEvents("")
+		_, ok = <-events
+		assert.False(t, ok)
+	})
+
+	tests := []struct {
+		name      string
+		handle    func(*Worker) error
+		eventType pkg.LiveEventType
+		data      any
+	}{
+		{
+			"WaterStatus",
+			func(w *Worker) error {
+				return w.doWaterCompleteStatusMessage("test-garden/data/water", []byte("water,status=start,zone=0,zone_id=zoneID,id=eventID millis=0"))
+			},
+			pkg.LiveEventWaterStatus,
+			action.WaterStatusEvent{ZoneID: "zoneID", EventID: "eventID", Status: pkg.WaterStatusStarted},
+		},
+		{
+			"Health",
+			func(w *Worker) error {
+				w.handleHealthMessage("test-garden/data/health", `health garden="test-garden"`)
+				return nil
+			},
+			pkg.LiveEventHealth,
+			nil,
+		},
+		{
+			"Sensor",
+			func(w *Worker) error {
+				return w.doSensorDataMessage("test-garden/data/sensor", "sensor,sensor_id=abc temperature=21.50")
+			},
+			pkg.LiveEventSensor,
+			pkg.SensorLiveEventData{SensorID: "abc", Readings: map[string]float64{"temperature": 21.5}},
+		},
+		{
+			"Log",
+			func(w *Worker) error {
+				return w.getGardenAndHandleLogMessage("test-garden/data/logs", `logs,level=warn,source=wifi message="reconnecting"`)
+			},
+			pkg.LiveEventLog,
+			map[string]any{"level": "warn", "source": "wifi", "message": "reconnecting"},
+		},
+		{
+			"Light",
+			func(w *Worker) error {
+				return w.getGardenAndHandleLightState("test-garden/data/light", `light,garden="test-garden" state=1`)
+			},
+			pkg.LiveEventLight,
+			map[string]any{"state": "ON"},
+		},
+		{
+			"Fan",
+			func(w *Worker) error {
+				return w.getGardenAndHandleFanState("test-garden/data/fan", `fan,garden="test-garden" power=127`)
+			},
+			pkg.LiveEventFan,
+			map[string]any{"power": uint8(127)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, garden := setup(t)
+
+			events, unsubscribe := w.SubscribeLiveEvents(garden.GetID())
+			defer unsubscribe()
+
+			require.NoError(t, tt.handle(w))
+
+			require.Len(t, events, 1)
+			event := <-events
+			assert.Equal(t, tt.eventType, event.Type)
+			assert.Equal(t, garden.GetID(), event.GardenID)
+			assert.Equal(t, tt.data, event.Data)
+			if event.Type == pkg.LiveEventWaterStatus {
+				assert.Equal(t, "zoneID", event.ZoneID)
+			} else {
+				assert.Empty(t, event.ZoneID)
+			}
+		})
+	}
+}
@@ -25,12 +25,14 @@ func (w *Worker) handleControllerLogMessage(_ mqtt.Client, msg mqtt.Message) {
 
 // controllerLog represents a log message published by a garden controller.
 type controllerLog struct {
-	Level       string
-	Source      string
-	Message     string
-	ResetReason string
-	ExtraTags   map[string]string
-	ExtraFields map[string]string
+	Level           string
+	Source          string
+	Message         string
+	ResetReason     string
+	ConfigHash      *string
+	ScheduleVersion string
+	ExtraTags       map[string]string
+	ExtraFields     map[string]string
 }
 
 func (w *Worker) getGardenAndHandleLogMessage(topic string, payload string) error {
@@ -48,6 +50,12 @@ func (w *Worker) getGardenAndHandleLogMessage(topic string, payload string) erro
 	}
 	logger = logger.With("garden_id", garden.GetID())
 
+	w.publishLiveEvent(pkg.LiveEventLog, garden, map[string]any{
+		"level":   log.Level,
+		"source":  log.Source,
+		"message": log.Message,
+	})
+
 	ctx := context.Background()
 
 	if log.Message == "garden-controller setup complete" {
@@ -72,10 +80,31 @@ func (w *Worker) handleStartupLog(ctx context.Context, garden *pkg.Garden, topic
 		message += fmt.Sprintf("\nError setting FanState: %v", err)
 	}
 
+	synced, err := w.syncControllerConfig(ctx, garden, log.ConfigHash)
+	if err != nil {
+		logger.Warn("unable to sync controller config", "error", err.Error())
+		message += fmt.Sprintf("\nError syncing controller config: %v", err)
+	} else if synced {
+		message += "\nController config was out of sync and has been re-sent"
+	}
+
+	sent, err := w.syncControllerSchedule(ctx, garden, log.ScheduleVersion)
+	if err != nil {
+		logger.Warn("unable to sync controller schedule", "error", err.Error())
+		message += fmt.Sprintf("\nError syncing controller schedule: %v", err)
+	} else if sent {
+		message += "\nController schedule was out of date and has been re-sent"
+	}
+
 	if log.ResetReason != "" {
 		message += fmt.Sprintf("\nReset reason: %s", log.ResetReason)
 	}
 
+	w.SendWebhookEvent(ctx, pkg.WebhookEventControllerStartup, garden, map[string]any{
+		"message":      message,
+		"reset_reason": log.ResetReason,
+	})
+
 	return w.sendGardenStartupMessage(ctx, garden, topic, message)
 }
 
@@ -104,6 +133,16 @@ func (w *Worker) handleGenericLog(ctx context.Context, garden *pkg.Garden, log *
 		logger.Info("controller log", logArgs...)
 	}
 
+	if log.Level == "error" || log.Level == "alert" {
+		w.SendWebhookEvent(ctx, pkg.WebhookEventControllerAlert, garden, map[string]any{
+			"level":   log.Level,
+			"source":  log.Source,
+			"message": log.Message,
+			"tags":    log.ExtraTags,
+			"fields":  log.ExtraFields,
+		})
+	}
+
 	if (log.Level == "error" || log.Level == "alert") && garden.GetNotificationSettings().ControllerAlerts {
 		title := fmt.Sprintf("%s: Controller Error", garden.Name)
 		if log.Level == "alert" {
@@ -269,6 +308,14 @@ func parseControllerLogMessage(msg string) (*controllerLog, error) {
 			if s, ok := field.Value.(string); ok {
 				log.ResetReason = s
 			}
+		case "config_hash":
+			if s, ok := field.Value.(string); ok {
+				log.ConfigHash = &s
+			}
+		case "schedule_version":
+			if s, ok := field.Value.(string); ok {
+				log.ScheduleVersion = s
+			}
 		default:
 			log.ExtraFields[field.Key] = fmt.Sprintf("%v", field.Value)
 		}
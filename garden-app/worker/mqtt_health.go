@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+)
+
+const (
+	defaultMQTTHealthCheckInterval       = time.Minute
+	defaultMQTTHealthDisconnectThreshold = 5 * time.Minute
+)
+
+// MQTTHealthConfig configures the background job that sends a notification when the server is disconnected from
+// the MQTT broker
+type MQTTHealthConfig struct {
+	// CheckInterval is how often the connection is checked. Defaults to 1 minute
+	CheckInterval time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+	// DisconnectThreshold is how long the server needs to be disconnected before a notification is sent. Defaults
+	// to 5 minutes
+	DisconnectThreshold time.Duration `mapstructure:"disconnect_threshold" yaml:"disconnect_threshold"`
+	// NotificationClientID is the notification client used to send disconnect alerts
+	NotificationClientID string `mapstructure:"notification_client_id" yaml:"notification_client_id"`
+}
+
+func (c MQTTHealthConfig) checkInterval() time.Duration {
+	if c.CheckInterval <= 0 {
+		return defaultMQTTHealthCheckInterval
+	}
+	return c.CheckInterval
+}
+
+func (c MQTTHealthConfig) disconnectThreshold() time.Duration {
+	if c.DisconnectThreshold <= 0 {
+		return defaultMQTTHealthDisconnectThreshold
+	}
+	return c.DisconnectThreshold
+}
+
+// WithMQTTHealthConfig configures the MQTT disconnect notification job
+func WithMQTTHealthConfig(cfg MQTTHealthConfig) WorkerOption {
+	return func(w *Worker) {
+		w.mqttHealthConfig = cfg
+	}
+}
+
+func (w *Worker) scheduleMQTTHealthCheck() error {
+	if _, isMock := w.mqttClient.(*mqtt.MockClient); isMock || w.mqttClient == nil {
+		return nil
+	}
+
+	_, err := w.scheduler.
+		Every(w.mqttHealthConfig.checkInterval()).
+		Tag("mqtt_health").
+		Do(w.CheckMQTTHealth)
+	if err != nil {
+		return fmt.Errorf("error scheduling MQTT health check: %w", err)
+	}
+	return nil
+}
+
+// CheckMQTTHealth sends a notification if the server has been disconnected from the MQTT broker for longer than
+// the threshold. Only one notification is sent for each disconnect and another is sent when it reconnects
+func (w *Worker) CheckMQTTHealth() {
+	status := w.mqttClient.Status()
+
+	w.mqttHealthMutex.Lock()
+	defer w.mqttHealthMutex.Unlock()
+
+	logger := w.logger.With("disconnected_for", status.DisconnectedFor())
+
+	if status.Connected {
+		if w.mqttDisconnectNotified != nil {
+			logger.Info("reconnected to MQTT broker")
+			w.sendMQTTHealthNotification("MQTT Reconnected", "The server reconnected to the MQTT broker.")
+		}
+		w.mqttDisconnectNotified = nil
+		return
+	}
+
+	if status.DisconnectedFor() < w.mqttHealthConfig.disconnectThreshold() || w.mqttDisconnectNotified != nil {
+		return
+	}
+
+	logger.Warn("disconnected from MQTT broker", "last_error", status.LastError)
+
+	message := fmt.Sprintf("The server has been disconnected from the MQTT broker for %s, so controllers will not receive commands.", status.DisconnectedFor().Truncate(time.Second))
+	if status.LastError != "" {
+		message += "\nLast error: " + status.LastError
+	}
+	w.sendMQTTHealthNotification("MQTT Disconnected", message)
+
+	disconnectedSince := *status.DisconnectedSince
+	w.mqttDisconnectNotified = &disconnectedSince
+}
+
+func (w *Worker) sendMQTTHealthNotification(title, message string) {
+	if w.mqttHealthConfig.NotificationClientID == "" {
+		return
+	}
+	w.sendNotification(context.Background(), w.mqttHealthConfig.NotificationClientID, title, message, w.logger)
+}
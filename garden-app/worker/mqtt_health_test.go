@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications/fake"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckMQTTHealth(t *testing.T) {
+	fake.Reset()
+	defer fake.Reset()
+	mockClock := clock.MockTime()
+	defer clock.Reset()
+
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	nc := &notifications.Client{
+		ID:   babyapi.NewID(),
+		Name: "test",
+		URL:  "fake://success",
+	}
+	require.NoError(t, storageClient.NotificationClientConfigs.Set(context.Background(), nc))
+
+	disconnectedSince := clock.Now()
+	status := mqtt.Status{DisconnectedSince: &disconnectedSince, LastError: "connection refused"}
+
+	mqttClient := new(mqtt.MockClient)
+	mqttClient.On("Status").Return(func() mqtt.Status { return status })
+
+	w := NewWorker(storageClient, nil, mqttClient, slog.Default(), WithMQTTHealthConfig(MQTTHealthConfig{
+		DisconnectThreshold:  10 * time.Minute,
+		NotificationClientID: nc.GetID(),
+	}))
+
+	t.Run("DisconnectedBelowThreshold", func(t *testing.T) {
+		mockClock.Add(5 * time.Minute)
+		w.CheckMQTTHealth()
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	t.Run("DisconnectedAboveThreshold", func(t *testing.T) {
+		mockClock.Add(10 * time.Minute)
+		w.CheckMQTTHealth()
+
+		last := fake.LastMessage()
+		assert.Equal(t, "MQTT Disconnected", last.Title)
+		assert.Equal(t, `The server has been disconnected from the MQTT broker for 15m0s, so controllers will not receive commands.
+Last error: connection refused`, last.Message)
+	})
+
+	t.Run("NoRepeatedNotification", func(t *testing.T) {
+		fake.Reset()
+		mockClock.Add(10 * time.Minute)
+		w.CheckMQTTHealth()
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	t.Run("Reconnected", func(t *testing.T) {
+		now := clock.Now()
+		status = mqtt.Status{Connected: true, LastConnect: &now}
+
+		w.CheckMQTTHealth()
+		assert.Equal(t, "MQTT Reconnected", fake.LastMessage().Title)
+
+		fake.Reset()
+		w.CheckMQTTHealth()
+		assert.Empty(t, fake.LastMessage())
+	})
+}
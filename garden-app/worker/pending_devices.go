@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/babyapi"
+)
+
+// recordPendingDevice saves a controller that is publishing on a topic prefix that doesn't belong to a Garden so
+// it can be adopted later. It returns true if the device was recorded, meaning err was a not found error
+func (w *Worker) recordPendingDevice(topic string, info *pkg.ControllerInfo, err error) bool {
+	if !errors.Is(err, babyapi.ErrNotFound) {
+		return false
+	}
+
+	topicPrefix, err := getTopicPrefix(topic)
+	if err != nil {
+		return false
+	}
+
+	logger := w.logger.With("topic_prefix", topicPrefix)
+
+	now := time.Now()
+	device := &pkg.PendingDevice{
+		TopicPrefix: topicPrefix,
+		LastSeen:    &now,
+	}
+	if info != nil {
+		device.MACAddress = info.MACAddress
+		device.IPAddress = info.IPAddress
+		device.FirmwareVersion = info.FirmwareVersion
+	}
+
+	err = w.storageClient.PendingDevices.Upsert(context.Background(), device)
+	if err != nil {
+		logger.Error("error saving pending device", "error", err)
+		return true
+	}
+
+	logger.Debug("recorded pending device")
+	return true
+}
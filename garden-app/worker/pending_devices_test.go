@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordPendingDevice(t *testing.T) {
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	w := NewWorker(storageClient, nil, nil, slog.Default())
+
+	t.Run("HealthMessage", func(t *testing.T) {
+		w.handleHealthMessage("new-garden/data/health", `health garden="new-garden"`)
+
+		device, err := storageClient.PendingDevices.Get(context.Background(), "new-garden")
+		require.NoError(t, err)
+		require.NotNil(t, device)
+		assert.Equal(t, "new-garden", device.TopicPrefix)
+		assert.Empty(t, device.MACAddress)
+		assert.NotNil(t, device.FirstSeen)
+		assert.NotNil(t, device.LastSeen)
+	})
+
+	t.Run("InfoMessageAddsDetails", func(t *testing.T) {
+		err := w.getGardenAndSaveControllerInfo("new-garden/data/info", `info mac="aa:bb:cc:dd:ee:ff",ip="192.168.1.42",version="v1.0.0"`)
+		require.NoError(t, err)
+
+		device, err := storageClient.PendingDevices.Get(context.Background(), "new-garden")
+		require.NoError(t, err)
+		require.NotNil(t, device)
+		assert.Equal(t, "aa:bb:cc:dd:ee:ff", device.MACAddress)
+		assert.Equal(t, "192.168.1.42", device.IPAddress)
+		assert.Equal(t, "v1.0.0", device.FirmwareVersion)
+	})
+
+	t.Run("HealthMessageKeepsDetails", func(t *testing.T) {
+		w.handleHealthMessage("new-garden/data/health", `health garden="new-garden"`)
+
+		devices, err := storageClient.PendingDevices.List(context.Background())
+		require.NoError(t, err)
+		require.Len(t, devices, 1)
+		assert.Equal(t, "aa:bb:cc:dd:ee:ff", devices[0].MACAddress)
+		assert.Equal(t, "v1.0.0", devices[0].FirmwareVersion)
+	})
+
+	t.Run("UnexpectedHealthMessageIgnored", func(t *testing.T) {
+		w.handleHealthMessage("other/data/health", "not connected")
+
+		device, err := storageClient.PendingDevices.Get(context.Background(), "other")
+		require.NoError(t, err)
+		assert.Nil(t, device)
+	})
+}
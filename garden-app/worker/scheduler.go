@@ -58,6 +58,8 @@ func (w *Worker) ScheduleWaterAction(waterSchedule *pkg.WaterSchedule) error {
 					scaledDuration, err := w.ScaleWateringDuration(ws)
 					if err != nil {
 						jobLogger.Warn("weather data unavailable, proceeding with unscaled duration", "error", err)
+					} else if err := w.recordWaterScaleFactor(context.Background(), ws, scaledDuration); err != nil {
+						jobLogger.Warn("unable to store weather scale factor", "error", err)
 					}
 					duration = scaledDuration
 				}
@@ -76,6 +78,7 @@ func (w *Worker) ScheduleWaterAction(waterSchedule *pkg.WaterSchedule) error {
 				// If duration is 0 (weather says skip), don't water any zones
 				if duration == 0 {
 					jobLogger.Info("skipping watering all zones due to weather control")
+					w.sendWaterSkippedWebhookEvents(ctx, ws, zonesAndGardens, "weather_control")
 					return nil
 				}
 				for _, zg := range zonesAndGardens {
@@ -3,6 +3,7 @@ package worker
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
 	"strconv"
 	"time"
@@ -33,6 +34,8 @@ func (w *Worker) doWaterCompleteStatusMessage(topic string, payload []byte) erro
 		"status", waterMessage.Status,
 	)
 
+	w.handleWateringStatusEvent(waterMessage)
+
 	garden, err := w.getGardenForTopic(topic)
 	if err != nil {
 		return err
@@ -40,6 +43,16 @@ func (w *Worker) doWaterCompleteStatusMessage(topic string, payload []byte) erro
 	logger = logger.With("garden_id", garden.GetID())
 	logger.Debug("found garden with topic-prefix")
 
+	return w.handleGardenWaterStatus(garden, waterMessage, logger)
+}
+
+// handleGardenWaterStatus publishes the water status to live events, Home Assistant, and webhooks and sends a
+// notification if it is enabled for the Garden
+func (w *Worker) handleGardenWaterStatus(garden *pkg.Garden, waterMessage action.WaterStatusEvent, logger *slog.Logger) error {
+	w.publishZoneLiveEvent(pkg.LiveEventWaterStatus, garden, waterMessage.ZoneID, waterMessage)
+	w.publishHomeAssistantZoneState(garden, waterMessage)
+	w.sendWaterStatusWebhookEvent(context.Background(), garden, waterMessage)
+
 	if garden.GetNotificationClientID() == "" {
 		logger.Debug("garden does not have notification client", "garden_id", garden.GetID())
 		return nil
@@ -2,6 +2,7 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -38,10 +39,15 @@ func (w *Worker) ExecuteScheduledWaterAction(ctx context.Context, g *pkg.Garden,
 		w.sendDownNotification(ctx, g, ws.GetNotificationClientID(), "Water")
 	}
 
-	return w.ExecuteWaterAction(ctx, g, z, &action.WaterAction{
+	var notificationClientID string
+	if ws.GetNotificationSettings().WateringErrors {
+		notificationClientID = ws.GetNotificationClientID()
+	}
+
+	return w.executeWaterAction(ctx, g, z, &action.WaterAction{
 		Duration: &pkg.Duration{Duration: duration},
 		Source:   action.SourceSchedule,
-	})
+	}, notificationClientID)
 }
 
 // CalculateETDuration calculates watering duration based on ET data using the citrus tree formula.
@@ -101,7 +107,7 @@ func (w *Worker) CalculateETDuration(ctx context.Context, ws *pkg.WaterSchedule)
 // If any weather API fails, the last error is returned and the unscaled duration
 // is used so that scheduled watering is not blocked by transient weather service
 // issues. If ET control is configured and succeeds, it provides the base duration
-// which can then be scaled by temperature and rain controls if they are also configured.
+// which can then be scaled by temperature, rain, humidity, and solar radiation controls if they are also configured.
 func (w *Worker) ScaleWateringDuration(ws *pkg.WaterSchedule) (time.Duration, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), weatherDataTimeout)
 	defer cancel()
@@ -162,6 +168,56 @@ func (w *Worker) ScaleWateringDuration(ws *pkg.WaterSchedule) (time.Duration, er
 		}
 	}
 
+	if ws.HasHumidityControl() {
+		weatherClient, err := w.storageClient.GetWeatherClient(ws.WeatherControl.Humidity.ClientID)
+		if err != nil {
+			lastErr = err
+			w.logger.Warn("error getting WeatherClient for HumidityControl", "error", err)
+		} else if !weather.SupportsHumidity(weatherClient) {
+			lastErr = errors.New("weather client does not support humidity data")
+			w.logger.Warn("weather client does not support humidity data")
+		} else {
+			avgHumidity, err := weatherClient.(weather.HumidityProvider).GetAverageHumidity(ctx, ws.Interval.Duration)
+			if err != nil {
+				lastErr = err
+				w.logger.Warn("error getting average humidity", "error", err)
+			} else {
+				humidityScaleFactor := ws.WeatherControl.Humidity.Scale(float64(avgHumidity))
+				scaleFactor *= humidityScaleFactor
+				w.logger.With(
+					"avg_humidity", avgHumidity,
+					"time_period", ws.Interval.String(),
+					"scale_factor", humidityScaleFactor,
+				).Debug("weather client calculated the average humidity and resulting scale factor")
+			}
+		}
+	}
+
+	if ws.HasSolarRadiationControl() {
+		weatherClient, err := w.storageClient.GetWeatherClient(ws.WeatherControl.SolarRadiation.ClientID)
+		if err != nil {
+			lastErr = err
+			w.logger.Warn("error getting WeatherClient for SolarRadiationControl", "error", err)
+		} else if !weather.SupportsSolarRadiation(weatherClient) {
+			lastErr = errors.New("weather client does not support solar radiation data")
+			w.logger.Warn("weather client does not support solar radiation data")
+		} else {
+			avgSolarRadiation, err := weatherClient.(weather.SolarRadiationProvider).GetAverageSolarRadiation(ctx, ws.Interval.Duration)
+			if err != nil {
+				lastErr = err
+				w.logger.Warn("error getting average solar radiation", "error", err)
+			} else {
+				solarRadiationScaleFactor := ws.WeatherControl.SolarRadiation.Scale(float64(avgSolarRadiation))
+				scaleFactor *= solarRadiationScaleFactor
+				w.logger.With(
+					"avg_solar_radiation", avgSolarRadiation,
+					"time_period", ws.Interval.String(),
+					"scale_factor", solarRadiationScaleFactor,
+				).Debug("weather client calculated the average solar radiation and resulting scale factor")
+			}
+		}
+	}
+
 	w.logger.Debug("compounded scale factor", "compound_scale_factor", scaleFactor, "base_duration", baseDuration)
 
 	result := time.Duration(float64(baseDuration) * scaleFactor)
@@ -170,6 +170,22 @@ func TestScaleWateringDuration(t *testing.T) {
 		FactorMin:     float64Ptr(1.0),
 		FactorMax:     float64Ptr(0.0),
 	}
+	humidityControl := &weather.WeatherScaler{
+		ClientID:      weatherClientID,
+		Interpolation: weather.Linear,
+		InputMin:      float64Ptr(0),
+		InputMax:      float64Ptr(100),
+		FactorMin:     float64Ptr(1.5),
+		FactorMax:     float64Ptr(0.5),
+	}
+	solarRadiationControl := &weather.WeatherScaler{
+		ClientID:      weatherClientID,
+		Interpolation: weather.Linear,
+		InputMin:      float64Ptr(10),
+		InputMax:      float64Ptr(30),
+		FactorMin:     float64Ptr(0.5),
+		FactorMax:     float64Ptr(1.5),
+	}
 
 	tests := []struct {
 		name             string
@@ -388,6 +404,99 @@ func TestScaleWateringDuration(t *testing.T) {
 			},
 			expectedDuration: 375 * time.Millisecond,
 		},
+		{
+			name: "HumidityPartialScaleDown",
+			waterSchedule: &pkg.WaterSchedule{
+				Duration: &pkg.Duration{Duration: time.Second},
+				Interval: &pkg.Duration{Duration: time.Hour * 24},
+				WeatherControl: &weather.Control{
+					Humidity: humidityControl,
+				},
+			},
+			setupWeather: func(sc *storage.Client) {
+				_ = sc.WeatherClientConfigs.Set(context.Background(), &weather.Config{
+					ID:   babyapi.ID{ID: weatherClientID},
+					Name: "test",
+					Type: "fake",
+					Options: map[string]any{
+						"rain_interval": "24h",
+						"avg_humidity":  75,
+					},
+				})
+			},
+			expectedDuration: 750 * time.Millisecond,
+		},
+		{
+			name: "HumidityAndSolarRadiationNotSupported",
+			waterSchedule: &pkg.WaterSchedule{
+				Duration: &pkg.Duration{Duration: time.Second},
+				Interval: &pkg.Duration{Duration: time.Hour * 24},
+				WeatherControl: &weather.Control{
+					Humidity:       humidityControl,
+					SolarRadiation: solarRadiationControl,
+				},
+			},
+			setupWeather: func(sc *storage.Client) {
+				_ = sc.WeatherClientConfigs.Set(context.Background(), &weather.Config{
+					ID:      babyapi.ID{ID: weatherClientID},
+					Name:    "test",
+					Type:    "pws",
+					Options: map[string]any{"passkey": "secret"},
+				})
+			},
+			expectedDuration: time.Second,
+			expectedError:    "weather client does not support solar radiation data",
+		},
+		{
+			name: "SolarRadiationPartialScaleUp",
+			waterSchedule: &pkg.WaterSchedule{
+				Duration: &pkg.Duration{Duration: time.Second},
+				Interval: &pkg.Duration{Duration: time.Hour * 24},
+				WeatherControl: &weather.Control{
+					SolarRadiation: solarRadiationControl,
+				},
+			},
+			setupWeather: func(sc *storage.Client) {
+				_ = sc.WeatherClientConfigs.Set(context.Background(), &weather.Config{
+					ID:   babyapi.ID{ID: weatherClientID},
+					Name: "test",
+					Type: "fake",
+					Options: map[string]any{
+						"rain_interval":       "24h",
+						"avg_solar_radiation": 25,
+					},
+				})
+			},
+			expectedDuration: 1250 * time.Millisecond,
+		},
+		{
+			name: "CompoundScalingAllControls",
+			waterSchedule: &pkg.WaterSchedule{
+				Duration: &pkg.Duration{Duration: time.Second},
+				Interval: &pkg.Duration{Duration: time.Hour * 24},
+				WeatherControl: &weather.Control{
+					Temperature:    temperatureControl,
+					Rain:           rainControl,
+					Humidity:       humidityControl,
+					SolarRadiation: solarRadiationControl,
+				},
+			},
+			setupWeather: func(sc *storage.Client) {
+				_ = sc.WeatherClientConfigs.Set(context.Background(), &weather.Config{
+					ID:   babyapi.ID{ID: weatherClientID},
+					Name: "test",
+					Type: "fake",
+					Options: map[string]any{
+						"rain_interval":        "24h",
+						"rain_mm":              25,
+						"avg_high_temperature": 85,
+						"avg_humidity":         50,
+						"avg_solar_radiation":  30,
+					},
+				})
+			},
+			expectedDuration: 937500 * time.Microsecond,
+		},
 		{
 			name: "WeatherClientErrorReturnsBaseDuration",
 			waterSchedule: &pkg.WaterSchedule{
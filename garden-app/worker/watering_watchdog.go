@@ -0,0 +1,378 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+)
+
+const (
+	defaultWateringStartTimeout        = time.Minute
+	defaultWateringCompleteGracePeriod = time.Minute
+)
+
+// WateringWatchdogConfig configures how the worker watches for controllers to start and complete watering after a
+// WaterMessage is published
+type WateringWatchdogConfig struct {
+	// Disabled turns off the watchdog
+	Disabled bool `mapstructure:"disabled" yaml:"disabled"`
+	// StartTimeout is how long to wait for watering to start after it is expected to. Controllers water one Zone at
+	// a time, so watering is expected to start after earlier waterings for the Garden are done. Defaults to 1 minute
+	StartTimeout time.Duration `mapstructure:"start_timeout" yaml:"start_timeout"`
+	// CompleteGracePeriod is how long to wait after the watering duration for watering to complete. Defaults to
+	// 1 minute
+	CompleteGracePeriod time.Duration `mapstructure:"complete_grace_period" yaml:"complete_grace_period"`
+	// StartRetries is how many times the WaterMessage is published again if watering doesn't start. Controllers don't
+	// ignore duplicate messages, so a retry waters again if the controller only failed to report that it started.
+	// Defaults to 0
+	StartRetries int `mapstructure:"start_retries" yaml:"start_retries"`
+}
+
+func (c WateringWatchdogConfig) startTimeout() time.Duration {
+	if c.StartTimeout <= 0 {
+		return defaultWateringStartTimeout
+	}
+	return c.StartTimeout
+}
+
+func (c WateringWatchdogConfig) completeGracePeriod() time.Duration {
+	if c.CompleteGracePeriod <= 0 {
+		return defaultWateringCompleteGracePeriod
+	}
+	return c.CompleteGracePeriod
+}
+
+// WithWateringWatchdogConfig enables the watchdog that makes sure controllers start and complete watering. Without
+// this option, waterings are not watched
+func WithWateringWatchdogConfig(cfg WateringWatchdogConfig) WorkerOption {
+	return func(w *Worker) {
+		w.wateringWatchdogConfig = &cfg
+	}
+}
+
+// wateringWatch tracks a single watering until it completes or fails
+type wateringWatch struct {
+	// seq orders watches for the same Garden since the controller waters them in order
+	seq                  uint64
+	status               *pkg.WateringStatus
+	garden               *pkg.Garden
+	zone                 *pkg.Zone
+	message              action.WaterMessage
+	notificationClientID string
+	expectedStart        time.Time
+	timer                clock.Timer
+}
+
+// GetWateringStatus returns the watchdog's status for the most recent watering of a Zone. It returns nil if the
+// Zone has not been watered since the server started
+func (w *Worker) GetWateringStatus(zoneID string) *pkg.WateringStatus {
+	w.wateringWatchdogMutex.Lock()
+	defer w.wateringWatchdogMutex.Unlock()
+
+	status, ok := w.wateringStatus[zoneID]
+	if !ok {
+		return nil
+	}
+	result := *status
+	return &result
+}
+
+// watchWatering starts watching for the controller to start watering. If the event is already being watched
+// because the message was published again, the number of retries is kept
+func (w *Worker) watchWatering(g *pkg.Garden, z *pkg.Zone, msg action.WaterMessage, notificationClientID string) {
+	if w.wateringWatchdogConfig == nil || w.wateringWatchdogConfig.Disabled {
+		return
+	}
+
+	w.wateringWatchdogMutex.Lock()
+	defer w.wateringWatchdogMutex.Unlock()
+
+	now := clock.Now()
+	w.wateringWatchSeq++
+	watch := &wateringWatch{
+		seq: w.wateringWatchSeq,
+		status: &pkg.WateringStatus{
+			EventID:  msg.EventID,
+			State:    pkg.WateringStateSent,
+			Duration: pkg.Duration{Duration: time.Duration(msg.Duration) * time.Millisecond},
+			SentAt:   now,
+		},
+		garden:               g,
+		zone:                 z,
+		message:              msg,
+		notificationClientID: notificationClientID,
+	}
+
+	if existing, ok := w.wateringWatches[msg.EventID]; ok {
+		existing.timer.Stop()
+		watch.seq = existing.seq
+		watch.status.Retries = existing.status.Retries
+		if watch.notificationClientID == "" {
+			watch.notificationClientID = existing.notificationClientID
+		}
+	}
+
+	watch.expectedStart = w.expectedWateringStart(watch, now)
+	watch.timer = clock.AfterFunc(
+		watch.expectedStart.Add(w.wateringWatchdogConfig.startTimeout()).Sub(now),
+		func() { w.handleWateringStartTimeout(msg.EventID) },
+	)
+
+	w.wateringWatches[msg.EventID] = watch
+	w.wateringStatus[z.GetID()] = watch.status
+}
+
+// watchQueuedWatering starts watching a watering that was published from the command queue
+func (w *Worker) watchQueuedWatering(ctx context.Context, cmd *pkg.QueuedCommand) {
+	if w.wateringWatchdogConfig == nil || w.wateringWatchdogConfig.Disabled {
+		return
+	}
+
+	logger := w.logger.With("garden_id", cmd.GardenID, "command_id", cmd.ID)
+
+	var msg action.WaterMessage
+	err := json.Unmarshal([]byte(cmd.Payload), &msg)
+	if err != nil {
+		logger.Error("unable to parse queued WaterMessage", "error", err)
+		return
+	}
+
+	g, err := w.storageClient.Gardens.Get(ctx, cmd.GardenID)
+	if err != nil {
+		logger.Error("unable to get Garden for queued WaterMessage", "error", err)
+		return
+	}
+	z, err := w.storageClient.Zones.Get(ctx, msg.ZoneID)
+	if err != nil {
+		logger.Error("unable to get Zone for queued WaterMessage", "error", err, "zone_id", msg.ZoneID)
+		return
+	}
+
+	w.watchWatering(g, z, msg, "")
+}
+
+// expectedWateringStart returns when the watering should start based on earlier waterings for the same Garden. The
+// result is never before the earliest time. It expects the caller to hold wateringWatchdogMutex
+func (w *Worker) expectedWateringStart(watch *wateringWatch, earliest time.Time) time.Time {
+	start := earliest
+	for _, other := range w.wateringWatches {
+		if other.seq >= watch.seq || other.garden.GetID() != watch.garden.GetID() {
+			continue
+		}
+
+		end := other.expectedStart
+		if other.status.StartedAt != nil {
+			end = *other.status.StartedAt
+		}
+		end = end.Add(other.status.Duration.Duration)
+
+		if end.After(start) {
+			start = end
+		}
+	}
+	return start
+}
+
+// handleWateringStatusEvent updates the watched watering when the controller reports that it started, completed,
+// or cancelled watering
+func (w *Worker) handleWateringStatusEvent(event action.WaterStatusEvent) {
+	w.wateringWatchdogMutex.Lock()
+	defer w.wateringWatchdogMutex.Unlock()
+
+	watch, ok := w.wateringWatches[event.EventID]
+	if !ok {
+		return
+	}
+
+	now := clock.Now()
+	switch event.Status {
+	case pkg.WaterStatusStarted:
+		watch.timer.Stop()
+		watch.status.State = pkg.WateringStateStarted
+		watch.status.StartedAt = &now
+		watch.timer = clock.AfterFunc(
+			watch.status.Duration.Duration+w.wateringWatchdogConfig.completeGracePeriod(),
+			func() { w.handleWateringCompleteTimeout(event.EventID) },
+		)
+	case pkg.WaterStatusCompleted:
+		watch.timer.Stop()
+		watch.status.State = pkg.WateringStateCompleted
+		watch.status.CompletedAt = &now
+		delete(w.wateringWatches, event.EventID)
+	case pkg.WaterStatusCancelled:
+		watch.timer.Stop()
+		watch.status.State = pkg.WateringStateCancelled
+		watch.status.CompletedAt = &now
+		delete(w.wateringWatches, event.EventID)
+	}
+}
+
+// failWatering marks a watched watering as not started when it could not be sent. It returns the watch, or nil if
+// the watering was not watched
+func (w *Worker) failWatering(eventID string, err error) *wateringWatch {
+	w.wateringWatchdogMutex.Lock()
+	defer w.wateringWatchdogMutex.Unlock()
+
+	watch, ok := w.wateringWatches[eventID]
+	if !ok {
+		return nil
+	}
+
+	watch.timer.Stop()
+	watch.status.State = pkg.WateringStateNotStarted
+	watch.status.Error = err.Error()
+	delete(w.wateringWatches, eventID)
+	return watch
+}
+
+// handleWateringStartTimeout publishes the WaterMessage again if watering did not start. After all retries, the
+// watering is marked as failed and a notification is sent
+func (w *Worker) handleWateringStartTimeout(eventID string) {
+	w.wateringWatchdogMutex.Lock()
+
+	watch, ok := w.wateringWatches[eventID]
+	if !ok || watch.status.State != pkg.WateringStateSent {
+		w.wateringWatchdogMutex.Unlock()
+		return
+	}
+
+	logger := w.contextLogger(watch.garden, watch.zone, nil).With("event_id", eventID)
+	now := clock.Now()
+
+	// The controller could still be busy with an earlier watering that started late
+	expectedStart := w.expectedWateringStart(watch, watch.expectedStart)
+	if expectedStart.After(watch.expectedStart) {
+		watch.expectedStart = expectedStart
+		watch.timer = clock.AfterFunc(
+			expectedStart.Add(w.wateringWatchdogConfig.startTimeout()).Sub(now),
+			func() { w.handleWateringStartTimeout(eventID) },
+		)
+		w.wateringWatchdogMutex.Unlock()
+		return
+	}
+
+	if watch.status.Retries < w.wateringWatchdogConfig.StartRetries {
+		watch.status.Retries++
+		watch.expectedStart = now
+		watch.timer = clock.AfterFunc(w.wateringWatchdogConfig.startTimeout(), func() { w.handleWateringStartTimeout(eventID) })
+		w.wateringWatchdogMutex.Unlock()
+
+		logger.Warn("watering did not start, publishing WaterMessage again", "retries", watch.status.Retries)
+		err := w.republishWaterMessage(watch)
+		if err != nil {
+			logger.Error("unable to publish WaterMessage again", "error", err)
+		}
+		return
+	}
+
+	watch.status.State = pkg.WateringStateNotStarted
+	watch.status.Error = pkg.ErrSentButNotStarted.Error()
+	delete(w.wateringWatches, eventID)
+	w.wateringWatchdogMutex.Unlock()
+
+	logger.Error("watering did not start", "retries", watch.status.Retries)
+	schedulerErrors.WithLabelValues(zoneLabels(watch.zone)...).Inc()
+
+	w.sendWateringErrorNotification(
+		watch,
+		fmt.Sprintf("%s: Watering Not Started", watch.zone.Name),
+		fmt.Sprintf("The controller did not start watering after %d attempt(s).\nGarden: %s", watch.status.Retries+1, watch.garden.Name),
+		logger,
+	)
+}
+
+// handleWateringCompleteTimeout sends a stop command if watering did not complete on time and sends a notification
+func (w *Worker) handleWateringCompleteTimeout(eventID string) {
+	w.wateringWatchdogMutex.Lock()
+
+	watch, ok := w.wateringWatches[eventID]
+	if !ok || watch.status.State != pkg.WateringStateStarted {
+		w.wateringWatchdogMutex.Unlock()
+		return
+	}
+
+	watch.status.State = pkg.WateringStateNotCompleted
+	watch.status.Error = pkg.ErrElapsedExceedsDuration.Error()
+	delete(w.wateringWatches, eventID)
+	w.wateringWatchdogMutex.Unlock()
+
+	logger := w.contextLogger(watch.garden, watch.zone, nil).With("event_id", eventID)
+	logger.Error("watering did not complete, sending stop command")
+	schedulerErrors.WithLabelValues(zoneLabels(watch.zone)...).Inc()
+
+	message := fmt.Sprintf("The controller did not complete watering after %s, so a stop command was sent.", watch.status.Duration.Duration)
+	err := w.ExecuteStopAction(context.Background(), watch.garden, &action.StopAction{})
+	if err != nil {
+		logger.Error("unable to send stop command", "error", err)
+		message = fmt.Sprintf("The controller did not complete watering after %s and the stop command failed: %v", watch.status.Duration.Duration, err)
+	}
+
+	w.sendWateringErrorNotification(
+		watch,
+		fmt.Sprintf("%s: Watering Not Completed", watch.zone.Name),
+		fmt.Sprintf("%s\nGarden: %s", message, watch.garden.Name),
+		logger,
+	)
+}
+
+func (w *Worker) republishWaterMessage(watch *wateringWatch) error {
+	d, err := w.gardenDriver(watch.garden)
+	if err != nil {
+		return err
+	}
+	// other drivers are only used by the worker, so the watering already started if the driver didn't fail
+	if _, ok := d.(mqttDriver); !ok {
+		return fmt.Errorf("waterings are not sent again for the %s driver", watch.garden.Driver.GetType())
+	}
+
+	msg, err := json.Marshal(watch.message)
+	if err != nil {
+		return fmt.Errorf("unable to marshal WaterMessage to JSON: %w", err)
+	}
+
+	topic, err := mqtt.WaterTopic(watch.garden.TopicPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to fill MQTT topic template: %w", err)
+	}
+
+	return w.publishCommand(context.Background(), watch.garden, CommandTypeWater, topic, msg)
+}
+
+// sendWateringErrorNotification notifies the Garden's notification client if it has watering_errors enabled and
+// the WaterSchedule's notification client for scheduled waterings
+func (w *Worker) sendWateringErrorNotification(watch *wateringWatch, title, message string, logger *slog.Logger) {
+	clientIDs := []string{}
+	if watch.garden.GetNotificationClientID() != "" && watch.garden.GetNotificationSettings().WateringErrors {
+		clientIDs = append(clientIDs, watch.garden.GetNotificationClientID())
+	}
+	if watch.notificationClientID != "" && !slices.Contains(clientIDs, watch.notificationClientID) {
+		clientIDs = append(clientIDs, watch.notificationClientID)
+	}
+
+	if len(clientIDs) == 0 {
+		logger.Debug("no notification client configured for watering errors")
+		return
+	}
+
+	for _, clientID := range clientIDs {
+		w.sendNotification(context.Background(), clientID, title, message, logger)
+	}
+}
+
+// stopWateringWatches stops all pending watchdog timers
+func (w *Worker) stopWateringWatches() {
+	w.wateringWatchdogMutex.Lock()
+	defer w.wateringWatchdogMutex.Unlock()
+
+	for _, watch := range w.wateringWatches {
+		watch.timer.Stop()
+	}
+}
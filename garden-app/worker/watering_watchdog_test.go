@@ -0,0 +1,234 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications/fake"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type watchdogTest struct {
+	w      *Worker
+	garden *pkg.Garden
+	zone   *pkg.Zone
+
+	mu        sync.Mutex
+	published []string
+}
+
+func newWatchdogTest(t *testing.T, startRetries int) *watchdogTest {
+	t.Helper()
+
+	fake.Reset()
+	t.Cleanup(fake.Reset)
+
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	nc := &notifications.Client{
+		ID:   babyapi.NewID(),
+		Name: "test",
+		URL:  "fake://success",
+	}
+	require.NoError(t, storageClient.NotificationClientConfigs.Set(context.Background(), nc))
+
+	wt := &watchdogTest{
+		garden: createExampleGarden(),
+		zone:   createExampleZone(),
+	}
+	ncID := nc.GetID()
+	wt.garden.NotificationClientID = &ncID
+	wt.garden.NotificationSettings = &pkg.NotificationSettings{WateringErrors: true}
+	require.NoError(t, storageClient.Gardens.Set(context.Background(), wt.garden))
+	require.NoError(t, storageClient.Zones.Set(context.Background(), wt.zone))
+
+	mqttClient := new(mqtt.MockClient)
+	mqttClient.On("Publish", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, topic string, _ []byte) error {
+		wt.mu.Lock()
+		defer wt.mu.Unlock()
+		wt.published = append(wt.published, topic)
+		return nil
+	})
+
+	wt.w = NewWorker(storageClient, nil, mqttClient, slog.Default(), WithWateringWatchdogConfig(WateringWatchdogConfig{
+		StartTimeout:        time.Minute,
+		CompleteGracePeriod: time.Minute,
+		StartRetries:        startRetries,
+	}))
+	t.Cleanup(wt.w.stopWateringWatches)
+
+	return wt
+}
+
+func (wt *watchdogTest) getPublished() []string {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	return append([]string{}, wt.published...)
+}
+
+// water executes a scheduled watering and returns the event ID
+func (wt *watchdogTest) water(t *testing.T, d time.Duration) string {
+	t.Helper()
+	err := wt.w.ExecuteScheduledWaterAction(context.Background(), wt.garden, wt.zone, createExampleWaterSchedule(), d)
+	require.NoError(t, err)
+	return wt.w.GetWateringStatus(wt.zone.GetID()).EventID
+}
+
+func (wt *watchdogTest) sendStatus(t *testing.T, eventID string, status pkg.WaterStatus) {
+	t.Helper()
+	payload := fmt.Sprintf("water,status=%s,zone=0,zone_id=%s,id=%s millis=0", status, wt.zone.GetID(), eventID)
+	require.NoError(t, wt.w.doWaterCompleteStatusMessage("test-garden/data/water", []byte(payload)))
+}
+
+func (wt *watchdogTest) eventuallyState(t *testing.T, expected pkg.WateringState) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return wt.w.GetWateringStatus(wt.zone.GetID()).State == expected
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWateringWatchdog(t *testing.T) {
+	t.Run("StartedAndCompleted", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+		wt := newWatchdogTest(t, 0)
+
+		eventID := wt.water(t, 5*time.Minute)
+		status := wt.w.GetWateringStatus(wt.zone.GetID())
+		assert.Equal(t, pkg.WateringStateSent, status.State)
+		assert.Equal(t, 5*time.Minute, status.Duration.Duration)
+
+		wt.sendStatus(t, eventID, pkg.WaterStatusStarted)
+		assert.Equal(t, pkg.WateringStateStarted, wt.w.GetWateringStatus(wt.zone.GetID()).State)
+
+		mockClock.Add(5 * time.Minute)
+		wt.sendStatus(t, eventID, pkg.WaterStatusCompleted)
+
+		status = wt.w.GetWateringStatus(wt.zone.GetID())
+		assert.Equal(t, pkg.WateringStateCompleted, status.State)
+		assert.NotNil(t, status.StartedAt)
+		assert.NotNil(t, status.CompletedAt)
+		assert.False(t, status.Failed())
+
+		mockClock.Add(time.Hour)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, []string{"test-garden/command/water"}, wt.getPublished())
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	t.Run("NotStarted", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+		wt := newWatchdogTest(t, 0)
+
+		wt.water(t, 5*time.Minute)
+
+		mockClock.Add(time.Minute)
+		wt.eventuallyState(t, pkg.WateringStateNotStarted)
+		assert.Equal(t, []string{"test-garden/command/water"}, wt.getPublished())
+
+		require.Eventually(t, func() bool { return fake.LastMessage().Title != "" }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "The controller did not start watering after 1 attempt(s).\nGarden: test-garden", fake.LastMessage().Message)
+	})
+
+	t.Run("NotStartedWithRetries", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+		wt := newWatchdogTest(t, 1)
+
+		wt.water(t, 5*time.Minute)
+
+		mockClock.Add(time.Minute)
+		require.Eventually(t, func() bool {
+			return len(wt.getPublished()) == 2
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, 1, wt.w.GetWateringStatus(wt.zone.GetID()).Retries)
+
+		mockClock.Add(time.Minute)
+		wt.eventuallyState(t, pkg.WateringStateNotStarted)
+
+		status := wt.w.GetWateringStatus(wt.zone.GetID())
+		assert.True(t, status.Failed())
+		assert.Equal(t, pkg.ErrSentButNotStarted.Error(), status.Error)
+
+		require.Eventually(t, func() bool { return fake.LastMessage().Title != "" }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, "test zone: Watering Not Started", fake.LastMessage().Title)
+		assert.Equal(t, "The controller did not start watering after 2 attempt(s).\nGarden: test-garden", fake.LastMessage().Message)
+	})
+
+	t.Run("NotCompleted", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+		wt := newWatchdogTest(t, 0)
+
+		eventID := wt.water(t, 5*time.Minute)
+		wt.sendStatus(t, eventID, pkg.WaterStatusStarted)
+
+		mockClock.Add(5 * time.Minute)
+		time.Sleep(10 * time.Millisecond)
+		assert.Equal(t, pkg.WateringStateStarted, wt.w.GetWateringStatus(wt.zone.GetID()).State)
+
+		mockClock.Add(time.Minute)
+		wt.eventuallyState(t, pkg.WateringStateNotCompleted)
+
+		require.Eventually(t, func() bool { return fake.LastMessage().Title != "" }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, []string{"test-garden/command/water", "test-garden/command/stop"}, wt.getPublished())
+		assert.Equal(t, "test zone: Watering Not Completed", fake.LastMessage().Title)
+		assert.Equal(t, "The controller did not complete watering after 5m0s, so a stop command was sent.\nGarden: test-garden", fake.LastMessage().Message)
+	})
+
+	t.Run("WaitsForEarlierWatering", func(t *testing.T) {
+		mockClock := clock.MockTime()
+		defer clock.Reset()
+		wt := newWatchdogTest(t, 0)
+
+		first := wt.water(t, 10*time.Minute)
+		second := wt.water(t, 5*time.Minute)
+		wt.sendStatus(t, first, pkg.WaterStatusStarted)
+
+		// The second watering is expected to start after the first completes, so it is not retried yet
+		mockClock.Add(10 * time.Minute)
+		time.Sleep(10 * time.Millisecond)
+		assert.Len(t, wt.getPublished(), 2)
+
+		wt.sendStatus(t, first, pkg.WaterStatusCompleted)
+		wt.sendStatus(t, second, pkg.WaterStatusStarted)
+		assert.Equal(t, pkg.WateringStateStarted, wt.w.GetWateringStatus(wt.zone.GetID()).State)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		clock.MockTime()
+		defer clock.Reset()
+		wt := newWatchdogTest(t, 0)
+
+		eventID := wt.water(t, 5*time.Minute)
+		wt.sendStatus(t, eventID, pkg.WaterStatusStarted)
+		wt.sendStatus(t, eventID, pkg.WaterStatusCancelled)
+
+		assert.Equal(t, pkg.WateringStateCancelled, wt.w.GetWateringStatus(wt.zone.GetID()).State)
+	})
+}
+
+func TestWateringWatchdogDisabledByDefault(t *testing.T) {
+	wt := newWatchdogTest(t, 0)
+	wt.w.wateringWatchdogConfig = nil
+
+	err := wt.w.ExecuteScheduledWaterAction(context.Background(), wt.garden, wt.zone, createExampleWaterSchedule(), time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, wt.w.GetWateringStatus(wt.zone.GetID()))
+}
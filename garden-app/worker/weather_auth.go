@@ -0,0 +1,154 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+)
+
+const (
+	defaultWeatherAuthCheckInterval = 30 * time.Minute
+	defaultWeatherAuthRefreshWindow = time.Hour
+)
+
+// WeatherAuthConfig configures the background job that keeps OAuth tokens for weather clients, like Netatmo,
+// refreshed and sends a notification when the user needs to authorize again
+type WeatherAuthConfig struct {
+	// CheckInterval is how often tokens are checked. Defaults to 30 minutes
+	CheckInterval time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+	// RefreshWindow is used to refresh tokens that expire within this duration. Defaults to 1 hour
+	RefreshWindow time.Duration `mapstructure:"refresh_window" yaml:"refresh_window"`
+	// NotificationClientID is the notification client used to send re-authorization alerts
+	NotificationClientID string `mapstructure:"notification_client_id" yaml:"notification_client_id"`
+	// BaseURL is the externally-reachable URL of the server, which is used to link to the re-authorization page
+	BaseURL string `mapstructure:"base_url" yaml:"base_url"`
+}
+
+func (c WeatherAuthConfig) checkInterval() time.Duration {
+	if c.CheckInterval <= 0 {
+		return defaultWeatherAuthCheckInterval
+	}
+	return c.CheckInterval
+}
+
+func (c WeatherAuthConfig) refreshWindow() time.Duration {
+	if c.RefreshWindow <= 0 {
+		return defaultWeatherAuthRefreshWindow
+	}
+	return c.RefreshWindow
+}
+
+// WithWeatherAuthConfig configures the weather client token refresh job
+func WithWeatherAuthConfig(cfg WeatherAuthConfig) WorkerOption {
+	return func(w *Worker) {
+		w.weatherAuthConfig = cfg
+	}
+}
+
+func (w *Worker) scheduleWeatherAuthCheck() error {
+	if w.storageClient == nil {
+		return nil
+	}
+
+	_, err := w.scheduler.
+		Every(w.weatherAuthConfig.checkInterval()).
+		Tag("weather_auth").
+		Do(w.checkAllWeatherClientAuth)
+	if err != nil {
+		return fmt.Errorf("error scheduling weather client auth check: %w", err)
+	}
+	return nil
+}
+
+// checkAllWeatherClientAuth refreshes tokens for all weather clients that use them and records the result
+func (w *Worker) checkAllWeatherClientAuth() {
+	ctx := context.Background()
+	for wc, err := range w.storageClient.WeatherClientConfigs.Search(ctx, "", nil) {
+		if err != nil {
+			w.logger.Error("error getting weather client for auth check", "error", err)
+			continue
+		}
+		if !wc.HasTokenRefresh() {
+			continue
+		}
+
+		w.CheckWeatherClientAuth(ctx, wc)
+	}
+}
+
+// CheckWeatherClientAuth refreshes the weather client's token if it expires soon, stores the resulting
+// AuthStatus, and sends a notification if the client changed to requiring re-authorization
+func (w *Worker) CheckWeatherClientAuth(ctx context.Context, wc *weather.Config) *weather.AuthStatus {
+	logger := w.logger.With("weather_client_id", wc.GetID())
+
+	status := &weather.AuthStatus{
+		State:       weather.AuthStateOK,
+		LastChecked: clock.Now(),
+	}
+
+	expiration, err := w.refreshWeatherClientToken(wc)
+	switch {
+	case errors.Is(err, weather.ErrReauthorizationRequired):
+		status.State = weather.AuthStateReauthorizationRequired
+		status.Error = err.Error()
+		logger.Warn("weather client requires re-authorization", "error", err)
+	case err != nil:
+		status.State = weather.AuthStateError
+		status.Error = err.Error()
+		logger.Error("error refreshing weather client token", "error", err)
+		schedulerErrors.WithLabelValues("weather_auth", wc.GetID()).Inc()
+	default:
+		status.TokenExpiration = &expiration
+		logger.Debug("weather client token is valid", "expiration", expiration)
+	}
+
+	previous, err := w.storageClient.WeatherClientHealth.GetAuthStatus(ctx, wc.GetID())
+	if err != nil {
+		logger.Error("error getting previous weather client auth status", "error", err)
+	}
+
+	err = w.storageClient.WeatherClientHealth.RecordAuthStatus(ctx, wc.GetID(), status)
+	if err != nil {
+		logger.Error("error storing weather client auth status", "error", err)
+	}
+
+	// Only notify when the state changes so the same alert isn't sent on every check. The previous status is stored
+	// so this also works after restarting
+	if status.ReauthorizationRequired() && !previous.ReauthorizationRequired() {
+		w.sendReauthorizationNotification(ctx, wc, status)
+	}
+
+	return status
+}
+
+func (w *Worker) refreshWeatherClientToken(wc *weather.Config) (time.Time, error) {
+	// Creating the client refreshes tokens that are already expired, so this can also fail for revoked tokens
+	client, err := w.storageClient.GetWeatherClient(wc.ID.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return weather.RefreshToken(client, w.weatherAuthConfig.refreshWindow())
+}
+
+func (w *Worker) sendReauthorizationNotification(ctx context.Context, wc *weather.Config, status *weather.AuthStatus) {
+	if w.weatherAuthConfig.NotificationClientID == "" {
+		return
+	}
+
+	link := fmt.Sprintf("%s/weather_clients/%s/netatmo/oauth/start", strings.TrimSuffix(w.weatherAuthConfig.BaseURL, "/"), wc.GetID())
+	w.sendNotification(
+		ctx,
+		w.weatherAuthConfig.NotificationClientID,
+		fmt.Sprintf("%s: Re-authorization Required", wc.Name),
+		fmt.Sprintf(`Weather data is unavailable and watering will not be scaled until the weather client is authorized again.
+Authorize: %s
+Details: %s`, link, status.Error),
+		w.logger.With("weather_client_id", wc.GetID()),
+	)
+}
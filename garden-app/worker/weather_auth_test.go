@@ -0,0 +1,144 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications/fake"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWeatherClientAuth(t *testing.T) {
+	fake.Reset()
+	defer fake.Reset()
+	weather.ResetCache()
+	defer weather.ResetCache()
+
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	nc := &notifications.Client{
+		ID:   babyapi.NewID(),
+		Name: "test",
+		URL:  "fake://success",
+	}
+	err = storageClient.NotificationClientConfigs.Set(context.Background(), nc)
+	require.NoError(t, err)
+
+	expiration := time.Now().Add(24 * time.Hour).Truncate(time.Second).UTC()
+	authorized := &weather.Config{
+		ID:   babyapi.NewID(),
+		Name: "authorized",
+		Type: "netatmo",
+		Options: map[string]any{
+			"station_id":        "station",
+			"rain_module_id":    "rain",
+			"outdoor_module_id": "outdoor",
+			"authentication": map[string]any{
+				"access_token":    "ACCESS_TOKEN",
+				"refresh_token":   "REFRESH_TOKEN",
+				"expiration_date": expiration.Format(time.RFC3339Nano),
+			},
+		},
+	}
+	err = storageClient.WeatherClientConfigs.Set(context.Background(), authorized)
+	require.NoError(t, err)
+
+	unauthorized := &weather.Config{
+		ID:   babyapi.NewID(),
+		Name: "unauthorized",
+		Type: "netatmo",
+		Options: map[string]any{
+			"station_id": "station",
+		},
+	}
+	err = storageClient.WeatherClientConfigs.Set(context.Background(), unauthorized)
+	require.NoError(t, err)
+
+	w := NewWorker(storageClient, nil, nil, slog.Default(), WithWeatherAuthConfig(WeatherAuthConfig{
+		NotificationClientID: nc.GetID(),
+		BaseURL:              "http://garden.local/",
+	}))
+
+	getAuthStatus := func(t *testing.T, id string) *weather.AuthStatus {
+		t.Helper()
+		status, err := storageClient.WeatherClientHealth.GetAuthStatus(context.Background(), id)
+		require.NoError(t, err)
+		return status
+	}
+
+	t.Run("NotCheckedYet", func(t *testing.T) {
+		assert.Nil(t, getAuthStatus(t, authorized.GetID()))
+	})
+
+	t.Run("ValidToken", func(t *testing.T) {
+		status := w.CheckWeatherClientAuth(context.Background(), authorized)
+		assert.Equal(t, weather.AuthStateOK, status.State)
+		require.NotNil(t, status.TokenExpiration)
+		assert.True(t, expiration.Equal(*status.TokenExpiration))
+		assert.Empty(t, status.Error)
+		assert.Empty(t, fake.LastMessage())
+
+		stored := getAuthStatus(t, authorized.GetID())
+		require.NotNil(t, stored)
+		assert.Equal(t, weather.AuthStateOK, stored.State)
+		assert.True(t, expiration.Equal(*stored.TokenExpiration))
+	})
+
+	t.Run("ReauthorizationRequired", func(t *testing.T) {
+		status := w.CheckWeatherClientAuth(context.Background(), unauthorized)
+		assert.Equal(t, weather.AuthStateReauthorizationRequired, status.State)
+		assert.Nil(t, status.TokenExpiration)
+		assert.Equal(t, "re-authorization required: authentication is not configured", status.Error)
+
+		last := fake.LastMessage()
+		assert.Equal(t, "unauthorized: Re-authorization Required", last.Title)
+		assert.Contains(t, last.Message, "Authorize: http://garden.local/weather_clients/"+unauthorized.GetID()+"/netatmo/oauth/start")
+	})
+
+	t.Run("NoRepeatedNotification", func(t *testing.T) {
+		fake.Reset()
+
+		status := w.CheckWeatherClientAuth(context.Background(), unauthorized)
+		assert.Equal(t, weather.AuthStateReauthorizationRequired, status.State)
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	t.Run("NoRepeatedNotificationAfterRestart", func(t *testing.T) {
+		fake.Reset()
+
+		restarted := NewWorker(storageClient, nil, nil, slog.Default(), WithWeatherAuthConfig(WeatherAuthConfig{
+			NotificationClientID: nc.GetID(),
+		}))
+
+		status := restarted.CheckWeatherClientAuth(context.Background(), unauthorized)
+		assert.Equal(t, weather.AuthStateReauthorizationRequired, status.State)
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	t.Run("CheckAll", func(t *testing.T) {
+		nonOAuth := &weather.Config{
+			ID:      babyapi.NewID(),
+			Name:    "fake",
+			Type:    "fake",
+			Options: map[string]any{},
+		}
+		err = storageClient.WeatherClientConfigs.Set(context.Background(), nonOAuth)
+		require.NoError(t, err)
+
+		w.checkAllWeatherClientAuth()
+
+		assert.Equal(t, weather.AuthStateOK, getAuthStatus(t, authorized.GetID()).State)
+		assert.Equal(t, weather.AuthStateReauthorizationRequired, getAuthStatus(t, unauthorized.GetID()).State)
+		assert.Nil(t, getAuthStatus(t, nonOAuth.GetID()))
+	})
+}
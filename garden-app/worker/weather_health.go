@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+)
+
+const (
+	defaultWeatherHealthCheckInterval    = 15 * time.Minute
+	defaultWeatherHealthFailureThreshold = 6 * time.Hour
+)
+
+// WeatherHealthConfig configures the background job that sends notifications for weather clients that keep failing
+type WeatherHealthConfig struct {
+	// CheckInterval is how often weather client health is checked. Defaults to 15 minutes
+	CheckInterval time.Duration `mapstructure:"check_interval" yaml:"check_interval"`
+	// FailureThreshold is how long a weather client needs to be failing before a notification is sent. Defaults
+	// to 6 hours
+	FailureThreshold time.Duration `mapstructure:"failure_threshold" yaml:"failure_threshold"`
+}
+
+func (c WeatherHealthConfig) checkInterval() time.Duration {
+	if c.CheckInterval <= 0 {
+		return defaultWeatherHealthCheckInterval
+	}
+	return c.CheckInterval
+}
+
+func (c WeatherHealthConfig) failureThreshold() time.Duration {
+	if c.FailureThreshold <= 0 {
+		return defaultWeatherHealthFailureThreshold
+	}
+	return c.FailureThreshold
+}
+
+// WithWeatherHealthConfig configures the weather client health notification job
+func WithWeatherHealthConfig(cfg WeatherHealthConfig) WorkerOption {
+	return func(w *Worker) {
+		w.weatherHealthConfig = cfg
+	}
+}
+
+func (w *Worker) scheduleWeatherHealthCheck() error {
+	if w.storageClient == nil {
+		return nil
+	}
+
+	_, err := w.scheduler.
+		Every(w.weatherHealthConfig.checkInterval()).
+		Tag("weather_health").
+		Do(w.checkAllWeatherClientHealth)
+	if err != nil {
+		return fmt.Errorf("error scheduling weather client health check: %w", err)
+	}
+	return nil
+}
+
+// checkAllWeatherClientHealth checks the health of all weather clients and sends notifications for failing ones
+func (w *Worker) checkAllWeatherClientHealth() {
+	ctx := context.Background()
+	for wc, err := range w.storageClient.WeatherClientConfigs.Search(ctx, "", nil) {
+		if err != nil {
+			w.logger.Error("error getting weather client for health check", "error", err)
+			continue
+		}
+
+		err = w.CheckWeatherClientHealth(ctx, wc)
+		if err != nil {
+			w.logger.Error("error checking weather client health", "weather_client_id", wc.GetID(), "error", err)
+			schedulerErrors.WithLabelValues("weather_health", wc.GetID()).Inc()
+		}
+	}
+}
+
+// CheckWeatherClientHealth sends a notification to each active WaterSchedule using the weather client if it has
+// been failing for longer than the threshold. Only one notification is sent for each period of failures
+func (w *Worker) CheckWeatherClientHealth(ctx context.Context, wc *weather.Config) error {
+	health, err := w.storageClient.WeatherClientHealth.Get(ctx, wc.GetID())
+	if err != nil {
+		return err
+	}
+
+	w.weatherHealthMutex.Lock()
+	defer w.weatherHealthMutex.Unlock()
+
+	if !health.Failing() {
+		delete(w.weatherHealthNotified, wc.GetID())
+		return nil
+	}
+
+	if health.FailingFor() < w.weatherHealthConfig.failureThreshold() {
+		return nil
+	}
+
+	// FailingSince identifies the period of failures, so a new notification is sent if the client recovers and
+	// then fails again
+	if notified, ok := w.weatherHealthNotified[wc.GetID()]; ok && health.FailingSince != nil && notified.Equal(*health.FailingSince) {
+		return nil
+	}
+
+	waterSchedules, err := w.storageClient.GetWaterSchedulesUsingWeatherClient(wc.GetID())
+	if err != nil {
+		return fmt.Errorf("error getting WaterSchedules using weather client: %w", err)
+	}
+
+	logger := w.logger.With("weather_client_id", wc.GetID())
+	title := fmt.Sprintf("%s: Weather Client Failing", wc.Name)
+	message := fmt.Sprintf(`Weather client has been failing for %s (%d consecutive failures), so watering might not be scaled correctly.
+Last error: %s`, health.FailingFor().Truncate(time.Minute), health.ConsecutiveFailures, health.LastError)
+
+	now := clock.Now()
+	notificationClients := map[string]struct{}{}
+	for _, ws := range waterSchedules {
+		if ws.EndDated() || !ws.IsActive(now) || !ws.GetNotificationSettings().WateringErrors {
+			continue
+		}
+
+		clientID := ws.GetNotificationClientID()
+		if clientID == "" {
+			continue
+		}
+		if _, ok := notificationClients[clientID]; ok {
+			continue
+		}
+		notificationClients[clientID] = struct{}{}
+
+		w.sendNotification(ctx, clientID, title, message, logger.With("water_schedule_id", ws.GetID()))
+	}
+
+	if len(notificationClients) > 0 {
+		logger.Warn("sent weather client failure notifications", "failing_for", health.FailingFor())
+	}
+	w.weatherHealthNotified[wc.GetID()] = *health.FailingSince
+
+	return nil
+}
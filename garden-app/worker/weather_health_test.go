@@ -0,0 +1,127 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/notifications/fake"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/weather"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWeatherClientHealth(t *testing.T) {
+	fake.Reset()
+	defer fake.Reset()
+	mockClock := clock.MockTime()
+	defer clock.Reset()
+
+	storageClient, err := storage.NewClient(storage.Config{
+		ConnectionString: ":memory:",
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	nc := &notifications.Client{
+		ID:   babyapi.NewID(),
+		Name: "test",
+		URL:  "fake://success",
+	}
+	require.NoError(t, storageClient.NotificationClientConfigs.Set(ctx, nc))
+
+	wc := &weather.Config{
+		ID:      babyapi.NewID(),
+		Name:    "weather",
+		Type:    "fake",
+		Options: map[string]any{},
+	}
+	require.NoError(t, storageClient.WeatherClientConfigs.Set(ctx, wc))
+
+	ncID := nc.GetID()
+	startDate := pkg.NewDate(clock.Now().Add(-24 * time.Hour))
+	ws := &pkg.WaterSchedule{
+		ID:        babyapi.NewID(),
+		Duration:  &pkg.Duration{Duration: time.Hour},
+		Interval:  &pkg.Duration{Duration: 24 * time.Hour},
+		StartDate: &startDate,
+		StartTime: pkg.NewStartTime(clock.Now()),
+		WeatherControl: &weather.Control{
+			Rain: &weather.WeatherScaler{ClientID: wc.ID.ID},
+		},
+		NotificationClientID: &ncID,
+		NotificationSettings: &pkg.WaterScheduleNotificationSettings{WateringErrors: true},
+	}
+	require.NoError(t, storageClient.WaterSchedules.Set(ctx, ws))
+
+	w := NewWorker(storageClient, nil, nil, slog.Default(), WithWeatherHealthConfig(WeatherHealthConfig{
+		FailureThreshold: time.Hour,
+	}))
+
+	t.Run("NoRecords", func(t *testing.T) {
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	require.NoError(t, storageClient.WeatherClientHealth.RecordFailure(ctx, wc.GetID(), clock.Now(), errors.New("API is down")))
+
+	t.Run("FailingBelowThreshold", func(t *testing.T) {
+		mockClock.Add(30 * time.Minute)
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	t.Run("FailingAboveThreshold", func(t *testing.T) {
+		mockClock.Add(time.Hour)
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+
+		last := fake.LastMessage()
+		assert.Equal(t, "weather: Weather Client Failing", last.Title)
+		assert.Equal(t, `Weather client has been failing for 1h30m0s (1 consecutive failures), so watering might not be scaled correctly.
+Last error: API is down`, last.Message)
+	})
+
+	t.Run("NoRepeatedNotification", func(t *testing.T) {
+		fake.Reset()
+		mockClock.Add(time.Hour)
+
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+		assert.Empty(t, fake.LastMessage())
+	})
+
+	t.Run("NotifyAgainAfterRecovery", func(t *testing.T) {
+		fake.Reset()
+
+		require.NoError(t, storageClient.WeatherClientHealth.RecordSuccess(ctx, wc.GetID(), clock.Now()))
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+
+		require.NoError(t, storageClient.WeatherClientHealth.RecordFailure(ctx, wc.GetID(), clock.Now(), errors.New("API is down again")))
+		mockClock.Add(2 * time.Hour)
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+
+		assert.Contains(t, fake.LastMessage().Message, "Last error: API is down again")
+	})
+
+	t.Run("WateringErrorsDisabled", func(t *testing.T) {
+		fake.Reset()
+
+		ws.NotificationSettings.WateringErrors = false
+		require.NoError(t, storageClient.WaterSchedules.Set(ctx, ws))
+
+		require.NoError(t, storageClient.WeatherClientHealth.RecordSuccess(ctx, wc.GetID(), clock.Now()))
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+		require.NoError(t, storageClient.WeatherClientHealth.RecordFailure(ctx, wc.GetID(), clock.Now(), errors.New("API is down")))
+		mockClock.Add(2 * time.Hour)
+
+		require.NoError(t, w.CheckWeatherClientHealth(ctx, wc))
+		assert.Empty(t, fake.LastMessage())
+	})
+}
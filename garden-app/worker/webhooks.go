@@ -0,0 +1,326 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
+	"github.com/calvinmclean/babyapi"
+)
+
+const (
+	defaultWebhookMaxAttempts     = 5
+	defaultWebhookRetryDelay      = 30 * time.Second
+	defaultWebhookTimeout         = 10 * time.Second
+	defaultWebhookDeliveryLogSize = 100
+)
+
+// WebhookConfig configures how WebhookEvents are delivered
+type WebhookConfig struct {
+	// MaxAttempts is the number of times a delivery is attempted before giving up. Defaults to 5
+	MaxAttempts int `mapstructure:"max_attempts" yaml:"max_attempts"`
+	// RetryDelay is the time to wait before the first retry. It doubles after each failed attempt. Defaults to 30s
+	RetryDelay time.Duration `mapstructure:"retry_delay" yaml:"retry_delay"`
+	// Timeout is the time to wait for a response to each request. Defaults to 10s
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	// DeliveryLogSize is the number of deliveries that are kept in the log for each Webhook. Defaults to 100
+	DeliveryLogSize int `mapstructure:"delivery_log_size" yaml:"delivery_log_size"`
+}
+
+func (c WebhookConfig) maxAttempts() int {
+	if c.MaxAttempts <= 0 {
+		return defaultWebhookMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+func (c WebhookConfig) retryDelay(attempt int) time.Duration {
+	delay := c.RetryDelay
+	if delay <= 0 {
+		delay = defaultWebhookRetryDelay
+	}
+	return delay << (attempt - 1)
+}
+
+func (c WebhookConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultWebhookTimeout
+	}
+	return c.Timeout
+}
+
+func (c WebhookConfig) deliveryLogSize() int {
+	if c.DeliveryLogSize <= 0 {
+		return defaultWebhookDeliveryLogSize
+	}
+	return c.DeliveryLogSize
+}
+
+// WithWebhookConfig configures how WebhookEvents are delivered
+func WithWebhookConfig(cfg WebhookConfig) WorkerOption {
+	return func(w *Worker) {
+		w.webhookConfig = cfg
+	}
+}
+
+// SendWebhookEvent sends an event to every Webhook that subscribes to its type. The Garden is optional. Deliveries
+// happen in the background and are retried if they fail, so errors are only logged
+func (w *Worker) SendWebhookEvent(ctx context.Context, eventType pkg.WebhookEventType, g *pkg.Garden, data map[string]any) {
+	if w.storageClient == nil {
+		return
+	}
+
+	logger := w.contextLogger(g, nil, nil).With("event_type", eventType)
+
+	event := newWebhookEvent(eventType, g, data)
+	for webhook, err := range w.storageClient.Webhooks.Search(ctx, "", nil) {
+		if err != nil {
+			logger.Error("error getting webhooks", "error", err)
+			continue
+		}
+		if !webhook.Subscribed(eventType) {
+			continue
+		}
+
+		delivery, err := w.addWebhookDelivery(ctx, webhook, event)
+		if err != nil {
+			logger.Error("unable to create webhook delivery", "webhook_id", webhook.GetID(), "error", err)
+			continue
+		}
+
+		w.webhookWg.Add(1)
+		go func() {
+			defer w.webhookWg.Done()
+			w.attemptWebhookDelivery(delivery, 1)
+		}()
+	}
+}
+
+// SendTestWebhookEvent immediately sends a test event to the Webhook without retries. The delivery is recorded in
+// the log and returned so the result can be displayed
+func (w *Worker) SendTestWebhookEvent(ctx context.Context, webhook *pkg.Webhook) (*pkg.WebhookDelivery, error) {
+	event := newWebhookEvent(pkg.WebhookEventTest, nil, map[string]any{
+		"message": "This is a test event from the garden-app",
+	})
+
+	delivery, err := w.addWebhookDelivery(ctx, webhook, event)
+	if err != nil {
+		return nil, err
+	}
+
+	statusCode, sendErr := w.postWebhook(ctx, webhook, delivery)
+	err = w.storageClient.WebhookDeliveries.RecordAttempt(ctx, delivery.ID, statusCode, sendErr)
+	if err != nil {
+		return nil, fmt.Errorf("error recording webhook delivery attempt: %w", err)
+	}
+
+	return w.storageClient.WebhookDeliveries.Get(ctx, delivery.ID)
+}
+
+func newWebhookEvent(eventType pkg.WebhookEventType, g *pkg.Garden, data map[string]any) pkg.WebhookEvent {
+	event := pkg.WebhookEvent{
+		ID:        CreateNewID().String(),
+		Type:      eventType,
+		Timestamp: clock.Now().UTC(),
+		Data:      data,
+	}
+	if g != nil {
+		event.GardenID = g.GetID()
+		event.GardenName = g.Name
+	}
+	return event
+}
+
+func (w *Worker) addWebhookDelivery(ctx context.Context, webhook *pkg.Webhook, event pkg.WebhookEvent) (*pkg.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal WebhookEvent to JSON: %w", err)
+	}
+
+	delivery := &pkg.WebhookDelivery{
+		WebhookID: webhook.GetID(),
+		EventID:   event.ID,
+		EventType: event.Type,
+		Payload:   string(payload),
+		CreatedAt: clock.Now(),
+	}
+
+	err = w.storageClient.WebhookDeliveries.Add(ctx, delivery, w.webhookConfig.deliveryLogSize())
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// attemptWebhookDelivery sends the delivery and schedules a retry if it fails. The Webhook is read from storage for
+// each attempt so retries stop if it is deleted or disabled
+func (w *Worker) attemptWebhookDelivery(delivery *pkg.WebhookDelivery, attempt int) {
+	ctx := context.Background()
+	logger := w.logger.With(
+		"webhook_id", delivery.WebhookID,
+		"event_id", delivery.EventID,
+		"event_type", delivery.EventType,
+		"attempt", attempt,
+	)
+
+	webhook, err := w.storageClient.Webhooks.Get(ctx, delivery.WebhookID)
+	if err != nil {
+		if !errors.Is(err, babyapi.ErrNotFound) {
+			logger.Error("error getting webhook", "error", err)
+		}
+		return
+	}
+	if webhook.IsDisabled() {
+		logger.Debug("not sending webhook event because the webhook is disabled")
+		return
+	}
+
+	statusCode, sendErr := w.postWebhook(ctx, webhook, delivery)
+
+	err = w.storageClient.WebhookDeliveries.RecordAttempt(ctx, delivery.ID, statusCode, sendErr)
+	if err != nil {
+		logger.Error("error recording webhook delivery attempt", "error", err)
+	}
+
+	if sendErr == nil {
+		logger.Debug("successfully delivered webhook event")
+		return
+	}
+
+	if attempt >= w.webhookConfig.maxAttempts() {
+		logger.Error("failed to deliver webhook event", "error", sendErr)
+		return
+	}
+
+	delay := w.webhookConfig.retryDelay(attempt)
+	logger.Warn("failed to deliver webhook event, retrying", "error", sendErr, "retry_delay", delay)
+
+	w.webhookMutex.Lock()
+	defer w.webhookMutex.Unlock()
+
+	if w.webhooksStopped {
+		return
+	}
+
+	w.webhookWg.Add(1)
+	w.webhookRetries[delivery.ID] = clock.AfterFunc(delay, func() {
+		defer w.webhookWg.Done()
+
+		w.webhookMutex.Lock()
+		delete(w.webhookRetries, delivery.ID)
+		w.webhookMutex.Unlock()
+
+		w.attemptWebhookDelivery(delivery, attempt+1)
+	})
+}
+
+// postWebhook sends the delivery's payload to the Webhook. It returns the response status code, if there is one
+func (w *Worker) postWebhook(ctx context.Context, webhook *pkg.Webhook, delivery *pkg.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, w.webhookConfig.timeout())
+	defer cancel()
+
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+
+	timestamp := clock.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "garden-app")
+	req.Header.Set(pkg.WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(pkg.WebhookDeliveryHeader, delivery.EventID)
+	req.Header.Set(pkg.WebhookTimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	if webhook.Secret != "" {
+		req.Header.Set(pkg.WebhookSignatureHeader, webhook.Sign(timestamp, body))
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// stopWebhookRetries cancels pending retries and waits for in-flight deliveries to finish
+func (w *Worker) stopWebhookRetries() {
+	w.webhookMutex.Lock()
+	w.webhooksStopped = true
+	for id, timer := range w.webhookRetries {
+		if timer.Stop() {
+			w.webhookWg.Done()
+		}
+		delete(w.webhookRetries, id)
+	}
+	w.webhookMutex.Unlock()
+
+	w.webhookWg.Wait()
+}
+
+func (w *Worker) sendWaterSentWebhookEvent(ctx context.Context, g *pkg.Garden, z *pkg.Zone, waterMessage action.WaterMessage, queued bool) {
+	w.SendWebhookEvent(ctx, pkg.WebhookEventWaterSent, g, map[string]any{
+		"zone_id":     z.GetID(),
+		"zone_name":   z.Name,
+		"position":    waterMessage.Position,
+		"duration_ms": waterMessage.Duration,
+		"event_id":    waterMessage.EventID,
+		"source":      waterMessage.Source,
+		"queued":      queued,
+	})
+}
+
+func (w *Worker) sendWaterStatusWebhookEvent(ctx context.Context, g *pkg.Garden, waterMessage action.WaterStatusEvent) {
+	var eventType pkg.WebhookEventType
+	switch waterMessage.Status {
+	case pkg.WaterStatusStarted:
+		eventType = pkg.WebhookEventWaterStarted
+	case pkg.WaterStatusCompleted:
+		eventType = pkg.WebhookEventWaterCompleted
+	case pkg.WaterStatusCancelled:
+		eventType = pkg.WebhookEventWaterCancelled
+	default:
+		return
+	}
+
+	w.SendWebhookEvent(ctx, eventType, g, map[string]any{
+		"zone_id":     waterMessage.ZoneID,
+		"position":    waterMessage.Position,
+		"duration_ms": waterMessage.Duration,
+		"event_id":    waterMessage.EventID,
+	})
+}
+
+// sendWaterSkippedWebhookEvents sends an event for each Garden that has Zones using the WaterSchedule
+func (w *Worker) sendWaterSkippedWebhookEvents(ctx context.Context, ws *pkg.WaterSchedule, zonesAndGardens []*pkg.ZoneAndGarden, reason string) {
+	gardens := map[string]*pkg.Garden{}
+	zoneIDs := map[string][]string{}
+	for _, zg := range zonesAndGardens {
+		gardens[zg.Garden.GetID()] = zg.Garden
+		zoneIDs[zg.Garden.GetID()] = append(zoneIDs[zg.Garden.GetID()], zg.Zone.GetID())
+	}
+
+	for id, g := range gardens {
+		w.SendWebhookEvent(ctx, pkg.WebhookEventWaterSkipped, g, map[string]any{
+			"water_schedule_id":   ws.GetID(),
+			"water_schedule_name": ws.Name,
+			"zone_ids":            zoneIDs[id],
+			"reason":              reason,
+		})
+	}
+}
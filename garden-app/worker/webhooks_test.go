@@ -0,0 +1,231 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
+	"github.com/calvinmclean/babyapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func newWebhookReceiver(statuses ...int) *webhookReceiver {
+	r := &webhookReceiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status = r.statuses[0]
+			r.statuses = r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return r
+}
+
+func (r *webhookReceiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+func TestWebhooks(t *testing.T) {
+	c := clock.MockTime()
+	defer clock.Reset()
+
+	setup := func(t *testing.T, receiver *webhookReceiver, eventTypes ...pkg.WebhookEventType) (*Worker, *storage.Client, *pkg.Webhook) {
+		t.Helper()
+
+		storageClient, err := storage.NewClient(storage.Config{
+			ConnectionString: ":memory:",
+		})
+		require.NoError(t, err)
+
+		webhook := &pkg.Webhook{
+			ID:         babyapi.NewID(),
+			Name:       "receiver",
+			URL:        receiver.URL,
+			Secret:     "secret",
+			EventTypes: eventTypes,
+		}
+		require.NoError(t, storageClient.Webhooks.Set(context.Background(), webhook))
+
+		w := NewWorker(storageClient, nil, nil, slog.Default(),
+			WithHTTPClient(receiver.Client()),
+			WithWebhookConfig(WebhookConfig{
+				MaxAttempts: 3,
+				RetryDelay:  time.Minute,
+			}),
+		)
+		t.Cleanup(w.stopWebhookRetries)
+
+		return w, storageClient, webhook
+	}
+
+	t.Run("SignedDelivery", func(t *testing.T) {
+		receiver := newWebhookReceiver()
+		defer receiver.Close()
+
+		w, storageClient, webhook := setup(t, receiver, pkg.WebhookEventWaterCompleted)
+		garden := createExampleGarden()
+
+		w.SendWebhookEvent(context.Background(), pkg.WebhookEventWaterCompleted, garden, map[string]any{"zone_id": "zone"})
+		w.webhookWg.Wait()
+
+		require.Equal(t, 1, receiver.count())
+		req, body := receiver.requests[0], receiver.bodies[0]
+
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.Equal(t, "water.completed", req.Header.Get(pkg.WebhookEventHeader))
+		assert.Equal(t, strconv.FormatInt(c.Now().Unix(), 10), req.Header.Get(pkg.WebhookTimestampHeader))
+		assert.Equal(t, pkg.SignWebhookPayload("secret", c.Now(), body), req.Header.Get(pkg.WebhookSignatureHeader))
+
+		var event pkg.WebhookEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, req.Header.Get(pkg.WebhookDeliveryHeader), event.ID)
+		assert.Equal(t, pkg.WebhookEventWaterCompleted, event.Type)
+		assert.Equal(t, garden.GetID(), event.GardenID)
+		assert.Equal(t, "test-garden", event.GardenName)
+		assert.Equal(t, map[string]any{"zone_id": "zone"}, event.Data)
+
+		deliveries, err := storageClient.WebhookDeliveries.List(context.Background(), webhook.GetID(), 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, 1, deliveries[0].Attempts)
+		assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+		assert.True(t, deliveries[0].Delivered())
+	})
+
+	t.Run("NotSubscribed", func(t *testing.T) {
+		receiver := newWebhookReceiver()
+		defer receiver.Close()
+
+		w, _, _ := setup(t, receiver, pkg.WebhookEventWaterCompleted)
+
+		w.SendWebhookEvent(context.Background(), pkg.WebhookEventLightChanged, createExampleGarden(), nil)
+		w.webhookWg.Wait()
+
+		assert.Equal(t, 0, receiver.count())
+	})
+
+	t.Run("RetryUntilSuccess", func(t *testing.T) {
+		receiver := newWebhookReceiver(http.StatusInternalServerError, http.StatusBadGateway)
+		defer receiver.Close()
+
+		w, storageClient, webhook := setup(t, receiver, pkg.WebhookEventControllerDown)
+
+		w.SendWebhookEvent(context.Background(), pkg.WebhookEventControllerDown, createExampleGarden(), nil)
+
+		require.Eventually(t, func() bool { return receiver.count() == 1 }, time.Second, 10*time.Millisecond)
+
+		// the second retry waits twice as long as the first
+		for i, delay := range []time.Duration{time.Minute, 2 * time.Minute} {
+			require.Eventually(t, func() bool {
+				w.webhookMutex.Lock()
+				defer w.webhookMutex.Unlock()
+				return len(w.webhookRetries) == 1
+			}, time.Second, 10*time.Millisecond)
+
+			c.Add(delay - time.Second)
+			assert.Equal(t, i+1, receiver.count())
+
+			c.Add(time.Second)
+			require.Eventually(t, func() bool { return receiver.count() == i+2 }, time.Second, 10*time.Millisecond)
+		}
+		w.webhookWg.Wait()
+
+		deliveries, err := storageClient.WebhookDeliveries.List(context.Background(), webhook.GetID(), 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, 3, deliveries[0].Attempts)
+		assert.True(t, deliveries[0].Delivered())
+		assert.Empty(t, deliveries[0].LastError)
+	})
+
+	t.Run("StopAfterMaxAttempts", func(t *testing.T) {
+		receiver := newWebhookReceiver(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+		defer receiver.Close()
+
+		w, storageClient, webhook := setup(t, receiver, pkg.WebhookEventControllerDown)
+		w.webhookConfig.RetryDelay = time.Second
+
+		w.SendWebhookEvent(context.Background(), pkg.WebhookEventControllerDown, createExampleGarden(), nil)
+
+		require.Eventually(t, func() bool {
+			c.Add(time.Hour)
+			return receiver.count() == 3
+		}, time.Second, 10*time.Millisecond)
+		w.webhookWg.Wait()
+
+		assert.Equal(t, 3, receiver.count())
+
+		deliveries, err := storageClient.WebhookDeliveries.List(context.Background(), webhook.GetID(), 10)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, 3, deliveries[0].Attempts)
+		assert.False(t, deliveries[0].Delivered())
+		assert.Equal(t, http.StatusInternalServerError, deliveries[0].StatusCode)
+		assert.Equal(t, "unexpected status code: 500", deliveries[0].LastError)
+	})
+
+	t.Run("StopCancelsRetries", func(t *testing.T) {
+		receiver := newWebhookReceiver(http.StatusInternalServerError)
+		defer receiver.Close()
+
+		w, _, _ := setup(t, receiver, pkg.WebhookEventControllerDown)
+
+		w.SendWebhookEvent(context.Background(), pkg.WebhookEventControllerDown, createExampleGarden(), nil)
+		require.Eventually(t, func() bool {
+			w.webhookMutex.Lock()
+			defer w.webhookMutex.Unlock()
+			return len(w.webhookRetries) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		w.stopWebhookRetries()
+
+		c.Add(time.Hour)
+		assert.Equal(t, 1, receiver.count())
+	})
+
+	t.Run("DisabledWebhook", func(t *testing.T) {
+		receiver := newWebhookReceiver()
+		defer receiver.Close()
+
+		w, storageClient, webhook := setup(t, receiver, pkg.WebhookEventControllerDown)
+		disabled := true
+		webhook.Disabled = &disabled
+		require.NoError(t, storageClient.Webhooks.Set(context.Background(), webhook))
+
+		w.SendWebhookEvent(context.Background(), pkg.WebhookEventControllerDown, createExampleGarden(), nil)
+		w.webhookWg.Wait()
+
+		assert.Equal(t, 0, receiver.count())
+	})
+}
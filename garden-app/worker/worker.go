@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/calvinmclean/automated-garden/garden-app/clock"
+	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/influxdb"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/storage"
@@ -45,6 +46,7 @@ func init() {
 		prometheus.MustRegister(
 			scheduleJobsGauge,
 			schedulerErrors,
+			commandQueueExpired,
 		)
 	})()
 }
@@ -89,6 +91,83 @@ type Worker struct {
 	// firmwareUpdateInProgress tracks gardens that are currently executing a firmware update.
 	firmwareUpdateInProgress map[string]struct{}
 	firmwareUpdateMutex      sync.Mutex
+
+	// weatherAuthConfig configures the background job that refreshes weather client tokens
+	weatherAuthConfig WeatherAuthConfig
+
+	// weatherHealthConfig configures the background job that notifies about failing weather clients
+	weatherHealthConfig WeatherHealthConfig
+
+	// weatherHealthNotified stores the start of the failing period that was last notified for each weather client
+	weatherHealthNotified map[string]time.Time
+	weatherHealthMutex    sync.Mutex
+
+	// mqttHealthConfig configures the background job that notifies about MQTT disconnects
+	mqttHealthConfig MQTTHealthConfig
+	// mqttDisconnectNotified stores the start of the disconnect that was last notified
+	mqttDisconnectNotified *time.Time
+	mqttHealthMutex        sync.Mutex
+
+	// commandQueueConfig configures the queue for commands that can't be published while the broker is unavailable
+	commandQueueConfig CommandQueueConfig
+	// commandQueueMutex makes sure commands are published or queued in order
+	commandQueueMutex sync.Mutex
+
+	// wateringWatchdogConfig enables watching for controllers to start and complete watering when it is set
+	wateringWatchdogConfig *WateringWatchdogConfig
+	// wateringWatches are the waterings that have not completed yet, by event ID
+	wateringWatches map[string]*wateringWatch
+	// wateringStatus is the most recent watering for each Zone
+	wateringStatus        map[string]*pkg.WateringStatus
+	wateringWatchSeq      uint64
+	wateringWatchdogMutex sync.Mutex
+
+	// driverWaterings are the waterings for each Garden that uses a driver without its own queue, by Garden ID
+	driverWaterings      map[string]*driverWateringQueue
+	driverWateringsMutex sync.Mutex
+
+	// deviceStateConfig configures how light and fan state mismatches are handled
+	deviceStateConfig DeviceStateConfig
+	// deviceStates are the light and fan states reported by each Garden's controller, by Garden ID
+	deviceStates     map[string]*deviceState
+	deviceStateMutex sync.Mutex
+
+	// homeAssistantConfig configures publishing Home Assistant MQTT discovery configs
+	homeAssistantConfig HomeAssistantConfig
+	// homeAssistantZoneDurations are the watering durations set in Home Assistant for each Zone
+	homeAssistantZoneDurations map[string]time.Duration
+	// homeAssistantWateringZones is the Zone that each Garden reported it is watering, by Garden ID
+	homeAssistantWateringZones map[string]string
+	homeAssistantMutex         sync.Mutex
+
+	// configSyncConfig configures automatically sending the ControllerConfig when it is out of sync
+	configSyncConfig ConfigSyncConfig
+	// configSyncs is the last time the ControllerConfig was automatically sent, by Garden ID
+	configSyncs     map[string]time.Time
+	configSyncMutex sync.Mutex
+
+	// desiredStateConfig configures publishing retained desired-state messages for the light and fan
+	desiredStateConfig DesiredStateConfig
+
+	// controllerScheduleConfig configures sending fallback schedules to controllers
+	controllerScheduleConfig ControllerScheduleConfig
+	// controllerScheduleVersions is the version of the last ControllerSchedule sent to each Garden, by Garden ID
+	controllerScheduleVersions map[string]string
+	controllerScheduleMutex    sync.Mutex
+
+	// webhookConfig configures how WebhookEvents are delivered
+	webhookConfig WebhookConfig
+	// webhookRetries are the Timers for deliveries that will be retried, by WebhookDelivery ID
+	webhookRetries  map[int64]clock.Timer
+	webhooksStopped bool
+	// webhookWg tracks in-flight deliveries and retries so Stop() can wait for them
+	webhookWg    sync.WaitGroup
+	webhookMutex sync.Mutex
+
+	// liveEventSubscriptions are the clients streaming LiveEvents
+	liveEventSubscriptions map[*liveEventSubscription]struct{}
+	liveEventsStopped      bool
+	liveEventMutex         sync.Mutex
 }
 
 // WorkerOption configures a Worker during creation
@@ -133,14 +212,25 @@ func NewWorker(
 	scheduler := gocron.NewScheduler(time.UTC)
 	scheduler.CustomTime(clock.DefaultClock)
 	w := &Worker{
-		storageClient:            storageClient,
-		influxdbClient:           influxdbClient,
-		mqttClient:               mqttClient,
-		scheduler:                scheduler,
-		logger:                   logger.With("source", "worker"),
-		downTimers:               map[string]clock.Timer{},
-		firmwareUpdateInProgress: make(map[string]struct{}),
-		httpClient:               http.DefaultClient,
+		storageClient:              storageClient,
+		influxdbClient:             influxdbClient,
+		mqttClient:                 mqttClient,
+		scheduler:                  scheduler,
+		logger:                     logger.With("source", "worker"),
+		downTimers:                 map[string]clock.Timer{},
+		firmwareUpdateInProgress:   make(map[string]struct{}),
+		weatherHealthNotified:      map[string]time.Time{},
+		wateringWatches:            map[string]*wateringWatch{},
+		wateringStatus:             map[string]*pkg.WateringStatus{},
+		driverWaterings:            map[string]*driverWateringQueue{},
+		deviceStates:               map[string]*deviceState{},
+		homeAssistantZoneDurations: map[string]time.Duration{},
+		homeAssistantWateringZones: map[string]string{},
+		configSyncs:                map[string]time.Time{},
+		controllerScheduleVersions: map[string]string{},
+		webhookRetries:             map[int64]clock.Timer{},
+		liveEventSubscriptions:     map[*liveEventSubscription]struct{}{},
+		httpClient:                 http.DefaultClient,
 		controllerSetupURLFunc: func(topicPrefix string) string {
 			return fmt.Sprintf("http://%s.local/paramsave", topicPrefix)
 		},
@@ -170,6 +260,24 @@ func (w *Worker) StartAsync() {
 	w.setupMQTT()
 	w.syncLightStateAllGardens()
 	w.syncFanStateAllGardens()
+	w.publishHomeAssistantDiscoveryAllGardens()
+	w.restoreDriverWaterings()
+
+	if err := w.scheduleWeatherAuthCheck(); err != nil {
+		w.logger.Error("error scheduling weather client auth check", "error", err)
+	}
+	if err := w.scheduleWeatherHealthCheck(); err != nil {
+		w.logger.Error("error scheduling weather client health check", "error", err)
+	}
+	if err := w.scheduleMQTTHealthCheck(); err != nil {
+		w.logger.Error("error scheduling MQTT health check", "error", err)
+	}
+	if err := w.scheduleCommandQueueFlush(); err != nil {
+		w.logger.Error("error scheduling command queue flush", "error", err)
+	}
+	if err := w.scheduleControllerScheduleRefresh(); err != nil {
+		w.logger.Error("error scheduling controller schedule refresh", "error", err)
+	}
 }
 
 func (w *Worker) setupMQTT() {
@@ -192,6 +300,19 @@ func (w *Worker) setupMQTT() {
 		Topic:   "+/data/info",
 		Handler: w.handleControllerInfoMessage,
 	})
+	w.mqttClient.AddHandler(mqtt.TopicHandler{
+		Topic:   "+/data/light",
+		Handler: w.handleLightStateMessage,
+	})
+	w.mqttClient.AddHandler(mqtt.TopicHandler{
+		Topic:   "+/data/fan",
+		Handler: w.handleFanStateMessage,
+	})
+	w.mqttClient.AddHandler(mqtt.TopicHandler{
+		Topic:   "+/data/sensor",
+		Handler: w.handleSensorDataMessage,
+	})
+	w.setupHomeAssistant()
 
 	if err := w.mqttClient.Connect(); err != nil {
 		w.logger.Error("failed to connect to MQTT broker", "error", err)
@@ -263,6 +384,11 @@ func (w *Worker) Stop() {
 	}
 	w.downTimerWg.Wait()
 
+	w.stopWateringWatches()
+	w.stopDriverWaterings()
+	w.stopWebhookRetries()
+	w.stopLiveEvents()
+
 	prometheus.Unregister(scheduleJobsGauge)
 	prometheus.Unregister(schedulerErrors)
 }
@@ -2,12 +2,10 @@ package worker
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/calvinmclean/automated-garden/garden-app/pkg"
 	"github.com/calvinmclean/automated-garden/garden-app/pkg/action"
-	"github.com/calvinmclean/automated-garden/garden-app/pkg/mqtt"
 	"github.com/rs/xid"
 )
 
@@ -25,30 +23,61 @@ func (w *Worker) ExecuteZoneAction(ctx context.Context, g *pkg.Garden, z *pkg.Zo
 	return nil
 }
 
-// ExecuteWaterAction sends the message over MQTT to the embedded garden controller. This is used for a directly-requested
+// ExecuteWaterAction uses the Garden's driver to water the Zone. This is used for a directly-requested
 // WaterAction and does not perform any of the watering checks that are usuall done for a scheduled watering
 func (w *Worker) ExecuteWaterAction(ctx context.Context, g *pkg.Garden, z *pkg.Zone, input *action.WaterAction) error {
+	return w.executeWaterAction(ctx, g, z, input, "")
+}
+
+// executeWaterAction sends the WaterMessage to the Garden's driver and starts watching for watering to start and
+// complete. The notificationClientID is notified about watering errors in addition to the Garden's notification
+// client
+func (w *Worker) executeWaterAction(ctx context.Context, g *pkg.Garden, z *pkg.Zone, input *action.WaterAction, notificationClientID string) error {
 	if input.Duration.Duration == 0 {
 		w.logger.Info("weather control determined that watering should be skipped")
 		return nil
 	}
 
 	eventID := CreateNewID().String()
-	msg, err := json.Marshal(action.WaterMessage{
+	waterMessage := action.WaterMessage{
 		Duration: input.Duration.Duration.Milliseconds(),
 		ZoneID:   z.GetID(),
 		Position: *z.Position,
 		EventID:  eventID,
 		Source:   input.Source,
-	})
+	}
+
+	d, err := w.gardenDriver(g)
 	if err != nil {
-		return fmt.Errorf("unable to marshal WaterMessage to JSON: %w", err)
+		return err
+	}
+
+	// other drivers don't have a queue, so the worker runs their waterings one at a time
+	md, ok := d.(mqttDriver)
+	if !ok {
+		err = w.waterWithDriver(ctx, &driverWatering{
+			garden:               g,
+			zone:                 z,
+			driver:               d,
+			message:              waterMessage,
+			notificationClientID: notificationClientID,
+		})
+		if err != nil {
+			return err
+		}
+		w.sendWaterSentWebhookEvent(ctx, g, z, waterMessage, false)
+		return nil
 	}
 
-	topic, err := mqtt.WaterTopic(g.TopicPrefix)
+	queued, err := md.water(ctx, g, waterMessage)
 	if err != nil {
-		return fmt.Errorf("unable to fill MQTT topic template: %w", err)
+		return err
+	}
+	w.sendWaterSentWebhookEvent(ctx, g, z, waterMessage, queued)
+	if queued {
+		return nil
 	}
 
-	return w.mqttClient.Publish(ctx, topic, msg)
+	w.watchWatering(g, z, waterMessage, notificationClientID)
+	return nil
 }
@@ -15,8 +15,21 @@
  *   IP address or hostname for MQTT broker
  * MQTT_PORT
  *   Port for MQTT broker
+ * MQTT_USERNAME, MQTT_PASSWORD
+ *   Credentials for brokers that require authentication
+ * MQTT_TLS
+ *   Connect to the broker using TLS. MQTT_CA_CERT is the PEM CA certificate used to verify the broker and
+ *   MQTT_CLIENT_CERT/MQTT_CLIENT_KEY are the PEM client certificate and key for mutual TLS. Define
+ *   MQTT_TLS_INSECURE to skip verifying the broker
  */
 // #define MQTT_ADDRESS "192.168.0.32"
 // #define MQTT_PORT 1883
+// #define MQTT_USERNAME "garden"
+// #define MQTT_PASSWORD "password"
+// #define MQTT_TLS true
+// #define MQTT_CA_CERT "-----BEGIN CERTIFICATE-----\n...\n-----END CERTIFICATE-----\n"
+
+// NTP server used to set the time for retained desired-state messages. Defaults to "pool.ntp.org"
+// #define NTP_SERVER "pool.ntp.org"
 
 #endif
@@ -24,10 +24,15 @@ struct Config {
 
     int numSensors;
     SensorConfig sensors[16];
+
+    // configHash is computed from the applied config and reported so the garden-app can detect drift. It is
+    // empty if no config has been applied
+    char configHash[17];
 };
 
 void serializeConfig(const Config& config, String& jsonString);
 bool deserializeConfig(const char* jsonString, Config& config);
+void computeConfigHash(Config& config);
 void initFS();
 bool configFileExists();
 void saveConfigToFile(const Config& config);
@@ -21,6 +21,9 @@
 #define MQTT_FAN_TOPIC "/command/fan"
 #define MQTT_UPDATE_CONFIG_TOPIC "/command/update_config"
 
+#define MQTT_DESIRED_LIGHT_TOPIC "/desired/light"
+#define MQTT_DESIRED_FAN_TOPIC "/desired/fan"
+
 #define MQTT_LIGHT_DATA_TOPIC       "/data/light"
 #define MQTT_FAN_DATA_TOPIC         "/data/fan"
 #define MQTT_WATER_DATA_TOPIC       "/data/water"
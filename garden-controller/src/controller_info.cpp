@@ -2,6 +2,7 @@
 #include <WiFi.h>
 #include "version.h"
 #include "mqtt.h"
+#include "main.h"
 
 void publishControllerInfo() {
     uint8_t mac[6];
@@ -14,9 +15,9 @@ void publishControllerInfo() {
     char ipStr[16];
     snprintf(ipStr, sizeof(ipStr), "%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3]);
 
-    char message[150];
-    snprintf(message, sizeof(message), "info mac=\"%s\",ip=\"%s\",version=\"%s\"",
-             macStr, ipStr, FIRMWARE_VERSION);
+    char message[200];
+    snprintf(message, sizeof(message), "info mac=\"%s\",ip=\"%s\",version=\"%s\",config_hash=\"%s\"",
+             macStr, ipStr, FIRMWARE_VERSION, config.configHash);
 
     publishInfoMessage(message);
 }
@@ -62,9 +62,46 @@ bool deserializeConfig(const char* jsonString, Config& config) {
         config.sensors[i].interval = doc["sensors"][i]["interval"].as<int>();
     }
 
+    computeConfigHash(config);
+
     return true;
 }
 
+static void fnv1aWrite(uint64_t& hash, const char* data) {
+    for (const char* c = data; *c != '\0'; c++) {
+        hash ^= (uint8_t)*c;
+        hash *= 0x100000001b3ULL;
+    }
+}
+
+// Compute the FNV-1a hash of the applied config. This must build the same string as
+// ControllerConfigMessage.Hash in the garden-app
+void computeConfigHash(Config& config) {
+    uint64_t hash = 0xcbf29ce484222325ULL;
+    char buf[96];
+
+    snprintf(buf, sizeof(buf), "zones=%d;", config.numZones);
+    fnv1aWrite(hash, buf);
+    for (int i = 0; i < config.numZones; i++) {
+        snprintf(buf, sizeof(buf), "zone=%d,%d;", (int)config.valvePins[i], (int)config.pumpPins[i]);
+        fnv1aWrite(hash, buf);
+    }
+
+    // Pins for disabled outputs are ignored
+    snprintf(buf, sizeof(buf), "light=%s,%d;fan=%s,%d;",
+             config.light ? "true" : "false", config.light ? (int)config.lightPin : 0,
+             config.fan ? "true" : "false", config.fan ? (int)config.fanPin : 0);
+    fnv1aWrite(hash, buf);
+
+    for (int i = 0; i < config.numSensors; i++) {
+        snprintf(buf, sizeof(buf), "sensor=%s,%s,%d,%d;",
+                 config.sensors[i].id, config.sensors[i].type, (int)config.sensors[i].pin, config.sensors[i].interval);
+        fnv1aWrite(hash, buf);
+    }
+
+    snprintf(config.configHash, sizeof(config.configHash), "%016llx", (unsigned long long)hash);
+}
+
 void initFS() {
     printf("setting up filesystem\n");
     if (!LittleFS.begin(true)) {
@@ -143,4 +180,6 @@ void printConfig(Config& config) {
         printf("    Sensor %d: type=%s pin=%d interval=%d\n",
                i, config.sensors[i].type, (int)config.sensors[i].pin, config.sensors[i].interval);
     }
+
+    printf("  Config Hash: %s\n", config.configHash);
 }
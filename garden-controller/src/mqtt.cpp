@@ -5,7 +5,20 @@
 
 #include <esp_system.h>
 
+#ifdef MQTT_TLS
+#include <WiFiClientSecure.h>
+WiFiClientSecure wifiClient;
+#else
 WiFiClient wifiClient;
+#endif
+
+#ifdef MQTT_USERNAME
+const char* mqttUsername = MQTT_USERNAME;
+const char* mqttPassword = MQTT_PASSWORD;
+#else
+const char* mqttUsername = NULL;
+const char* mqttPassword = NULL;
+#endif
 PubSubClient client(wifiClient);
 
 SemaphoreHandle_t mqttMutex;
@@ -34,6 +47,10 @@ char lightCommandTopic[80];
 char fanCommandTopic[80];
 char updateConfigCommandTopic[80];
 
+// retained desired-state topics (subscribe)
+char desiredLightTopic[80];
+char desiredFanTopic[80];
+
 // data topics (publish)
 char waterDataTopic[80];
 char lightDataTopic[80];
@@ -75,6 +92,18 @@ void setupMQTTMutexAndQueue() {
 void setupMQTT() {
     // Connect to MQTT
     printf("connecting to mqtt server: %s:%d\n", mqtt_server, mqtt_port);
+#ifdef MQTT_TLS
+#ifdef MQTT_CA_CERT
+    wifiClient.setCACert(MQTT_CA_CERT);
+#endif
+#if defined MQTT_CLIENT_CERT && defined MQTT_CLIENT_KEY
+    wifiClient.setCertificate(MQTT_CLIENT_CERT);
+    wifiClient.setPrivateKey(MQTT_CLIENT_KEY);
+#endif
+#ifdef MQTT_TLS_INSECURE
+    wifiClient.setInsecure();
+#endif
+#endif
     client.setServer(mqtt_server, mqtt_port);
     client.setCallback(processIncomingMessage);
     client.setKeepAlive(MQTT_KEEPALIVE);
@@ -85,6 +114,8 @@ void setupMQTT() {
     snprintf(lightCommandTopic, sizeof(lightCommandTopic), "%s" MQTT_LIGHT_TOPIC, mqtt_topic_prefix);
     snprintf(fanCommandTopic, sizeof(fanCommandTopic), "%s" MQTT_FAN_TOPIC, mqtt_topic_prefix);
     snprintf(updateConfigCommandTopic, sizeof(updateConfigCommandTopic), "%s" MQTT_UPDATE_CONFIG_TOPIC, mqtt_topic_prefix);
+    snprintf(desiredLightTopic, sizeof(desiredLightTopic), "%s" MQTT_DESIRED_LIGHT_TOPIC, mqtt_topic_prefix);
+    snprintf(desiredFanTopic, sizeof(desiredFanTopic), "%s" MQTT_DESIRED_FAN_TOPIC, mqtt_topic_prefix);
 
     snprintf(waterDataTopic, sizeof(waterDataTopic), "%s" MQTT_WATER_DATA_TOPIC, mqtt_topic_prefix);
     snprintf(lightDataTopic, sizeof(lightDataTopic), "%s" MQTT_LIGHT_DATA_TOPIC, mqtt_topic_prefix);
@@ -303,7 +334,7 @@ void mqttConnectTask(void* parameters) {
         if (!client.connected()) {
             printf("attempting MQTT connection...");
             // Connect with defaul arguments + cleanSession = false for persistent sessions
-            if (client.connect(mqtt_topic_prefix, NULL, NULL, 0, 0, 0, 0, false)) {
+            if (client.connect(mqtt_topic_prefix, mqttUsername, mqttPassword, 0, 0, 0, 0, false)) {
                 printf(firstConnect ? "connected\n" : "reconnected\n");
                 client.subscribe(waterCommandTopic, 1);
                 client.subscribe(stopCommandTopic, 1);
@@ -312,15 +343,18 @@ void mqttConnectTask(void* parameters) {
 
                 if (config.light) {
                     client.subscribe(lightCommandTopic, 1);
+                    client.subscribe(desiredLightTopic, 1);
                 }
 
                 if (config.fan) {
                     client.subscribe(fanCommandTopic, 1);
+                    client.subscribe(desiredFanTopic, 1);
                 }
 
                 if (firstConnect) {
                     publishLog("info", "startup", "garden-controller setup complete",
-                               {{"reset_reason", resetReasonString(esp_reset_reason())}});
+                               {{"reset_reason", resetReasonString(esp_reset_reason())},
+                                {"config_hash", config.configHash}});
                     firstConnect = false;
                 }
                 publishControllerInfo();
@@ -396,6 +430,71 @@ void handleFanCommand(char* message) {
     changeFan(fe);
 }
 
+/*
+  handleDesiredLightState sets the light from the retained desired-state message. Unlike the light command,
+  a missing state is ignored instead of toggling the light since the message is received on every reconnect
+*/
+void handleDesiredLightState(char* message) {
+    DynamicJsonDocument doc(256);
+    DeserializationError err = deserializeJson(doc, message);
+    if (err) {
+        printf("deserialize failed: %s\n", err.c_str());
+        return;
+    }
+
+    LightEvent le = {
+        doc["state"] | ""
+    };
+    if (strlen(le.state) == 0) {
+        printf("ignoring desired light state without a state\n");
+        return;
+    }
+    printf("received desired light state: '%s'\n", le.state);
+    changeLight(le);
+}
+
+/*
+  handleDesiredFanState runs the fan until the Unix timestamp from the retained desired-state message. The
+  message is received again on every reconnect, so it is ignored if it was already applied. The time comes
+  from NTP, so a running fan is ignored if the time can't be synced
+*/
+void handleDesiredFanState(char* message) {
+    static long long lastUntil = -1;
+
+    DynamicJsonDocument doc(256);
+    DeserializationError err = deserializeJson(doc, message);
+    if (err) {
+        printf("deserialize failed: %s\n", err.c_str());
+        return;
+    }
+
+    unsigned int power = doc["power"] | 0;
+    long long until = doc["until"] | 0LL;
+    if (until == lastUntil) {
+        printf("desired fan state was already applied\n");
+        return;
+    }
+
+    FanEvent fe = {ZERO, 0};
+    if (power > 0) {
+        struct tm timeinfo;
+        if (!getLocalTime(&timeinfo, 5000)) {
+            printf("ignoring desired fan state: time is not synced\n");
+            return;
+        }
+
+        long long remaining = until - (long long)time(nullptr);
+        if (remaining > 0) {
+            fe.duration = (unsigned long)remaining * 1000UL;
+            fe.power = power;
+        }
+    }
+
+    lastUntil = until;
+    printf("received desired fan state: power=%d for %lu ms\n", fe.power, fe.duration);
+    changeFan(fe);
+}
+
 void handleConfigCommand(char* message) {
     printf("handling update_config command\n");
     publishLog("alert", "config", "received config update request");
@@ -492,6 +591,8 @@ void publishLog(const char* level, const char* source, const char* message,
     - lightCommandTopic: accepts LightEvent JSON to control a grow light
     - fanCommandTopic: accepts FanEvent JSON to control a fan
     - updateConfigCommandTopic: accepts Config JSON to update
+    - desiredLightTopic: retained desired light state JSON
+    - desiredFanTopic: retained desired fan power and Unix timestamp to run until
 */
 void processIncomingMessage(char* topic, byte* message, unsigned int length) {
     if (length == 0) {
@@ -543,6 +644,10 @@ void mqttCommandTask(void* parameters) {
                 handleFanCommand(cmd->message);
             } else if (strcmp(cmd->topic, updateConfigCommandTopic) == 0) {
                 handleConfigCommand(cmd->message);
+            } else if (strcmp(cmd->topic, desiredLightTopic) == 0) {
+                handleDesiredLightState(cmd->message);
+            } else if (strcmp(cmd->topic, desiredFanTopic) == 0) {
+                handleDesiredFanState(cmd->message);
             } else {
                 printf("unexpected topic: %s\n", cmd->topic);
             }
@@ -83,6 +83,10 @@ void wifiManagerLoopTask(void* parameters) {
     vTaskDelete(NULL);
 }
 
+#ifndef NTP_SERVER
+#define NTP_SERVER "pool.ntp.org"
+#endif
+
 static unsigned long lastDisconnectTime = 0;
 const unsigned long WIFI_RECONNECT_TIMEOUT_MS = 30000;
 
@@ -90,6 +94,9 @@ void wifiConnectHandler(WiFiEvent_t event, WiFiEventInfo_t info) {
     lastDisconnectTime = 0;
     printf("WiFi connected, ip=%s\n", WiFi.localIP().toString().c_str());
 
+    // Time is only used for retained desired-state messages, so UTC is fine
+    configTime(0, 0, NTP_SERVER);
+
     MDNS.end();
     if (!MDNS.begin(mqtt_topic_prefix)) {
         printf("error restarting mDNS after reconnect\n");
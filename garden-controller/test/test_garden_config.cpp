@@ -18,8 +18,8 @@ void test_loadAndSaveConfig() {
         GPIO_NUM_22, // fanPin
         2, // numSensors
         {
-            { "", "DHT22", GPIO_NUM_21, 5000 },
-            { "", "DS18B20", GPIO_NUM_22, 5000 }
+            { "sensor1", "DHT22", GPIO_NUM_21, 5000 },
+            { "sensor2", "DS18B20", GPIO_NUM_22, 5000 }
         }
     };
 
@@ -45,10 +45,14 @@ void test_loadAndSaveConfig() {
 
     TEST_ASSERT_EQUAL(inputConfig.numSensors, outputConfig.numSensors);
     for (int i = 0; i < inputConfig.numSensors; i++) {
+        TEST_ASSERT_EQUAL_STRING(inputConfig.sensors[i].id, outputConfig.sensors[i].id);
         TEST_ASSERT_EQUAL_STRING(inputConfig.sensors[i].type, outputConfig.sensors[i].type);
         TEST_ASSERT_EQUAL(inputConfig.sensors[i].pin, outputConfig.sensors[i].pin);
         TEST_ASSERT_EQUAL(inputConfig.sensors[i].interval, outputConfig.sensors[i].interval);
     }
+
+    // The hash is computed from the loaded config
+    TEST_ASSERT_EQUAL_STRING("66c7f6d93b4f75d9", outputConfig.configHash);
 }
 
 void test_serializeConfig(void) {
@@ -74,7 +78,7 @@ void test_serializeConfig(void) {
 }
 
 void test_deserializeConfig(void) {
-    const char* inputJSON = "{\"num_zones\":4,\"valve_pins\":[4,5,6,7],\"pump_pins\":[12,13,14,15],\"light\":true,\"light_pin\":2,\"fan\":true,\"fan_pin\":22,\"num_sensors\":2,\"sensors\":[{\"type\":\"DHT22\",\"pin\":21,\"interval\":5000},{\"type\":\"DS18B20\",\"pin\":22,\"interval\":5000}]}";
+    const char* inputJSON = "{\"num_zones\":4,\"valve_pins\":[4,5,6,7],\"pump_pins\":[12,13,14,15],\"light\":true,\"light_pin\":2,\"fan\":true,\"fan_pin\":22,\"num_sensors\":2,\"sensors\":[{\"id\":\"sensor1\",\"type\":\"DHT22\",\"pin\":21,\"interval\":5000},{\"id\":\"sensor2\",\"type\":\"DS18B20\",\"pin\":22,\"interval\":5000}],\"config_hash\":\"0123456789abcdef\"}";
     Config outputConfig;
 
     bool result = deserializeConfig(inputJSON, outputConfig);
@@ -91,8 +95,8 @@ void test_deserializeConfig(void) {
         GPIO_NUM_22, // fanPin
         2, // numSensors
         {
-            { "", "DHT22", GPIO_NUM_21, 5000 },
-            { "", "DS18B20", GPIO_NUM_22, 5000 }
+            { "sensor1", "DHT22", GPIO_NUM_21, 5000 },
+            { "sensor2", "DS18B20", GPIO_NUM_22, 5000 }
         }
     };
 
@@ -114,6 +118,40 @@ void test_deserializeConfig(void) {
         TEST_ASSERT_EQUAL(expectedConfig.sensors[i].pin, outputConfig.sensors[i].pin);
         TEST_ASSERT_EQUAL(expectedConfig.sensors[i].interval, outputConfig.sensors[i].interval);
     }
+
+    // The config_hash sent by the garden-app is ignored and computed from the applied config instead
+    TEST_ASSERT_EQUAL_STRING("66c7f6d93b4f75d9", outputConfig.configHash);
+}
+
+void test_computeConfigHash(void) {
+    // This must match the hash in the garden-app's TestControllerConfigMessageHash
+    Config inputConfig = {
+        4, // numZones
+        { GPIO_NUM_4, GPIO_NUM_5, GPIO_NUM_6, GPIO_NUM_7 }, // valvePins
+        { GPIO_NUM_12, GPIO_NUM_13, GPIO_NUM_14, GPIO_NUM_15 }, // pumpPins
+        true, // light
+        GPIO_NUM_2, // lightPin
+        true, // fan
+        GPIO_NUM_22, // fanPin
+        2, // numSensors
+        {
+            { "sensor1", "DHT22", GPIO_NUM_21, 5000 },
+            { "sensor2", "DS18B20", GPIO_NUM_22, 5000 }
+        }
+    };
+
+    computeConfigHash(inputConfig);
+    TEST_ASSERT_EQUAL_STRING("66c7f6d93b4f75d9", inputConfig.configHash);
+
+    // Pins for disabled outputs don't change the hash
+    inputConfig.light = false;
+    computeConfigHash(inputConfig);
+    char withLightPin[17];
+    strlcpy(withLightPin, inputConfig.configHash, sizeof(withLightPin));
+
+    inputConfig.lightPin = GPIO_NUM_0;
+    computeConfigHash(inputConfig);
+    TEST_ASSERT_EQUAL_STRING(withLightPin, inputConfig.configHash);
 }
 
 void setup() {
@@ -123,6 +161,7 @@ void setup() {
     RUN_TEST(test_loadAndSaveConfig);
     RUN_TEST(test_serializeConfig);
     RUN_TEST(test_deserializeConfig);
+    RUN_TEST(test_computeConfigHash);
     UNITY_END();
 }
 